@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/config"
+	"hospital-portal/internal/database"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/services"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <task>\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Tasks:")
+	fmt.Fprintln(os.Stderr, "  contacts   parse free-text addresses and phone numbers, flagging the ones that fail for review")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		usage()
+	}
+	task := os.Args[1]
+
+	// Initialize configuration
+	config.Load()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Can't initialize zap logger: %v", err)
+	}
+	defer logger.Sync()
+
+	db := database.Connect()
+	database.Migrate(db)
+
+	switch task {
+	case "contacts":
+		patientRepo := repositories.NewPatientRepository(db)
+		consentService := services.NewConsentService(repositories.NewConsentRepository(db), patientRepo, logger)
+		webhookService := services.NewWebhookService(repositories.NewWebhookRepository(db), consentService, logger)
+		patientService := services.NewPatientService(patientRepo, repositories.NewContactRepository(db), webhookService, consentService, logger)
+		parsed, flagged, err := patientService.BackfillContactDetails()
+		if err != nil {
+			log.Fatalf("Failed to backfill contact details: %v", err)
+		}
+		log.Printf("Parsed %d patients, flagged %d for contact review", parsed, flagged)
+	default:
+		usage()
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/config"
+	"hospital-portal/internal/database"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/services"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <dataset> [flags] <file>\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Datasets:")
+	fmt.Fprintln(os.Stderr, "  interactions   drug-drug and drug-allergy interactions (CSV or JSON)")
+	fmt.Fprintln(os.Stderr, "  icd10          ICD-10 code table (CSV with code,description or a CMS codes or order .txt file)")
+	fmt.Fprintln(os.Stderr, "  payers         insurance payer catalogue (CSV with code,name,edi_payer_id,member_id_pattern[,claim_filing_code,active])")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	dataset := os.Args[1]
+
+	flags := flag.NewFlagSet(dataset, flag.ExitOnError)
+	source := flags.String("source", "", "name recorded as the source of the entries (defaults to the file name)")
+	replace := flags.Bool("replace", true, "replace existing entries from the same source")
+	flags.Parse(os.Args[2:])
+	if flags.NArg() != 1 {
+		usage()
+	}
+	path := flags.Arg(0)
+
+	// Initialize configuration
+	config.Load()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Can't initialize zap logger: %v", err)
+	}
+	defer logger.Sync()
+
+	db := database.Connect()
+	database.Migrate(db)
+
+	switch dataset {
+	case "interactions":
+		interactionRepo := repositories.NewInteractionRepository(db)
+		interactionService := services.NewInteractionService(interactionRepo, nil, nil, logger)
+		count, err := interactionService.ImportFile(path, *source, *replace)
+		if err != nil {
+			log.Fatalf("Failed to import interactions: %v", err)
+		}
+		log.Printf("Imported %d interactions from %s", count, path)
+	case "icd10":
+		icd10Service := services.NewICD10Service(repositories.NewICD10Repository(db), logger)
+		count, err := icd10Service.ImportFile(path)
+		if err != nil {
+			log.Fatalf("Failed to import ICD-10 codes: %v", err)
+		}
+		log.Printf("Imported %d ICD-10 codes from %s", count, path)
+	case "payers":
+		insuranceService := services.NewInsuranceService(repositories.NewInsuranceRepository(db), nil, nil, logger)
+		count, err := insuranceService.ImportPayersFile(path)
+		if err != nil {
+			log.Fatalf("Failed to import payers: %v", err)
+		}
+		log.Printf("Imported %d payers from %s", count, path)
+	default:
+		usage()
+	}
+}
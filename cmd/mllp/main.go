@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/config"
+	"hospital-portal/internal/database"
+	"hospital-portal/internal/hl7"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/services"
+)
+
+func main() {
+	// Initialize configuration
+	config.Load()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Can't initialize zap logger: %v", err)
+	}
+	defer logger.Sync()
+
+	db := database.Connect()
+	database.Migrate(db)
+
+	patientRepo := repositories.NewPatientRepository(db)
+	consentService := services.NewConsentService(repositories.NewConsentRepository(db), patientRepo, logger)
+	// Events raised here are queued and sent by the API server's dispatcher
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(db), consentService, logger)
+	patientService := services.NewPatientService(patientRepo, repositories.NewContactRepository(db), webhookService, consentService, logger)
+	adtService := services.NewADTService(patientService, patientRepo, repositories.NewHL7Repository(db), logger)
+
+	address := viper.GetString("hl7.mllp.address")
+	if address == "" {
+		address = ":2575" // Default MLLP port
+	}
+	server := &hl7.Server{
+		Addr:           address,
+		Handler:        adtService.HandleMessage,
+		IdleTimeout:    time.Duration(viper.GetInt("hl7.mllp.idle_timeout_seconds")) * time.Second,
+		MaxMessageSize: viper.GetInt("hl7.mllp.max_message_bytes"),
+		Logger:         logger,
+	}
+
+	// Stop on SIGINT or SIGTERM, letting messages being applied finish
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+		logger.Info("Shutting down MLLP listener")
+		server.Close()
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, net.ErrClosed) {
+		logger.Fatal("Failed to start MLLP listener", zap.Error(err))
+	}
+}
@@ -3,82 +3,19 @@ package main
 import (
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
-	"hospital-portal/internal/models"
+	"hospital-portal/internal/config"
+	"hospital-portal/internal/database"
+	"hospital-portal/internal/repositories"
 	"hospital-portal/internal/routes"
+	"hospital-portal/internal/services"
 )
 
-func initConfig() {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./configs")
-	viper.AutomaticEnv()
-
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalf("Error reading config file: %s", err)
-	}
-
-	// Override with environment variables if they exist
-
-	if os.Getenv("PORT") != "" {
-		viper.Set("server.port", os.Getenv("PORT"))
-	}
-
-	if os.Getenv("JWT_SECRET") != "" {
-		viper.Set("auth.jwt_secret", os.Getenv("JWT_SECRET"))
-	}
-}
-
-func initDB() *gorm.DB {
-	var dsn string
-
-	// Check if DATABASE_URL is set
-	if os.Getenv("DATABASE_URL") != "" {
-		// Use the DATABASE_URL directly
-		dsn = os.Getenv("DATABASE_URL")
-	} else {
-		// Construct DSN from individual environment variables or config
-
-		dsn = fmt.Sprintf(
-			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
-			getEnvOrDefault("PGHOST", viper.GetString("database.host")),
-			getEnvOrDefault("PGUSER", viper.GetString("database.user")),
-			getEnvOrDefault("PGPASSWORD", viper.GetString("database.password")),
-			getEnvOrDefault("PGDATABASE", viper.GetString("database.dbname")),
-			getEnvOrDefault("PGPORT", viper.GetString("database.port")),
-		)
-	}
-
-	log.Println("Connecting to database...")
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-
-	// Auto migrate the schema
-	log.Println("Running auto migrations...")
-	err = db.AutoMigrate(&models.User{}, &models.Patient{})
-	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
-	}
-
-	return db
-}
-
-func getEnvOrDefault(env string, defaultValue string) string {
-	if value := os.Getenv(env); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
 func initLogger() *zap.Logger {
 	logger, err := zap.NewProduction()
 	if err != nil {
@@ -87,9 +24,22 @@ func initLogger() *zap.Logger {
 	return logger
 }
 
+// backfillContacts parses the free-text contact details left by migration
+// 0011. Records already parsed or flagged for review are skipped, so this
+// is cheap once the backfill has run.
+func backfillContacts(db *gorm.DB, logger *zap.Logger) {
+	patientRepo := repositories.NewPatientRepository(db)
+	consentService := services.NewConsentService(repositories.NewConsentRepository(db), patientRepo, logger)
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(db), consentService, logger)
+	patientService := services.NewPatientService(patientRepo, repositories.NewContactRepository(db), webhookService, consentService, logger)
+	if _, _, err := patientService.BackfillContactDetails(); err != nil {
+		logger.Fatal("Failed to backfill contact details", zap.Error(err))
+	}
+}
+
 func main() {
 	// Initialize configuration
-	initConfig()
+	config.Load()
 
 	// Initialize logger
 	logger := initLogger()
@@ -97,7 +47,9 @@ func main() {
 
 	// Initialize database connection
 
-	db := initDB()
+	db := database.Connect()
+	database.Migrate(db)
+	backfillContacts(db, logger)
 
 	// Set up Gin
 	gin.SetMode(viper.GetString("server.mode"))
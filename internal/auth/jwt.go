@@ -14,10 +14,24 @@ import (
 type Role string
 
 const (
-	RoleDoctor       Role = "doctor"
-	RoleReceptionist Role = "receptionist"
+	RoleDoctor        Role = "doctor"
+	RoleReceptionist  Role = "receptionist"
+	RoleLabTechnician Role = "lab_technician"
+	RoleBilling       Role = "billing"
+	RoleNurse         Role = "nurse"
+	RoleAdmin         Role = "admin" // manages integrations; cannot be self-registered
 )
 
+// ParseRole converts a stored role name into a Role
+func ParseRole(name string) (Role, bool) {
+	switch role := Role(name); role {
+	case RoleDoctor, RoleReceptionist, RoleLabTechnician, RoleBilling, RoleNurse, RoleAdmin:
+		return role, true
+	default:
+		return "", false
+	}
+}
+
 // Claims represents the JWT claims
 type Claims struct {
 	UserID uint   `json:"user_id"`
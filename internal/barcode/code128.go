@@ -0,0 +1,98 @@
+// Package barcode encodes text as linear barcodes for printing on labels
+package barcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+// QuietZone is the blank margin, in modules, a scanner needs on either side
+// of a Code 128 symbol
+const QuietZone = 10
+
+// code128Patterns are the bar and space widths of each Code 128 symbol
+// value, starting with a bar. 103 to 105 are the start codes for sets A, B
+// and C; 106 is the stop code.
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code 128 special values
+const (
+	code128CodeB  = 100
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 encodes printable ASCII text as a Code 128 symbol and returns the
+// widths of its bars and spaces in modules, starting with a bar, without
+// quiet zones. Text made only of digits is packed two to a symbol with code
+// set C; anything else is encoded in set B throughout.
+func Code128(text string) ([]int, error) {
+	if text == "" {
+		return nil, errors.New("barcode: nothing to encode")
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < 32 || text[i] > 126 {
+			return nil, fmt.Errorf("barcode: %q cannot be encoded in Code 128", text[i])
+		}
+	}
+
+	// All-digit text uses set C up to an odd one out, which is finished in
+	// set B
+	digits := 0
+	for digits < len(text) && text[digits] >= '0' && text[digits] <= '9' {
+		digits++
+	}
+	var values []int
+	rest := text
+	if digits == len(text) && digits >= 2 {
+		values = append(values, code128StartC)
+		for i := 0; i+1 < digits; i += 2 {
+			values = append(values, int(text[i]-'0')*10+int(text[i+1]-'0'))
+		}
+		rest = text[digits-digits%2:]
+		if rest != "" {
+			values = append(values, code128CodeB)
+		}
+	} else {
+		values = append(values, code128StartB)
+	}
+	for i := 0; i < len(rest); i++ {
+		values = append(values, int(rest[i])-32)
+	}
+
+	checksum := values[0]
+	for i, value := range values[1:] {
+		checksum += (i + 1) * value
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var widths []int
+	for _, value := range values {
+		for _, width := range code128Patterns[value] {
+			widths = append(widths, int(width-'0'))
+		}
+	}
+	return widths, nil
+}
+
+// Modules returns the total width of a symbol in modules
+func Modules(widths []int) int {
+	total := 0
+	for _, width := range widths {
+		total += width
+	}
+	return total
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// Load reads configs/config.yaml and applies environment overrides
+func Load() {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("./configs")
+	viper.AutomaticEnv()
+
+	if err := viper.ReadInConfig(); err != nil {
+		log.Fatalf("Error reading config file: %s", err)
+	}
+
+	// Override with environment variables if they exist
+
+	if os.Getenv("PORT") != "" {
+		viper.Set("server.port", os.Getenv("PORT"))
+	}
+
+	if os.Getenv("JWT_SECRET") != "" {
+		viper.Set("auth.jwt_secret", os.Getenv("JWT_SECRET"))
+	}
+
+	if os.Getenv("STORAGE_DRIVER") != "" {
+		viper.Set("storage.driver", os.Getenv("STORAGE_DRIVER"))
+	}
+
+	if os.Getenv("S3_ENDPOINT") != "" {
+		viper.Set("storage.s3.endpoint", os.Getenv("S3_ENDPOINT"))
+	}
+
+	if os.Getenv("S3_ACCESS_KEY") != "" {
+		viper.Set("storage.s3.access_key", os.Getenv("S3_ACCESS_KEY"))
+	}
+
+	if os.Getenv("S3_SECRET_KEY") != "" {
+		viper.Set("storage.s3.secret_key", os.Getenv("S3_SECRET_KEY"))
+	}
+
+	if os.Getenv("RESEARCH_PSEUDONYM_KEY") != "" {
+		viper.Set("research.pseudonym_key", os.Getenv("RESEARCH_PSEUDONYM_KEY"))
+	}
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// AdmissionController handles ward, bed and admission related requests
+type AdmissionController struct {
+	admissionService *services.AdmissionService
+	logger           *zap.Logger
+}
+
+// NewAdmissionController creates a new admission controller instance
+func NewAdmissionController(admissionService *services.AdmissionService, logger *zap.Logger) *AdmissionController {
+	return &AdmissionController{
+		admissionService: admissionService,
+		logger:           logger,
+	}
+}
+
+// WardRequest represents the ward request body
+type WardRequest struct {
+	Code  string `json:"code" binding:"required"`
+	Name  string `json:"name" binding:"required"`
+	Floor string `json:"floor"`
+}
+
+// RoomRequest represents the room request body
+type RoomRequest struct {
+	Number string `json:"number" binding:"required"`
+}
+
+// BedRequest represents the bed request body
+type BedRequest struct {
+	Label  string `json:"label" binding:"required"`
+	Status string `json:"status" binding:"omitempty,oneof=available cleaning blocked"`
+}
+
+// BedStatusRequest represents the bed status request body
+type BedStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=available cleaning blocked"`
+	Note   string `json:"note"`
+}
+
+// AdmissionRequest represents the admission request body
+type AdmissionRequest struct {
+	BedID             uint       `json:"bed_id" binding:"required"`
+	AttendingDoctorID uint       `json:"attending_doctor_id" binding:"required"`
+	Reason            string     `json:"reason"`
+	AdmittedAt        *time.Time `json:"admitted_at"`
+}
+
+// TransferRequest represents the bed transfer request body
+type TransferRequest struct {
+	BedID  uint   `json:"bed_id" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// DischargeRequest represents the discharge request body
+type DischargeRequest struct {
+	Disposition  string     `json:"disposition" binding:"required,oneof=home home_care transfer against_advice deceased other"`
+	DischargedAt *time.Time `json:"discharged_at"`
+}
+
+// CreateWard handles creating a ward
+func (c *AdmissionController) CreateWard(ctx *gin.Context) {
+	var req WardRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid ward request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	ward, err := c.admissionService.CreateWard(&models.Ward{Code: req.Code, Name: req.Name, Floor: req.Floor})
+	if err != nil {
+		c.logger.Error("Failed to create ward", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to create ward", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Ward created successfully",
+		"ward":    ward,
+	})
+}
+
+// CreateRoom handles adding a room to a ward
+func (c *AdmissionController) CreateRoom(ctx *gin.Context) {
+	wardID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ward ID", err)
+		return
+	}
+
+	var req RoomRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid room request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	room, err := c.admissionService.CreateRoom(wardID, &models.Room{Number: req.Number})
+	if err != nil {
+		c.logger.Error("Failed to create room", zap.Error(err), zap.Uint("ward_id", wardID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to create room", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Room created successfully",
+		"room":    room,
+	})
+}
+
+// CreateBed handles adding a bed to a room
+func (c *AdmissionController) CreateBed(ctx *gin.Context) {
+	roomID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID", err)
+		return
+	}
+
+	var req BedRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid bed request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	bed, err := c.admissionService.CreateBed(roomID, &models.Bed{Label: req.Label, Status: req.Status})
+	if err != nil {
+		c.logger.Error("Failed to create bed", zap.Error(err), zap.Uint("room_id", roomID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to create bed", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Bed created successfully",
+		"bed":     bed,
+	})
+}
+
+// SetBedStatus handles changing the housekeeping status of a bed
+func (c *AdmissionController) SetBedStatus(ctx *gin.Context) {
+	bedID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid bed ID", err)
+		return
+	}
+
+	var req BedStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid bed status request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	bed, err := c.admissionService.SetBedStatus(bedID, req.Status, req.Note)
+	if err != nil {
+		c.logger.Error("Failed to update bed status", zap.Error(err), zap.Uint("bed_id", bedID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update bed status", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Bed status updated successfully",
+		"bed":     bed,
+	})
+}
+
+// GetBedBoard handles the live bed board, optionally for one ward_id
+func (c *AdmissionController) GetBedBoard(ctx *gin.Context) {
+	var wardID uint
+	if value := ctx.Query("ward_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid ward ID", err)
+			return
+		}
+		wardID = uint(id)
+	}
+
+	wards, err := c.admissionService.GetBedBoard(wardID)
+	if err != nil {
+		c.logger.Error("Failed to build bed board", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to build bed board", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"wards": wards,
+	})
+}
+
+// Admit handles admitting a patient to a bed
+func (c *AdmissionController) Admit(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req AdmissionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid admission request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	admission, err := c.admissionService.Admit(patientID, services.AdmissionInput{
+		BedID:             req.BedID,
+		AttendingDoctorID: req.AttendingDoctorID,
+		Reason:            req.Reason,
+		AdmittedAt:        req.AdmittedAt,
+	}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to admit patient", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to admit patient", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":   "Patient admitted successfully",
+		"admission": admission,
+	})
+}
+
+// GetPatientAdmissions handles listing a patient's admissions
+func (c *AdmissionController) GetPatientAdmissions(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	admissions, err := c.admissionService.GetPatientAdmissions(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch admissions", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch admissions", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"admissions": admissions,
+	})
+}
+
+// GetAdmission handles retrieving an admission with its bed movements
+func (c *AdmissionController) GetAdmission(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	admission, err := c.admissionService.GetAdmission(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Admission not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"admission": admission,
+	})
+}
+
+// Transfer handles moving an admitted patient to another bed
+func (c *AdmissionController) Transfer(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	var req TransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid transfer request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	admission, err := c.admissionService.Transfer(id, services.TransferInput{BedID: req.BedID, Reason: req.Reason}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to transfer patient", zap.Error(err), zap.Uint("admission_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to transfer patient", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Patient transferred successfully",
+		"admission": admission,
+	})
+}
+
+// Discharge handles discharging an admitted patient
+func (c *AdmissionController) Discharge(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	var req DischargeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid discharge request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	admission, err := c.admissionService.Discharge(id, services.DischargeInput{
+		Disposition:  req.Disposition,
+		DischargedAt: req.DischargedAt,
+	}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to discharge patient", zap.Error(err), zap.Uint("admission_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to discharge patient", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Patient discharged successfully",
+		"admission": admission,
+	})
+}
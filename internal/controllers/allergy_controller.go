@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// AllergyController handles allergy related requests
+type AllergyController struct {
+	allergyService *services.AllergyService
+	logger         *zap.Logger
+}
+
+// NewAllergyController creates a new allergy controller instance
+func NewAllergyController(allergyService *services.AllergyService, logger *zap.Logger) *AllergyController {
+	return &AllergyController{
+		allergyService: allergyService,
+		logger:         logger,
+	}
+}
+
+// AllergyRequest represents the allergy request body
+type AllergyRequest struct {
+	Substance     string `json:"substance" binding:"required"`
+	SubstanceCode string `json:"substance_code"`
+	CodeSystem    string `json:"code_system"`
+	Category      string `json:"category" binding:"required,oneof=drug food environment other"`
+	Reaction      string `json:"reaction"`
+	Severity      string `json:"severity" binding:"omitempty,oneof=mild moderate severe life_threatening"`
+	Status        string `json:"status" binding:"omitempty,oneof=active inactive resolved entered_in_error"`
+	Notes         string `json:"notes"`
+}
+
+// AllergyUpdateRequest represents the allergy update request body. An NKDA
+// statement has no substance or category, so only status and notes are
+// read for one; other allergies need substance and category as on create.
+type AllergyUpdateRequest struct {
+	Substance     string `json:"substance"`
+	SubstanceCode string `json:"substance_code"`
+	CodeSystem    string `json:"code_system"`
+	Category      string `json:"category" binding:"omitempty,oneof=drug food environment other"`
+	Reaction      string `json:"reaction"`
+	Severity      string `json:"severity" binding:"omitempty,oneof=mild moderate severe life_threatening"`
+	Status        string `json:"status" binding:"omitempty,oneof=active inactive resolved entered_in_error"`
+	Notes         string `json:"notes"`
+}
+
+// NKDARequest represents the no-known-drug-allergies request body
+type NKDARequest struct {
+	Notes string `json:"notes"`
+}
+
+// GetAllergies handles listing a patient's allergies
+func (c *AllergyController) GetAllergies(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	allergies, err := c.allergyService.GetPatientAllergies(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch allergies", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to fetch allergies", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"allergies": allergies,
+	})
+}
+
+// CreateAllergy handles recording a new allergy
+func (c *AllergyController) CreateAllergy(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req AllergyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid allergy create request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	allergy, err := c.allergyService.RecordAllergy(patientID, req.toModel(), currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to record allergy", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to record allergy", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Allergy recorded successfully",
+		"allergy": allergy,
+	})
+}
+
+// RecordNKDA handles recording that a patient has no known drug allergies
+func (c *AllergyController) RecordNKDA(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req NKDARequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+			return
+		}
+	}
+
+	allergy, err := c.allergyService.RecordNKDA(patientID, req.Notes, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to record NKDA", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to record NKDA", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "No known drug allergies recorded",
+		"allergy": allergy,
+	})
+}
+
+// UpdateAllergy handles updating an allergy record
+func (c *AllergyController) UpdateAllergy(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	allergyID, err := parseIDParam(ctx, "allergyId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid allergy ID", err)
+		return
+	}
+
+	var req AllergyUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid allergy update request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	fields := AllergyRequest(req)
+	allergy := fields.toModel()
+	allergy.ID = allergyID
+
+	updated, err := c.allergyService.UpdateAllergy(patientID, allergy)
+	if err != nil {
+		c.logger.Error("Failed to update allergy", zap.Error(err), zap.Uint("allergy_id", allergyID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update allergy", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Allergy updated successfully",
+		"allergy": updated,
+	})
+}
+
+// DeleteAllergy handles deleting an allergy record
+func (c *AllergyController) DeleteAllergy(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	allergyID, err := parseIDParam(ctx, "allergyId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid allergy ID", err)
+		return
+	}
+
+	if err := c.allergyService.DeleteAllergy(patientID, allergyID); err != nil {
+		c.logger.Error("Failed to delete allergy", zap.Error(err), zap.Uint("allergy_id", allergyID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to delete allergy", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Allergy deleted successfully",
+	})
+}
+
+func (req *AllergyRequest) toModel() *models.Allergy {
+	return &models.Allergy{
+		Substance:     req.Substance,
+		SubstanceCode: req.SubstanceCode,
+		CodeSystem:    req.CodeSystem,
+		Category:      req.Category,
+		Reaction:      req.Reaction,
+		Severity:      req.Severity,
+		Status:        req.Status,
+		Notes:         req.Notes,
+	}
+}
@@ -55,12 +55,8 @@ func (c *AuthController) Login(ctx *gin.Context) {
 	}
 
 	// Determine the role
-	var role auth.Role
-	if user.Role == "doctor" {
-		role = auth.RoleDoctor
-	} else if user.Role == "receptionist" {
-		role = auth.RoleReceptionist
-	} else {
+	role, ok := auth.ParseRole(user.Role)
+	if !ok {
 		c.logger.Error("Invalid user role", zap.String("role", user.Role))
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Invalid user role", nil)
 		return
@@ -88,7 +84,7 @@ type RegisterRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role" binding:"required,oneof=doctor receptionist"`
+	Role     string `json:"role" binding:"required,oneof=doctor receptionist lab_technician billing nurse"`
 }
 
 // Register handles user registration
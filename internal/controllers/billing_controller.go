@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// BillingController handles charge master, charge, invoice and payment requests
+type BillingController struct {
+	billingService *services.BillingService
+	logger         *zap.Logger
+}
+
+// NewBillingController creates a new billing controller instance
+func NewBillingController(billingService *services.BillingService, logger *zap.Logger) *BillingController {
+	return &BillingController{
+		billingService: billingService,
+		logger:         logger,
+	}
+}
+
+// ChargeItemRequest represents the charge master item request body.
+// Prices are in cents.
+type ChargeItemRequest struct {
+	Description string `json:"description" binding:"required"`
+	CPTCode     string `json:"cpt_code"`
+	Price       int64  `json:"price" binding:"min=0"`
+	Taxable     bool   `json:"taxable"`
+	Active      *bool  `json:"active"`
+}
+
+// ChargeRequest represents the charge request body
+type ChargeRequest struct {
+	ServiceCode string `json:"service_code" binding:"required"`
+	Quantity    int    `json:"quantity" binding:"omitempty,min=1"`
+	ServiceDate string `json:"service_date"` // YYYY-MM-DD, defaults to the encounter start
+}
+
+// VoidRequest represents the request body for voiding a charge
+type VoidRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// InvoiceRequest represents the invoice generation request body
+type InvoiceRequest struct {
+	ChargeIDs       []uint  `json:"charge_ids"`
+	DiscountPercent float64 `json:"discount_percent" binding:"min=0,max=100"`
+}
+
+// PaymentRequest represents the payment, refund and adjustment request
+// body. Amounts are in cents.
+type PaymentRequest struct {
+	Method     string `json:"method" binding:"required,oneof=cash card check insurance other"`
+	Amount     int64  `json:"amount" binding:"required,min=1"`
+	Reference  string `json:"reference"`
+	Notes      string `json:"notes"`
+	ReceivedAt string `json:"received_at"` // RFC 3339, defaults to now
+}
+
+// GetChargeMaster handles listing the charge master
+func (c *BillingController) GetChargeMaster(ctx *gin.Context) {
+	items, err := c.billingService.GetChargeMaster(ctx.Query("include_inactive") == "true")
+	if err != nil {
+		c.logger.Error("Failed to fetch charge master", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch charge master", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"items": items,
+	})
+}
+
+// SaveChargeItem handles creating or updating a charge master item
+func (c *BillingController) SaveChargeItem(ctx *gin.Context) {
+	var req ChargeItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	item := &models.ChargeMasterItem{
+		Code:        ctx.Param("code"),
+		Description: req.Description,
+		CPTCode:     req.CPTCode,
+		Price:       req.Price,
+		Taxable:     req.Taxable,
+		Active:      req.Active == nil || *req.Active,
+	}
+	saved, err := c.billingService.SaveChargeItem(item)
+	if err != nil {
+		c.logger.Error("Failed to save charge master item", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to save charge master item", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Charge master item saved successfully",
+		"item":    saved,
+	})
+}
+
+// GetEncounterCharges handles listing the charges of an encounter
+func (c *BillingController) GetEncounterCharges(ctx *gin.Context) {
+	encounterID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	charges, err := c.billingService.GetEncounterCharges(encounterID)
+	if err != nil {
+		c.logger.Error("Failed to fetch charges", zap.Error(err), zap.Uint("encounter_id", encounterID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch charges", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"charges": charges,
+	})
+}
+
+// PostCharge handles posting a charge against an encounter
+func (c *BillingController) PostCharge(ctx *gin.Context) {
+	encounterID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	var req ChargeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	serviceDate, err := parseOptionalDate(req.ServiceDate)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	charge, err := c.billingService.PostCharge(encounterID, req.ServiceCode, req.Quantity, serviceDate, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to post charge", zap.Error(err), zap.Uint("encounter_id", encounterID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to post charge", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Charge posted successfully",
+		"charge":  charge,
+	})
+}
+
+// VoidCharge handles voiding a charge posted in error
+func (c *BillingController) VoidCharge(ctx *gin.Context) {
+	chargeID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid charge ID", err)
+		return
+	}
+
+	var req VoidRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	charge, err := c.billingService.VoidCharge(chargeID, req.Reason)
+	if err != nil {
+		c.logger.Error("Failed to void charge", zap.Error(err), zap.Uint("charge_id", chargeID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to void charge", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Charge voided successfully",
+		"charge":  charge,
+	})
+}
+
+// GetPatientInvoices handles listing a patient's invoices
+func (c *BillingController) GetPatientInvoices(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	invoices, err := c.billingService.GetPatientInvoices(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch invoices", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch invoices", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"invoices": invoices,
+	})
+}
+
+// GenerateInvoice handles invoicing a patient's uninvoiced charges
+func (c *BillingController) GenerateInvoice(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req InvoiceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	invoice, err := c.billingService.GenerateInvoice(patientID, services.InvoiceOptions{
+		ChargeIDs:       req.ChargeIDs,
+		DiscountPercent: req.DiscountPercent,
+	}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to generate invoice", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to generate invoice", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Invoice generated successfully",
+		"invoice": invoice,
+	})
+}
+
+// GetPatientBalance handles computing a patient's outstanding balance
+func (c *BillingController) GetPatientBalance(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	balance, err := c.billingService.GetPatientBalance(patientID)
+	if err != nil {
+		c.logger.Error("Failed to compute balance", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to compute balance", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"balance": balance,
+	})
+}
+
+// GetInvoice handles retrieving an invoice
+func (c *BillingController) GetInvoice(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid invoice ID", err)
+		return
+	}
+
+	invoice, err := c.billingService.GetInvoice(id)
+	if err != nil {
+		c.logger.Error("Failed to fetch invoice", zap.Error(err), zap.Uint("invoice_id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Invoice not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"invoice": invoice,
+	})
+}
+
+// VoidInvoice handles voiding an unpaid invoice
+func (c *BillingController) VoidInvoice(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid invoice ID", err)
+		return
+	}
+
+	invoice, err := c.billingService.VoidInvoice(id)
+	if err != nil {
+		c.logger.Error("Failed to void invoice", zap.Error(err), zap.Uint("invoice_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to void invoice", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Invoice voided successfully",
+		"invoice": invoice,
+	})
+}
+
+// RecordPayment handles recording a payment against an invoice
+func (c *BillingController) RecordPayment(ctx *gin.Context) {
+	c.recordPayment(ctx, models.PaymentKindPayment)
+}
+
+// RecordRefund handles recording a refund against an invoice
+func (c *BillingController) RecordRefund(ctx *gin.Context) {
+	c.recordPayment(ctx, models.PaymentKindRefund)
+}
+
+// RecordAdjustment handles writing off part of an invoice
+func (c *BillingController) RecordAdjustment(ctx *gin.Context) {
+	c.recordPayment(ctx, models.PaymentKindAdjustment)
+}
+
+func (c *BillingController) recordPayment(ctx *gin.Context, kind string) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid invoice ID", err)
+		return
+	}
+
+	var req PaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	var receivedAt time.Time
+	if req.ReceivedAt != "" {
+		if receivedAt, err = time.Parse(time.RFC3339, req.ReceivedAt); err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "received_at must be an RFC 3339 timestamp", err)
+			return
+		}
+	}
+
+	invoice, err := c.billingService.RecordPayment(id, services.PaymentInput{
+		Kind:       kind,
+		Method:     req.Method,
+		Amount:     req.Amount,
+		Reference:  req.Reference,
+		Notes:      req.Notes,
+		ReceivedAt: receivedAt,
+	}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to record "+kind, zap.Error(err), zap.Uint("invoice_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to record "+kind, err)
+		return
+	}
+
+	message := "Payment recorded successfully"
+	switch kind {
+	case models.PaymentKindRefund:
+		message = "Refund recorded successfully"
+	case models.PaymentKindAdjustment:
+		message = "Adjustment recorded successfully"
+	}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": message,
+		"invoice": invoice,
+	})
+}
+
+// GetCollectionsReport handles the daily collections report. Without
+// dates it covers today.
+func (c *BillingController) GetCollectionsReport(ctx *gin.Context) {
+	from, err := parseTimeQuery(ctx, "from", false)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid from date", err)
+		return
+	}
+	to, err := parseTimeQuery(ctx, "to", false)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid to date", err)
+		return
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = from
+	}
+
+	report, err := c.billingService.GetCollectionsReport(from, to)
+	if err != nil {
+		c.logger.Error("Failed to build collections report", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to build collections report", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"report": report,
+	})
+}
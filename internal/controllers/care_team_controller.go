@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// CareTeamController handles care team related requests
+type CareTeamController struct {
+	careTeamService *services.CareTeamService
+	logger          *zap.Logger
+}
+
+// NewCareTeamController creates a new care team controller instance
+func NewCareTeamController(careTeamService *services.CareTeamService, logger *zap.Logger) *CareTeamController {
+	return &CareTeamController{
+		careTeamService: careTeamService,
+		logger:          logger,
+	}
+}
+
+// CareTeamRequest represents the care team member request body
+type CareTeamRequest struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Role   string `json:"role" binding:"required,oneof=attending consulting nursing other"`
+}
+
+// GetCareTeam handles listing a patient's care team
+func (c *CareTeamController) GetCareTeam(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	members, err := c.careTeamService.GetCareTeam(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch care team", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch care team", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"care_team": members,
+	})
+}
+
+// AddMember handles adding a user to a patient's care team
+func (c *CareTeamController) AddMember(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req CareTeamRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	member, err := c.careTeamService.AddMember(patientID, req.UserID, req.Role, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to add care team member", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to add care team member", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Care team member added successfully",
+		"member":  member,
+	})
+}
+
+// RemoveMember handles removing a user from a patient's care team
+func (c *CareTeamController) RemoveMember(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	userID, err := parseIDParam(ctx, "userId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	if err := c.careTeamService.RemoveMember(patientID, userID); err != nil {
+		c.logger.Error("Failed to remove care team member", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to remove care team member", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Care team member removed successfully",
+	})
+}
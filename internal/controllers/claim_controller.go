@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// ClaimController handles insurance claim and remittance requests
+type ClaimController struct {
+	claimService *services.ClaimService
+	logger       *zap.Logger
+}
+
+// NewClaimController creates a new claim controller instance
+func NewClaimController(claimService *services.ClaimService, logger *zap.Logger) *ClaimController {
+	return &ClaimController{
+		claimService: claimService,
+		logger:       logger,
+	}
+}
+
+// ClaimBatchRequest represents the claim batch request body
+type ClaimBatchRequest struct {
+	EncounterIDs []uint `json:"encounter_ids" binding:"required,min=1"`
+}
+
+// CreateClaimBatch handles generating an 837P file for finished encounters
+func (c *ClaimController) CreateClaimBatch(ctx *gin.Context) {
+	var req ClaimBatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid claim batch request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	batch, err := c.claimService.GenerateBatch(ctx.Request.Context(), req.EncounterIDs, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to generate claim batch", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to generate claim batch", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Claim batch generated successfully",
+		"batch":   batch,
+	})
+}
+
+// GetClaimBatch handles retrieving a claim batch with its claims
+func (c *ClaimController) GetClaimBatch(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid batch ID", err)
+		return
+	}
+
+	batch, err := c.claimService.GetBatch(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Claim batch not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"batch": batch,
+	})
+}
+
+// DownloadClaimBatch handles downloading the 837P file of a batch
+func (c *ClaimController) DownloadClaimBatch(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid batch ID", err)
+		return
+	}
+
+	batch, body, err := c.claimService.OpenBatchFile(ctx.Request.Context(), id)
+	if err != nil {
+		c.logger.Error("Failed to open claim file", zap.Error(err), zap.Uint("batch_id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to open claim file", err)
+		return
+	}
+	defer body.Close()
+
+	ctx.Header("Content-Type", "application/edi-x12")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", batch.FileName))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, body); err != nil {
+		c.logger.Warn("Claim file download interrupted", zap.Error(err), zap.Uint("batch_id", id))
+	}
+}
+
+// GetClaims handles listing claims, filtered by status and patient_id
+func (c *ClaimController) GetClaims(ctx *gin.Context) {
+	var patientID uint
+	if value := ctx.Query("patient_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+			return
+		}
+		patientID = uint(id)
+	}
+
+	claims, err := c.claimService.GetClaims(ctx.Query("status"), patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch claims", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch claims", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"claims": claims,
+	})
+}
+
+// ImportRemittance handles uploading an 835 remittance file
+func (c *ClaimController) ImportRemittance(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, services.MaxRemittanceSize+1<<20)
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "File too large", err)
+			return
+		}
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "A file field is required", err)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, services.MaxRemittanceSize+1))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Failed to read file", err)
+		return
+	}
+	if len(content) > services.MaxRemittanceSize {
+		utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "File too large", errors.New("remittance file exceeds the size limit"))
+		return
+	}
+
+	result, err := c.claimService.ImportRemittance(ctx.Request.Context(), header.Filename, content, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to import remittance", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to import remittance", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":     "Remittance imported successfully",
+		"remittances": result.Remittances,
+		"postings":    result.Postings,
+	})
+}
+
+// GetRemittance handles retrieving a remittance with its claims and adjustments
+func (c *ClaimController) GetRemittance(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid remittance ID", err)
+		return
+	}
+
+	remittance, claims, adjustments, err := c.claimService.GetRemittance(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Remittance not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"remittance":  remittance,
+		"claims":      claims,
+		"adjustments": adjustments,
+	})
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// ConsentController handles consent, patient contact and research extract requests
+type ConsentController struct {
+	consentService  *services.ConsentService
+	contactService  *services.ContactService
+	researchService *services.ResearchService
+	logger          *zap.Logger
+}
+
+// NewConsentController creates a new consent controller instance
+func NewConsentController(consentService *services.ConsentService, contactService *services.ContactService, researchService *services.ResearchService, logger *zap.Logger) *ConsentController {
+	return &ConsentController{
+		consentService:  consentService,
+		contactService:  contactService,
+		researchService: researchService,
+		logger:          logger,
+	}
+}
+
+// ConsentRequest represents the consent request body
+type ConsentRequest struct {
+	Type          string `json:"type" binding:"required,oneof=data_sharing research sms_contact treatment"`
+	Version       string `json:"version"`
+	WitnessName   string `json:"witness_name"`
+	WitnessUserID *uint  `json:"witness_user_id"`
+	DocumentID    *uint  `json:"document_id"`
+	Notes         string `json:"notes"`
+}
+
+// MessageRequest represents the patient message request body
+type MessageRequest struct {
+	Message string `json:"message" binding:"required,max=640"`
+}
+
+func (r ConsentRequest) input() services.ConsentInput {
+	return services.ConsentInput{
+		Type:          r.Type,
+		Version:       r.Version,
+		WitnessName:   r.WitnessName,
+		WitnessUserID: r.WitnessUserID,
+		DocumentID:    r.DocumentID,
+		Notes:         r.Notes,
+	}
+}
+
+// GetConsents handles listing a patient's consents
+func (c *ConsentController) GetConsents(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	history, current, err := c.consentService.GetConsents(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch consents", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch consents", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"current":  current,
+		"consents": history,
+	})
+}
+
+// GrantConsent handles recording a granted consent
+func (c *ConsentController) GrantConsent(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req ConsentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	consent, err := c.consentService.Grant(patientID, req.input(), currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to record consent", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to record consent", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Consent recorded successfully",
+		"consent": consent,
+	})
+}
+
+// WithdrawConsent handles recording a withdrawn or refused consent
+func (c *ConsentController) WithdrawConsent(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req ConsentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	consent, err := c.consentService.Withdraw(patientID, req.input(), currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to withdraw consent", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to withdraw consent", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Consent withdrawn successfully",
+		"consent": consent,
+	})
+}
+
+// SendMessage handles texting a patient who consented to SMS contact
+func (c *ConsentController) SendMessage(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req MessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	if err := c.contactService.SendSMS(patientID, req.Message); err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to send message", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Message sent successfully",
+	})
+}
+
+// GetResearchExtract handles generating a de-identified research extract
+func (c *ConsentController) GetResearchExtract(ctx *gin.Context) {
+	records, err := c.researchService.Extract()
+	if err != nil {
+		c.logger.Error("Failed to generate research extract", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to generate research extract", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"count":    len(records),
+		"patients": records,
+	})
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// ContactController handles guardian, next of kin and emergency contact requests
+type ContactController struct {
+	contactService *services.PatientContactService
+	logger         *zap.Logger
+}
+
+// NewContactController creates a new contact controller instance
+func NewContactController(contactService *services.PatientContactService, logger *zap.Logger) *ContactController {
+	return &ContactController{
+		contactService: contactService,
+		logger:         logger,
+	}
+}
+
+// ContactRequest represents the patient contact request body
+type ContactRequest struct {
+	Name                  string `json:"name" binding:"required"`
+	Relationship          string `json:"relationship" binding:"required"`
+	PhoneNumber           string `json:"phone_number" binding:"required"`
+	Address               string `json:"address"`
+	IsGuardian            bool   `json:"is_guardian"`
+	IsNextOfKin           bool   `json:"is_next_of_kin"`
+	IsEmergencyContact    bool   `json:"is_emergency_contact"`
+	MayReceiveInformation bool   `json:"may_receive_information"`
+	Notes                 string `json:"notes"`
+}
+
+func (r ContactRequest) toModel() models.PatientContact {
+	return models.PatientContact{
+		Name:                  r.Name,
+		Relationship:          r.Relationship,
+		PhoneNumber:           r.PhoneNumber,
+		Address:               r.Address,
+		IsGuardian:            r.IsGuardian,
+		IsNextOfKin:           r.IsNextOfKin,
+		IsEmergencyContact:    r.IsEmergencyContact,
+		MayReceiveInformation: r.MayReceiveInformation,
+		Notes:                 r.Notes,
+	}
+}
+
+// GetContacts handles listing a patient's contacts
+func (c *ContactController) GetContacts(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	contacts, err := c.contactService.GetContacts(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch contacts", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch contacts", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"contacts": contacts,
+	})
+}
+
+// CreateContact handles adding a contact to a patient
+func (c *ContactController) CreateContact(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req ContactRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	contact := req.toModel()
+	created, err := c.contactService.AddContact(patientID, &contact)
+	if err != nil {
+		c.logger.Error("Failed to add contact", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to add contact", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Contact added successfully",
+		"contact": created,
+	})
+}
+
+// UpdateContact handles updating a patient's contact
+func (c *ContactController) UpdateContact(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	contactID, err := parseIDParam(ctx, "contactId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid contact ID", err)
+		return
+	}
+
+	var req ContactRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	contact := req.toModel()
+	updated, err := c.contactService.UpdateContact(patientID, contactID, &contact)
+	if err != nil {
+		c.logger.Error("Failed to update contact", zap.Error(err), zap.Uint("contact_id", contactID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update contact", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Contact updated successfully",
+		"contact": updated,
+	})
+}
+
+// DeleteContact handles removing a patient's contact
+func (c *ContactController) DeleteContact(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	contactID, err := parseIDParam(ctx, "contactId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid contact ID", err)
+		return
+	}
+
+	if err := c.contactService.DeleteContact(patientID, contactID); err != nil {
+		c.logger.Error("Failed to delete contact", zap.Error(err), zap.Uint("contact_id", contactID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to delete contact", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Contact deleted successfully",
+	})
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// DischargeSummaryController handles discharge summary requests
+type DischargeSummaryController struct {
+	summaryService *services.DischargeSummaryService
+	logger         *zap.Logger
+}
+
+// NewDischargeSummaryController creates a new discharge summary controller instance
+func NewDischargeSummaryController(summaryService *services.DischargeSummaryService, logger *zap.Logger) *DischargeSummaryController {
+	return &DischargeSummaryController{
+		summaryService: summaryService,
+		logger:         logger,
+	}
+}
+
+// SummaryItemRequest represents a diagnosis, procedure or medication on a summary
+type SummaryItemRequest struct {
+	Section     string `json:"section" binding:"required,oneof=diagnosis procedure medication"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Detail      string `json:"detail"`
+}
+
+// DischargeSummaryRequest represents the discharge summary edit body.
+// Omitted fields are left unchanged; items, when sent, replace them all.
+type DischargeSummaryRequest struct {
+	HospitalCourse *string              `json:"hospital_course"`
+	FollowUp       *string              `json:"follow_up"`
+	Items          []SummaryItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+// GetSummary handles retrieving the discharge summary of an admission
+func (c *DischargeSummaryController) GetSummary(ctx *gin.Context) {
+	admissionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	summary, err := c.summaryService.GetSummary(admissionID)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Discharge summary not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"summary": summary,
+	})
+}
+
+// DraftSummary handles (re)drafting a summary from the stay's records
+func (c *DischargeSummaryController) DraftSummary(ctx *gin.Context) {
+	admissionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	summary, err := c.summaryService.Draft(admissionID, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to draft discharge summary", zap.Error(err), zap.Uint("admission_id", admissionID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to draft discharge summary", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Discharge summary drafted successfully",
+		"summary": summary,
+	})
+}
+
+// UpdateSummary handles editing an unsigned summary
+func (c *DischargeSummaryController) UpdateSummary(ctx *gin.Context) {
+	admissionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	var req DischargeSummaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid discharge summary request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	edit := services.DischargeSummaryEdit{
+		HospitalCourse: req.HospitalCourse,
+		FollowUp:       req.FollowUp,
+	}
+	if req.Items != nil {
+		edit.Items = make([]models.DischargeSummaryItem, 0, len(req.Items))
+		for _, item := range req.Items {
+			edit.Items = append(edit.Items, models.DischargeSummaryItem{
+				Section:     item.Section,
+				Code:        item.Code,
+				Description: item.Description,
+				Detail:      item.Detail,
+			})
+		}
+	}
+
+	summary, err := c.summaryService.Edit(admissionID, edit)
+	if err != nil {
+		c.logger.Error("Failed to update discharge summary", zap.Error(err), zap.Uint("admission_id", admissionID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update discharge summary", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Discharge summary updated successfully",
+		"summary": summary,
+	})
+}
+
+// SignSummary handles signing and freezing a summary
+func (c *DischargeSummaryController) SignSummary(ctx *gin.Context) {
+	admissionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	summary, err := c.summaryService.Sign(admissionID, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to sign discharge summary", zap.Error(err), zap.Uint("admission_id", admissionID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to sign discharge summary", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Discharge summary signed successfully",
+		"summary": summary,
+	})
+}
+
+// DownloadSummaryPDF handles exporting a signed summary as PDF
+func (c *DischargeSummaryController) DownloadSummaryPDF(ctx *gin.Context) {
+	admissionID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid admission ID", err)
+		return
+	}
+
+	var file bytes.Buffer
+	summary, err := c.summaryService.WritePDF(admissionID, &file)
+	if err != nil {
+		c.logger.Error("Failed to export discharge summary", zap.Error(err), zap.Uint("admission_id", admissionID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to export discharge summary", err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"discharge-summary-%d.pdf\"", summary.ID))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, "application/pdf", file.Bytes())
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// DocumentController handles patient document requests
+type DocumentController struct {
+	documentService *services.DocumentService
+	logger          *zap.Logger
+}
+
+// NewDocumentController creates a new document controller instance
+func NewDocumentController(documentService *services.DocumentService, logger *zap.Logger) *DocumentController {
+	return &DocumentController{
+		documentService: documentService,
+		logger:          logger,
+	}
+}
+
+// UploadDocument handles a multipart document upload. The form carries the
+// file plus category, title and visibility fields.
+func (c *DocumentController) UploadDocument(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	// Leave room for the other form fields on top of the file itself
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, services.MaxDocumentSize()+1<<20)
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "File too large", err)
+			return
+		}
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "A file field is required", err)
+		return
+	}
+	defer file.Close()
+
+	upload := services.DocumentUpload{
+		FileName:   header.Filename,
+		Category:   ctx.PostForm("category"),
+		Title:      ctx.PostForm("title"),
+		Visibility: ctx.PostForm("visibility"),
+		Body:       file,
+	}
+
+	document, err := c.documentService.Upload(ctx.Request.Context(), patientID, upload, currentUserID(ctx), currentUserRole(ctx))
+	if err != nil {
+		c.logger.Error("Failed to upload document", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to upload document", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":  "Document uploaded successfully",
+		"document": document,
+	})
+}
+
+// GetPatientDocuments handles listing the documents of a patient
+func (c *DocumentController) GetPatientDocuments(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	documents, err := c.documentService.GetPatientDocuments(patientID, currentUserRole(ctx))
+	if err != nil {
+		c.logger.Error("Failed to fetch documents", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch documents", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"documents": documents,
+	})
+}
+
+// GetDocument handles retrieving a document's metadata
+func (c *DocumentController) GetDocument(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid document ID", err)
+		return
+	}
+
+	document, err := c.documentService.GetDocument(id, currentUserRole(ctx))
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Document not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"document": document,
+	})
+}
+
+// DownloadDocument handles streaming a document's content
+func (c *DocumentController) DownloadDocument(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid document ID", err)
+		return
+	}
+
+	document, body, err := c.documentService.OpenDocument(ctx.Request.Context(), id, currentUserRole(ctx))
+	if err != nil {
+		c.logger.Error("Failed to open document", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to open document", err)
+		return
+	}
+	defer body.Close()
+
+	ctx.Header("Content-Type", document.ContentType)
+	ctx.Header("Content-Length", strconv.FormatInt(document.Size, 10))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", document.FileName))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("X-Checksum-Sha256", document.SHA256)
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, body); err != nil {
+		c.logger.Warn("Document download interrupted", zap.Error(err), zap.Uint("id", id))
+	}
+}
+
+// DeleteDocument handles deleting a document
+func (c *DocumentController) DeleteDocument(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid document ID", err)
+		return
+	}
+
+	if err := c.documentService.DeleteDocument(id, currentUserID(ctx), currentUserRole(ctx)); err != nil {
+		c.logger.Error("Failed to delete document", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to delete document", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Document deleted successfully",
+	})
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// EncounterController handles encounter related requests
+type EncounterController struct {
+	encounterService *services.EncounterService
+	logger           *zap.Logger
+}
+
+// NewEncounterController creates a new encounter controller instance
+func NewEncounterController(encounterService *services.EncounterService, logger *zap.Logger) *EncounterController {
+	return &EncounterController{
+		encounterService: encounterService,
+		logger:           logger,
+	}
+}
+
+// EncounterRequest represents the encounter request body
+type EncounterRequest struct {
+	Type      string     `json:"type" binding:"required,oneof=outpatient inpatient emergency telehealth"`
+	Reason    string     `json:"reason"`
+	StartedAt *time.Time `json:"started_at"`
+}
+
+// StartEncounter handles opening an encounter for a patient
+func (c *EncounterController) StartEncounter(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req EncounterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid encounter request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	encounter := &models.Encounter{
+		Type:   req.Type,
+		Reason: req.Reason,
+	}
+	if req.StartedAt != nil {
+		encounter.StartedAt = *req.StartedAt
+	}
+
+	created, err := c.encounterService.StartEncounter(patientID, encounter, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to start encounter", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to start encounter", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":   "Encounter started successfully",
+		"encounter": created,
+	})
+}
+
+// GetPatientEncounters handles listing a patient's encounters
+func (c *EncounterController) GetPatientEncounters(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	encounters, err := c.encounterService.GetPatientEncounters(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch encounters", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch encounters", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"encounters": encounters,
+	})
+}
+
+// GetEncounterByID handles retrieving an encounter by ID
+func (c *EncounterController) GetEncounterByID(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	encounter, err := c.encounterService.GetEncounterByID(id)
+	if err != nil {
+		c.logger.Error("Failed to fetch encounter", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Encounter not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"encounter": encounter,
+	})
+}
+
+// FinishEncounter handles closing an encounter
+func (c *EncounterController) FinishEncounter(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid encounter ID", err)
+		return
+	}
+
+	encounter, err := c.encounterService.FinishEncounter(id)
+	if err != nil {
+		c.logger.Error("Failed to finish encounter", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to finish encounter", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Encounter finished successfully",
+		"encounter": encounter,
+	})
+}
@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/fhir"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+)
+
+// maxFHIRBodySize bounds the resources clients may send
+const maxFHIRBodySize = 1 << 20
+
+// FHIRController serves the FHIR R4 API. Unlike the portal's own API it
+// answers errors with OperationOutcome resources.
+type FHIRController struct {
+	fhirPatientService *services.FHIRPatientService
+	bulkExportService  *services.BulkExportService
+	startedAt          time.Time
+	logger             *zap.Logger
+}
+
+// NewFHIRController creates a new FHIR controller instance
+func NewFHIRController(fhirPatientService *services.FHIRPatientService, bulkExportService *services.BulkExportService, logger *zap.Logger) *FHIRController {
+	return &FHIRController{
+		fhirPatientService: fhirPatientService,
+		bulkExportService:  bulkExportService,
+		startedAt:          time.Now(),
+		logger:             logger,
+	}
+}
+
+// Metadata handles serving the CapabilityStatement
+func (c *FHIRController) Metadata(ctx *gin.Context) {
+	writeFHIR(ctx, http.StatusOK, fhir.NewCapabilityStatement(fhirBaseURL(ctx), "Hospital Portal", c.startedAt))
+}
+
+// ReadPatient handles reading a Patient resource
+func (c *FHIRController) ReadPatient(ctx *gin.Context) {
+	patient, err := c.fhirPatientService.Read(ctx.Param("id"))
+	if err != nil {
+		writeFHIRError(ctx, statusForError(err, http.StatusNotFound), err)
+		return
+	}
+	writeFHIR(ctx, http.StatusOK, patient)
+}
+
+// SearchPatients handles a Patient search, by GET or by POST to _search
+// with form-encoded parameters
+func (c *FHIRController) SearchPatients(ctx *gin.Context) {
+	if err := ctx.Request.ParseForm(); err != nil {
+		writeFHIRError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	bundle, err := c.fhirPatientService.Search(ctx.Request.Form, fhirBaseURL(ctx), currentUserID(ctx), currentUserRole(ctx), ctx.ClientIP())
+	if err != nil {
+		c.logger.Error("FHIR patient search failed", zap.Error(err))
+		writeFHIRError(ctx, statusForError(err, http.StatusInternalServerError), err)
+		return
+	}
+	writeFHIR(ctx, http.StatusOK, bundle)
+}
+
+// CreatePatient handles creating a patient from a Patient resource
+func (c *FHIRController) CreatePatient(ctx *gin.Context) {
+	resource, err := readFHIRPatient(ctx)
+	if err != nil {
+		writeFHIRError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	patient, err := c.fhirPatientService.Create(resource)
+	if err != nil {
+		c.logger.Error("Failed to create patient through FHIR", zap.Error(err))
+		writeFHIRError(ctx, statusForError(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	ctx.Header("Location", fhirBaseURL(ctx)+"/Patient/"+patient.ID)
+	writeFHIR(ctx, http.StatusCreated, patient)
+}
+
+// UpdatePatient handles replacing a patient's demographics from a Patient
+// resource
+func (c *FHIRController) UpdatePatient(ctx *gin.Context) {
+	resource, err := readFHIRPatient(ctx)
+	if err != nil {
+		writeFHIRError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	patient, err := c.fhirPatientService.Update(ctx.Param("id"), resource)
+	if err != nil {
+		c.logger.Error("Failed to update patient through FHIR", zap.Error(err), zap.String("id", ctx.Param("id")))
+		writeFHIRError(ctx, statusForError(err, http.StatusNotFound), err)
+		return
+	}
+	writeFHIR(ctx, http.StatusOK, patient)
+}
+
+// ExportKickoff handles a bulk data $export kick-off request. The export
+// runs in the background; the Content-Location header is where to poll it.
+func (c *FHIRController) ExportKickoff(ctx *gin.Context) {
+	if !strings.Contains(ctx.GetHeader("Prefer"), "respond-async") {
+		writeFHIRError(ctx, http.StatusBadRequest, errors.New("bulk export requires the Prefer: respond-async header"))
+		return
+	}
+
+	base := fhirBaseURL(ctx)
+	request := base + strings.TrimPrefix(ctx.Request.URL.Path, "/fhir/r4")
+	if ctx.Request.URL.RawQuery != "" {
+		request += "?" + ctx.Request.URL.RawQuery
+	}
+
+	job, err := c.bulkExportService.Kickoff(ctx.Request.URL.Query(), request, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to start bulk export", zap.Error(err))
+		writeFHIRError(ctx, statusForError(err, http.StatusInternalServerError), err)
+		return
+	}
+
+	ctx.Header("Content-Location", fmt.Sprintf("%s/$export-status/%d", base, job.ID))
+	writeFHIR(ctx, http.StatusAccepted, fhir.NewOperationOutcome(fhir.SeverityInformation, fhir.IssueInformational, "Export started"))
+}
+
+// ExportStatus handles polling a bulk export: 202 while it runs, then the
+// manifest once it completes
+func (c *FHIRController) ExportStatus(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		writeFHIRError(ctx, http.StatusNotFound, errors.New("bulk export not found"))
+		return
+	}
+
+	job, err := c.bulkExportService.GetJob(id, currentUserID(ctx))
+	if err != nil {
+		writeFHIRError(ctx, statusForError(err, http.StatusNotFound), err)
+		return
+	}
+
+	switch job.Status {
+	case models.BulkExportStatusInProgress:
+		ctx.Header("X-Progress", job.Progress)
+		ctx.Header("Retry-After", "10")
+		ctx.Status(http.StatusAccepted)
+	case models.BulkExportStatusCompleted:
+		if job.ExpiresAt != nil {
+			ctx.Header("Expires", job.ExpiresAt.UTC().Format(http.TimeFormat))
+		}
+		ctx.JSON(http.StatusOK, c.bulkExportService.Manifest(job, fhirBaseURL(ctx)))
+	default:
+		writeFHIR(ctx, http.StatusInternalServerError, fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueProcessing, job.Error))
+	}
+}
+
+// CancelExport handles cancelling a bulk export, or deleting the files of
+// a finished one
+func (c *FHIRController) CancelExport(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		writeFHIRError(ctx, http.StatusNotFound, errors.New("bulk export not found"))
+		return
+	}
+
+	if err := c.bulkExportService.Cancel(id, currentUserID(ctx)); err != nil {
+		writeFHIRError(ctx, statusForError(err, http.StatusNotFound), err)
+		return
+	}
+	writeFHIR(ctx, http.StatusAccepted, fhir.NewOperationOutcome(fhir.SeverityInformation, fhir.IssueInformational, "Export cancelled"))
+}
+
+// DownloadExportFile handles streaming an NDJSON file of a completed export
+func (c *FHIRController) DownloadExportFile(ctx *gin.Context) {
+	jobID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		writeFHIRError(ctx, http.StatusNotFound, errors.New("bulk export not found"))
+		return
+	}
+	fileID, err := parseIDParam(ctx, "fileId")
+	if err != nil {
+		writeFHIRError(ctx, http.StatusNotFound, errors.New("export file not found"))
+		return
+	}
+
+	file, body, err := c.bulkExportService.OpenFile(ctx.Request.Context(), jobID, fileID, currentUserID(ctx))
+	if err != nil {
+		writeFHIRError(ctx, statusForError(err, http.StatusNotFound), err)
+		return
+	}
+	defer body.Close()
+
+	ctx.Header("Content-Type", fhir.NDJSONContentType)
+	ctx.Header("Content-Length", strconv.FormatInt(file.Size, 10))
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, body); err != nil {
+		c.logger.Warn("Export file download interrupted", zap.Error(err), zap.Uint("job_id", jobID), zap.Uint("file_id", fileID))
+	}
+}
+
+func readFHIRPatient(ctx *gin.Context) (*fhir.Patient, error) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxFHIRBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxFHIRBodySize {
+		return nil, errors.New("resource exceeds the size limit")
+	}
+	return fhir.DecodePatient(body)
+}
+
+// fhirBaseURL is the service base of the FHIR API: the fhir.base_url
+// config, or else the URL the request came in on
+func fhirBaseURL(ctx *gin.Context) string {
+	if base := viper.GetString("fhir.base_url"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	scheme := "http"
+	if ctx.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := ctx.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + ctx.Request.Host + "/fhir/r4"
+}
+
+func writeFHIR(ctx *gin.Context, status int, resource fhir.Resource) {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueException, err.Error()))
+	}
+	ctx.Data(status, fhir.ContentType+"; charset=utf-8", body)
+}
+
+// writeFHIRError answers with an OperationOutcome for the error
+func writeFHIRError(ctx *gin.Context, status int, err error) {
+	code := fhir.IssueException
+	switch status {
+	case http.StatusBadRequest:
+		code = fhir.IssueInvalid
+	case http.StatusForbidden:
+		code = fhir.IssueForbidden
+	case http.StatusNotFound:
+		code = fhir.IssueNotFound
+	case http.StatusConflict:
+		code = fhir.IssueConflict
+	}
+	writeFHIR(ctx, status, fhir.NewOperationOutcome(fhir.SeverityError, code, err.Error()))
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"hospital-portal/internal/auth"
+	"hospital-portal/internal/services"
+)
+
+// parseIDParam reads a numeric path parameter
+func parseIDParam(ctx *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// currentUserID returns the ID of the authenticated user
+func currentUserID(ctx *gin.Context) uint {
+	if id, exists := ctx.Get("user_id"); exists {
+		if userID, ok := id.(uint); ok {
+			return userID
+		}
+	}
+	return 0
+}
+
+// currentUserRole returns the role of the authenticated user
+func currentUserRole(ctx *gin.Context) auth.Role {
+	if role, exists := ctx.Get("user_role"); exists {
+		if userRole, ok := role.(auth.Role); ok {
+			return userRole
+		}
+	}
+	return ""
+}
+
+// statusForError maps service errors to HTTP status codes
+func statusForError(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return fallback
+	}
+}
+
+// parseTimeQuery reads an optional RFC 3339 or YYYY-MM-DD query parameter.
+// A bare date used as the end of a range covers the whole day.
+func parseTimeQuery(ctx *gin.Context, name string, endOfDay bool) (time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New(name + " must be an RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, returning nil when empty
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, errors.New("dates must be formatted as YYYY-MM-DD")
+	}
+	return &t, nil
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// HL7Controller handles requests about HL7 messages that were not applied
+type HL7Controller struct {
+	adtService *services.ADTService
+	logger     *zap.Logger
+}
+
+// NewHL7Controller creates a new HL7 controller instance
+func NewHL7Controller(adtService *services.ADTService, logger *zap.Logger) *HL7Controller {
+	return &HL7Controller{
+		adtService: adtService,
+		logger:     logger,
+	}
+}
+
+// GetDeadLetters handles listing rejected ADT messages, optionally for a
+// control_id
+func (c *HL7Controller) GetDeadLetters(ctx *gin.Context) {
+	letters, err := c.adtService.GetDeadLetters(ctx.Query("control_id"))
+	if err != nil {
+		c.logger.Error("Failed to fetch HL7 dead letters", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch dead letters", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"dead_letters": letters,
+	})
+}
+
+// GetDeadLetter handles retrieving a rejected ADT message
+func (c *HL7Controller) GetDeadLetter(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid dead letter ID", err)
+		return
+	}
+
+	letter, err := c.adtService.GetDeadLetter(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Dead letter not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"dead_letter": letter,
+	})
+}
+
+// DismissDeadLetter handles removing a rejected ADT message once it has
+// been dealt with
+func (c *HL7Controller) DismissDeadLetter(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid dead letter ID", err)
+		return
+	}
+
+	if err := c.adtService.DismissDeadLetter(id, currentUserID(ctx)); err != nil {
+		c.logger.Error("Failed to dismiss HL7 dead letter", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to dismiss dead letter", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Dead letter dismissed successfully",
+	})
+}
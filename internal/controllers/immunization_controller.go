@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// ImmunizationController handles immunization record and forecast requests
+type ImmunizationController struct {
+	immunizationService *services.ImmunizationService
+	logger              *zap.Logger
+}
+
+// NewImmunizationController creates a new immunization controller instance
+func NewImmunizationController(immunizationService *services.ImmunizationService, logger *zap.Logger) *ImmunizationController {
+	return &ImmunizationController{
+		immunizationService: immunizationService,
+		logger:              logger,
+	}
+}
+
+// ImmunizationRequest represents the immunization request body. Historical
+// records transcribe doses given elsewhere.
+type ImmunizationRequest struct {
+	VaccineCode    string     `json:"vaccine_code" binding:"required"`
+	VaccineName    string     `json:"vaccine_name"`
+	DoseNumber     int        `json:"dose_number" binding:"omitempty,min=1"`
+	LotNumber      string     `json:"lot_number"`
+	Site           string     `json:"site" binding:"omitempty,oneof=left_arm right_arm left_thigh right_thigh oral nasal"`
+	AdministeredAt *time.Time `json:"administered_at"`
+	Historical     bool       `json:"historical"`
+	Notes          string     `json:"notes"`
+}
+
+// EnteredInErrorRequest represents the request body for retracting a record
+type EnteredInErrorRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// GetImmunizations handles listing a patient's immunizations
+func (c *ImmunizationController) GetImmunizations(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	immunizations, err := c.immunizationService.GetPatientImmunizations(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch immunizations", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch immunizations", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"immunizations": immunizations,
+	})
+}
+
+// RecordImmunization handles recording a vaccine dose
+func (c *ImmunizationController) RecordImmunization(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req ImmunizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid immunization request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	immunization := &models.Immunization{
+		VaccineCode: req.VaccineCode,
+		VaccineName: req.VaccineName,
+		DoseNumber:  req.DoseNumber,
+		LotNumber:   req.LotNumber,
+		Site:        req.Site,
+		Historical:  req.Historical,
+		Notes:       req.Notes,
+	}
+	if req.AdministeredAt != nil {
+		immunization.AdministeredAt = *req.AdministeredAt
+	}
+
+	created, err := c.immunizationService.RecordImmunization(patientID, immunization, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to record immunization", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to record immunization", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":      "Immunization recorded successfully",
+		"immunization": created,
+	})
+}
+
+// MarkEnteredInError handles retracting an immunization record
+func (c *ImmunizationController) MarkEnteredInError(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	immunizationID, err := parseIDParam(ctx, "immunizationId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid immunization ID", err)
+		return
+	}
+
+	var req EnteredInErrorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	immunization, err := c.immunizationService.MarkEnteredInError(patientID, immunizationID, req.Reason)
+	if err != nil {
+		c.logger.Error("Failed to retract immunization", zap.Error(err), zap.Uint("immunization_id", immunizationID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to retract immunization", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Immunization marked as entered in error",
+		"immunization": immunization,
+	})
+}
+
+// GetForecast handles forecasting a patient's due and overdue doses
+func (c *ImmunizationController) GetForecast(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	forecast, err := c.immunizationService.GetForecast(patientID)
+	if err != nil {
+		c.logger.Error("Failed to forecast immunizations", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to forecast immunizations", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"forecast": forecast,
+	})
+}
+
+// GetSchedule handles retrieving the configured immunization schedule
+func (c *ImmunizationController) GetSchedule(ctx *gin.Context) {
+	schedule, err := c.immunizationService.GetSchedule()
+	if err != nil {
+		c.logger.Error("Failed to load immunization schedule", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to load immunization schedule", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"schedule": schedule,
+	})
+}
+
+// GetOverduePatients handles listing patients with overdue immunizations,
+// optionally of one vaccine series
+func (c *ImmunizationController) GetOverduePatients(ctx *gin.Context) {
+	patients, err := c.immunizationService.GetOverduePatients(ctx.Query("vaccine"))
+	if err != nil {
+		c.logger.Error("Failed to list overdue immunizations", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to list overdue immunizations", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"patients": patients,
+	})
+}
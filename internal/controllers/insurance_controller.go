@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// InsuranceController handles payer and coverage related requests
+type InsuranceController struct {
+	insuranceService *services.InsuranceService
+	logger           *zap.Logger
+}
+
+// NewInsuranceController creates a new insurance controller instance
+func NewInsuranceController(insuranceService *services.InsuranceService, logger *zap.Logger) *InsuranceController {
+	return &InsuranceController{
+		insuranceService: insuranceService,
+		logger:           logger,
+	}
+}
+
+// CoverageRequest represents the insurance coverage request body
+type CoverageRequest struct {
+	PayerCode              string `json:"payer_code" binding:"required"`
+	PlanName               string `json:"plan_name" binding:"required"`
+	MemberID               string `json:"member_id" binding:"required"`
+	GroupNumber            string `json:"group_number"`
+	SubscriberRelationship string `json:"subscriber_relationship" binding:"omitempty,oneof=self spouse child other"`
+	SubscriberName         string `json:"subscriber_name"`
+	Priority               int    `json:"priority" binding:"required,min=1,max=3"`
+	EffectiveFrom          string `json:"effective_from" binding:"required"` // YYYY-MM-DD
+	EffectiveTo            string `json:"effective_to"`
+}
+
+// SearchPayers handles the payer catalogue lookup
+func (c *InsuranceController) SearchPayers(ctx *gin.Context) {
+	payers, err := c.insuranceService.SearchPayers(ctx.Query("q"))
+	if err != nil {
+		c.logger.Error("Failed to search payers", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to search payers", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"payers": payers,
+	})
+}
+
+// GetCoverages handles listing a patient's insurance coverages
+func (c *InsuranceController) GetCoverages(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	coverages, err := c.insuranceService.GetCoverages(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch coverages", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch coverages", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"coverages": coverages,
+	})
+}
+
+// CreateCoverage handles adding an insurance coverage to a patient
+func (c *InsuranceController) CreateCoverage(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	coverage, err := c.bindCoverage(ctx)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	created, err := c.insuranceService.AddCoverage(patientID, coverage, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to add coverage", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to add coverage", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":  "Coverage added successfully",
+		"coverage": created,
+	})
+}
+
+// UpdateCoverage handles updating an insurance coverage
+func (c *InsuranceController) UpdateCoverage(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	coverageID, err := parseIDParam(ctx, "coverageId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid coverage ID", err)
+		return
+	}
+
+	coverage, err := c.bindCoverage(ctx)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	updated, err := c.insuranceService.UpdateCoverage(patientID, coverageID, coverage)
+	if err != nil {
+		c.logger.Error("Failed to update coverage", zap.Error(err), zap.Uint("coverage_id", coverageID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update coverage", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Coverage updated successfully",
+		"coverage": updated,
+	})
+}
+
+// DeleteCoverage handles removing an insurance coverage entered in error
+func (c *InsuranceController) DeleteCoverage(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	coverageID, err := parseIDParam(ctx, "coverageId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid coverage ID", err)
+		return
+	}
+
+	if err := c.insuranceService.DeleteCoverage(patientID, coverageID); err != nil {
+		c.logger.Error("Failed to delete coverage", zap.Error(err), zap.Uint("coverage_id", coverageID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to delete coverage", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Coverage deleted successfully",
+	})
+}
+
+// CheckEligibility handles verifying a coverage with the payer
+func (c *InsuranceController) CheckEligibility(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	coverageID, err := parseIDParam(ctx, "coverageId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid coverage ID", err)
+		return
+	}
+
+	coverage, err := c.insuranceService.CheckEligibility(ctx.Request.Context(), patientID, coverageID)
+	if err != nil {
+		c.logger.Error("Failed to check eligibility", zap.Error(err), zap.Uint("coverage_id", coverageID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to check eligibility", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"coverage": coverage,
+	})
+}
+
+func (c *InsuranceController) bindCoverage(ctx *gin.Context) (*models.InsuranceCoverage, error) {
+	var req CoverageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+	from, err := parseOptionalDate(req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseOptionalDate(req.EffectiveTo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InsuranceCoverage{
+		PayerCode:              req.PayerCode,
+		PlanName:               req.PlanName,
+		MemberID:               req.MemberID,
+		GroupNumber:            req.GroupNumber,
+		SubscriberRelationship: req.SubscriberRelationship,
+		SubscriberName:         req.SubscriberName,
+		Priority:               req.Priority,
+		EffectiveFrom:          *from,
+		EffectiveTo:            to,
+	}, nil
+}
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// LabController handles lab order and result requests
+type LabController struct {
+	labService *services.LabService
+	logger     *zap.Logger
+}
+
+// NewLabController creates a new lab controller instance
+func NewLabController(labService *services.LabService, logger *zap.Logger) *LabController {
+	return &LabController{
+		labService: labService,
+		logger:     logger,
+	}
+}
+
+// LabOrderRequest represents the lab order request body
+type LabOrderRequest struct {
+	TestCode      string `json:"test_code" binding:"required"`
+	TestName      string `json:"test_name" binding:"required"`
+	Priority      string `json:"priority" binding:"omitempty,oneof=routine urgent stat"`
+	SpecimenType  string `json:"specimen_type" binding:"required"`
+	EncounterID   *uint  `json:"encounter_id"`
+	ClinicalNotes string `json:"clinical_notes"`
+}
+
+// LabResultRequest represents a single result in the results request body
+type LabResultRequest struct {
+	AnalyteCode  string   `json:"analyte_code"`
+	AnalyteName  string   `json:"analyte_name" binding:"required"`
+	Value        string   `json:"value" binding:"required"`
+	Unit         string   `json:"unit"`
+	RefLow       *float64 `json:"ref_low"`
+	RefHigh      *float64 `json:"ref_high"`
+	CriticalLow  *float64 `json:"critical_low"`
+	CriticalHigh *float64 `json:"critical_high"`
+	Abnormal     bool     `json:"abnormal"` // for qualitative results
+	Comment      string   `json:"comment"`
+}
+
+// LabResultsRequest represents the results request body
+type LabResultsRequest struct {
+	Results []LabResultRequest `json:"results" binding:"required,min=1,dive"`
+}
+
+// CancelLabOrderRequest represents the cancel request body
+type CancelLabOrderRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// PlaceOrder handles ordering a lab test for a patient
+func (c *LabController) PlaceOrder(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req LabOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid lab order request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	order := &models.LabOrder{
+		TestCode:      req.TestCode,
+		TestName:      req.TestName,
+		Priority:      req.Priority,
+		SpecimenType:  req.SpecimenType,
+		EncounterID:   req.EncounterID,
+		ClinicalNotes: req.ClinicalNotes,
+	}
+
+	created, err := c.labService.PlaceOrder(patientID, order, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to place lab order", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to place lab order", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":   "Lab order placed successfully",
+		"lab_order": created,
+	})
+}
+
+// GetPatientOrders handles listing a patient's lab orders
+func (c *LabController) GetPatientOrders(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	orders, err := c.labService.GetPatientOrders(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch lab orders", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch lab orders", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"lab_orders": orders,
+	})
+}
+
+// GetWorklist handles listing lab orders by status
+func (c *LabController) GetWorklist(ctx *gin.Context) {
+	orders, err := c.labService.GetWorklist(ctx.Query("status"))
+	if err != nil {
+		c.logger.Error("Failed to fetch lab worklist", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to fetch lab worklist", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"lab_orders": orders,
+	})
+}
+
+// GetOrderByID handles retrieving a lab order with its results
+func (c *LabController) GetOrderByID(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	order, err := c.labService.GetOrderByID(id)
+	if err != nil {
+		c.logger.Error("Failed to fetch lab order", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Lab order not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"lab_order": order,
+	})
+}
+
+// CollectSpecimen handles marking a specimen as collected
+func (c *LabController) CollectSpecimen(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	order, err := c.labService.CollectSpecimen(id, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to collect specimen", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to collect specimen", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Specimen collected",
+		"lab_order": order,
+	})
+}
+
+// EnterResults handles entering the results of a lab order
+func (c *LabController) EnterResults(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	var req LabResultsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid lab results request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	results := make([]models.LabResult, 0, len(req.Results))
+	for _, r := range req.Results {
+		result := models.LabResult{
+			AnalyteCode:  r.AnalyteCode,
+			AnalyteName:  r.AnalyteName,
+			Value:        r.Value,
+			Unit:         r.Unit,
+			RefLow:       r.RefLow,
+			RefHigh:      r.RefHigh,
+			CriticalLow:  r.CriticalLow,
+			CriticalHigh: r.CriticalHigh,
+			Comment:      r.Comment,
+		}
+		if r.Abnormal {
+			result.Flag = models.LabFlagAbnormal
+		}
+		results = append(results, result)
+	}
+
+	order, err := c.labService.EnterResults(id, results, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to enter lab results", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to enter lab results", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Lab results entered",
+		"lab_order": order,
+	})
+}
+
+// VerifyResults handles verifying the results of a lab order
+func (c *LabController) VerifyResults(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	order, err := c.labService.VerifyResults(id, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to verify lab results", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to verify lab results", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Lab results verified",
+		"lab_order": order,
+	})
+}
+
+// CancelOrder handles cancelling a lab order
+func (c *LabController) CancelOrder(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid lab order ID", err)
+		return
+	}
+
+	var req CancelLabOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	order, err := c.labService.CancelOrder(id, req.Reason)
+	if err != nil {
+		c.logger.Error("Failed to cancel lab order", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to cancel lab order", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Lab order cancelled",
+		"lab_order": order,
+	})
+}
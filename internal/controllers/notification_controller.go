@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// NotificationController handles notification related requests
+type NotificationController struct {
+	notificationService *services.NotificationService
+	logger              *zap.Logger
+}
+
+// NewNotificationController creates a new notification controller instance
+func NewNotificationController(notificationService *services.NotificationService, logger *zap.Logger) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// GetMyNotifications handles listing the current user's notifications
+func (c *NotificationController) GetMyNotifications(ctx *gin.Context) {
+	notifications, err := c.notificationService.GetUserNotifications(currentUserID(ctx), ctx.Query("unread") == "true")
+	if err != nil {
+		c.logger.Error("Failed to fetch notifications", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch notifications", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+	})
+}
+
+// MarkRead handles marking a notification as read
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid notification ID", err)
+		return
+	}
+
+	if err := c.notificationService.MarkRead(id, currentUserID(ctx)); err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to mark notification as read", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Notification marked as read",
+	})
+}
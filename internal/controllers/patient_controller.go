@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -16,28 +17,63 @@ import (
 // PatientController handles patient-related requests
 type PatientController struct {
 	patientService *services.PatientService
+	allergyService *services.AllergyService
+	exportService  *services.PatientExportService
 	logger         *zap.Logger
 }
 
 // NewPatientController creates a new patient controller instance
-func NewPatientController(patientService *services.PatientService, logger *zap.Logger) *PatientController {
+func NewPatientController(patientService *services.PatientService, allergyService *services.AllergyService, exportService *services.PatientExportService, logger *zap.Logger) *PatientController {
 	return &PatientController{
 		patientService: patientService,
+		allergyService: allergyService,
+		exportService:  exportService,
 		logger:         logger,
 	}
 }
 
 // PatientRequest represents the patient request body
 type PatientRequest struct {
-	Name           string `json:"name" binding:"required"`
-	Age            int    `json:"age" binding:"required,min=0,max=150"`
-	Gender         string `json:"gender" binding:"required,oneof=male female other"`
-	Address        string `json:"address" binding:"required"`
-	PhoneNumber    string `json:"phone_number" binding:"required"`
-	MedicalHistory string `json:"medical_history"`
-	Diagnosis      string `json:"diagnosis"`
-	Treatment      string `json:"treatment"`
-	Notes          string `json:"notes"`
+	Name           string         `json:"name" binding:"required"`
+	Age            int            `json:"age" binding:"required_without=DateOfBirth,min=0,max=150"`
+	DateOfBirth    string         `json:"date_of_birth"` // YYYY-MM-DD; when given, age is derived from it
+	Gender         string         `json:"gender" binding:"required,oneof=male female other"`
+	Address        AddressRequest `json:"address" binding:"required"`
+	PhoneNumber    string         `json:"phone_number" binding:"required"`
+	MedicalHistory string         `json:"medical_history"`
+	Diagnosis      string         `json:"diagnosis"`
+	Treatment      string         `json:"treatment"`
+	Notes          string         `json:"notes"`
+	// Contacts are only read on create; afterwards they are managed
+	// through the contacts endpoints
+	Contacts []ContactRequest `json:"contacts" binding:"dive"`
+}
+
+// AddressRequest represents a structured address in request bodies
+type AddressRequest struct {
+	Line1      string `json:"line1" binding:"required"`
+	Line2      string `json:"line2"`
+	City       string `json:"city" binding:"required"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country" binding:"omitempty,len=2"`
+}
+
+func (r AddressRequest) toModel() models.Address {
+	return models.Address{
+		Line1:      r.Line1,
+		Line2:      r.Line2,
+		City:       r.City,
+		Region:     r.Region,
+		PostalCode: r.PostalCode,
+		Country:    r.Country,
+	}
+}
+
+// ContactDetailsRequest represents the patient contact details request body
+type ContactDetailsRequest struct {
+	Address     AddressRequest `json:"address" binding:"required"`
+	PhoneNumber string         `json:"phone_number" binding:"required"`
 }
 
 // CreatePatient handles creating a new patient
@@ -49,22 +85,32 @@ func (c *PatientController) CreatePatient(ctx *gin.Context) {
 		return
 	}
 
+	dateOfBirth, err := parseOptionalDate(req.DateOfBirth)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid date of birth", err)
+		return
+	}
+
 	patient := &models.Patient{
 		Name:           req.Name,
 		Age:            req.Age,
+		DateOfBirth:    dateOfBirth,
 		Gender:         req.Gender,
-		Address:        req.Address,
+		Address:        req.Address.toModel(),
 		PhoneNumber:    req.PhoneNumber,
 		MedicalHistory: req.MedicalHistory,
 		Diagnosis:      req.Diagnosis,
 		Treatment:      req.Treatment,
 		Notes:          req.Notes,
 	}
+	for _, contact := range req.Contacts {
+		patient.Contacts = append(patient.Contacts, contact.toModel())
+	}
 
 	createdPatient, err := c.patientService.CreatePatient(patient)
 	if err != nil {
 		c.logger.Error("Failed to create patient", zap.Error(err))
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to create patient", err)
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to create patient", err)
 		return
 	}
 
@@ -74,18 +120,67 @@ func (c *PatientController) CreatePatient(ctx *gin.Context) {
 	})
 }
 
-// GetAllPatients handles retrieving all patients
+// GetAllPatients handles paging through the patient list, narrowed by the
+// list filters, with offset and limit. fields picks the columns, as in an
+// export. With format=csv, xlsx or pdf the list is downloaded as a file
+// instead.
 func (c *PatientController) GetAllPatients(ctx *gin.Context) {
-	patients, err := c.patientService.GetAllPatients()
+	if format := ctx.Query("format"); format != "" && format != services.ExportFormatJSON {
+		c.exportPatients(ctx, format)
+		return
+	}
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+
+	page, err := c.exportService.ListPage(patientExportRequest(ctx, services.ExportFormatJSON), offset, limit)
 	if err != nil {
 		c.logger.Error("Failed to fetch patients", zap.Error(err))
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch patients", err)
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to fetch patients", err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"patients": patients,
-	})
+	ctx.JSON(http.StatusOK, page)
+}
+
+// exportPatients streams the filtered patient list as a file. fields picks
+// the columns, comma-separated.
+func (c *PatientController) exportPatients(ctx *gin.Context, format string) {
+	export, err := c.exportService.StartExport(patientExportRequest(ctx, format))
+	if err != nil {
+		c.logger.Error("Failed to export patients", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to export patients", err)
+		return
+	}
+
+	ctx.Header("Content-Type", export.ContentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", export.FileName))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Status(http.StatusOK)
+	// The rows are already on their way, so a failure can only be logged
+	c.exportService.WriteExport(export, ctx.Writer)
+}
+
+// patientExportRequest reads the fields and list filters of a patient list
+// request, whether it is paged or exported
+func patientExportRequest(ctx *gin.Context, format string) services.PatientExportRequest {
+	var fields []string
+	if value := ctx.Query("fields"); value != "" {
+		fields = strings.Split(value, ",")
+	}
+	params := url.Values{}
+	for key, values := range ctx.Request.URL.Query() {
+		if key != "offset" && key != "limit" {
+			params[key] = values
+		}
+	}
+	return services.PatientExportRequest{
+		Format:     format,
+		Fields:     fields,
+		Params:     params,
+		UserID:     currentUserID(ctx),
+		Role:       currentUserRole(ctx),
+		RemoteAddr: ctx.ClientIP(),
+	}
 }
 
 // GetPatientByID handles retrieving a patient by ID
@@ -105,8 +200,16 @@ func (c *PatientController) GetPatientByID(ctx *gin.Context) {
 		return
 	}
 
+	allergies, err := c.allergyService.GetAllergySummary(patient.ID)
+	if err != nil {
+		c.logger.Error("Failed to fetch allergy warnings", zap.Error(err), zap.Uint64("id", id))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch allergy warnings", err)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"patient": patient,
+		"patient":          patient,
+		"allergy_warnings": allergies,
 	})
 }
 
@@ -147,23 +250,37 @@ func (c *PatientController) UpdatePatient(ctx *gin.Context) {
 		return
 	}
 
-	patient := &models.Patient{
-		Name:           req.Name,
-		Age:            req.Age,
-		Gender:         req.Gender,
-		Address:        req.Address,
-		PhoneNumber:    req.PhoneNumber,
-		MedicalHistory: req.MedicalHistory,
-		Diagnosis:      req.Diagnosis,
-		Treatment:      req.Treatment,
-		Notes:          req.Notes,
+	dateOfBirth, err := parseOptionalDate(req.DateOfBirth)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid date of birth", err)
+		return
+	}
+
+	// The request is merged into the stored patient, so that what it
+	// leaves out, such as a recorded date of birth, is kept
+	patient, err := c.patientService.GetPatientByID(uint(id))
+	if err != nil {
+		c.logger.Error("Failed to fetch patient", zap.Error(err), zap.Uint64("id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Patient not found", err)
+		return
+	}
+	patient.Name = req.Name
+	patient.Age = req.Age
+	if dateOfBirth != nil {
+		patient.DateOfBirth = dateOfBirth
 	}
-	patient.ID = uint(id)
+	patient.Gender = req.Gender
+	patient.Address = req.Address.toModel()
+	patient.PhoneNumber = req.PhoneNumber
+	patient.MedicalHistory = req.MedicalHistory
+	patient.Diagnosis = req.Diagnosis
+	patient.Treatment = req.Treatment
+	patient.Notes = req.Notes
 
 	updatedPatient, err := c.patientService.UpdatePatient(patient)
 	if err != nil {
 		c.logger.Error("Failed to update patient", zap.Error(err), zap.Uint64("id", id))
-		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to update patient", err)
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to update patient", err)
 		return
 	}
 
@@ -173,6 +290,47 @@ func (c *PatientController) UpdatePatient(ctx *gin.Context) {
 	})
 }
 
+// UpdateContactDetails handles correcting a patient's address and phone number
+func (c *PatientController) UpdateContactDetails(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req ContactDetailsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	patient, err := c.patientService.UpdateContactDetails(id, req.Address.toModel(), req.PhoneNumber)
+	if err != nil {
+		c.logger.Error("Failed to update contact details", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update contact details", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Contact details updated successfully",
+		"patient": patient,
+	})
+}
+
+// GetContactReviewQueue handles listing patients whose contact details need review
+func (c *PatientController) GetContactReviewQueue(ctx *gin.Context) {
+	patients, err := c.patientService.GetContactReviewQueue()
+	if err != nil {
+		c.logger.Error("Failed to fetch contact review queue", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch contact review queue", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"patients": patients,
+	})
+}
+
 // DeletePatient handles deleting a patient
 func (c *PatientController) DeletePatient(ctx *gin.Context) {
 	idStr := ctx.Param("id")
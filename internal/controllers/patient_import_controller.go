@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// PatientImportController handles bulk patient import requests
+type PatientImportController struct {
+	importService *services.PatientImportService
+	logger        *zap.Logger
+}
+
+// NewPatientImportController creates a new patient import controller instance
+func NewPatientImportController(importService *services.PatientImportService, logger *zap.Logger) *PatientImportController {
+	return &PatientImportController{
+		importService: importService,
+		logger:        logger,
+	}
+}
+
+// StartImport handles a multipart CSV or XLSX upload. Besides the file, the
+// form may carry format, mapping (a JSON object of field to column name),
+// identifier_system and dry_run.
+func (c *PatientImportController) StartImport(ctx *gin.Context) {
+	// Leave room for the other form fields on top of the file itself
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, services.MaxImportSize()+1<<20)
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ErrorResponse(ctx, http.StatusRequestEntityTooLarge, "File too large", err)
+			return
+		}
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "A file field is required", err)
+		return
+	}
+	defer file.Close()
+
+	var mapping map[string]string
+	if value := ctx.PostForm("mapping"); value != "" {
+		if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "mapping must be a JSON object of field to column name", err)
+			return
+		}
+	}
+	dryRun := false
+	if value := ctx.PostForm("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "dry_run must be true or false", err)
+			return
+		}
+	}
+
+	job, err := c.importService.StartImport(services.PatientImportUpload{
+		FileName:         header.Filename,
+		Format:           ctx.PostForm("format"),
+		Body:             file,
+		Size:             header.Size,
+		Mapping:          mapping,
+		IdentifierSystem: ctx.PostForm("identifier_system"),
+		DryRun:           dryRun,
+	}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to start patient import", zap.Error(err), zap.String("file", header.Filename))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to start import", err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": "Import started",
+		"import":  job,
+	})
+}
+
+// GetImports handles listing the current user's imports
+func (c *PatientImportController) GetImports(ctx *gin.Context) {
+	jobs, err := c.importService.GetJobs(currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to fetch patient imports", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch imports", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"imports": jobs,
+	})
+}
+
+// GetImport handles polling an import's progress. The row errors are paged
+// with offset and limit.
+func (c *PatientImportController) GetImport(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid import ID", err)
+		return
+	}
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+
+	job, err := c.importService.GetJob(id, currentUserID(ctx), offset, limit)
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Import not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"import": job,
+	})
+}
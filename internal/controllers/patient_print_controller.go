@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// PatientPrintController handles printable patient documents
+type PatientPrintController struct {
+	printService *services.PatientPrintService
+	logger       *zap.Logger
+}
+
+// NewPatientPrintController creates a new patient print controller instance
+func NewPatientPrintController(printService *services.PatientPrintService, logger *zap.Logger) *PatientPrintController {
+	return &PatientPrintController{
+		printService: printService,
+		logger:       logger,
+	}
+}
+
+// DownloadSummary handles rendering a patient summary as PDF
+func (c *PatientPrintController) DownloadSummary(ctx *gin.Context) {
+	c.download(ctx, "summary", c.printService.WriteSummaryPDF)
+}
+
+// DownloadLabel handles rendering a patient wristband or label as PDF
+func (c *PatientPrintController) DownloadLabel(ctx *gin.Context) {
+	c.download(ctx, "label", c.printService.WriteLabelPDF)
+}
+
+func (c *PatientPrintController) download(ctx *gin.Context, kind string, write func(uint, io.Writer) error) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var file bytes.Buffer
+	if err := write(patientID, &file); err != nil {
+		c.logger.Error("Failed to render patient "+kind, zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to render patient "+kind, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"patient-%d-%s.pdf\"", patientID, kind))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(http.StatusOK, "application/pdf", file.Bytes())
+}
@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// PrescriptionController handles prescription related requests
+type PrescriptionController struct {
+	prescriptionService *services.PrescriptionService
+	logger              *zap.Logger
+}
+
+// NewPrescriptionController creates a new prescription controller instance
+func NewPrescriptionController(prescriptionService *services.PrescriptionService, logger *zap.Logger) *PrescriptionController {
+	return &PrescriptionController{
+		prescriptionService: prescriptionService,
+		logger:              logger,
+	}
+}
+
+// PrescriptionRequest represents the prescription request body
+type PrescriptionRequest struct {
+	Drug         string `json:"drug" binding:"required"`
+	DrugCode     string `json:"drug_code"`
+	Strength     string `json:"strength" binding:"required"`
+	Route        string `json:"route" binding:"required"`
+	Frequency    string `json:"frequency" binding:"required"`
+	DurationDays int    `json:"duration_days" binding:"required,min=1"`
+	Quantity     int    `json:"quantity" binding:"required,min=1"`
+	Refills      int    `json:"refills" binding:"min=0,max=12"`
+	Instructions string `json:"instructions"`
+	// OverrideReason acknowledges interactions that need an override
+	OverrideReason string `json:"override_reason"`
+}
+
+// InteractionCheckRequest represents the interaction check request body
+type InteractionCheckRequest struct {
+	Drug     string `json:"drug" binding:"required"`
+	DrugCode string `json:"drug_code"`
+}
+
+// DiscontinueRequest represents the discontinue request body
+type DiscontinueRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// RenewRequest represents the renew request body
+type RenewRequest struct {
+	DurationDays int  `json:"duration_days" binding:"omitempty,min=1"`
+	Quantity     int  `json:"quantity" binding:"omitempty,min=1"`
+	Refills      *int `json:"refills" binding:"omitempty,min=0,max=12"`
+	// OverrideReason acknowledges interactions that need an override
+	OverrideReason string `json:"override_reason"`
+}
+
+// GetPatientPrescriptions handles listing a patient's prescriptions
+func (c *PrescriptionController) GetPatientPrescriptions(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	prescriptions, err := c.prescriptionService.GetPatientPrescriptions(patientID, ctx.Query("status"))
+	if err != nil {
+		c.logger.Error("Failed to fetch prescriptions", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch prescriptions", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"prescriptions": prescriptions,
+	})
+}
+
+// CreatePrescription handles prescribing a medication
+func (c *PrescriptionController) CreatePrescription(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req PrescriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid prescription request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	prescription := &models.Prescription{
+		Drug:         req.Drug,
+		DrugCode:     req.DrugCode,
+		Strength:     req.Strength,
+		Route:        req.Route,
+		Frequency:    req.Frequency,
+		DurationDays: req.DurationDays,
+		Quantity:     req.Quantity,
+		Refills:      req.Refills,
+		Instructions: req.Instructions,
+	}
+
+	created, err := c.prescriptionService.Prescribe(patientID, prescription, currentUserID(ctx), req.OverrideReason)
+	if err != nil {
+		if c.interactionResponse(ctx, err) {
+			return
+		}
+		c.logger.Error("Failed to create prescription", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to create prescription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":      "Prescription created successfully",
+		"prescription": created,
+	})
+}
+
+// GetPrescriptionByID handles retrieving a prescription by ID
+func (c *PrescriptionController) GetPrescriptionByID(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	prescription, err := c.prescriptionService.GetPrescriptionByID(id)
+	if err != nil {
+		c.logger.Error("Failed to fetch prescription", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Prescription not found", err)
+		return
+	}
+
+	alerts, err := c.prescriptionService.GetInteractionAlerts(id)
+	if err != nil {
+		c.logger.Error("Failed to fetch interaction alerts", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch interaction alerts", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"prescription":       prescription,
+		"interaction_alerts": alerts,
+	})
+}
+
+// CheckInteractions handles checking a drug for interactions before prescribing
+func (c *PrescriptionController) CheckInteractions(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req InteractionCheckRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	check, err := c.prescriptionService.CheckInteractions(patientID, req.Drug, req.DrugCode)
+	if err != nil {
+		c.logger.Error("Failed to check interactions", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to check interactions", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"interactions": check,
+	})
+}
+
+// DiscontinuePrescription handles discontinuing a prescription
+func (c *PrescriptionController) DiscontinuePrescription(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	var req DiscontinueRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	prescription, err := c.prescriptionService.Discontinue(id, req.Reason, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to discontinue prescription", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to discontinue prescription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Prescription discontinued successfully",
+		"prescription": prescription,
+	})
+}
+
+// RenewPrescription handles renewing a prescription
+func (c *PrescriptionController) RenewPrescription(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	var req RenewRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+			return
+		}
+	}
+
+	opts := services.RenewalOptions{
+		DurationDays:   req.DurationDays,
+		Quantity:       req.Quantity,
+		Refills:        req.Refills,
+		OverrideReason: req.OverrideReason,
+	}
+	prescription, err := c.prescriptionService.Renew(id, opts, currentUserID(ctx))
+	if err != nil {
+		if c.interactionResponse(ctx, err) {
+			return
+		}
+		c.logger.Error("Failed to renew prescription", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to renew prescription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":      "Prescription renewed successfully",
+		"prescription": prescription,
+	})
+}
+
+// PrintPrescription handles rendering a printable prescription
+func (c *PrescriptionController) PrintPrescription(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid prescription ID", err)
+		return
+	}
+
+	page, err := c.prescriptionService.RenderPrintable(id)
+	if err != nil {
+		c.logger.Error("Failed to render prescription", zap.Error(err), zap.Uint("id", id))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to render prescription", err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// interactionResponse answers with the interaction findings when err is an
+// interaction error. It reports whether a response was written.
+func (c *PrescriptionController) interactionResponse(ctx *gin.Context, err error) bool {
+	var interactionErr *services.InteractionError
+	if !errors.As(err, &interactionErr) {
+		return false
+	}
+
+	status := http.StatusConflict
+	if interactionErr.Check.Blocked {
+		status = http.StatusUnprocessableEntity
+	}
+	ctx.JSON(status, gin.H{
+		"error": gin.H{
+			"message":      interactionErr.Error(),
+			"details":      "",
+			"interactions": interactionErr.Check,
+		},
+	})
+	return true
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// ProblemController handles problem list and ICD-10 related requests
+type ProblemController struct {
+	problemService *services.ProblemService
+	icd10Service   *services.ICD10Service
+	logger         *zap.Logger
+}
+
+// NewProblemController creates a new problem controller instance
+func NewProblemController(problemService *services.ProblemService, icd10Service *services.ICD10Service, logger *zap.Logger) *ProblemController {
+	return &ProblemController{
+		problemService: problemService,
+		icd10Service:   icd10Service,
+		logger:         logger,
+	}
+}
+
+// ProblemRequest represents the problem list entry request body
+type ProblemRequest struct {
+	ICD10Code    string `json:"icd10_code" binding:"required"`
+	Description  string `json:"description"`
+	EncounterID  *uint  `json:"encounter_id"`
+	OnsetDate    string `json:"onset_date"`    // YYYY-MM-DD
+	ResolvedDate string `json:"resolved_date"` // YYYY-MM-DD
+	Status       string `json:"status" binding:"omitempty,oneof=active resolved"`
+	IsPrimary    bool   `json:"is_primary"`
+	Notes        string `json:"notes"`
+}
+
+// SearchICD10 handles the ICD-10 typeahead
+func (c *ProblemController) SearchICD10(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	codes, err := c.icd10Service.Search(ctx.Query("q"), limit)
+	if err != nil {
+		c.logger.Error("Failed to search ICD-10 codes", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to search ICD-10 codes", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"codes": codes,
+	})
+}
+
+// GetProblemList handles listing a patient's problems
+func (c *ProblemController) GetProblemList(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	problems, err := c.problemService.GetProblemList(patientID, ctx.Query("status"))
+	if err != nil {
+		c.logger.Error("Failed to fetch problem list", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch problem list", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"problems": problems,
+	})
+}
+
+// AddProblem handles adding a diagnosis to a patient's problem list
+func (c *ProblemController) AddProblem(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	problem, err := c.bindProblem(ctx)
+	if err != nil {
+		c.logger.Error("Invalid problem request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	created, err := c.problemService.AddProblem(patientID, problem, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to add problem", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to add problem", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Problem added successfully",
+		"problem": created,
+	})
+}
+
+// UpdateProblem handles updating a problem list entry
+func (c *ProblemController) UpdateProblem(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	problemID, err := parseIDParam(ctx, "problemId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid problem ID", err)
+		return
+	}
+
+	problem, err := c.bindProblem(ctx)
+	if err != nil {
+		c.logger.Error("Invalid problem request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+	problem.ID = problemID
+
+	updated, err := c.problemService.UpdateProblem(patientID, problem)
+	if err != nil {
+		c.logger.Error("Failed to update problem", zap.Error(err), zap.Uint("problem_id", problemID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update problem", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Problem updated successfully",
+		"problem": updated,
+	})
+}
+
+// DeleteProblem handles removing a problem list entry
+func (c *ProblemController) DeleteProblem(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	problemID, err := parseIDParam(ctx, "problemId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid problem ID", err)
+		return
+	}
+
+	if err := c.problemService.DeleteProblem(patientID, problemID); err != nil {
+		c.logger.Error("Failed to delete problem", zap.Error(err), zap.Uint("problem_id", problemID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to delete problem", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Problem deleted successfully",
+	})
+}
+
+func (c *ProblemController) bindProblem(ctx *gin.Context) (*models.Problem, error) {
+	var req ProblemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return nil, err
+	}
+	onset, err := parseOptionalDate(req.OnsetDate)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := parseOptionalDate(req.ResolvedDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Problem{
+		ICD10Code:    req.ICD10Code,
+		Description:  req.Description,
+		EncounterID:  req.EncounterID,
+		OnsetDate:    onset,
+		ResolvedDate: resolved,
+		Status:       req.Status,
+		IsPrimary:    req.IsPrimary,
+		Notes:        req.Notes,
+	}, nil
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// QueueController handles waiting-room check-in, triage and call-in requests
+type QueueController struct {
+	queueService *services.QueueService
+	logger       *zap.Logger
+}
+
+// NewQueueController creates a new queue controller instance
+func NewQueueController(queueService *services.QueueService, logger *zap.Logger) *QueueController {
+	return &QueueController{
+		queueService: queueService,
+		logger:       logger,
+	}
+}
+
+// CheckInRequest represents the check-in request body
+type CheckInRequest struct {
+	PatientID     uint       `json:"patient_id" binding:"required"`
+	Source        string     `json:"source" binding:"required,oneof=appointment walk_in"`
+	AppointmentAt *time.Time `json:"appointment_at"`
+	Reason        string     `json:"reason"`
+}
+
+// TriageRequest represents the triage request body; level is the ESI level
+type TriageRequest struct {
+	Level int    `json:"level" binding:"required,min=1,max=5"`
+	Notes string `json:"notes"`
+}
+
+// RemoveFromQueueRequest represents the request body for taking a patient
+// off the queue
+type RemoveFromQueueRequest struct {
+	Left   bool   `json:"left"` // left without being seen, rather than cancelled
+	Reason string `json:"reason"`
+}
+
+// CheckIn handles checking a patient in
+func (c *QueueController) CheckIn(ctx *gin.Context) {
+	var req CheckInRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid check-in request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	entry, err := c.queueService.CheckIn(req.PatientID, services.CheckInInput{
+		Source:        req.Source,
+		AppointmentAt: req.AppointmentAt,
+		Reason:        req.Reason,
+	}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to check in patient", zap.Error(err), zap.Uint("patient_id", req.PatientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to check in patient", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Patient checked in successfully",
+		"entry":   entry,
+	})
+}
+
+// GetQueue handles listing the live waiting-room queue
+func (c *QueueController) GetQueue(ctx *gin.Context) {
+	queue, err := c.queueService.GetQueue()
+	if err != nil {
+		c.logger.Error("Failed to fetch queue", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch queue", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"queue": queue,
+	})
+}
+
+// GetEntry handles retrieving a queue entry
+func (c *QueueController) GetEntry(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid queue entry ID", err)
+		return
+	}
+
+	entry, err := c.queueService.GetEntry(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Queue entry not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"entry": entry,
+	})
+}
+
+// Triage handles assigning a triage level
+func (c *QueueController) Triage(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid queue entry ID", err)
+		return
+	}
+
+	var req TriageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid triage request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	entry, err := c.queueService.Triage(id, req.Level, req.Notes, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to triage patient", zap.Error(err), zap.Uint("entry_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to triage patient", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Patient triaged successfully",
+		"entry":   entry,
+	})
+}
+
+// CallNext handles a doctor calling in the next patient
+func (c *QueueController) CallNext(ctx *gin.Context) {
+	entry, err := c.queueService.CallNext(currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to call next patient", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to call next patient", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Patient called in successfully",
+		"entry":   entry,
+	})
+}
+
+// Complete handles closing the entry of a patient who has been seen
+func (c *QueueController) Complete(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid queue entry ID", err)
+		return
+	}
+
+	entry, err := c.queueService.Complete(id, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to complete queue entry", zap.Error(err), zap.Uint("entry_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to complete queue entry", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Queue entry completed successfully",
+		"entry":   entry,
+	})
+}
+
+// Remove handles taking a waiting patient off the queue
+func (c *QueueController) Remove(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid queue entry ID", err)
+		return
+	}
+
+	var req RemoveFromQueueRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid queue removal request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	entry, err := c.queueService.Remove(id, req.Left, req.Reason)
+	if err != nil {
+		c.logger.Error("Failed to remove queue entry", zap.Error(err), zap.Uint("entry_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to remove queue entry", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Patient removed from the queue successfully",
+		"entry":   entry,
+	})
+}
+
+// GetMetrics handles the wait-time metrics. Without dates it covers today.
+func (c *QueueController) GetMetrics(ctx *gin.Context) {
+	from, err := parseTimeQuery(ctx, "from", false)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid from date", err)
+		return
+	}
+	to, err := parseTimeQuery(ctx, "to", true)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid to date", err)
+		return
+	}
+	if from.IsZero() {
+		now := time.Now()
+		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	metrics, err := c.queueService.GetMetrics(from, to)
+	if err != nil {
+		c.logger.Error("Failed to build queue metrics", zap.Error(err))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to build queue metrics", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"metrics": metrics,
+	})
+}
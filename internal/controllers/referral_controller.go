@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// ReferralController handles referral requests
+type ReferralController struct {
+	referralService *services.ReferralService
+	logger          *zap.Logger
+}
+
+// NewReferralController creates a new referral controller instance
+func NewReferralController(referralService *services.ReferralService, logger *zap.Logger) *ReferralController {
+	return &ReferralController{
+		referralService: referralService,
+		logger:          logger,
+	}
+}
+
+// ReferralRequest represents the referral request body. Set either
+// receiving_doctor_id or external_provider.
+type ReferralRequest struct {
+	ReceivingDoctorID *uint  `json:"receiving_doctor_id"`
+	ExternalProvider  string `json:"external_provider"`
+	ExternalContact   string `json:"external_contact"`
+	Specialty         string `json:"specialty"`
+	Reason            string `json:"reason" binding:"required"`
+	Urgency           string `json:"urgency" binding:"omitempty,oneof=routine urgent emergency"`
+	DocumentIDs       []uint `json:"document_ids"`
+}
+
+// ReferralResponseRequest represents the body of an accept, decline or
+// complete request
+type ReferralResponseRequest struct {
+	Note string `json:"note"` // required to decline; the outcome when completing
+}
+
+// CreateReferral handles referring a patient
+func (c *ReferralController) CreateReferral(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req ReferralRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid referral request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	referral, err := c.referralService.CreateReferral(patientID, services.ReferralInput{
+		ReceivingDoctorID: req.ReceivingDoctorID,
+		ExternalProvider:  req.ExternalProvider,
+		ExternalContact:   req.ExternalContact,
+		Specialty:         req.Specialty,
+		Reason:            req.Reason,
+		Urgency:           req.Urgency,
+		DocumentIDs:       req.DocumentIDs,
+	}, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to create referral", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to create referral", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":  "Referral sent successfully",
+		"referral": referral,
+	})
+}
+
+// GetPatientReferrals handles listing a patient's referrals
+func (c *ReferralController) GetPatientReferrals(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	referrals, err := c.referralService.GetPatientReferrals(patientID)
+	if err != nil {
+		c.logger.Error("Failed to fetch referrals", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to fetch referrals", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"referrals": referrals,
+	})
+}
+
+// GetInbox handles listing the referrals sent to the current doctor
+func (c *ReferralController) GetInbox(ctx *gin.Context) {
+	referrals, err := c.referralService.GetInbox(currentUserID(ctx), ctx.Query("status"))
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to fetch referral inbox", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"referrals": referrals,
+	})
+}
+
+// GetSent handles listing the referrals the current doctor has sent
+func (c *ReferralController) GetSent(ctx *gin.Context) {
+	referrals, err := c.referralService.GetSent(currentUserID(ctx), ctx.Query("status"))
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to fetch sent referrals", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"referrals": referrals,
+	})
+}
+
+// GetReferral handles retrieving a referral
+func (c *ReferralController) GetReferral(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid referral ID", err)
+		return
+	}
+
+	referral, err := c.referralService.GetReferral(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Referral not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"referral": referral,
+	})
+}
+
+// AcceptReferral handles accepting a referral
+func (c *ReferralController) AcceptReferral(ctx *gin.Context) {
+	c.respond(ctx, "accept", c.referralService.Accept)
+}
+
+// DeclineReferral handles declining a referral
+func (c *ReferralController) DeclineReferral(ctx *gin.Context) {
+	c.respond(ctx, "decline", c.referralService.Decline)
+}
+
+// CompleteReferral handles completing an accepted referral
+func (c *ReferralController) CompleteReferral(ctx *gin.Context) {
+	c.respond(ctx, "complete", c.referralService.Complete)
+}
+
+func (c *ReferralController) respond(ctx *gin.Context, action string, apply func(id, userID uint, note string) (*models.Referral, error)) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid referral ID", err)
+		return
+	}
+
+	// The body is optional when there is nothing to note
+	var req ReferralResponseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	referral, err := apply(id, currentUserID(ctx), req.Note)
+	if err != nil {
+		c.logger.Error("Failed to "+action+" referral", zap.Error(err), zap.Uint("referral_id", id))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to "+action+" referral", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Referral " + referral.Status + " successfully",
+		"referral": referral,
+	})
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// VitalSignsController handles vital signs related requests
+type VitalSignsController struct {
+	vitalsService *services.VitalSignsService
+	logger        *zap.Logger
+}
+
+// NewVitalSignsController creates a new vital signs controller instance
+func NewVitalSignsController(vitalsService *services.VitalSignsService, logger *zap.Logger) *VitalSignsController {
+	return &VitalSignsController{
+		vitalsService: vitalsService,
+		logger:        logger,
+	}
+}
+
+// VitalSignsRequest represents the vital signs request body
+type VitalSignsRequest struct {
+	EncounterID          *uint      `json:"encounter_id"`
+	RecordedAt           *time.Time `json:"recorded_at"`
+	SystolicBP           *int       `json:"systolic_bp"`
+	DiastolicBP          *int       `json:"diastolic_bp"`
+	HeartRate            *int       `json:"heart_rate"`
+	RespiratoryRate      *int       `json:"respiratory_rate"`
+	Temperature          *float64   `json:"temperature"`
+	SpO2                 *int       `json:"spo2"`
+	OnSupplementalOxygen bool       `json:"on_supplemental_oxygen"`
+	HypercapnicFailure   bool       `json:"hypercapnic_failure"`
+	Consciousness        string     `json:"consciousness" binding:"omitempty,oneof=alert confusion voice pain unresponsive"`
+	WeightKg             *float64   `json:"weight_kg"`
+	HeightCm             *float64   `json:"height_cm"`
+	Notes                string     `json:"notes"`
+}
+
+// RecordVitals handles recording a set of vital signs
+func (c *VitalSignsController) RecordVitals(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+
+	var req VitalSignsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid vital signs request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	vitals := &models.VitalSigns{
+		EncounterID:          req.EncounterID,
+		SystolicBP:           req.SystolicBP,
+		DiastolicBP:          req.DiastolicBP,
+		HeartRate:            req.HeartRate,
+		RespiratoryRate:      req.RespiratoryRate,
+		Temperature:          req.Temperature,
+		SpO2:                 req.SpO2,
+		OnSupplementalOxygen: req.OnSupplementalOxygen,
+		HypercapnicFailure:   req.HypercapnicFailure,
+		Consciousness:        req.Consciousness,
+		WeightKg:             req.WeightKg,
+		HeightCm:             req.HeightCm,
+		Notes:                req.Notes,
+	}
+	if req.RecordedAt != nil {
+		vitals.RecordedAt = *req.RecordedAt
+	}
+
+	created, err := c.vitalsService.RecordVitals(patientID, vitals, currentUserID(ctx))
+	if err != nil {
+		c.logger.Error("Failed to record vital signs", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to record vital signs", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Vital signs recorded successfully",
+		"vitals":  created,
+	})
+}
+
+// GetVitalsSeries handles retrieving a patient's vital signs over a time range
+func (c *VitalSignsController) GetVitalsSeries(ctx *gin.Context) {
+	patientID, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid patient ID", err)
+		return
+	}
+	from, err := parseTimeQuery(ctx, "from", false)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid from parameter", err)
+		return
+	}
+	to, err := parseTimeQuery(ctx, "to", true)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid to parameter", err)
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "500"))
+	if err != nil || limit < 1 {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid limit parameter", err)
+		return
+	}
+
+	series, err := c.vitalsService.GetVitalsSeries(patientID, from, to, limit)
+	if err != nil {
+		c.logger.Error("Failed to fetch vital signs", zap.Error(err), zap.Uint("patient_id", patientID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to fetch vital signs", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"vitals": series,
+	})
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/services"
+	"hospital-portal/internal/utils"
+)
+
+// WebhookController handles webhook subscription and delivery requests
+type WebhookController struct {
+	webhookService *services.WebhookService
+	logger         *zap.Logger
+}
+
+// NewWebhookController creates a new webhook controller instance
+func NewWebhookController(webhookService *services.WebhookService, logger *zap.Logger) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+// WebhookSubscriptionRequest represents the webhook subscription request body
+type WebhookSubscriptionRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required,min=1"`
+	Active *bool    `json:"active"`
+}
+
+func (r WebhookSubscriptionRequest) input() services.WebhookSubscriptionInput {
+	return services.WebhookSubscriptionInput{
+		Name:   r.Name,
+		URL:    r.URL,
+		Events: r.Events,
+		Active: r.Active,
+	}
+}
+
+// GetSubscriptions handles listing webhook subscriptions
+func (c *WebhookController) GetSubscriptions(ctx *gin.Context) {
+	subscriptions, err := c.webhookService.GetSubscriptions()
+	if err != nil {
+		c.logger.Error("Failed to fetch webhook subscriptions", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, "Failed to fetch webhook subscriptions", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"subscriptions": subscriptions,
+	})
+}
+
+// GetSubscription handles retrieving a webhook subscription
+func (c *WebhookController) GetSubscription(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid subscription ID", err)
+		return
+	}
+
+	subscription, err := c.webhookService.GetSubscription(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Webhook subscription not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"subscription": subscription,
+	})
+}
+
+// CreateSubscription handles adding a webhook subscription. The signing
+// secret is in the response and is not shown again.
+func (c *WebhookController) CreateSubscription(ctx *gin.Context) {
+	var req WebhookSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid webhook subscription request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	subscription, secret, err := c.webhookService.CreateSubscription(req.input(), currentUserID(ctx))
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusInternalServerError), "Failed to create webhook subscription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":      "Webhook subscription created successfully",
+		"subscription": subscription,
+		"secret":       secret,
+	})
+}
+
+// UpdateSubscription handles changing a webhook subscription
+func (c *WebhookController) UpdateSubscription(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid subscription ID", err)
+		return
+	}
+
+	var req WebhookSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Error("Invalid webhook subscription request", zap.Error(err))
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid input", err)
+		return
+	}
+
+	subscription, err := c.webhookService.UpdateSubscription(id, req.input())
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to update webhook subscription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Webhook subscription updated successfully",
+		"subscription": subscription,
+	})
+}
+
+// RotateSecret handles replacing a webhook subscription's signing secret
+func (c *WebhookController) RotateSecret(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid subscription ID", err)
+		return
+	}
+
+	subscription, secret, err := c.webhookService.RotateSecret(id)
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to rotate webhook secret", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":      "Webhook secret rotated successfully",
+		"subscription": subscription,
+		"secret":       secret,
+	})
+}
+
+// DeleteSubscription handles removing a webhook subscription
+func (c *WebhookController) DeleteSubscription(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid subscription ID", err)
+		return
+	}
+
+	if err := c.webhookService.DeleteSubscription(id); err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Failed to delete webhook subscription", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Webhook subscription deleted successfully",
+	})
+}
+
+// GetDeliveries handles paging through a subscription's delivery log,
+// optionally filtered by status, with offset and limit
+func (c *WebhookController) GetDeliveries(ctx *gin.Context) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid subscription ID", err)
+		return
+	}
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+
+	deliveries, err := c.webhookService.GetDeliveries(id, ctx.Query("status"), offset, limit)
+	if err != nil {
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to fetch webhook deliveries", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+	})
+}
+
+// GetDelivery handles retrieving one delivery with its payload and the
+// endpoint's last answer
+func (c *WebhookController) GetDelivery(ctx *gin.Context) {
+	id, deliveryID, ok := c.deliveryParams(ctx)
+	if !ok {
+		return
+	}
+
+	delivery, err := c.webhookService.GetDelivery(id, deliveryID)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusNotFound, "Webhook delivery not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"delivery": delivery,
+	})
+}
+
+// Redeliver handles queueing a delivery's event to be sent again
+func (c *WebhookController) Redeliver(ctx *gin.Context) {
+	id, deliveryID, ok := c.deliveryParams(ctx)
+	if !ok {
+		return
+	}
+
+	delivery, err := c.webhookService.Redeliver(id, deliveryID)
+	if err != nil {
+		c.logger.Error("Failed to redeliver webhook", zap.Error(err), zap.Uint("delivery_id", deliveryID))
+		utils.ErrorResponse(ctx, statusForError(err, http.StatusNotFound), "Failed to redeliver webhook", err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message":  "Redelivery queued",
+		"delivery": delivery,
+	})
+}
+
+func (c *WebhookController) deliveryParams(ctx *gin.Context) (uint, uint, bool) {
+	id, err := parseIDParam(ctx, "id")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid subscription ID", err)
+		return 0, 0, false
+	}
+	deliveryID, err := parseIDParam(ctx, "deliveryId")
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, "Invalid delivery ID", err)
+		return 0, 0, false
+	}
+	return id, deliveryID, true
+}
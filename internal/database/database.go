@@ -0,0 +1,108 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/viper"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// Connect opens the PostgreSQL connection described by DATABASE_URL or
+// the database section of the config
+func Connect() *gorm.DB {
+	var dsn string
+
+	// Check if DATABASE_URL is set
+	if os.Getenv("DATABASE_URL") != "" {
+		// Use the DATABASE_URL directly
+		dsn = os.Getenv("DATABASE_URL")
+	} else {
+		// Construct DSN from individual environment variables or config
+
+		dsn = fmt.Sprintf(
+			"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
+			getEnvOrDefault("PGHOST", viper.GetString("database.host")),
+			getEnvOrDefault("PGUSER", viper.GetString("database.user")),
+			getEnvOrDefault("PGPASSWORD", viper.GetString("database.password")),
+			getEnvOrDefault("PGDATABASE", viper.GetString("database.dbname")),
+			getEnvOrDefault("PGPORT", viper.GetString("database.port")),
+		)
+	}
+
+	log.Println("Connecting to database...")
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	return db
+}
+
+// Migrate runs the GORM auto migrations for all models
+func Migrate(db *gorm.DB) {
+	log.Println("Running auto migrations...")
+	err := db.AutoMigrate(
+		&models.User{},
+		&models.Patient{},
+		&models.Allergy{},
+		&models.Prescription{},
+		&models.DrugInteraction{},
+		&models.InteractionAlert{},
+		&models.Encounter{},
+		&models.VitalSigns{},
+		&models.CareTeamMember{},
+		&models.Notification{},
+		&models.ICD10Code{},
+		&models.Problem{},
+		&models.LabOrder{},
+		&models.LabResult{},
+		&models.PatientDocument{},
+		&models.PatientConsent{},
+		&models.PatientContact{},
+		&models.Payer{},
+		&models.InsuranceCoverage{},
+		&models.ChargeMasterItem{},
+		&models.Charge{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
+		&models.Payment{},
+		&models.ClaimBatch{},
+		&models.InsuranceClaim{},
+		&models.Remittance{},
+		&models.ClaimAdjustment{},
+		&models.Ward{},
+		&models.Room{},
+		&models.Bed{},
+		&models.Admission{},
+		&models.AdmissionBedMovement{},
+		&models.DischargeSummary{},
+		&models.DischargeSummaryItem{},
+		&models.QueueEntry{},
+		&models.Immunization{},
+		&models.Referral{},
+		&models.BulkExportJob{},
+		&models.BulkExportFile{},
+		&models.PatientIdentifier{},
+		&models.HL7DeadLetter{},
+		&models.PatientImportJob{},
+		&models.PatientImportError{},
+		&models.AuditLog{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+	)
+	if err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+}
+
+func getEnvOrDefault(env string, defaultValue string) string {
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+	return defaultValue
+}
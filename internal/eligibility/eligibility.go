@@ -0,0 +1,50 @@
+// Package eligibility checks whether a patient's insurance coverage is
+// active with the payer. Real clearinghouse integrations implement
+// Checker; the stub lets the flow be exercised locally.
+package eligibility
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Eligibility outcomes
+const (
+	StatusEligible   = "eligible"
+	StatusIneligible = "ineligible"
+)
+
+// Request describes the coverage to verify
+type Request struct {
+	PayerCode   string
+	EDIPayerID  string
+	MemberID    string
+	GroupNumber string
+	PatientName string
+	ServiceDate time.Time
+}
+
+// Result is the payer's answer
+type Result struct {
+	Status  string
+	Message string
+}
+
+// Checker verifies coverage with a payer. An error means the check could
+// not be completed, not that the patient is ineligible.
+type Checker interface {
+	Check(ctx context.Context, req Request) (Result, error)
+}
+
+// NewFromConfig builds the checker selected by insurance.eligibility.driver
+func NewFromConfig() (Checker, error) {
+	switch driver := viper.GetString("insurance.eligibility.driver"); driver {
+	case "", "stub":
+		return StubChecker{}, nil
+	default:
+		return nil, fmt.Errorf("unknown eligibility driver %q", driver)
+	}
+}
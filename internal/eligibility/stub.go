@@ -0,0 +1,27 @@
+package eligibility
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// StubChecker answers eligibility checks without contacting a payer.
+// Member IDs ending in "9" are reported ineligible and IDs ending in "8"
+// fail as if the payer were unreachable; all others are eligible.
+type StubChecker struct{}
+
+// Check returns a canned answer based on the member ID
+func (StubChecker) Check(ctx context.Context, req Request) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	switch {
+	case strings.HasSuffix(req.MemberID, "9"):
+		return Result{Status: StatusIneligible, Message: "stub: member not found or coverage terminated"}, nil
+	case strings.HasSuffix(req.MemberID, "8"):
+		return Result{}, errors.New("stub: payer unavailable")
+	default:
+		return Result{Status: StatusEligible, Message: "stub: coverage active"}, nil
+	}
+}
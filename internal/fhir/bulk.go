@@ -0,0 +1,25 @@
+package fhir
+
+// NDJSONContentType is the media type of bulk data files, one resource per line
+const NDJSONContentType = "application/fhir+ndjson"
+
+// ExportOutputFormats are the _outputFormat values a bulk export accepts,
+// all meaning NDJSON
+var ExportOutputFormats = []string{NDJSONContentType, "application/ndjson", "ndjson"}
+
+// ExportFile is a file of a completed bulk export
+type ExportFile struct {
+	Type  string `json:"type"`
+	URL   string `json:"url"`
+	Count int    `json:"count,omitempty"`
+}
+
+// ExportManifest is the Bulk Data complete-status response listing the
+// files of an export. It is plain JSON, not a FHIR resource.
+type ExportManifest struct {
+	TransactionTime     string       `json:"transactionTime"`
+	Request             string       `json:"request"`
+	RequiresAccessToken bool         `json:"requiresAccessToken"`
+	Output              []ExportFile `json:"output"`
+	Error               []ExportFile `json:"error"`
+}
@@ -0,0 +1,134 @@
+package fhir
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SearchParam describes a search parameter the server supports
+type SearchParam struct {
+	Name          string `json:"name"`
+	Definition    string `json:"definition,omitempty"`
+	Type          string `json:"type"` // string, token, date, ...
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// Interaction is a RESTful interaction the server supports
+type Interaction struct {
+	Code string `json:"code"` // read, search-type, create, update, ...
+}
+
+// Operation is an extended operation the server supports
+type Operation struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
+// RestResource describes what the server supports for a resource type
+type RestResource struct {
+	Type              string        `json:"type"`
+	Profile           string        `json:"profile,omitempty"`
+	Interaction       []Interaction `json:"interaction"`
+	Versioning        string        `json:"versioning,omitempty"`
+	ReadHistory       bool          `json:"readHistory"`
+	UpdateCreate      bool          `json:"updateCreate"`
+	ConditionalCreate bool          `json:"conditionalCreate"`
+	SearchParam       []SearchParam `json:"searchParam,omitempty"`
+}
+
+// RestSecurity describes how the server is secured
+type RestSecurity struct {
+	Cors        bool              `json:"cors"`
+	Service     []CodeableConcept `json:"service,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// Rest describes the RESTful server
+type Rest struct {
+	Mode      string         `json:"mode"` // server
+	Security  *RestSecurity  `json:"security,omitempty"`
+	Resource  []RestResource `json:"resource"`
+	Operation []Operation    `json:"operation,omitempty"`
+}
+
+// Software names the server software
+type Software struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Implementation is the server instance the statement describes
+type Implementation struct {
+	Description string `json:"description"`
+	URL         string `json:"url,omitempty"`
+}
+
+// CapabilityStatement describes what the server can do
+type CapabilityStatement struct {
+	Status         string          `json:"status"` // active
+	Date           string          `json:"date"`
+	Kind           string          `json:"kind"` // instance
+	Software       *Software       `json:"software,omitempty"`
+	Implementation *Implementation `json:"implementation,omitempty"`
+	FHIRVersion    string          `json:"fhirVersion"`
+	Format         []string        `json:"format"`
+	Rest           []Rest          `json:"rest"`
+}
+
+// ResourceType implements Resource
+func (CapabilityStatement) ResourceType() string { return "CapabilityStatement" }
+
+// MarshalJSON adds the resourceType element
+func (c CapabilityStatement) MarshalJSON() ([]byte, error) {
+	type statement CapabilityStatement
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		statement
+	}{c.ResourceType(), statement(c)})
+}
+
+// NewCapabilityStatement describes this server, reachable at baseURL
+func NewCapabilityStatement(baseURL, software string, date time.Time) *CapabilityStatement {
+	return &CapabilityStatement{
+		Status:         "active",
+		Date:           date.UTC().Format(time.RFC3339),
+		Kind:           "instance",
+		Software:       &Software{Name: software},
+		Implementation: &Implementation{Description: software + " FHIR API", URL: baseURL},
+		FHIRVersion:    Version,
+		Format:         []string{"json"},
+		Rest: []Rest{{
+			Mode: "server",
+			Security: &RestSecurity{
+				Service: []CodeableConcept{{Text: "Bearer token"}},
+				Description: "Requests other than metadata need the portal's bearer token " +
+					"in the Authorization header",
+			},
+			Resource: []RestResource{{
+				Type:    "Patient",
+				Profile: "http://hl7.org/fhir/StructureDefinition/Patient",
+				Interaction: []Interaction{
+					{Code: "read"},
+					{Code: "search-type"},
+					{Code: "create"},
+					{Code: "update"},
+				},
+				Versioning: "no-version",
+				SearchParam: []SearchParam{
+					{Name: "_id", Type: "token", Definition: "http://hl7.org/fhir/SearchParameter/Resource-id"},
+					{Name: "identifier", Type: "token", Definition: "http://hl7.org/fhir/SearchParameter/Patient-identifier"},
+					{Name: "name", Type: "string", Definition: "http://hl7.org/fhir/SearchParameter/Patient-name",
+						Documentation: "Matches the start of any part of the name; supports :exact and :contains"},
+					{Name: "gender", Type: "token", Definition: "http://hl7.org/fhir/SearchParameter/individual-gender"},
+					{Name: "birthdate", Type: "date", Definition: "http://hl7.org/fhir/SearchParameter/individual-birthdate",
+						Documentation: "Supports the eq, lt, le, gt and ge prefixes"},
+					{Name: "_count", Type: "number", Documentation: "Page size"},
+				},
+			}},
+			Operation: []Operation{
+				{Name: "export", Definition: "http://hl7.org/fhir/uv/bulkdata/OperationDefinition/export"},
+				{Name: "patient-export", Definition: "http://hl7.org/fhir/uv/bulkdata/OperationDefinition/patient-export"},
+			},
+		}},
+	}
+}
@@ -0,0 +1,223 @@
+package fhir
+
+import "encoding/json"
+
+// Reference points at another resource, e.g. "Patient/12"
+type Reference struct {
+	Reference string `json:"reference,omitempty"`
+	Display   string `json:"display,omitempty"`
+}
+
+// Period is a time range; an open end means it is ongoing
+type Period struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+}
+
+// Quantity is a measured amount
+type Quantity struct {
+	Value  *float64 `json:"value,omitempty"`
+	Unit   string   `json:"unit,omitempty"`
+	System string   `json:"system,omitempty"`
+	Code   string   `json:"code,omitempty"`
+}
+
+// Annotation is a free-text note
+type Annotation struct {
+	Text string `json:"text"`
+}
+
+// Encounter is the FHIR Encounter resource
+type Encounter struct {
+	ID         string            `json:"id,omitempty"`
+	Meta       *Meta             `json:"meta,omitempty"`
+	Status     string            `json:"status"` // planned, in-progress, finished, cancelled, ...
+	Class      Coding            `json:"class"`
+	Type       []CodeableConcept `json:"type,omitempty"`
+	Subject    *Reference        `json:"subject,omitempty"`
+	Period     *Period           `json:"period,omitempty"`
+	ReasonCode []CodeableConcept `json:"reasonCode,omitempty"`
+}
+
+// ResourceType implements Resource
+func (Encounter) ResourceType() string { return "Encounter" }
+
+// MarshalJSON adds the resourceType element
+func (e Encounter) MarshalJSON() ([]byte, error) {
+	type encounter Encounter
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		encounter
+	}{e.ResourceType(), encounter(e)})
+}
+
+// Condition is the FHIR Condition resource, here a problem list item
+type Condition struct {
+	ID                 string            `json:"id,omitempty"`
+	Meta               *Meta             `json:"meta,omitempty"`
+	ClinicalStatus     *CodeableConcept  `json:"clinicalStatus,omitempty"`
+	VerificationStatus *CodeableConcept  `json:"verificationStatus,omitempty"`
+	Category           []CodeableConcept `json:"category,omitempty"`
+	Code               *CodeableConcept  `json:"code,omitempty"`
+	Subject            Reference         `json:"subject"`
+	Encounter          *Reference        `json:"encounter,omitempty"`
+	OnsetDateTime      string            `json:"onsetDateTime,omitempty"`
+	AbatementDateTime  string            `json:"abatementDateTime,omitempty"`
+	RecordedDate       string            `json:"recordedDate,omitempty"`
+	Note               []Annotation      `json:"note,omitempty"`
+}
+
+// ResourceType implements Resource
+func (Condition) ResourceType() string { return "Condition" }
+
+// MarshalJSON adds the resourceType element
+func (c Condition) MarshalJSON() ([]byte, error) {
+	type condition Condition
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		condition
+	}{c.ResourceType(), condition(c)})
+}
+
+// AllergyReaction is a reaction recorded against an allergy
+type AllergyReaction struct {
+	Manifestation []CodeableConcept `json:"manifestation"`
+	Severity      string            `json:"severity,omitempty"` // mild, moderate, severe
+}
+
+// AllergyIntolerance is the FHIR AllergyIntolerance resource
+type AllergyIntolerance struct {
+	ID                 string            `json:"id,omitempty"`
+	Meta               *Meta             `json:"meta,omitempty"`
+	ClinicalStatus     *CodeableConcept  `json:"clinicalStatus,omitempty"`
+	VerificationStatus *CodeableConcept  `json:"verificationStatus,omitempty"`
+	Category           []string          `json:"category,omitempty"` // food, medication, environment, biologic
+	Criticality        string            `json:"criticality,omitempty"`
+	Code               *CodeableConcept  `json:"code,omitempty"`
+	Patient            Reference         `json:"patient"`
+	RecordedDate       string            `json:"recordedDate,omitempty"`
+	Reaction           []AllergyReaction `json:"reaction,omitempty"`
+	Note               []Annotation      `json:"note,omitempty"`
+}
+
+// ResourceType implements Resource
+func (AllergyIntolerance) ResourceType() string { return "AllergyIntolerance" }
+
+// MarshalJSON adds the resourceType element
+func (a AllergyIntolerance) MarshalJSON() ([]byte, error) {
+	type allergy AllergyIntolerance
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		allergy
+	}{a.ResourceType(), allergy(a)})
+}
+
+// Dosage is how a medication is to be taken
+type Dosage struct {
+	Text  string           `json:"text,omitempty"`
+	Route *CodeableConcept `json:"route,omitempty"`
+}
+
+// DispenseRequest is the supply authorised by a prescription
+type DispenseRequest struct {
+	ValidityPeriod         *Period   `json:"validityPeriod,omitempty"`
+	NumberOfRepeatsAllowed int       `json:"numberOfRepeatsAllowed"`
+	Quantity               *Quantity `json:"quantity,omitempty"`
+}
+
+// MedicationRequest is the FHIR MedicationRequest resource, here a prescription
+type MedicationRequest struct {
+	ID                        string           `json:"id,omitempty"`
+	Meta                      *Meta            `json:"meta,omitempty"`
+	Status                    string           `json:"status"` // active, completed, stopped, ...
+	StatusReason              *CodeableConcept `json:"statusReason,omitempty"`
+	Intent                    string           `json:"intent"` // order
+	MedicationCodeableConcept *CodeableConcept `json:"medicationCodeableConcept,omitempty"`
+	Subject                   Reference        `json:"subject"`
+	AuthoredOn                string           `json:"authoredOn,omitempty"`
+	Requester                 *Reference       `json:"requester,omitempty"`
+	PriorPrescription         *Reference       `json:"priorPrescription,omitempty"`
+	DosageInstruction         []Dosage         `json:"dosageInstruction,omitempty"`
+	DispenseRequest           *DispenseRequest `json:"dispenseRequest,omitempty"`
+}
+
+// ResourceType implements Resource
+func (MedicationRequest) ResourceType() string { return "MedicationRequest" }
+
+// MarshalJSON adds the resourceType element
+func (m MedicationRequest) MarshalJSON() ([]byte, error) {
+	type request MedicationRequest
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		request
+	}{m.ResourceType(), request(m)})
+}
+
+// ObservationRange is the reference range of an observation
+type ObservationRange struct {
+	Low  *Quantity `json:"low,omitempty"`
+	High *Quantity `json:"high,omitempty"`
+}
+
+// Observation is the FHIR Observation resource, here a vital sign or a lab
+// result
+type Observation struct {
+	ID                string             `json:"id,omitempty"`
+	Meta              *Meta              `json:"meta,omitempty"`
+	Status            string             `json:"status"` // preliminary, final, ...
+	Category          []CodeableConcept  `json:"category,omitempty"`
+	Code              CodeableConcept    `json:"code"`
+	Subject           Reference          `json:"subject"`
+	Encounter         *Reference         `json:"encounter,omitempty"`
+	EffectiveDateTime string             `json:"effectiveDateTime,omitempty"`
+	ValueQuantity     *Quantity          `json:"valueQuantity,omitempty"`
+	ValueString       string             `json:"valueString,omitempty"`
+	Interpretation    []CodeableConcept  `json:"interpretation,omitempty"`
+	Note              []Annotation       `json:"note,omitempty"`
+	ReferenceRange    []ObservationRange `json:"referenceRange,omitempty"`
+}
+
+// ResourceType implements Resource
+func (Observation) ResourceType() string { return "Observation" }
+
+// MarshalJSON adds the resourceType element
+func (o Observation) MarshalJSON() ([]byte, error) {
+	type observation Observation
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		observation
+	}{o.ResourceType(), observation(o)})
+}
+
+// ImmunizationProtocol is the dose of a series an immunization was
+type ImmunizationProtocol struct {
+	DoseNumberPositiveInt int `json:"doseNumberPositiveInt"`
+}
+
+// Immunization is the FHIR Immunization resource
+type Immunization struct {
+	ID                 string                 `json:"id,omitempty"`
+	Meta               *Meta                  `json:"meta,omitempty"`
+	Status             string                 `json:"status"` // completed, entered-in-error, not-done
+	StatusReason       *CodeableConcept       `json:"statusReason,omitempty"`
+	VaccineCode        CodeableConcept        `json:"vaccineCode"`
+	Patient            Reference              `json:"patient"`
+	OccurrenceDateTime string                 `json:"occurrenceDateTime"`
+	PrimarySource      bool                   `json:"primarySource"`
+	LotNumber          string                 `json:"lotNumber,omitempty"`
+	Site               *CodeableConcept       `json:"site,omitempty"`
+	Note               []Annotation           `json:"note,omitempty"`
+	ProtocolApplied    []ImmunizationProtocol `json:"protocolApplied,omitempty"`
+}
+
+// ResourceType implements Resource
+func (Immunization) ResourceType() string { return "Immunization" }
+
+// MarshalJSON adds the resourceType element
+func (i Immunization) MarshalJSON() ([]byte, error) {
+	type immunization Immunization
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		immunization
+	}{i.ResourceType(), immunization(i)})
+}
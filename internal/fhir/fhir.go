@@ -0,0 +1,221 @@
+// Package fhir holds the HL7 FHIR R4 resources the portal exposes, limited
+// to the elements it fills in, and helpers to build search bundles and
+// OperationOutcome errors.
+package fhir
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Version is the FHIR version the API implements
+const Version = "4.0.1"
+
+// ContentType is the media type of FHIR JSON bodies
+const ContentType = "application/fhir+json"
+
+// Resource is any FHIR resource
+type Resource interface {
+	ResourceType() string
+}
+
+// Meta is the metadata of a resource
+type Meta struct {
+	LastUpdated *time.Time `json:"lastUpdated,omitempty"`
+}
+
+// Coding is a code from a code system
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// CodeableConcept is a concept given by codings and/or text
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+// Identifier is a business identifier of a resource
+type Identifier struct {
+	Use    string           `json:"use,omitempty"`
+	Type   *CodeableConcept `json:"type,omitempty"`
+	System string           `json:"system,omitempty"`
+	Value  string           `json:"value,omitempty"`
+}
+
+// HumanName is the name of a person
+type HumanName struct {
+	Use    string   `json:"use,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Family string   `json:"family,omitempty"`
+	Given  []string `json:"given,omitempty"`
+}
+
+// ContactPoint is a phone number, email address or similar
+type ContactPoint struct {
+	System string `json:"system,omitempty"` // phone, fax, email, ...
+	Value  string `json:"value,omitempty"`
+	Use    string `json:"use,omitempty"`
+}
+
+// Address is a postal address
+type Address struct {
+	Use        string   `json:"use,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Line       []string `json:"line,omitempty"`
+	City       string   `json:"city,omitempty"`
+	State      string   `json:"state,omitempty"`
+	PostalCode string   `json:"postalCode,omitempty"`
+	Country    string   `json:"country,omitempty"`
+}
+
+// PatientContact is a guardian, next of kin or other contact of a patient
+type PatientContact struct {
+	Relationship []CodeableConcept `json:"relationship,omitempty"`
+	Name         *HumanName        `json:"name,omitempty"`
+	Telecom      []ContactPoint    `json:"telecom,omitempty"`
+	Address      *Address          `json:"address,omitempty"`
+}
+
+// Patient is the FHIR Patient resource
+type Patient struct {
+	ID         string           `json:"id,omitempty"`
+	Meta       *Meta            `json:"meta,omitempty"`
+	Identifier []Identifier     `json:"identifier,omitempty"`
+	Active     *bool            `json:"active,omitempty"`
+	Name       []HumanName      `json:"name,omitempty"`
+	Telecom    []ContactPoint   `json:"telecom,omitempty"`
+	Gender     string           `json:"gender,omitempty"`    // male, female, other, unknown
+	BirthDate  string           `json:"birthDate,omitempty"` // YYYY, YYYY-MM or YYYY-MM-DD
+	Address    []Address        `json:"address,omitempty"`
+	Contact    []PatientContact `json:"contact,omitempty"`
+}
+
+// ResourceType implements Resource
+func (Patient) ResourceType() string { return "Patient" }
+
+// MarshalJSON adds the resourceType element
+func (p Patient) MarshalJSON() ([]byte, error) {
+	type patient Patient
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		patient
+	}{p.ResourceType(), patient(p)})
+}
+
+// BundleLink is a navigation link of a bundle
+type BundleLink struct {
+	Relation string `json:"relation"`
+	URL      string `json:"url"`
+}
+
+// BundleSearch tells why an entry is in a search set
+type BundleSearch struct {
+	Mode string `json:"mode"` // match or include
+}
+
+// BundleEntry is a resource in a bundle
+type BundleEntry struct {
+	FullURL  string        `json:"fullUrl,omitempty"`
+	Resource Resource      `json:"resource"`
+	Search   *BundleSearch `json:"search,omitempty"`
+}
+
+// Bundle is a collection of resources, here the result of a search
+type Bundle struct {
+	Type      string        `json:"type"` // searchset
+	Timestamp *time.Time    `json:"timestamp,omitempty"`
+	Total     *int          `json:"total,omitempty"`
+	Link      []BundleLink  `json:"link,omitempty"`
+	Entry     []BundleEntry `json:"entry,omitempty"`
+}
+
+// ResourceType implements Resource
+func (Bundle) ResourceType() string { return "Bundle" }
+
+// MarshalJSON adds the resourceType element
+func (b Bundle) MarshalJSON() ([]byte, error) {
+	type bundle Bundle
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		bundle
+	}{b.ResourceType(), bundle(b)})
+}
+
+// NewSearchSet starts a search set bundle with the total number of matches
+func NewSearchSet(total int) *Bundle {
+	now := time.Now().UTC()
+	return &Bundle{Type: "searchset", Timestamp: &now, Total: &total}
+}
+
+// AddLink adds a navigation link, such as self, next or previous
+func (b *Bundle) AddLink(relation, url string) {
+	b.Link = append(b.Link, BundleLink{Relation: relation, URL: url})
+}
+
+// AddMatch adds a resource that matched the search
+func (b *Bundle) AddMatch(fullURL string, resource Resource) {
+	b.Entry = append(b.Entry, BundleEntry{FullURL: fullURL, Resource: resource, Search: &BundleSearch{Mode: "match"}})
+}
+
+// Issue severities and the issue codes the API reports
+const (
+	SeverityError       = "error"
+	SeverityInformation = "information"
+
+	IssueInvalid       = "invalid"
+	IssueNotFound      = "not-found"
+	IssueNotSupported  = "not-supported"
+	IssueConflict      = "conflict"
+	IssueForbidden     = "forbidden"
+	IssueProcessing    = "processing"
+	IssueException     = "exception"
+	IssueInformational = "informational"
+)
+
+// OperationOutcomeIssue is a single error, warning or information message
+type OperationOutcomeIssue struct {
+	Severity    string `json:"severity"`
+	Code        string `json:"code"`
+	Diagnostics string `json:"diagnostics,omitempty"`
+}
+
+// OperationOutcome reports the outcome of an operation, usually an error
+type OperationOutcome struct {
+	Issue []OperationOutcomeIssue `json:"issue"`
+}
+
+// ResourceType implements Resource
+func (OperationOutcome) ResourceType() string { return "OperationOutcome" }
+
+// MarshalJSON adds the resourceType element
+func (o OperationOutcome) MarshalJSON() ([]byte, error) {
+	type outcome OperationOutcome
+	return json.Marshal(struct {
+		ResourceType string `json:"resourceType"`
+		outcome
+	}{o.ResourceType(), outcome(o)})
+}
+
+// NewOperationOutcome builds an outcome with a single issue
+func NewOperationOutcome(severity, code, diagnostics string) *OperationOutcome {
+	return &OperationOutcome{Issue: []OperationOutcomeIssue{{Severity: severity, Code: code, Diagnostics: diagnostics}}}
+}
+
+// DecodePatient parses a Patient resource, checking its resourceType
+func DecodePatient(data []byte) (*Patient, error) {
+	var patient struct {
+		ResourceType string `json:"resourceType"`
+		Patient
+	}
+	if err := json.Unmarshal(data, &patient); err != nil {
+		return nil, err
+	}
+	if patient.ResourceType != "Patient" {
+		return nil, fmt.Errorf("expected a Patient resource, got %q", patient.ResourceType)
+	}
+	return &patient.Patient, nil
+}
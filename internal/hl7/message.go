@@ -0,0 +1,303 @@
+// Package hl7 parses HL7 v2 messages, builds acknowledgements for them and
+// carries them over MLLP, the framing legacy interfaces use on TCP.
+package hl7
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Acknowledgement codes of MSA-1, original mode
+const (
+	AckAccept = "AA" // processed
+	AckError  = "AE" // could not be processed; resending will not help
+	AckReject = "AR" // not accepted, e.g. an unsupported message type
+)
+
+// Error codes of HL7 table 0357, reported in ERR-3
+const (
+	ErrSegmentSequence      = "100"
+	ErrRequiredFieldMissing = "101"
+	ErrDataType             = "102"
+	ErrUnsupportedMessage   = "200"
+	ErrUnsupportedEvent     = "201"
+	ErrUnknownKey           = "204"
+	ErrApplicationInternal  = "207"
+)
+
+// errorTexts are the descriptions of the table 0357 codes
+var errorTexts = map[string]string{
+	ErrSegmentSequence:      "Segment sequence error",
+	ErrRequiredFieldMissing: "Required field missing",
+	ErrDataType:             "Data type error",
+	ErrUnsupportedMessage:   "Unsupported message type",
+	ErrUnsupportedEvent:     "Unsupported event code",
+	ErrUnknownKey:           "Unknown key identifier",
+	ErrApplicationInternal:  "Application internal error",
+}
+
+// Encoding holds the delimiters a message declares in MSH-1 and MSH-2
+type Encoding struct {
+	Field        byte
+	Component    byte
+	Repetition   byte
+	Escape       byte
+	Subcomponent byte
+}
+
+// DefaultEncoding is the usual |^~\& set
+var DefaultEncoding = Encoding{Field: '|', Component: '^', Repetition: '~', Escape: '\\', Subcomponent: '&'}
+
+// Segment is one line of a message. Fields are kept raw and indexed by
+// their HL7 position, so Fields[3] is PID-3; for MSH, Fields[1] is the
+// field separator itself.
+type Segment struct {
+	Name   string
+	Fields []string
+}
+
+// Message is a parsed HL7 v2 message
+type Message struct {
+	Encoding Encoding
+	Segments []Segment
+}
+
+// Parse splits a message into segments and fields. The message must start
+// with an MSH segment; segments may end with CR, LF or CRLF.
+func Parse(data []byte) (*Message, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\r")
+	text = strings.ReplaceAll(text, "\n", "\r")
+	text = strings.Trim(text, "\r")
+	if len(text) < 8 || !strings.HasPrefix(text, "MSH") {
+		return nil, errors.New("message does not start with an MSH segment")
+	}
+
+	enc := Encoding{Field: text[3]}
+	chars := text[4:]
+	if end := strings.IndexByte(chars, enc.Field); end >= 0 {
+		chars = chars[:end]
+	}
+	if len(chars) < 4 {
+		return nil, errors.New("MSH-2 must declare the component, repetition, escape and subcomponent separators")
+	}
+	enc.Component, enc.Repetition, enc.Escape, enc.Subcomponent = chars[0], chars[1], chars[2], chars[3]
+
+	message := &Message{Encoding: enc}
+	for _, line := range strings.Split(text, "\r") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, string(enc.Field))
+		if len(fields[0]) != 3 {
+			return nil, fmt.Errorf("invalid segment name %q", fields[0])
+		}
+		if fields[0] == "MSH" {
+			// MSH-1 is the separator between the name and MSH-2
+			fields = append([]string{"MSH", string(enc.Field)}, fields[1:]...)
+		}
+		message.Segments = append(message.Segments, Segment{Name: fields[0], Fields: fields})
+	}
+	return message, nil
+}
+
+// Segment returns the first segment with the given name, or nil
+func (m *Message) Segment(name string) *Segment {
+	for i := range m.Segments {
+		if m.Segments[i].Name == name {
+			return &m.Segments[i]
+		}
+	}
+	return nil
+}
+
+// SegmentsNamed returns all segments with the given name, in order
+func (m *Message) SegmentsNamed(name string) []*Segment {
+	var segments []*Segment
+	for i := range m.Segments {
+		if m.Segments[i].Name == name {
+			segments = append(segments, &m.Segments[i])
+		}
+	}
+	return segments
+}
+
+// Field returns the raw value of a field, or "" when it is absent
+func (s *Segment) Field(n int) string {
+	if s == nil || n < 0 || n >= len(s.Fields) {
+		return ""
+	}
+	return s.Fields[n]
+}
+
+// Repetitions splits a raw field into its repetitions
+func (m *Message) Repetitions(field string) []string {
+	if field == "" {
+		return nil
+	}
+	return strings.Split(field, string(m.Encoding.Repetition))
+}
+
+// Component returns the unescaped n-th component (1-based) of a raw field
+// or repetition, up to its first subcomponent separator
+func (m *Message) Component(value string, n int) string {
+	components := strings.Split(value, string(m.Encoding.Component))
+	if n < 1 || n > len(components) {
+		return ""
+	}
+	component := components[n-1]
+	if i := strings.IndexByte(component, m.Encoding.Subcomponent); i >= 0 {
+		component = component[:i]
+	}
+	return m.Unescape(component)
+}
+
+// Get returns component c of the first repetition of field n of the first
+// segment with the given name, e.g. Get("PID", 5, 1) for the family name
+func (m *Message) Get(segment string, n, c int) string {
+	repetitions := m.Repetitions(m.Segment(segment).Field(n))
+	if len(repetitions) == 0 {
+		return ""
+	}
+	return m.Component(repetitions[0], c)
+}
+
+// Type returns the message code and trigger event of MSH-9, e.g. "ADT" and
+// "A04"
+func (m *Message) Type() (string, string) {
+	return m.Get("MSH", 9, 1), m.Get("MSH", 9, 2)
+}
+
+// ControlID returns MSH-10, echoed in the acknowledgement
+func (m *Message) ControlID() string {
+	return m.Get("MSH", 10, 1)
+}
+
+// Unescape replaces the escape sequences for the delimiters. Formatting
+// and hexadecimal escapes are dropped.
+func (m *Message) Unescape(value string) string {
+	esc := string(m.Encoding.Escape)
+	if !strings.Contains(value, esc) {
+		return value
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(value, esc)
+		if start < 0 {
+			b.WriteString(value)
+			return b.String()
+		}
+		end := strings.Index(value[start+1:], esc)
+		if end < 0 {
+			b.WriteString(value)
+			return b.String()
+		}
+		b.WriteString(value[:start])
+		switch value[start+1 : start+1+end] {
+		case "F":
+			b.WriteByte(m.Encoding.Field)
+		case "S":
+			b.WriteByte(m.Encoding.Component)
+		case "R":
+			b.WriteByte(m.Encoding.Repetition)
+		case "E":
+			b.WriteByte(m.Encoding.Escape)
+		case "T":
+			b.WriteByte(m.Encoding.Subcomponent)
+		case ".br":
+			b.WriteByte('\n')
+		}
+		value = value[start+end+2:]
+	}
+}
+
+// EscapeText replaces the delimiters in a value with escape sequences
+func (e Encoding) EscapeText(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case e.Escape:
+			b.WriteString(string(e.Escape) + "E" + string(e.Escape))
+		case e.Field:
+			b.WriteString(string(e.Escape) + "F" + string(e.Escape))
+		case e.Component:
+			b.WriteString(string(e.Escape) + "S" + string(e.Escape))
+		case e.Repetition:
+			b.WriteString(string(e.Escape) + "R" + string(e.Escape))
+		case e.Subcomponent:
+			b.WriteString(string(e.Escape) + "T" + string(e.Escape))
+		case '\r', '\n':
+			b.WriteString(string(e.Escape) + ".br" + string(e.Escape))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// ParseDate reads the date part of an HL7 DT or TS value, YYYYMMDD
+// optionally followed by a time
+func ParseDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// Timestamp formats a time as an HL7 TS value
+func Timestamp(t time.Time) string {
+	return t.Format("20060102150405-0700")
+}
+
+// ErrorDetail describes why a message was not accepted. Code is a table 0357
+// error code; Location names the segment and field at fault, e.g. "PID^1^7".
+type ErrorDetail struct {
+	Code     string
+	Location string
+	Text     string
+}
+
+// Ack builds the original-mode acknowledgement of a message. app and
+// facility identify this system in MSH-3 and MSH-4; the message's sender
+// becomes the receiver. detail is nil for AA.
+func Ack(m *Message, code, app, facility, controlID string, detail *ErrorDetail, now time.Time) []byte {
+	enc := DefaultEncoding
+	var trigger, sendingApp, sendingFacility, originalID, processingID, version string
+	if m != nil {
+		enc = m.Encoding
+		_, trigger = m.Type()
+		msh := m.Segment("MSH")
+		sendingApp, sendingFacility = msh.Field(3), msh.Field(4)
+		originalID, processingID, version = msh.Field(10), msh.Field(11), msh.Field(12)
+	}
+	if processingID == "" {
+		processingID = "P"
+	}
+	if version == "" {
+		version = "2.5.1"
+	}
+
+	f := string(enc.Field)
+	c := string(enc.Component)
+	encodingChars := string([]byte{enc.Component, enc.Repetition, enc.Escape, enc.Subcomponent})
+	messageType := "ACK"
+	if trigger != "" {
+		messageType = "ACK" + c + enc.EscapeText(trigger) + c + "ACK"
+	}
+
+	segments := []string{
+		strings.Join([]string{"MSH", encodingChars, enc.EscapeText(app), enc.EscapeText(facility), sendingApp, sendingFacility,
+			Timestamp(now), "", messageType, enc.EscapeText(controlID), processingID, version}, f),
+	}
+	msa := []string{"MSA", code, originalID}
+	if detail != nil {
+		msa = append(msa, enc.EscapeText(detail.Text))
+	}
+	segments = append(segments, strings.Join(msa, f))
+	if detail != nil {
+		errorCode := detail.Code + c + enc.EscapeText(errorTexts[detail.Code]) + c + "HL70357"
+		segments = append(segments, strings.Join([]string{"ERR", "", detail.Location, errorCode, "E", "", "", "", enc.EscapeText(detail.Text)}, f))
+	}
+	return []byte(strings.Join(segments, "\r") + "\r")
+}
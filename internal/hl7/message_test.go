@@ -0,0 +1,308 @@
+package hl7
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// segments joins segments with the carriage returns HL7 ends them with
+func segments(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r") + "\r")
+}
+
+var (
+	a01 = segments(
+		`MSH|^~\&|REGSYS|GENHOSP|PORTAL|GENHOSP|20261015083000||ADT^A01^ADT_A01|MSG00001|P|2.5.1`,
+		`EVN|A01|20261015083000`,
+		`PID|1||100234^^^GENHOSP^MR~998877^^^STATE^SS||DOE^JANE^Q^^^^L~SMITH^JANE^^^^^M||19800412|F|||12 Elm St^Apt 3^Chicago^IL^60614^USA||(312)555-0199^PRN^PH~^NET^Internet^jane@example.com`,
+		`NK1|1|DOE^JOHN|SPO^Spouse|12 Elm St^^Chicago^IL^60614^USA|(312)555-0123||C`,
+		`PV1|1|I|4WEST^401^A`,
+	)
+	a04 = segments(
+		`MSH|^~\&|REGSYS|GENHOSP|PORTAL|GENHOSP|20261015090000||ADT^A04|MSG00002|P|2.5.1`,
+		`EVN|A04|20261015090000`,
+		`PID|1||100500^^^GENHOSP^MR||ROE^SAM||20150703|M|||5 Oak Ave^^Evanston^IL^60201||^PRN^PH^^1^847^5550142`,
+		`NK1|1|ROE^RICHARD|FTH||(847)555-0143`,
+		`PV1|1|O`,
+	)
+	a08 = segments(
+		`MSH|^~\&|REGSYS|GENHOSP|PORTAL|GENHOSP|20261016101500||ADT^A08|MSG00003|P|2.5.1`,
+		`EVN|A08|20261016101500`,
+		`PID|1||100234^^^GENHOSP^MR||DOE^JANE^Q||19800412|F|||77 Lake Shore Dr^^Chicago^IL^60611^USA`,
+	)
+	a40 = segments(
+		`MSH|^~\&|REGSYS|GENHOSP|PORTAL|GENHOSP|20261017120000||ADT^A40^ADT_A39|MSG00004|P|2.5.1`,
+		`EVN|A40|20261017120000`,
+		`PID|1||100234^^^GENHOSP^MR||DOE^JANE^Q||19800412|F`,
+		`MRG|100999^^^GENHOSP^MR`,
+	)
+)
+
+func TestParseA01(t *testing.T) {
+	message, err := Parse(a01)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if message.Encoding != DefaultEncoding {
+		t.Errorf("Encoding = %+v", message.Encoding)
+	}
+	if code, trigger := message.Type(); code != "ADT" || trigger != "A01" {
+		t.Errorf("Type = %s, %s", code, trigger)
+	}
+	if id := message.ControlID(); id != "MSG00001" {
+		t.Errorf("ControlID = %s", id)
+	}
+	if got := len(message.Segments); got != 5 {
+		t.Fatalf("got %d segments, want 5", got)
+	}
+
+	// MSH-1 is the field separator, so the positions match the standard
+	msh := message.Segment("MSH")
+	if msh.Field(1) != "|" || msh.Field(2) != `^~\&` || msh.Field(3) != "REGSYS" || msh.Field(12) != "2.5.1" {
+		t.Errorf("MSH fields = %q", msh.Fields)
+	}
+
+	pid := message.Segment("PID")
+	identifiers := message.Repetitions(pid.Field(3))
+	if len(identifiers) != 2 {
+		t.Fatalf("PID-3 has %d repetitions, want 2", len(identifiers))
+	}
+	if message.Component(identifiers[0], 1) != "100234" || message.Component(identifiers[0], 4) != "GENHOSP" || message.Component(identifiers[0], 5) != "MR" {
+		t.Errorf("PID-3 first repetition = %q", identifiers[0])
+	}
+	if message.Component(identifiers[1], 5) != "SS" {
+		t.Errorf("PID-3 second repetition = %q", identifiers[1])
+	}
+	if family := message.Get("PID", 5, 1); family != "DOE" {
+		t.Errorf("PID-5.1 = %q", family)
+	}
+	if birth := message.Get("PID", 7, 1); birth != "19800412" {
+		t.Errorf("PID-7 = %q", birth)
+	}
+	if city := message.Get("PID", 11, 3); city != "Chicago" {
+		t.Errorf("PID-11.3 = %q", city)
+	}
+	if phones := message.Repetitions(pid.Field(13)); len(phones) != 2 || message.Component(phones[1], 4) != "jane@example.com" {
+		t.Errorf("PID-13 = %q", phones)
+	}
+	if relationship := message.Get("NK1", 3, 2); relationship != "Spouse" {
+		t.Errorf("NK1-3.2 = %q", relationship)
+	}
+}
+
+func TestParseA04A08A40(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      []byte
+		trigger  string
+		control  string
+		segments []string
+	}{
+		{"A04", a04, "A04", "MSG00002", []string{"MSH", "EVN", "PID", "NK1", "PV1"}},
+		{"A08", a08, "A08", "MSG00003", []string{"MSH", "EVN", "PID"}},
+		{"A40", a40, "A40", "MSG00004", []string{"MSH", "EVN", "PID", "MRG"}},
+	}
+	for _, tt := range tests {
+		message, err := Parse(tt.raw)
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", tt.name, err)
+		}
+		if code, trigger := message.Type(); code != "ADT" || trigger != tt.trigger {
+			t.Errorf("%s: Type = %s, %s", tt.name, code, trigger)
+		}
+		if id := message.ControlID(); id != tt.control {
+			t.Errorf("%s: ControlID = %s", tt.name, id)
+		}
+		var names []string
+		for _, segment := range message.Segments {
+			names = append(names, segment.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.segments, ",") {
+			t.Errorf("%s: segments = %v, want %v", tt.name, names, tt.segments)
+		}
+	}
+
+	message, _ := Parse(a40)
+	if prior := message.Get("MRG", 1, 1); prior != "100999" {
+		t.Errorf("MRG-1.1 = %q", prior)
+	}
+	message, _ = Parse(a04)
+	if area := message.Get("PID", 13, 6); area != "847" {
+		t.Errorf("PID-13.6 = %q", area)
+	}
+}
+
+func TestParseLineEndingsAndDelimiters(t *testing.T) {
+	for name, raw := range map[string]string{
+		"LF":   "MSH|^~\\&|A|B|C|D|20261015||ADT^A08|1|P|2.5.1\nPID|1||42^^^X^MR\n",
+		"CRLF": "MSH|^~\\&|A|B|C|D|20261015||ADT^A08|1|P|2.5.1\r\nPID|1||42^^^X^MR\r\n",
+		"#$*@": "MSH#$*@%#A#B#C#D#20261015##ADT$A08#1#P#2.5.1\rPID#1##42$$$X$MR\r",
+	} {
+		message, err := Parse([]byte(raw))
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", name, err)
+		}
+		if len(message.Segments) != 2 {
+			t.Errorf("%s: got %d segments, want 2", name, len(message.Segments))
+		}
+		if _, trigger := message.Type(); trigger != "A08" {
+			t.Errorf("%s: trigger = %q", name, trigger)
+		}
+		if id := message.Get("PID", 3, 1); id != "42" {
+			t.Errorf("%s: PID-3.1 = %q", name, id)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, raw := range map[string]string{
+		"empty":         "",
+		"no MSH":        "PID|1||42\r",
+		"short MSH-2":   "MSH|^~|A|B\r",
+		"bad segment":   "MSH|^~\\&|A|B|C|D|20261015||ADT^A08|1|P|2.5.1\rPIDX|1\r",
+		"short segment": "MSH|^~\\&|A|B|C|D|20261015||ADT^A08|1|P|2.5.1\rPI|1\r",
+	} {
+		if _, err := Parse([]byte(raw)); err == nil {
+			t.Errorf("%s: Parse succeeded, want an error", name)
+		}
+	}
+}
+
+func TestComponentUnescapesAndStopsAtSubcomponents(t *testing.T) {
+	message, err := Parse(segments(
+		`MSH|^~\&|A|B|C|D|20261015||ADT^A08|1|P|2.5.1`,
+		`PID|1||42&extra^^^X^MR||O\S\BRIEN^ANN \T\ CO\.br\LINE\X0D\`,
+	))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if id := message.Get("PID", 3, 1); id != "42" {
+		t.Errorf("PID-3.1 = %q", id)
+	}
+	if family := message.Get("PID", 5, 1); family != "O^BRIEN" {
+		t.Errorf("PID-5.1 = %q", family)
+	}
+	if given := message.Get("PID", 5, 2); given != "ANN & CO\nLINE" {
+		t.Errorf("PID-5.2 = %q", given)
+	}
+	if missing := message.Get("PID", 5, 9); missing != "" {
+		t.Errorf("absent component = %q", missing)
+	}
+	if missing := message.Get("ZZZ", 1, 1); missing != "" {
+		t.Errorf("absent segment = %q", missing)
+	}
+}
+
+func TestEscapeTextRoundTrip(t *testing.T) {
+	message, _ := Parse(a08)
+	value := `a|b^c~d\e&f` + "\n" + "g"
+	if got := message.Unescape(DefaultEncoding.EscapeText(value)); got != value {
+		t.Errorf("round trip = %q, want %q", got, value)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for value, want := range map[string]string{
+		"19800412":            "1980-04-12",
+		"19800412083000":      "1980-04-12",
+		"19800412083000-0500": "1980-04-12",
+		"20240229":            "2024-02-29",
+	} {
+		got, err := ParseDate(value)
+		if err != nil || got.Format("2006-01-02") != want {
+			t.Errorf("ParseDate(%q) = %v, %v", value, got, err)
+		}
+	}
+	for _, value := range []string{"", "1980", "198004", "19801340", "20230229"} {
+		if _, err := ParseDate(value); err == nil {
+			t.Errorf("ParseDate(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestAckAccept(t *testing.T) {
+	message, _ := Parse(a01)
+	now := time.Date(2026, 10, 15, 8, 30, 5, 0, time.FixedZone("CDT", -5*3600))
+	ack, err := Parse(Ack(message, AckAccept, "PORTAL", "GENHOSP", "ACK0001", nil, now))
+	if err != nil {
+		t.Fatalf("ACK does not parse: %v", err)
+	}
+
+	msh := ack.Segment("MSH")
+	// The sender of the message is the receiver of the ACK
+	if msh.Field(3) != "PORTAL" || msh.Field(4) != "GENHOSP" || msh.Field(5) != "REGSYS" || msh.Field(6) != "GENHOSP" {
+		t.Errorf("MSH-3..6 = %q", msh.Fields[3:7])
+	}
+	if msh.Field(7) != "20261015083005-0500" {
+		t.Errorf("MSH-7 = %q", msh.Field(7))
+	}
+	if msh.Field(9) != "ACK^A01^ACK" || msh.Field(10) != "ACK0001" || msh.Field(11) != "P" || msh.Field(12) != "2.5.1" {
+		t.Errorf("MSH-9..12 = %q", msh.Fields[9:])
+	}
+	msa := ack.Segment("MSA")
+	if msa.Field(1) != AckAccept || msa.Field(2) != "MSG00001" || msa.Field(3) != "" {
+		t.Errorf("MSA = %q", msa.Fields)
+	}
+	if ack.Segment("ERR") != nil {
+		t.Error("an AA carries an ERR segment")
+	}
+}
+
+func TestAckErrorAndReject(t *testing.T) {
+	message, _ := Parse(a04)
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	detail := &ErrorDetail{Code: ErrDataType, Location: "PID^1^7", Text: "PID-7 date of birth \"1980|13\" is not a valid date"}
+	ack, err := Parse(Ack(message, AckError, "PORTAL", "", "ACK0002", detail, now))
+	if err != nil {
+		t.Fatalf("AE does not parse: %v", err)
+	}
+	msa := ack.Segment("MSA")
+	if msa.Field(1) != AckError || msa.Field(2) != "MSG00002" {
+		t.Errorf("MSA = %q", msa.Fields)
+	}
+	// The delimiter in the text is escaped rather than splitting the field
+	if text := ack.Get("MSA", 3, 1); text != detail.Text {
+		t.Errorf("MSA-3 = %q", text)
+	}
+	errSegment := ack.Segment("ERR")
+	if errSegment == nil {
+		t.Fatal("AE has no ERR segment")
+	}
+	if errSegment.Field(2) != "PID^1^7" {
+		t.Errorf("ERR-2 = %q", errSegment.Field(2))
+	}
+	if ack.Get("ERR", 3, 1) != ErrDataType || ack.Get("ERR", 3, 2) != "Data type error" || ack.Get("ERR", 3, 3) != "HL70357" {
+		t.Errorf("ERR-3 = %q", errSegment.Field(3))
+	}
+	if errSegment.Field(4) != "E" || ack.Get("ERR", 8, 1) != detail.Text {
+		t.Errorf("ERR = %q", errSegment.Fields)
+	}
+
+	detail = &ErrorDetail{Code: ErrUnsupportedEvent, Location: "MSH^1^9", Text: "event A99 is not supported"}
+	ack, err = Parse(Ack(message, AckReject, "PORTAL", "", "ACK0003", detail, now))
+	if err != nil {
+		t.Fatalf("AR does not parse: %v", err)
+	}
+	if ack.Get("MSA", 1, 1) != AckReject || ack.Get("ERR", 3, 1) != ErrUnsupportedEvent || ack.Get("ERR", 3, 2) != "Unsupported event code" {
+		t.Errorf("AR = %q", ack.Segments)
+	}
+}
+
+func TestAckOfUnparsableMessage(t *testing.T) {
+	detail := &ErrorDetail{Code: ErrSegmentSequence, Location: "MSH", Text: "message does not start with an MSH segment"}
+	ack, err := Parse(Ack(nil, AckReject, "PORTAL", "GENHOSP", "ACK0004", detail, time.Now()))
+	if err != nil {
+		t.Fatalf("ACK does not parse: %v", err)
+	}
+	if got := ack.Segment("MSH").Field(9); got != "ACK" {
+		t.Errorf("MSH-9 = %q", got)
+	}
+	if ack.Get("MSA", 1, 1) != AckReject || ack.Get("MSA", 2, 1) != "" {
+		t.Errorf("MSA = %q", ack.Segment("MSA").Fields)
+	}
+	if ack.Get("ERR", 3, 1) != ErrSegmentSequence {
+		t.Errorf("ERR-3 = %q", ack.Segment("ERR").Field(3))
+	}
+}
@@ -0,0 +1,197 @@
+package hl7
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// MLLP frame delimiters: a message is sent as <VT> message <FS><CR>
+const (
+	startBlock     = 0x0b
+	endBlock       = 0x1c
+	carriageReturn = 0x0d
+)
+
+// ErrFrameTooLarge is returned when a frame exceeds the size limit
+var ErrFrameTooLarge = errors.New("MLLP frame exceeds the size limit")
+
+// ReadFrame reads the next MLLP frame and returns the message inside it.
+// Bytes before the start block, such as stray line breaks, are skipped. A
+// maxSize of 0 means no limit.
+func ReadFrame(r *bufio.Reader, maxSize int) ([]byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == startBlock {
+			break
+		}
+	}
+
+	var message []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if b == endBlock {
+			next, err := r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			if next != carriageReturn {
+				return nil, fmt.Errorf("MLLP end block followed by 0x%02x instead of a carriage return", next)
+			}
+			return message, nil
+		}
+		if maxSize > 0 && len(message) >= maxSize {
+			return nil, ErrFrameTooLarge
+		}
+		message = append(message, b)
+	}
+}
+
+// WriteFrame writes a message as an MLLP frame
+func WriteFrame(w io.Writer, message []byte) error {
+	frame := make([]byte, 0, len(message)+3)
+	frame = append(frame, startBlock)
+	frame = append(frame, message...)
+	frame = append(frame, endBlock, carriageReturn)
+	_, err := w.Write(frame)
+	return err
+}
+
+// Handler processes a received message and returns the acknowledgement
+// to send back. remoteAddr is the sending peer.
+type Handler func(message []byte, remoteAddr string) []byte
+
+// Server accepts MLLP connections and answers every message with the
+// handler's acknowledgement. Messages on a connection are handled one at
+// a time, in order, as senders wait for each ACK before the next message.
+type Server struct {
+	Addr           string
+	Handler        Handler
+	IdleTimeout    time.Duration // connections idle this long are closed
+	MaxMessageSize int           // 0 means no limit
+	Logger         *zap.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// ListenAndServe listens on the server's address and serves connections
+// until Close is called
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on the listener until Close is called
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return net.ErrClosed
+	}
+	s.listener = listener
+	s.conns = make(map[net.Conn]struct{})
+	s.mu.Unlock()
+
+	s.Logger.Info("MLLP listener started", zap.String("address", listener.Addr().String()))
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return net.ErrClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections, closes the open ones and waits for
+// messages being handled to finish
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	remoteAddr := conn.RemoteAddr().String()
+	s.Logger.Info("MLLP connection opened", zap.String("remote_addr", remoteAddr))
+	reader := bufio.NewReader(conn)
+	for {
+		if s.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		message, err := ReadFrame(reader, s.MaxMessageSize)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				s.Logger.Info("MLLP connection closed", zap.String("remote_addr", remoteAddr))
+			} else {
+				// The stream cannot be resynchronised reliably, so drop it
+				s.Logger.Warn("MLLP connection dropped", zap.Error(err), zap.String("remote_addr", remoteAddr))
+			}
+			return
+		}
+
+		ack := s.Handler(message, remoteAddr)
+		if s.IdleTimeout > 0 {
+			conn.SetWriteDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		if err := WriteFrame(conn, ack); err != nil {
+			s.Logger.Warn("Failed to send MLLP acknowledgement", zap.Error(err), zap.String("remote_addr", remoteAddr))
+			return
+		}
+	}
+}
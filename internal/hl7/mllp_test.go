@@ -0,0 +1,333 @@
+package hl7
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func frame(message string) string {
+	return "\x0b" + message + "\x1c\r"
+}
+
+func TestReadFrame(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(frame("MSH|one")))
+	message, err := ReadFrame(reader, 0)
+	if err != nil || string(message) != "MSH|one" {
+		t.Fatalf("ReadFrame = %q, %v", message, err)
+	}
+	if _, err := ReadFrame(reader, 0); !errors.Is(err, io.EOF) {
+		t.Errorf("after the last frame got %v, want io.EOF", err)
+	}
+}
+
+func TestReadFrameBackToBack(t *testing.T) {
+	// Frames may arrive in one read, with line breaks between them
+	reader := bufio.NewReader(strings.NewReader(frame("MSH|one") + frame("MSH|two") + "\r\n" + frame("MSH|three")))
+	for _, want := range []string{"MSH|one", "MSH|two", "MSH|three"} {
+		message, err := ReadFrame(reader, 0)
+		if err != nil || string(message) != want {
+			t.Fatalf("ReadFrame = %q, %v, want %q", message, err, want)
+		}
+	}
+}
+
+func TestReadFrameSkipsBytesBeforeStartBlock(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("garbage\r\n" + frame("MSH|one")))
+	message, err := ReadFrame(reader, 0)
+	if err != nil || string(message) != "MSH|one" {
+		t.Fatalf("ReadFrame = %q, %v", message, err)
+	}
+}
+
+func TestReadFrameMissingEndBlock(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("\x0bMSH|cut off"))
+	if _, err := ReadFrame(reader, 0); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v, want io.ErrUnexpectedEOF", err)
+	}
+
+	reader = bufio.NewReader(strings.NewReader("\x0bMSH|cut off\x1c"))
+	if _, err := ReadFrame(reader, 0); err == nil {
+		t.Error("an end block without the carriage return was accepted at EOF")
+	}
+
+	reader = bufio.NewReader(strings.NewReader("\x0bMSH|one\x1cX" + frame("MSH|two")))
+	if _, err := ReadFrame(reader, 0); err == nil || !strings.Contains(err.Error(), "0x58") {
+		t.Errorf("got %v, want an error naming the byte after the end block", err)
+	}
+}
+
+func TestReadFrameSizeLimit(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader(frame("12345")))
+	if message, err := ReadFrame(reader, 5); err != nil || string(message) != "12345" {
+		t.Errorf("a message at the limit: %q, %v", message, err)
+	}
+
+	reader = bufio.NewReader(strings.NewReader(frame("123456")))
+	if _, err := ReadFrame(reader, 5); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("got %v, want ErrFrameTooLarge", err)
+	}
+}
+
+func TestWriteFrame(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteFrame(&out, []byte("MSH|one")); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != frame("MSH|one") {
+		t.Errorf("frame = %q", got)
+	}
+	message, err := ReadFrame(bufio.NewReader(&out), 0)
+	if err != nil || string(message) != "MSH|one" {
+		t.Errorf("round trip = %q, %v", message, err)
+	}
+}
+
+// testHandler acknowledges messages like an ADT interface: AA for the
+// events it supports, AE for a missing PID and AR for anything else
+func testHandler(raw []byte, remoteAddr string) []byte {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	message, err := Parse(raw)
+	if err != nil {
+		return Ack(nil, AckReject, "PORTAL", "GENHOSP", "ACK", &ErrorDetail{Code: ErrSegmentSequence, Location: "MSH", Text: err.Error()}, now)
+	}
+	code, trigger := message.Type()
+	switch {
+	case code != "ADT":
+		return Ack(message, AckReject, "PORTAL", "GENHOSP", "ACK", &ErrorDetail{Code: ErrUnsupportedMessage, Location: "MSH^1^9", Text: "message type " + code + " is not supported"}, now)
+	case trigger != "A01" && trigger != "A04" && trigger != "A08" && trigger != "A40":
+		return Ack(message, AckReject, "PORTAL", "GENHOSP", "ACK", &ErrorDetail{Code: ErrUnsupportedEvent, Location: "MSH^1^9", Text: "event " + trigger + " is not supported"}, now)
+	case message.Segment("PID") == nil:
+		return Ack(message, AckError, "PORTAL", "GENHOSP", "ACK", &ErrorDetail{Code: ErrSegmentSequence, Location: "PID", Text: "PID segment is required"}, now)
+	}
+	return Ack(message, AckAccept, "PORTAL", "GENHOSP", "ACK", nil, now)
+}
+
+// startServer serves handler on a loopback port and returns its address
+func startServer(t *testing.T, server *Server) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("loopback listener unavailable: %v", err)
+	}
+	if server.Logger == nil {
+		server.Logger = zap.NewNop()
+	}
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+	t.Cleanup(func() {
+		server.Close()
+		if err := <-done; !errors.Is(err, net.ErrClosed) {
+			t.Errorf("Serve returned %v, want net.ErrClosed", err)
+		}
+	})
+	return listener.Addr().String()
+}
+
+// exchange sends a message and reads back the parsed acknowledgement
+func exchange(t *testing.T, conn net.Conn, reader *bufio.Reader, message []byte) *Message {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := WriteFrame(conn, message); err != nil {
+		t.Fatalf("WriteFrame failed: %v", err)
+	}
+	raw, err := ReadFrame(reader, 0)
+	if err != nil {
+		t.Fatalf("no acknowledgement: %v", err)
+	}
+	ack, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("acknowledgement does not parse: %v", err)
+	}
+	return ack
+}
+
+func TestServerAcknowledgesEachMessageInOrder(t *testing.T) {
+	addr := startServer(t, &Server{Handler: testHandler, MaxMessageSize: 64 << 10})
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	tests := []struct {
+		name      string
+		message   []byte
+		code      string
+		control   string
+		errorCode string
+		location  string
+	}{
+		{"A01", a01, AckAccept, "MSG00001", "", ""},
+		{"A04", a04, AckAccept, "MSG00002", "", ""},
+		{"A08", a08, AckAccept, "MSG00003", "", ""},
+		{"A40", a40, AckAccept, "MSG00004", "", ""},
+		{"no PID", segments(`MSH|^~\&|REGSYS|GENHOSP|PORTAL|GENHOSP|20261015||ADT^A08|MSG00005|P|2.5.1`, `EVN|A08`), AckError, "MSG00005", ErrSegmentSequence, "PID"},
+		{"unsupported event", segments(`MSH|^~\&|REGSYS|GENHOSP|PORTAL|GENHOSP|20261015||ADT^A99|MSG00006|P|2.5.1`), AckReject, "MSG00006", ErrUnsupportedEvent, "MSH^1^9"},
+		{"unsupported type", segments(`MSH|^~\&|REGSYS|GENHOSP|PORTAL|GENHOSP|20261015||ORU^R01|MSG00007|P|2.5.1`), AckReject, "MSG00007", ErrUnsupportedMessage, "MSH^1^9"},
+		{"not HL7", []byte("hello"), AckReject, "", ErrSegmentSequence, "MSH"},
+	}
+	for _, tt := range tests {
+		ack := exchange(t, conn, reader, tt.message)
+		if got := ack.Get("MSA", 1, 1); got != tt.code {
+			t.Errorf("%s: MSA-1 = %s, want %s", tt.name, got, tt.code)
+		}
+		if got := ack.Get("MSA", 2, 1); got != tt.control {
+			t.Errorf("%s: MSA-2 = %q, want %q", tt.name, got, tt.control)
+		}
+		errSegment := ack.Segment("ERR")
+		if tt.errorCode == "" {
+			if errSegment != nil {
+				t.Errorf("%s: unexpected ERR %q", tt.name, errSegment.Fields)
+			}
+			continue
+		}
+		if errSegment == nil {
+			t.Errorf("%s: no ERR segment", tt.name)
+			continue
+		}
+		if got := ack.Get("ERR", 3, 1); got != tt.errorCode {
+			t.Errorf("%s: ERR-3 = %s, want %s", tt.name, got, tt.errorCode)
+		}
+		if got := errSegment.Field(2); got != tt.location {
+			t.Errorf("%s: ERR-2 = %q, want %q", tt.name, got, tt.location)
+		}
+		if ack.Get("ERR", 8, 1) == "" || ack.Get("MSA", 3, 1) != ack.Get("ERR", 8, 1) {
+			t.Errorf("%s: error text missing: MSA-3 %q, ERR-8 %q", tt.name, ack.Get("MSA", 3, 1), ack.Get("ERR", 8, 1))
+		}
+	}
+}
+
+func TestServerHandlesBackToBackFrames(t *testing.T) {
+	addr := startServer(t, &Server{Handler: testHandler})
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// A sender that does not wait for each ACK still gets them in order
+	var frames bytes.Buffer
+	for _, message := range [][]byte{a01, a08, a40} {
+		WriteFrame(&frames, message)
+	}
+	if _, err := conn.Write(frames.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	for _, want := range []string{"MSG00001", "MSG00003", "MSG00004"} {
+		raw, err := ReadFrame(reader, 0)
+		if err != nil {
+			t.Fatalf("no acknowledgement for %s: %v", want, err)
+		}
+		ack, err := Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ack.Get("MSA", 1, 1) != AckAccept || ack.Get("MSA", 2, 1) != want {
+			t.Errorf("got MSA %q, want AA for %s", ack.Segment("MSA").Fields, want)
+		}
+	}
+}
+
+func TestServerDropsOversizeFrames(t *testing.T) {
+	handled := make(chan struct{}, 1)
+	handler := func(raw []byte, remoteAddr string) []byte {
+		handled <- struct{}{}
+		return testHandler(raw, remoteAddr)
+	}
+	addr := startServer(t, &Server{Handler: handler, MaxMessageSize: 64})
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	WriteFrame(conn, a01)
+	// The stream cannot be trusted after an oversize frame, so the
+	// connection is closed without an acknowledgement
+	if _, err := ReadFrame(bufio.NewReader(conn), 0); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want the connection closed", err)
+	}
+	select {
+	case <-handled:
+		t.Error("an oversize message reached the handler")
+	default:
+	}
+}
+
+func TestServerDropsMalformedFrames(t *testing.T) {
+	addr := startServer(t, &Server{Handler: testHandler})
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// An end block must be followed by a carriage return
+	conn.Write([]byte("\x0b" + string(a08) + "\x1cX"))
+	if _, err := ReadFrame(bufio.NewReader(conn), 0); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want the connection closed", err)
+	}
+}
+
+func TestServerClosesIdleConnections(t *testing.T) {
+	addr := startServer(t, &Server{Handler: testHandler, IdleTimeout: 50 * time.Millisecond})
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	if ack := exchange(t, conn, reader, a08); ack.Get("MSA", 1, 1) != AckAccept {
+		t.Fatalf("MSA-1 = %s", ack.Get("MSA", 1, 1))
+	}
+	if _, err := ReadFrame(reader, 0); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want the idle connection closed", err)
+	}
+}
+
+func TestServerCloseEndsOpenConnections(t *testing.T) {
+	server := &Server{Handler: testHandler, Logger: zap.NewNop()}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("loopback listener unavailable: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(conn)
+	exchange(t, conn, reader, a01)
+
+	if err := server.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if err := <-done; !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Serve returned %v, want net.ErrClosed", err)
+	}
+	if _, err := ReadFrame(reader, 0); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want the connection closed", err)
+	}
+	if err := server.Serve(listener); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Serve after Close returned %v, want net.ErrClosed", err)
+	}
+}
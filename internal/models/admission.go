@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Bed statuses
+const (
+	BedStatusAvailable = "available"
+	BedStatusOccupied  = "occupied"
+	BedStatusCleaning  = "cleaning"
+	BedStatusBlocked   = "blocked"
+)
+
+// Admission statuses
+const (
+	AdmissionStatusAdmitted   = "admitted"
+	AdmissionStatusDischarged = "discharged"
+)
+
+// Ward is a hospital unit grouping rooms, e.g. "Cardiology 3B"
+type Ward struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Code      string    `json:"code" gorm:"not null;uniqueIndex"`
+	Name      string    `json:"name" gorm:"not null"`
+	Floor     string    `json:"floor"`
+	Rooms     []Room    `json:"rooms,omitempty" gorm:"foreignKey:WardID"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Room is a room of a ward holding one or more beds
+type Room struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	WardID    uint      `json:"ward_id" gorm:"not null;uniqueIndex:idx_rooms_ward_number"`
+	Number    string    `json:"number" gorm:"not null;uniqueIndex:idx_rooms_ward_number"`
+	Beds      []Bed     `json:"beds,omitempty" gorm:"foreignKey:RoomID"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Bed is a bed of a room. PatientID is set while the bed is occupied;
+// WardID is denormalized from the room for the bed board.
+type Bed struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	WardID     uint      `json:"ward_id" gorm:"not null;index"`
+	RoomID     uint      `json:"room_id" gorm:"not null;uniqueIndex:idx_beds_room_label"`
+	Label      string    `json:"label" gorm:"not null;uniqueIndex:idx_beds_room_label"` // e.g. "A"
+	Status     string    `json:"status" gorm:"not null;default:available"`
+	StatusNote string    `json:"status_note"`
+	PatientID  *uint     `json:"patient_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Admission is an inpatient stay. The stay is documented on an inpatient
+// encounter opened on admission and finished on discharge.
+type Admission struct {
+	ID                uint                   `json:"id" gorm:"primaryKey"`
+	PatientID         uint                   `json:"patient_id" gorm:"not null;index"`
+	Patient           *Patient               `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	EncounterID       uint                   `json:"encounter_id" gorm:"not null"`
+	AttendingDoctorID uint                   `json:"attending_doctor_id" gorm:"not null;index"`
+	BedID             *uint                  `json:"bed_id"` // current bed, cleared on discharge
+	Status            string                 `json:"status" gorm:"not null;default:admitted"`
+	Reason            string                 `json:"reason"`
+	AdmittedAt        time.Time              `json:"admitted_at" gorm:"not null"`
+	AdmittedByID      uint                   `json:"admitted_by_id" gorm:"not null"`
+	DischargedAt      *time.Time             `json:"discharged_at"`
+	DischargedByID    *uint                  `json:"discharged_by_id"`
+	Disposition       string                 `json:"disposition"` // home, transfer, deceased, ...
+	Movements         []AdmissionBedMovement `json:"movements,omitempty" gorm:"foreignKey:AdmissionID"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// AdmissionBedMovement records the time a patient spent in a bed during
+// an admission; the open movement is the current bed
+type AdmissionBedMovement struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	AdmissionID uint       `json:"admission_id" gorm:"not null;index"`
+	BedID       uint       `json:"bed_id" gorm:"not null"`
+	FromTime    time.Time  `json:"from_time" gorm:"not null"`
+	ToTime      *time.Time `json:"to_time"`
+	Reason      string     `json:"reason"`
+	MovedByID   uint       `json:"moved_by_id" gorm:"not null"`
+}
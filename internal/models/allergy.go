@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Allergy statuses
+const (
+	AllergyStatusActive         = "active"
+	AllergyStatusInactive       = "inactive"
+	AllergyStatusResolved       = "resolved"
+	AllergyStatusEnteredInError = "entered_in_error"
+)
+
+// Allergy records a single allergy or adverse reaction for a patient.
+// A record with NKDA set states explicitly that the patient has no known
+// drug allergies; it carries no substance.
+type Allergy struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	PatientID     uint           `json:"patient_id" gorm:"not null;index"`
+	NKDA          bool           `json:"nkda" gorm:"not null;default:false"`
+	Substance     string         `json:"substance"`
+	SubstanceCode string         `json:"substance_code"` // optional coded substance, e.g. RxNorm
+	CodeSystem    string         `json:"code_system"`
+	Category      string         `json:"category"` // drug, food, environment, other
+	Reaction      string         `json:"reaction"`
+	Severity      string         `json:"severity"` // mild, moderate, severe, life_threatening
+	Status        string         `json:"status" gorm:"not null;default:active"`
+	Notes         string         `json:"notes"`
+	RecordedByID  uint           `json:"recorded_by_id" gorm:"not null"`
+	RecordedAt    time.Time      `json:"recorded_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
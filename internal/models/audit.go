@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Audit log outcomes
+const (
+	AuditOutcomeStarted   = "started"
+	AuditOutcomeCompleted = "completed"
+	AuditOutcomeFailed    = "failed"
+)
+
+// Audit log actions
+const (
+	AuditActionPatientExport     = "patient.export"
+	AuditActionFHIRPatientSearch = "patient.fhir_search"
+)
+
+// AuditLog records who took data out of the system, how much and when. An
+// entry is written before the data leaves and completed afterwards, so an
+// interrupted transfer still shows up.
+type AuditLog struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	Role       string     `json:"role" gorm:"not null"`
+	Action     string     `json:"action" gorm:"not null;index"`
+	Details    string     `json:"details"` // JSON, e.g. the format, fields and filters of an export
+	RowCount   int        `json:"row_count" gorm:"not null;default:0"`
+	Outcome    string     `json:"outcome" gorm:"not null"`
+	Error      string     `json:"error,omitempty"`
+	RemoteAddr string     `json:"remote_addr"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+}
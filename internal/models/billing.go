@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// All monetary amounts in billing are integers in the smallest currency
+// unit (cents), so that totals add up exactly.
+
+// Charge statuses
+const (
+	ChargeStatusPosted = "posted"
+	ChargeStatusVoided = "voided"
+)
+
+// Invoice statuses
+const (
+	InvoiceStatusIssued = "issued"
+	InvoiceStatusPaid   = "paid"
+	InvoiceStatusVoided = "voided"
+)
+
+// Payment kinds. Adjustments write off part of an invoice, such as the
+// contractual discount of a payer, without money changing hands.
+const (
+	PaymentKindPayment    = "payment"
+	PaymentKindRefund     = "refund"
+	PaymentKindAdjustment = "adjustment"
+)
+
+// Payment methods
+const (
+	PaymentMethodCash      = "cash"
+	PaymentMethodCard      = "card"
+	PaymentMethodCheck     = "check"
+	PaymentMethodInsurance = "insurance"
+	PaymentMethodOther     = "other"
+)
+
+// ChargeMasterItem is a billable service with its list price
+type ChargeMasterItem struct {
+	Code        string    `json:"code" gorm:"primaryKey;size:20"`
+	Description string    `json:"description" gorm:"not null"`
+	CPTCode     string    `json:"cpt_code" gorm:"column:cpt_code"` // CPT/HCPCS code used on claims
+	Price       int64     `json:"price" gorm:"not null"`
+	Taxable     bool      `json:"taxable" gorm:"not null;default:false"`
+	Active      bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Charge is a service from the charge master posted against an encounter.
+// Code, description and price are copied so later price changes do not
+// alter posted charges.
+type Charge struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	PatientID   uint           `json:"patient_id" gorm:"not null;index"`
+	EncounterID uint           `json:"encounter_id" gorm:"not null;index"`
+	ServiceCode string         `json:"service_code" gorm:"not null"`
+	Description string         `json:"description" gorm:"not null"`
+	CPTCode     string         `json:"cpt_code" gorm:"column:cpt_code"`
+	Quantity    int            `json:"quantity" gorm:"not null"`
+	UnitPrice   int64          `json:"unit_price" gorm:"not null"`
+	Amount      int64          `json:"amount" gorm:"not null"`
+	Taxable     bool           `json:"taxable" gorm:"not null;default:false"`
+	ServiceDate time.Time      `json:"service_date" gorm:"not null"`
+	Status      string         `json:"status" gorm:"not null;default:posted"`
+	InvoiceID   *uint          `json:"invoice_id" gorm:"index"`
+	PostedByID  uint           `json:"posted_by_id" gorm:"not null"`
+	VoidReason  string         `json:"void_reason,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Invoice bills a patient for a set of charges
+type Invoice struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Number          string         `json:"number" gorm:"uniqueIndex"`
+	PatientID       uint           `json:"patient_id" gorm:"not null;index"`
+	Status          string         `json:"status" gorm:"not null;default:issued"`
+	IssuedAt        time.Time      `json:"issued_at" gorm:"not null"`
+	DueDate         time.Time      `json:"due_date" gorm:"not null"`
+	DiscountPercent float64        `json:"discount_percent" gorm:"not null;default:0"`
+	TaxRatePercent  float64        `json:"tax_rate_percent" gorm:"not null;default:0"`
+	Subtotal        int64          `json:"subtotal" gorm:"not null"`
+	DiscountTotal   int64          `json:"discount_total" gorm:"not null"`
+	TaxTotal        int64          `json:"tax_total" gorm:"not null"`
+	Total           int64          `json:"total" gorm:"not null"`
+	AmountPaid      int64          `json:"amount_paid" gorm:"not null;default:0"` // payments less refunds
+	AmountAdjusted  int64          `json:"amount_adjusted" gorm:"not null;default:0"`
+	Balance         int64          `json:"balance" gorm:"not null"`
+	IssuedByID      uint           `json:"issued_by_id" gorm:"not null"`
+	Lines           []InvoiceLine  `json:"lines,omitempty"`
+	Payments        []Payment      `json:"payments,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// InvoiceLine is one charge on an invoice
+type InvoiceLine struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID   uint      `json:"invoice_id" gorm:"not null;index"`
+	ChargeID    uint      `json:"charge_id" gorm:"not null"`
+	Description string    `json:"description" gorm:"not null"`
+	Quantity    int       `json:"quantity" gorm:"not null"`
+	UnitPrice   int64     `json:"unit_price" gorm:"not null"`
+	Amount      int64     `json:"amount" gorm:"not null"`   // quantity x unit price
+	Discount    int64     `json:"discount" gorm:"not null"` // already deducted from Total
+	Tax         int64     `json:"tax" gorm:"not null"`
+	Total       int64     `json:"total" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Payment is money received against an invoice, refunded from it, or
+// written off it
+type Payment struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	InvoiceID    uint      `json:"invoice_id" gorm:"not null;index"`
+	PatientID    uint      `json:"patient_id" gorm:"not null;index"`
+	Kind         string    `json:"kind" gorm:"not null"`
+	Method       string    `json:"method" gorm:"not null"`
+	Amount       int64     `json:"amount" gorm:"not null"` // always positive
+	Reference    string    `json:"reference"`              // card slip, check number, remittance trace
+	Notes        string    `json:"notes"`
+	ReceivedAt   time.Time `json:"received_at" gorm:"not null;index"`
+	RecordedByID uint      `json:"recorded_by_id" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
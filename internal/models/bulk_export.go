@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Bulk export job statuses
+const (
+	BulkExportStatusInProgress = "in_progress"
+	BulkExportStatusCompleted  = "completed"
+	BulkExportStatusFailed     = "failed"
+	BulkExportStatusCancelled  = "cancelled"
+)
+
+// BulkExportJob is a FHIR bulk data export running in the background. Its
+// files are kept in blob storage until the job expires.
+type BulkExportJob struct {
+	ID              uint             `json:"id" gorm:"primaryKey"`
+	RequestedByID   uint             `json:"requested_by_id" gorm:"not null;index"`
+	Request         string           `json:"request" gorm:"not null"`        // the kick-off URL
+	ResourceTypes   string           `json:"resource_types" gorm:"not null"` // comma-separated
+	Since           *time.Time       `json:"since"`
+	TransactionTime time.Time        `json:"transaction_time" gorm:"not null"`
+	Status          string           `json:"status" gorm:"not null;default:in_progress;index"`
+	Progress        string           `json:"progress"`
+	Error           string           `json:"error,omitempty"`
+	Files           []BulkExportFile `json:"files,omitempty" gorm:"foreignKey:JobID"`
+	CompletedAt     *time.Time       `json:"completed_at"`
+	ExpiresAt       *time.Time       `json:"expires_at"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// BulkExportFile is one NDJSON file written by a bulk export job
+type BulkExportFile struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	JobID        uint      `json:"job_id" gorm:"not null;index"`
+	ResourceType string    `json:"resource_type" gorm:"not null"`
+	StorageKey   string    `json:"-" gorm:"not null"`
+	Count        int       `json:"count" gorm:"not null"`
+	Size         int64     `json:"size" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+}
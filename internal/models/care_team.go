@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// CareTeamMember links a staff user to a patient they look after
+type CareTeamMember struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PatientID uint      `json:"patient_id" gorm:"not null;uniqueIndex:idx_care_team_patient_user"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_care_team_patient_user;index"`
+	Role      string    `json:"role" gorm:"not null"` // attending, consulting, nursing, other
+	AddedByID uint      `json:"added_by_id"`
+	CreatedAt time.Time `json:"created_at"`
+	User      *User     `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Insurance claim statuses
+const (
+	ClaimStatusSubmitted     = "submitted"
+	ClaimStatusPaid          = "paid"
+	ClaimStatusPartiallyPaid = "partially_paid"
+	ClaimStatusDenied        = "denied"
+)
+
+// ClaimBatch is one generated 837P file
+type ClaimBatch struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	FileName    string           `json:"file_name"`
+	StorageKey  string           `json:"-"`
+	ClaimCount  int              `json:"claim_count" gorm:"not null"`
+	TotalCharge int64            `json:"total_charge" gorm:"not null"` // cents
+	CreatedByID uint             `json:"created_by_id" gorm:"not null"`
+	Claims      []InsuranceClaim `json:"claims,omitempty" gorm:"foreignKey:BatchID"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// InsuranceClaim is the claim for one encounter sent to the patient's
+// primary payer. Amounts are in cents.
+type InsuranceClaim struct {
+	ID                    uint       `json:"id" gorm:"primaryKey"`
+	BatchID               uint       `json:"batch_id" gorm:"not null;index"`
+	EncounterID           uint       `json:"encounter_id" gorm:"not null;index"`
+	PatientID             uint       `json:"patient_id" gorm:"not null;index"`
+	CoverageID            uint       `json:"coverage_id" gorm:"not null"`
+	PayerCode             string     `json:"payer_code" gorm:"not null"`
+	ControlNumber         string     `json:"control_number" gorm:"uniqueIndex"` // CLM01, matched against 835 CLP01
+	TotalCharge           int64      `json:"total_charge" gorm:"not null"`
+	Status                string     `json:"status" gorm:"not null;default:submitted"`
+	PaidAmount            int64      `json:"paid_amount" gorm:"not null;default:0"`
+	AdjustmentAmount      int64      `json:"adjustment_amount" gorm:"not null;default:0"`
+	PatientResponsibility int64      `json:"patient_responsibility" gorm:"not null;default:0"`
+	PayerClaimNumber      string     `json:"payer_claim_number"`
+	RemittanceID          *uint      `json:"remittance_id"`
+	AdjudicatedAt         *time.Time `json:"adjudicated_at"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// Remittance is one imported 835 remittance advice
+type Remittance struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	FileName        string    `json:"file_name"`
+	StorageKey      string    `json:"-"`
+	TraceNumber     string    `json:"trace_number" gorm:"not null;uniqueIndex"`
+	PayerName       string    `json:"payer_name"`
+	PaymentDate     time.Time `json:"payment_date"`
+	TotalPaid       int64     `json:"total_paid" gorm:"not null"` // cents
+	ClaimsMatched   int       `json:"claims_matched" gorm:"not null;default:0"`
+	ClaimsUnmatched int       `json:"claims_unmatched" gorm:"not null;default:0"`
+	ImportedByID    uint      `json:"imported_by_id" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ClaimAdjustment is a CAS adjustment reported by a payer on a claim
+type ClaimAdjustment struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ClaimID      uint      `json:"claim_id" gorm:"not null;index"`
+	RemittanceID uint      `json:"remittance_id" gorm:"not null;index"`
+	GroupCode    string    `json:"group_code" gorm:"not null"` // CO, PR, OA or PI
+	ReasonCode   string    `json:"reason_code" gorm:"not null"`
+	Amount       int64     `json:"amount" gorm:"not null"` // cents
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Consent types
+const (
+	ConsentTypeDataSharing = "data_sharing"
+	ConsentTypeResearch    = "research"
+	ConsentTypeSMSContact  = "sms_contact"
+	ConsentTypeTreatment   = "treatment"
+)
+
+// Consent statuses
+const (
+	ConsentStatusGranted   = "granted"
+	ConsentStatusWithdrawn = "withdrawn"
+)
+
+// PatientConsent records one grant (and possibly its later withdrawal) of
+// a consent type. The most recent record of a type is the patient's
+// current decision; a withdrawal without an earlier grant records a
+// refusal.
+type PatientConsent struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	PatientID     uint           `json:"patient_id" gorm:"not null;index:idx_patient_consents_lookup"`
+	Type          string         `json:"type" gorm:"not null;index:idx_patient_consents_lookup"`
+	Version       string         `json:"version" gorm:"not null"` // version of the consent form
+	Status        string         `json:"status" gorm:"not null"`
+	GrantedAt     *time.Time     `json:"granted_at"`
+	WithdrawnAt   *time.Time     `json:"withdrawn_at"`
+	WitnessName   string         `json:"witness_name"`
+	WitnessUserID *uint          `json:"witness_user_id"`
+	DocumentID    *uint          `json:"document_id"` // signed form, if scanned
+	RecordedByID  uint           `json:"recorded_by_id" gorm:"not null"`
+	Notes         string         `json:"notes"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MinorAgeLimit is the age below which a patient needs a guardian on record
+const MinorAgeLimit = 18
+
+// PatientContact is a guardian, next of kin or emergency contact of a
+// patient. One person can hold several of these roles.
+type PatientContact struct {
+	ID                    uint           `json:"id" gorm:"primaryKey"`
+	PatientID             uint           `json:"patient_id" gorm:"not null;index"`
+	Name                  string         `json:"name" gorm:"not null"`
+	Relationship          string         `json:"relationship" gorm:"not null"` // e.g. mother, spouse, neighbour
+	PhoneNumber           string         `json:"phone_number" gorm:"not null"`
+	Address               string         `json:"address"`
+	IsGuardian            bool           `json:"is_guardian" gorm:"not null;default:false"`
+	IsNextOfKin           bool           `json:"is_next_of_kin" gorm:"not null;default:false"`
+	IsEmergencyContact    bool           `json:"is_emergency_contact" gorm:"not null;default:false"`
+	MayReceiveInformation bool           `json:"may_receive_information" gorm:"not null;default:false"`
+	Notes                 string         `json:"notes"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"-" gorm:"index"`
+}
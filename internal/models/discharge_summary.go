@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Discharge summary statuses
+const (
+	DischargeSummaryStatusDraft  = "draft"
+	DischargeSummaryStatusSigned = "signed"
+)
+
+// Discharge summary item sections
+const (
+	SummarySectionDiagnosis  = "diagnosis"
+	SummarySectionProcedure  = "procedure"
+	SummarySectionMedication = "medication"
+)
+
+// DischargeSummary documents an inpatient stay. It is drafted from the
+// stay's records, edited by a doctor and frozen once signed.
+type DischargeSummary struct {
+	ID                uint                   `json:"id" gorm:"primaryKey"`
+	AdmissionID       uint                   `json:"admission_id" gorm:"not null;uniqueIndex"`
+	PatientID         uint                   `json:"patient_id" gorm:"not null;index"`
+	AttendingDoctorID uint                   `json:"attending_doctor_id" gorm:"not null"`
+	Status            string                 `json:"status" gorm:"not null;default:draft"`
+	AdmittedAt        time.Time              `json:"admitted_at"`
+	DischargedAt      time.Time              `json:"discharged_at"`
+	Disposition       string                 `json:"disposition"`
+	AdmissionReason   string                 `json:"admission_reason"`
+	HospitalCourse    string                 `json:"hospital_course"`
+	FollowUp          string                 `json:"follow_up"`
+	Items             []DischargeSummaryItem `json:"items" gorm:"foreignKey:SummaryID"`
+	SignedAt          *time.Time             `json:"signed_at"`
+	SignedByID        *uint                  `json:"signed_by_id"`
+	ContentHash       string                 `json:"content_hash,omitempty"` // SHA-256 of the signed content
+	CreatedByID       uint                   `json:"created_by_id" gorm:"not null"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// DischargeSummaryItem is a diagnosis, procedure or discharge medication
+// listed on a summary
+type DischargeSummaryItem struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	SummaryID   uint   `json:"summary_id" gorm:"not null;index"`
+	Section     string `json:"section" gorm:"not null"`
+	Position    int    `json:"position" gorm:"not null"`
+	Code        string `json:"code"` // ICD-10, CPT or drug code
+	Description string `json:"description" gorm:"not null"`
+	Detail      string `json:"detail"` // e.g. dose and frequency, or the procedure date
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Document visibility levels, from widest to narrowest
+const (
+	DocumentVisibilityAllStaff = "all_staff"
+	DocumentVisibilityClinical = "clinical"
+	DocumentVisibilityDoctors  = "doctors"
+)
+
+// PatientDocument is an uploaded file attached to a patient, such as a
+// scanned referral letter, consent form or imaging report. The content
+// lives in blob storage under StorageKey.
+type PatientDocument struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	PatientID    uint           `json:"patient_id" gorm:"not null;index"`
+	Category     string         `json:"category" gorm:"not null"` // referral, consent, imaging_report, lab_report, other
+	Title        string         `json:"title" gorm:"not null"`
+	FileName     string         `json:"file_name" gorm:"not null"`
+	ContentType  string         `json:"content_type" gorm:"not null"`
+	Size         int64          `json:"size" gorm:"not null"`
+	SHA256       string         `json:"sha256" gorm:"column:sha256;not null;size:64"`
+	StorageKey   string         `json:"-" gorm:"not null;uniqueIndex"`
+	Visibility   string         `json:"visibility" gorm:"not null;default:clinical"`
+	UploadedByID uint           `json:"uploaded_by_id" gorm:"not null"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
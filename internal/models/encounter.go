@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Encounter statuses
+const (
+	EncounterStatusInProgress = "in_progress"
+	EncounterStatusFinished   = "finished"
+	EncounterStatusCancelled  = "cancelled"
+)
+
+// Encounter is a single visit or contact between a patient and a doctor
+type Encounter struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	PatientID uint           `json:"patient_id" gorm:"not null;index"`
+	DoctorID  uint           `json:"doctor_id" gorm:"not null;index"`
+	Type      string         `json:"type" gorm:"not null"` // outpatient, inpatient, emergency, telehealth
+	Status    string         `json:"status" gorm:"not null;default:in_progress"`
+	Reason    string         `json:"reason"`
+	StartedAt time.Time      `json:"started_at"`
+	EndedAt   *time.Time     `json:"ended_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
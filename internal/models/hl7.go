@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// PatientIdentifier is an identifier another system knows a patient by,
+// such as the MRN of the registration system feeding ADT messages. System
+// is the assigning authority; a value is unique within its system.
+type PatientIdentifier struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PatientID uint      `json:"patient_id" gorm:"not null;index"`
+	System    string    `json:"system" gorm:"not null;uniqueIndex:idx_patient_identifiers_system_value"`
+	Value     string    `json:"value" gorm:"not null;uniqueIndex:idx_patient_identifiers_system_value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HL7DeadLetter is an inbound HL7 v2 message that was not applied, kept
+// with the reason so that it can be fixed at the source and resent
+type HL7DeadLetter struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ControlID   string    `json:"control_id" gorm:"index"` // MSH-10
+	MessageType string    `json:"message_type"`            // e.g. ADT^A04
+	Sender      string    `json:"sender"`                  // MSH-3 and MSH-4
+	RemoteAddr  string    `json:"remote_addr"`
+	AckCode     string    `json:"ack_code" gorm:"not null"` // AE or AR
+	ErrorCode   string    `json:"error_code"`               // HL7 table 0357
+	Error       string    `json:"error" gorm:"not null"`
+	Message     string    `json:"message" gorm:"not null"`
+	ReceivedAt  time.Time `json:"received_at" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName keeps the table name readable
+func (HL7DeadLetter) TableName() string {
+	return "hl7_dead_letters"
+}
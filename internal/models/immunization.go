@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Immunization statuses
+const (
+	ImmunizationStatusCompleted      = "completed"
+	ImmunizationStatusEnteredInError = "entered_in_error"
+)
+
+// Immunization records one vaccine dose given to a patient. Historical
+// records transcribe doses given elsewhere, so they carry no administering
+// user and may lack the lot and site.
+type Immunization struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	PatientID        uint      `json:"patient_id" gorm:"not null;index"`
+	VaccineCode      string    `json:"vaccine_code" gorm:"not null;index"` // CVX
+	VaccineName      string    `json:"vaccine_name"`
+	DoseNumber       int       `json:"dose_number" gorm:"not null"`
+	LotNumber        string    `json:"lot_number"`
+	Site             string    `json:"site"` // left_arm, right_arm, left_thigh, right_thigh, oral, nasal
+	AdministeredAt   time.Time `json:"administered_at" gorm:"not null"`
+	AdministeredByID *uint     `json:"administered_by_id"`
+	Historical       bool      `json:"historical" gorm:"not null;default:false"`
+	Status           string    `json:"status" gorm:"not null;default:completed"`
+	StatusReason     string    `json:"status_reason,omitempty"`
+	Notes            string    `json:"notes"`
+	RecordedByID     uint      `json:"recorded_by_id" gorm:"not null"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
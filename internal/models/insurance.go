@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Coverage priorities, in the order payers are billed
+const (
+	CoveragePriorityPrimary   = 1
+	CoveragePrioritySecondary = 2
+	CoveragePriorityTertiary  = 3
+)
+
+// Subscriber relationships of the patient to the policy holder
+const (
+	SubscriberRelationshipSelf   = "self"
+	SubscriberRelationshipSpouse = "spouse"
+	SubscriberRelationshipChild  = "child"
+	SubscriberRelationshipOther  = "other"
+)
+
+// Eligibility statuses
+const (
+	EligibilityStatusUnknown    = "unknown"
+	EligibilityStatusEligible   = "eligible"
+	EligibilityStatusIneligible = "ineligible"
+	EligibilityStatusError      = "error"
+)
+
+// Payer is an entry of the locally loaded payer catalogue
+type Payer struct {
+	Code            string    `json:"code" gorm:"primaryKey;size:20"`
+	Name            string    `json:"name" gorm:"not null"`
+	EDIPayerID      string    `json:"edi_payer_id" gorm:"column:edi_payer_id"`      // payer ID used in electronic claims
+	MemberIDPattern string    `json:"member_id_pattern"`                            // regular expression member IDs must match
+	ClaimFilingCode string    `json:"claim_filing_code" gorm:"not null;default:CI"` // 837 SBR09, e.g. CI commercial, MB Medicare Part B
+	Active          bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// InsuranceCoverage is a patient's coverage under a payer's plan
+type InsuranceCoverage struct {
+	ID                     uint           `json:"id" gorm:"primaryKey"`
+	PatientID              uint           `json:"patient_id" gorm:"not null;index"`
+	PayerCode              string         `json:"payer_code" gorm:"not null;index"`
+	Payer                  *Payer         `json:"payer,omitempty" gorm:"foreignKey:PayerCode;references:Code"`
+	PlanName               string         `json:"plan_name" gorm:"not null"`
+	MemberID               string         `json:"member_id" gorm:"not null"`
+	GroupNumber            string         `json:"group_number"`
+	SubscriberRelationship string         `json:"subscriber_relationship" gorm:"not null;default:self"`
+	SubscriberName         string         `json:"subscriber_name"` // empty when the patient is the subscriber
+	Priority               int            `json:"priority" gorm:"not null"`
+	EffectiveFrom          time.Time      `json:"effective_from" gorm:"not null"`
+	EffectiveTo            *time.Time     `json:"effective_to"`
+	EligibilityStatus      string         `json:"eligibility_status" gorm:"not null;default:unknown"`
+	EligibilityMessage     string         `json:"eligibility_message"`
+	EligibilityCheckedAt   *time.Time     `json:"eligibility_checked_at"`
+	RecordedByID           uint           `json:"recorded_by_id" gorm:"not null"`
+	CreatedAt              time.Time      `json:"created_at"`
+	UpdatedAt              time.Time      `json:"updated_at"`
+	DeletedAt              gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ActiveOn reports whether the coverage is in effect on the given day
+func (c InsuranceCoverage) ActiveOn(day time.Time) bool {
+	if day.Before(c.EffectiveFrom) {
+		return false
+	}
+	return c.EffectiveTo == nil || !day.After(*c.EffectiveTo)
+}
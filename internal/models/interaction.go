@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// Interaction kinds
+const (
+	InteractionKindDrugDrug    = "drug_drug"
+	InteractionKindDrugAllergy = "drug_allergy"
+)
+
+// Interaction severities, from least to most serious
+const (
+	InteractionSeverityMinor           = "minor"
+	InteractionSeverityModerate        = "moderate"
+	InteractionSeverityMajor           = "major"
+	InteractionSeverityContraindicated = "contraindicated"
+)
+
+// DrugInteraction is one entry of the locally imported interaction dataset.
+// For drug_drug entries SubjectA and SubjectB are drugs; for drug_allergy
+// entries SubjectA is a drug and SubjectB an allergen or allergy class.
+// Subjects are stored lower-cased and may be names or codes.
+type DrugInteraction struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Kind        string    `json:"kind" gorm:"not null;index:idx_drug_interactions_lookup"`
+	SubjectA    string    `json:"subject_a" gorm:"not null;index:idx_drug_interactions_lookup"`
+	SubjectB    string    `json:"subject_b" gorm:"not null;index:idx_drug_interactions_lookup"`
+	Severity    string    `json:"severity" gorm:"not null"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// InteractionAlert records an interaction found when a prescription was
+// written, and the override that allowed it through
+type InteractionAlert struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	PrescriptionID   uint      `json:"prescription_id" gorm:"not null;index"`
+	Kind             string    `json:"kind" gorm:"not null"`
+	Severity         string    `json:"severity" gorm:"not null"`
+	Interacting      string    `json:"interacting"` // the other drug or the allergen
+	Description      string    `json:"description"`
+	OverrideReason   string    `json:"override_reason"`
+	AcknowledgedByID uint      `json:"acknowledged_by_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
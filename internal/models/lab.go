@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Lab order statuses, in lifecycle order
+const (
+	LabOrderStatusOrdered   = "ordered"
+	LabOrderStatusCollected = "collected"
+	LabOrderStatusResulted  = "resulted"
+	LabOrderStatusVerified  = "verified"
+	LabOrderStatusCancelled = "cancelled"
+)
+
+// Lab result flags
+const (
+	LabFlagNormal       = "normal"
+	LabFlagLow          = "low"
+	LabFlagHigh         = "high"
+	LabFlagCriticalLow  = "critical_low"
+	LabFlagCriticalHigh = "critical_high"
+	LabFlagAbnormal     = "abnormal"
+)
+
+// LabOrder is a laboratory test ordered by a doctor for a patient
+type LabOrder struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	PatientID        uint           `json:"patient_id" gorm:"not null;index"`
+	EncounterID      *uint          `json:"encounter_id" gorm:"index"`
+	OrderingDoctorID uint           `json:"ordering_doctor_id" gorm:"not null;index"`
+	TestCode         string         `json:"test_code" gorm:"not null"` // e.g. LOINC
+	TestName         string         `json:"test_name" gorm:"not null"`
+	Priority         string         `json:"priority" gorm:"not null;default:routine"` // routine, urgent, stat
+	SpecimenType     string         `json:"specimen_type" gorm:"not null"`
+	ClinicalNotes    string         `json:"clinical_notes"`
+	Status           string         `json:"status" gorm:"not null;default:ordered;index"`
+	OrderedAt        time.Time      `json:"ordered_at"`
+	CollectedAt      *time.Time     `json:"collected_at"`
+	CollectedByID    *uint          `json:"collected_by_id"`
+	ResultedAt       *time.Time     `json:"resulted_at"`
+	ResultedByID     *uint          `json:"resulted_by_id"`
+	VerifiedAt       *time.Time     `json:"verified_at"`
+	VerifiedByID     *uint          `json:"verified_by_id"`
+	CancelledAt      *time.Time     `json:"cancelled_at"`
+	CancelReason     string         `json:"cancel_reason"`
+	Results          []LabResult    `json:"results,omitempty" gorm:"foreignKey:LabOrderID"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// LabResult is one measured analyte of a lab order
+type LabResult struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	LabOrderID   uint      `json:"lab_order_id" gorm:"not null;index"`
+	AnalyteCode  string    `json:"analyte_code"`
+	AnalyteName  string    `json:"analyte_name" gorm:"not null"`
+	Value        string    `json:"value" gorm:"not null"`
+	NumericValue *float64  `json:"numeric_value"`
+	Unit         string    `json:"unit"`
+	RefLow       *float64  `json:"ref_low"`
+	RefHigh      *float64  `json:"ref_high"`
+	CriticalLow  *float64  `json:"critical_low"`
+	CriticalHigh *float64  `json:"critical_high"`
+	Flag         string    `json:"flag"`
+	Comment      string    `json:"comment"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Notification types
+const (
+	NotificationTypeDeterioration  = "deterioration"
+	NotificationTypeCriticalResult = "critical_result"
+	NotificationTypeReferral       = "referral"
+)
+
+// Notification is an in-app message for a staff user
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	PatientID *uint      `json:"patient_id" gorm:"index"`
+	Type      string     `json:"type" gorm:"not null"`
+	Title     string     `json:"title" gorm:"not null"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -7,17 +7,23 @@ import (
 )
 
 type Patient struct {
-	ID             uint           `json:"id" gorm:"primaryKey"`
-	Name           string         `json:"name" gorm:"not null"`
-	Age            int            `json:"age" gorm:"not null"`
-	Gender         string         `json:"gender" gorm:"not null"`
-	Address        string         `json:"address" gorm:"not null"`
-	PhoneNumber    string         `json:"phone_number" gorm:"not null"`
-	MedicalHistory string         `json:"medical_history"`
-	Diagnosis      string         `json:"diagnosis"`
-	Treatment      string         `json:"treatment"`
-	Notes          string         `json:"notes"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                    uint                `json:"id" gorm:"primaryKey"`
+	Name                  string              `json:"name" gorm:"not null"`
+	Age                   int                 `json:"age" gorm:"not null"`
+	DateOfBirth           *time.Time          `json:"date_of_birth,omitempty" gorm:"type:date"` // kept in step with Age when recorded
+	Gender                string              `json:"gender" gorm:"not null"`
+	Address               Address             `json:"address" gorm:"embedded;embeddedPrefix:address_"`
+	LegacyAddress         string              `json:"legacy_address,omitempty" gorm:"column:address"` // free text from before addresses were structured
+	PhoneNumber           string              `json:"phone_number" gorm:"not null"`                   // E.164
+	MedicalHistory        string              `json:"medical_history"`
+	Diagnosis             string              `json:"diagnosis"`
+	Treatment             string              `json:"treatment"`
+	Notes                 string              `json:"notes"`
+	Contacts              []PatientContact    `json:"contacts,omitempty" gorm:"foreignKey:PatientID"`
+	Identifiers           []PatientIdentifier `json:"identifiers,omitempty" gorm:"foreignKey:PatientID"`     // other systems' IDs
+	ContactReviewRequired bool                `json:"contact_review_required" gorm:"not null;default:false"` // address or phone needs a receptionist
+	ContactReviewReason   string              `json:"contact_review_reason,omitempty"`
+	CreatedAt             time.Time           `json:"created_at"`
+	UpdatedAt             time.Time           `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt      `json:"-" gorm:"index"`
 }
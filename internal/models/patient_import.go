@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Patient import job statuses
+const (
+	PatientImportStatusInProgress = "in_progress"
+	PatientImportStatusCompleted  = "completed"
+	PatientImportStatusFailed     = "failed"
+)
+
+// PatientImportJob is a CSV or XLSX patient import running in the
+// background. A dry run only validates the rows; otherwise the valid rows
+// are inserted and the invalid ones reported.
+type PatientImportJob struct {
+	ID               uint                 `json:"id" gorm:"primaryKey"`
+	RequestedByID    uint                 `json:"requested_by_id" gorm:"not null;index"`
+	FileName         string               `json:"file_name" gorm:"not null"`
+	Format           string               `json:"format" gorm:"not null"` // csv or xlsx
+	DryRun           bool                 `json:"dry_run" gorm:"not null;default:false"`
+	IdentifierSystem string               `json:"identifier_system,omitempty"` // system of the identifier column, if mapped
+	Status           string               `json:"status" gorm:"not null;default:in_progress;index"`
+	TotalRows        int                  `json:"total_rows" gorm:"not null;default:0"`
+	ProcessedRows    int                  `json:"processed_rows" gorm:"not null;default:0"`
+	ValidRows        int                  `json:"valid_rows" gorm:"not null;default:0"`
+	ImportedRows     int                  `json:"imported_rows" gorm:"not null;default:0"`
+	FailedRows       int                  `json:"failed_rows" gorm:"not null;default:0"`
+	Error            string               `json:"error,omitempty"`
+	Errors           []PatientImportError `json:"errors,omitempty" gorm:"foreignKey:JobID"`
+	CompletedAt      *time.Time           `json:"completed_at"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+// PatientImportError is a problem with one row of an import. Row is the
+// line of the file, counting the header as row 1.
+type PatientImportError struct {
+	ID      uint   `json:"-" gorm:"primaryKey"`
+	JobID   uint   `json:"-" gorm:"not null;index"`
+	Row     int    `json:"row" gorm:"not null"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message" gorm:"not null"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Prescription statuses
+const (
+	PrescriptionStatusActive       = "active"
+	PrescriptionStatusDiscontinued = "discontinued"
+	PrescriptionStatusCompleted    = "completed"
+)
+
+// Prescription is a medication order written by a doctor for a patient
+type Prescription struct {
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	PatientID         uint           `json:"patient_id" gorm:"not null;index"`
+	PrescriberID      uint           `json:"prescriber_id" gorm:"not null;index"`
+	Drug              string         `json:"drug" gorm:"not null"`
+	DrugCode          string         `json:"drug_code"` // optional coded drug, e.g. RxNorm
+	Strength          string         `json:"strength" gorm:"not null"`
+	Route             string         `json:"route" gorm:"not null"`
+	Frequency         string         `json:"frequency" gorm:"not null"`
+	DurationDays      int            `json:"duration_days" gorm:"not null"`
+	Quantity          int            `json:"quantity" gorm:"not null"`
+	Refills           int            `json:"refills" gorm:"not null;default:0"`
+	Instructions      string         `json:"instructions"`
+	Status            string         `json:"status" gorm:"not null;default:active;index"`
+	StartDate         time.Time      `json:"start_date"`
+	EndDate           time.Time      `json:"end_date"`
+	RenewedFromID     *uint          `json:"renewed_from_id"`
+	DiscontinuedAt    *time.Time     `json:"discontinued_at"`
+	DiscontinuedByID  *uint          `json:"discontinued_by_id"`
+	DiscontinueReason string         `json:"discontinue_reason"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
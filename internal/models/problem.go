@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Problem statuses
+const (
+	ProblemStatusActive   = "active"
+	ProblemStatusResolved = "resolved"
+)
+
+// ICD10Code is an entry of the locally loaded ICD-10 code table. Codes are
+// stored upper-case without the dot, as in the CMS code files.
+type ICD10Code struct {
+	Code        string    `json:"code" gorm:"primaryKey;size:8"`
+	Description string    `json:"description" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName keeps the table name readable
+func (ICD10Code) TableName() string {
+	return "icd10_codes"
+}
+
+// Problem is a coded diagnosis on a patient's problem list
+type Problem struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	PatientID    uint           `json:"patient_id" gorm:"not null;index"`
+	EncounterID  *uint          `json:"encounter_id" gorm:"index"`
+	ICD10Code    string         `json:"icd10_code" gorm:"column:icd10_code;not null;index"`
+	Description  string         `json:"description" gorm:"not null"`
+	OnsetDate    *time.Time     `json:"onset_date"`
+	ResolvedDate *time.Time     `json:"resolved_date"`
+	Status       string         `json:"status" gorm:"not null;default:active"`
+	IsPrimary    bool           `json:"is_primary" gorm:"not null;default:false"`
+	Notes        string         `json:"notes"`
+	RecordedByID uint           `json:"recorded_by_id" gorm:"not null"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
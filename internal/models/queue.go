@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Queue entry statuses
+const (
+	QueueStatusWaiting    = "waiting"
+	QueueStatusInProgress = "in_progress" // called in by a doctor
+	QueueStatusCompleted  = "completed"
+	QueueStatusLeft       = "left" // left without being seen
+	QueueStatusCancelled  = "cancelled"
+)
+
+// Queue entry sources
+const (
+	QueueSourceAppointment = "appointment"
+	QueueSourceWalkIn      = "walk_in"
+)
+
+// ESI triage levels, 1 being the most urgent
+const (
+	TriageLevelMostUrgent  = 1
+	TriageLevelLeastUrgent = 5
+)
+
+// QueueEntry is a patient checked in at the front desk and waiting to be
+// seen. A nurse assigns the triage level; doctors call patients in by
+// triage level and then by arrival.
+type QueueEntry struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	PatientID     uint       `json:"patient_id" gorm:"not null;index"`
+	Patient       *Patient   `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	Source        string     `json:"source" gorm:"not null"` // appointment or walk_in
+	AppointmentAt *time.Time `json:"appointment_at"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status" gorm:"not null;default:waiting;index"`
+	CheckedInAt   time.Time  `json:"checked_in_at" gorm:"not null"`
+	CheckedInByID uint       `json:"checked_in_by_id" gorm:"not null"`
+	TriageLevel   *int       `json:"triage_level"`
+	TriageNotes   string     `json:"triage_notes"`
+	TriagedAt     *time.Time `json:"triaged_at"`
+	TriagedByID   *uint      `json:"triaged_by_id"`
+	CalledAt      *time.Time `json:"called_at"`
+	DoctorID      *uint      `json:"doctor_id"`
+	EncounterID   *uint      `json:"encounter_id"`
+	ClosedAt      *time.Time `json:"closed_at"`
+	CloseReason   string     `json:"close_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Referral statuses
+const (
+	ReferralStatusSent      = "sent"
+	ReferralStatusAccepted  = "accepted"
+	ReferralStatusDeclined  = "declined"
+	ReferralStatusCompleted = "completed"
+)
+
+// Referral urgencies
+const (
+	ReferralUrgencyRoutine   = "routine"
+	ReferralUrgencyUrgent    = "urgent"
+	ReferralUrgencyEmergency = "emergency"
+)
+
+// Referral sends a patient to another doctor in the system or to an
+// external provider. Internal referrals are answered by the receiving
+// doctor; for external ones the referring doctor records the answer.
+type Referral struct {
+	ID                uint              `json:"id" gorm:"primaryKey"`
+	PatientID         uint              `json:"patient_id" gorm:"not null;index"`
+	Patient           *Patient          `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	ReferringDoctorID uint              `json:"referring_doctor_id" gorm:"not null;index"`
+	ReceivingDoctorID *uint             `json:"receiving_doctor_id" gorm:"index"` // nil for external referrals
+	ExternalProvider  string            `json:"external_provider,omitempty"`
+	ExternalContact   string            `json:"external_contact,omitempty"` // address, phone or fax of the external provider
+	Specialty         string            `json:"specialty"`
+	Reason            string            `json:"reason" gorm:"not null"`
+	Urgency           string            `json:"urgency" gorm:"not null;default:routine"` // routine, urgent, emergency
+	Status            string            `json:"status" gorm:"not null;default:sent;index"`
+	Documents         []PatientDocument `json:"documents,omitempty" gorm:"many2many:referral_documents"`
+	SentAt            time.Time         `json:"sent_at" gorm:"not null"`
+	RespondedAt       *time.Time        `json:"responded_at"`
+	ResponseNote      string            `json:"response_note,omitempty"` // why it was declined, or notes on acceptance
+	CompletedAt       *time.Time        `json:"completed_at"`
+	Outcome           string            `json:"outcome,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
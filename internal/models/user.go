@@ -11,7 +11,7 @@ type User struct {
 	Name      string         `json:"name" gorm:"not null"`
 	Email     string         `json:"email" gorm:"unique;not null"`
 	Password  string         `json:"-" gorm:"not null"`    // Password is not exposed in JSON
-	Role      string         `json:"role" gorm:"not null"` // doctor or receptionist
+	Role      string         `json:"role" gorm:"not null"` // doctor, receptionist, lab_technician, billing, nurse or admin
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
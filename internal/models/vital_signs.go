@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NEWS2 clinical risk bands
+const (
+	News2RiskLow       = "low"
+	News2RiskLowMedium = "low_medium" // a single parameter scored 3
+	News2RiskMedium    = "medium"
+	News2RiskHigh      = "high"
+)
+
+// VitalSigns is one set of observations for a patient. Measurements are
+// optional; BMI and the NEWS2 score are computed when enough are present.
+type VitalSigns struct {
+	ID                   uint           `json:"id" gorm:"primaryKey"`
+	PatientID            uint           `json:"patient_id" gorm:"not null;index:idx_vital_signs_patient_time"`
+	EncounterID          *uint          `json:"encounter_id" gorm:"index"`
+	RecordedAt           time.Time      `json:"recorded_at" gorm:"not null;index:idx_vital_signs_patient_time"`
+	RecordedByID         uint           `json:"recorded_by_id" gorm:"not null"`
+	SystolicBP           *int           `json:"systolic_bp"`      // mmHg
+	DiastolicBP          *int           `json:"diastolic_bp"`     // mmHg
+	HeartRate            *int           `json:"heart_rate"`       // beats/min
+	RespiratoryRate      *int           `json:"respiratory_rate"` // breaths/min
+	Temperature          *float64       `json:"temperature"`      // °C
+	SpO2                 *int           `json:"spo2"`             // %
+	OnSupplementalOxygen bool           `json:"on_supplemental_oxygen"`
+	HypercapnicFailure   bool           `json:"hypercapnic_failure"` // use NEWS2 SpO2 scale 2
+	Consciousness        string         `json:"consciousness"`       // alert, confusion, voice, pain, unresponsive
+	WeightKg             *float64       `json:"weight_kg"`
+	HeightCm             *float64       `json:"height_cm"`
+	BMI                  *float64       `json:"bmi"`
+	News2Score           *int           `json:"news2_score"`
+	News2Risk            string         `json:"news2_risk"`
+	Notes                string         `json:"notes"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"-" gorm:"index"`
+}
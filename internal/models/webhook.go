@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook event types
+const (
+	WebhookEventPatientCreated = "patient.created"
+	WebhookEventPatientUpdated = "patient.updated"
+	WebhookEventPatientDeleted = "patient.deleted"
+	WebhookEventPatientMerged  = "patient.merged"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription is a downstream system's endpoint and the events it
+// wants to be sent. Payloads are signed with Secret, which is only shown
+// when it is created.
+type WebhookSubscription struct {
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Name        string         `json:"name" gorm:"not null"`
+	URL         string         `json:"url" gorm:"not null"`
+	Events      string         `json:"events" gorm:"not null"` // comma-separated event types
+	Secret      string         `json:"-" gorm:"not null"`
+	Active      bool           `json:"active" gorm:"not null;default:true"`
+	CreatedByID uint           `json:"created_by_id" gorm:"not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// WebhookDelivery is one event queued for, or sent to, a subscription.
+// Failed attempts are retried with exponential backoff until the delivery
+// succeeds or runs out of attempts; a manual redelivery is a new delivery
+// of the same event.
+type WebhookDelivery struct {
+	ID             uint                 `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint                 `json:"subscription_id" gorm:"not null;index"`
+	Subscription   *WebhookSubscription `json:"-" gorm:"foreignKey:SubscriptionID"`
+	EventID        string               `json:"event_id" gorm:"not null;index"`
+	EventType      string               `json:"event_type" gorm:"not null"`
+	Payload        string               `json:"payload" gorm:"not null"`
+	Status         string               `json:"status" gorm:"not null;default:pending;index"`
+	Attempts       int                  `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time           `json:"next_attempt_at" gorm:"index"`
+	LastAttemptAt  *time.Time           `json:"last_attempt_at"`
+	ResponseStatus int                  `json:"response_status"`
+	ResponseBody   string               `json:"response_body"` // cut to the first kilobyte
+	Error          string               `json:"error"`
+	DeliveredAt    *time.Time           `json:"delivered_at"`
+	RedeliveryOfID *uint                `json:"redelivery_of_id"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
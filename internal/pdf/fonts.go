@@ -0,0 +1,104 @@
+package pdf
+
+import "strings"
+
+// Font is one of the standard PDF fonts with its glyph widths
+type Font struct {
+	name     string
+	resource string
+	widths   [95]int // characters 32 to 126, in 1/1000 of the font size
+	fallback int     // width of other Latin-1 characters
+}
+
+// The standard fonts available to documents
+var (
+	Helvetica = &Font{name: "Helvetica", resource: "F1", fallback: 556, widths: [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+	}}
+	HelveticaBold = &Font{name: "Helvetica-Bold", resource: "F2", fallback: 611, widths: [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}}
+	Courier = &Font{name: "Courier", resource: "F3", fallback: 600}
+)
+
+// fonts lists the fonts declared in every document, in resource order
+var fonts = []*Font{Helvetica, HelveticaBold, Courier}
+
+func init() {
+	for i := range Courier.widths {
+		Courier.widths[i] = 600
+	}
+}
+
+// TextWidth returns the width of text in points
+func (f *Font) TextWidth(text string, size float64) float64 {
+	total := 0
+	for _, c := range encode(text) {
+		if c >= 32 && c <= 126 {
+			total += f.widths[c-32]
+		} else {
+			total += f.fallback
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks text into lines no wider than width, keeping explicit line
+// breaks. Words longer than a line are split.
+func (f *Font) Wrap(text string, size, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if f.TextWidth(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for f.TextWidth(word, size) > width {
+				cut := f.fit(word, size, width)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit returns how many bytes of word fit in width, at least one rune
+func (f *Font) fit(word string, size, width float64) int {
+	cut := 0
+	for i := range word {
+		if i > 0 && f.TextWidth(word[:i], size) > width {
+			break
+		}
+		cut = i
+	}
+	if cut == 0 {
+		for i := range word {
+			if i > 0 {
+				return i
+			}
+		}
+		return len(word)
+	}
+	return cut
+}
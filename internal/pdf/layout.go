@@ -0,0 +1,127 @@
+package pdf
+
+import "fmt"
+
+// Layout flows text down the pages of a document, starting a new page
+// when the current one is full and numbering pages in the footer
+type Layout struct {
+	doc    *Document
+	size   Size
+	margin float64
+	page   *Page
+	y      float64
+
+	// Footer is printed at the bottom left of every page
+	Footer string
+	// OnNewPage, when set, is called at the top of every new page, e.g. to
+	// repeat the headings of a table
+	OnNewPage func()
+}
+
+// NewLayout starts a flowing layout on pages of the given size
+func NewLayout(doc *Document, size Size, margin float64) *Layout {
+	return &Layout{doc: doc, size: size, margin: margin}
+}
+
+// Width returns the width available between the margins
+func (l *Layout) Width() float64 {
+	return l.size.Width - 2*l.margin
+}
+
+// Heading writes a bold heading
+func (l *Layout) Heading(text string, size float64) {
+	l.Space(size * 0.4)
+	l.Paragraph(HelveticaBold, size, text)
+	l.Space(size * 0.2)
+}
+
+// Paragraph writes wrapped text
+func (l *Layout) Paragraph(font *Font, size float64, text string) {
+	l.indented(0, font, size, text)
+}
+
+// Field writes a bold label followed by a wrapped value
+func (l *Layout) Field(label, value string, size float64) {
+	labelWidth := HelveticaBold.TextWidth(label+": ", size)
+	lines := Helvetica.Wrap(value, size, l.Width()-labelWidth)
+	for i, line := range lines {
+		l.ensure(size * 1.4)
+		if i == 0 {
+			l.page.Text(l.margin, l.y+size, HelveticaBold, size, label+":")
+		}
+		l.page.Text(l.margin+labelWidth, l.y+size, Helvetica, size, line)
+		l.y += size * 1.4
+	}
+}
+
+// Bullet writes an indented, wrapped list item
+func (l *Layout) Bullet(text string, size float64) {
+	l.ensure(size * 1.4)
+	l.page.Text(l.margin+size*0.5, l.y+size, Helvetica, size, "-")
+	l.indented(size*1.5, Helvetica, size, text)
+}
+
+// Row writes one line of columns, each cut to its width. It returns false
+// without writing when widths do not match the values.
+func (l *Layout) Row(font *Font, size float64, widths []float64, values []string) bool {
+	if len(widths) != len(values) {
+		return false
+	}
+	l.ensure(size * 1.4)
+	x := l.margin
+	for i, value := range values {
+		for value != "" && font.TextWidth(value, size) > widths[i]-size*0.5 {
+			runes := []rune(value)
+			value = string(runes[:len(runes)-1])
+		}
+		l.page.Text(x, l.y+size, font, size, value)
+		x += widths[i]
+	}
+	l.y += size * 1.4
+	return true
+}
+
+// Rule draws a horizontal line across the text width
+func (l *Layout) Rule() {
+	l.ensure(6)
+	l.page.Line(l.margin, l.y+3, l.size.Width-l.margin, l.y+3, 0.5)
+	l.y += 6
+}
+
+// Space moves down without writing, unless at the top of a page
+func (l *Layout) Space(height float64) {
+	if l.page == nil || l.y == l.margin {
+		return
+	}
+	l.y += height
+}
+
+// NewPage starts a new page
+func (l *Layout) NewPage() {
+	l.page = l.doc.AddPage(l.size)
+	l.y = l.margin
+	footerY := l.size.Height - l.margin/2
+	if l.Footer != "" {
+		l.page.Text(l.margin, footerY, Helvetica, 8, l.Footer)
+	}
+	pageNumber := fmt.Sprintf("Page %d", l.doc.Pages())
+	l.page.Text(l.size.Width-l.margin-Helvetica.TextWidth(pageNumber, 8), footerY, Helvetica, 8, pageNumber)
+	if l.OnNewPage != nil {
+		l.OnNewPage()
+	}
+}
+
+func (l *Layout) indented(indent float64, font *Font, size float64, text string) {
+	for _, line := range font.Wrap(text, size, l.Width()-indent) {
+		l.ensure(size * 1.4)
+		l.page.Text(l.margin+indent, l.y+size, font, size, line)
+		l.y += size * 1.4
+	}
+}
+
+// ensure starts a new page unless height fits above the bottom margin
+func (l *Layout) ensure(height float64) {
+	if l.page == nil || l.y+height > l.size.Height-l.margin {
+		l.NewPage()
+	}
+}
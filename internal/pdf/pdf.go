@@ -0,0 +1,207 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// and Courier fonts, lines and filled rectangles. It needs no font files;
+// the standard fonts are built into every PDF reader, and text is encoded
+// as WinAnsi (Latin-1), so characters outside it are replaced with "?".
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page sizes in points (1/72 inch)
+var (
+	A4     = Size{Width: 595.28, Height: 841.89}
+	Letter = Size{Width: 612, Height: 792}
+)
+
+// Size is a page size in points
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+// Document is a PDF document being built in memory
+type Document struct {
+	Title   string
+	Author  string
+	Subject string
+	pages   []*Page
+}
+
+// New creates an empty document
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends a page of the given size
+func (d *Document) AddPage(size Size) *Page {
+	page := &Page{size: size}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Pages returns the number of pages
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// Page is one page. Coordinates are in points from the top-left corner,
+// with y growing downwards; text is placed by its baseline.
+type Page struct {
+	size    Size
+	content bytes.Buffer
+}
+
+// Size returns the page size
+func (p *Page) Size() Size {
+	return p.size
+}
+
+// Text draws a line of text with its baseline at (x, y)
+func (p *Page) Text(x, y float64, font *Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		font.resource, num(size), num(x), num(p.size.Height-y), escape(text))
+}
+
+// Line draws a straight line of the given width
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n",
+		num(width), num(x1), num(p.size.Height-y1), num(x2), num(p.size.Height-y2))
+}
+
+// FillRect draws a black rectangle whose top-left corner is at (x, y)
+func (p *Page) FillRect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n",
+		num(x), num(p.size.Height-y-height), num(width), num(height))
+}
+
+// StrokeRect draws the outline of a rectangle whose top-left corner is at (x, y)
+func (p *Page) StrokeRect(x, y, width, height, lineWidth float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s %s %s re S\n",
+		num(lineWidth), num(x), num(p.size.Height-y-height), num(width), num(height))
+}
+
+// Bars draws a linear barcode whose top-left corner is at (x, y). widths
+// are the bar and space widths in modules, starting with a bar.
+func (p *Page) Bars(x, y, moduleWidth, height float64, widths []int) {
+	for i, width := range widths {
+		if i%2 == 0 {
+			p.FillRect(x, y, float64(width)*moduleWidth, height)
+		}
+		x += float64(width) * moduleWidth
+	}
+}
+
+// WriteTo writes the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage(A4)
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and page tree, followed by the fonts
+	// and then a page and content stream pair per page
+	firstPage := 3 + len(fonts)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	fontRefs := make([]string, len(fonts))
+	for i, font := range fonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.name))
+		fontRefs[i] = fmt.Sprintf("/%s %d 0 R", font.resource, 3+i)
+	}
+	resources := fmt.Sprintf("<< /Font << %s >> >>", strings.Join(fontRefs, " "))
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R >>",
+			num(page.size.Width), num(page.size.Height), resources, firstPage+2*i+1))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.String()))
+	}
+
+	object(fmt.Sprintf("<< /Title (%s) /Author (%s) /Subject (%s) /Producer (hospital-portal) >>",
+		escape(d.Title), escape(d.Author), escape(d.Subject)))
+	info := len(offsets)
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
+
+// num formats a coordinate with at most two decimals
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// escape encodes text as a WinAnsi PDF string literal body
+func escape(text string) string {
+	var b strings.Builder
+	for _, c := range encode(text) {
+		switch c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// encode converts text to WinAnsi bytes. Latin-1 maps directly; control
+// characters and anything else become "?".
+func encode(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		case r == '‘' || r == '’':
+			out = append(out, '\'')
+		case r == '“' || r == '”':
+			out = append(out, '"')
+		case r == '–' || r == '—':
+			out = append(out, '-')
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,153 @@
+// Package phone normalizes phone numbers to E.164 using a small offline
+// table of country metadata (metadata.csv). The table only knows calling
+// codes, trunk prefixes and national number lengths, which is enough to
+// catch typos and to store numbers in one canonical form; it does not
+// validate number ranges within a country.
+package phone
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//go:embed metadata.csv
+var metadataCSV string
+
+// ErrInvalidNumber is returned for numbers that cannot be normalized
+var ErrInvalidNumber = errors.New("invalid phone number")
+
+// Region holds the numbering metadata of one country
+type Region struct {
+	Code        string // ISO 3166-1 alpha-2
+	Name        string
+	CallingCode string
+	TrunkPrefix string // dialled before national numbers, dropped in E.164
+	MinLength   int    // national significant number length
+	MaxLength   int
+}
+
+var (
+	regions       = map[string]Region{}
+	byCallingCode = map[string][]Region{}
+)
+
+func init() {
+	records, err := csv.NewReader(strings.NewReader(metadataCSV)).ReadAll()
+	if err != nil {
+		panic(fmt.Sprintf("phone: invalid metadata: %v", err))
+	}
+	for _, record := range records[1:] {
+		minLength, err1 := strconv.Atoi(record[4])
+		maxLength, err2 := strconv.Atoi(record[5])
+		if err1 != nil || err2 != nil {
+			panic(fmt.Sprintf("phone: invalid lengths for %s", record[0]))
+		}
+		region := Region{
+			Code:        record[0],
+			Name:        record[1],
+			CallingCode: record[2],
+			TrunkPrefix: record[3],
+			MinLength:   minLength,
+			MaxLength:   maxLength,
+		}
+		regions[region.Code] = region
+		byCallingCode[region.CallingCode] = append(byCallingCode[region.CallingCode], region)
+	}
+}
+
+// LookupRegion finds a region by ISO code or English name, ignoring case
+func LookupRegion(codeOrName string) (Region, bool) {
+	value := strings.TrimSpace(codeOrName)
+	if region, ok := regions[strings.ToUpper(value)]; ok {
+		return region, true
+	}
+	for _, region := range regions {
+		if strings.EqualFold(region.Name, value) {
+			return region, true
+		}
+	}
+	return Region{}, false
+}
+
+// Normalize converts a phone number to E.164. Numbers written with a
+// leading + or 00 are parsed as international; anything else is read as a
+// national number of defaultRegion.
+func Normalize(raw, defaultRegion string) (string, error) {
+	digits, international, err := clean(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if international {
+		// Calling codes are prefix-free, so at most one length matches
+		for length := 1; length <= 3 && length < len(digits); length++ {
+			code := digits[:length]
+			candidates, ok := byCallingCode[code]
+			if !ok {
+				continue
+			}
+			national := digits[length:]
+			for _, region := range candidates {
+				if validLength(region, national) {
+					return "+" + code + national, nil
+				}
+			}
+			return "", fmt.Errorf("%w: wrong length for +%s", ErrInvalidNumber, code)
+		}
+		return "", fmt.Errorf("%w: unknown country calling code", ErrInvalidNumber)
+	}
+
+	region, ok := LookupRegion(defaultRegion)
+	if !ok {
+		return "", fmt.Errorf("%w: unknown region %q", ErrInvalidNumber, defaultRegion)
+	}
+	national := digits
+	if region.TrunkPrefix != "" && strings.HasPrefix(national, region.TrunkPrefix) && !validLength(region, national) {
+		national = strings.TrimPrefix(national, region.TrunkPrefix)
+	}
+	if !validLength(region, national) {
+		return "", fmt.Errorf("%w: wrong length for %s", ErrInvalidNumber, region.Name)
+	}
+	return "+" + region.CallingCode + national, nil
+}
+
+// clean strips punctuation and reports whether the number carried an
+// international prefix
+func clean(raw string) (string, bool, error) {
+	value := strings.TrimSpace(raw)
+	international := false
+	switch {
+	case strings.HasPrefix(value, "+"):
+		international = true
+		value = value[1:]
+	case strings.HasPrefix(value, "00"):
+		international = true
+		value = value[2:]
+	}
+
+	var digits strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(" -().\t/", r):
+		default:
+			return "", false, fmt.Errorf("%w: unexpected character %q", ErrInvalidNumber, r)
+		}
+	}
+	if digits.Len() == 0 {
+		return "", false, fmt.Errorf("%w: no digits", ErrInvalidNumber)
+	}
+	return digits.String(), international, nil
+}
+
+func validLength(region Region, national string) bool {
+	if strings.HasPrefix(national, "0") && region.TrunkPrefix == "0" {
+		return false
+	}
+	return len(national) >= region.MinLength && len(national) <= region.MaxLength
+}
@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// AdmissionRepository handles database operations for inpatient stays.
+// Admit, transfer and discharge change the admission, its encounter and
+// bed occupancy in one transaction with the affected beds locked.
+type AdmissionRepository struct {
+	db *gorm.DB
+}
+
+// NewAdmissionRepository creates a new admission repository instance
+func NewAdmissionRepository(db *gorm.DB) *AdmissionRepository {
+	return &AdmissionRepository{
+		db: db,
+	}
+}
+
+// Admit opens the inpatient encounter, creates the admission and occupies
+// its bed. checkBed vetoes the admission once the bed is locked.
+func (r *AdmissionRepository) Admit(admission *models.Admission, encounter *models.Encounter, checkBed func(bed *models.Bed) error) (*models.Admission, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		bed, err := lockBed(tx, *admission.BedID)
+		if err != nil {
+			return err
+		}
+		if err := checkBed(bed); err != nil {
+			return err
+		}
+
+		if err := tx.Create(encounter).Error; err != nil {
+			return err
+		}
+		admission.EncounterID = encounter.ID
+		if err := tx.Omit(clause.Associations).Create(admission).Error; err != nil {
+			return err
+		}
+		movement := models.AdmissionBedMovement{
+			AdmissionID: admission.ID,
+			BedID:       bed.ID,
+			FromTime:    admission.AdmittedAt,
+			Reason:      "admission",
+			MovedByID:   admission.AdmittedByID,
+		}
+		if err := tx.Create(&movement).Error; err != nil {
+			return err
+		}
+		return occupyBed(tx, bed, admission.PatientID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(admission.ID)
+}
+
+// Transfer moves an admitted patient to another bed. The old bed goes to
+// cleaning. check vetoes the move once both beds are locked.
+func (r *AdmissionRepository) Transfer(admissionID uint, movement *models.AdmissionBedMovement, check func(admission *models.Admission, target *models.Bed) error) (*models.Admission, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		admission, err := lockAdmission(tx, admissionID)
+		if err != nil {
+			return err
+		}
+		if admission.BedID == nil {
+			return check(admission, nil)
+		}
+
+		// Lock both beds in ID order so concurrent transfers cannot deadlock
+		current, target, err := lockBedPair(tx, *admission.BedID, movement.BedID)
+		if err != nil {
+			return err
+		}
+		if err := check(admission, target); err != nil {
+			return err
+		}
+
+		if err := closeMovement(tx, admission.ID, movement.FromTime); err != nil {
+			return err
+		}
+		movement.AdmissionID = admission.ID
+		if err := tx.Create(movement).Error; err != nil {
+			return err
+		}
+		if err := releaseBed(tx, current); err != nil {
+			return err
+		}
+		if err := occupyBed(tx, target, admission.PatientID); err != nil {
+			return err
+		}
+		admission.BedID = &target.ID
+		return tx.Omit(clause.Associations).Save(admission).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(admissionID)
+}
+
+// Discharge ends an admission, finishes its encounter and sends the bed to
+// cleaning. check vetoes the discharge once the admission is locked.
+func (r *AdmissionRepository) Discharge(admissionID uint, dischargedAt time.Time, dischargedByID uint, disposition string, check func(admission *models.Admission) error) (*models.Admission, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		admission, err := lockAdmission(tx, admissionID)
+		if err != nil {
+			return err
+		}
+		if err := check(admission); err != nil {
+			return err
+		}
+
+		if admission.BedID != nil {
+			bed, err := lockBed(tx, *admission.BedID)
+			if err != nil {
+				return err
+			}
+			if err := closeMovement(tx, admission.ID, dischargedAt); err != nil {
+				return err
+			}
+			if err := releaseBed(tx, bed); err != nil {
+				return err
+			}
+		}
+
+		err = tx.Model(&models.Encounter{}).Where("id = ? AND status = ?", admission.EncounterID, models.EncounterStatusInProgress).
+			Updates(map[string]interface{}{"status": models.EncounterStatusFinished, "ended_at": dischargedAt}).Error
+		if err != nil {
+			return err
+		}
+
+		admission.Status = models.AdmissionStatusDischarged
+		admission.BedID = nil
+		admission.DischargedAt = &dischargedAt
+		admission.DischargedByID = &dischargedByID
+		admission.Disposition = disposition
+		return tx.Omit(clause.Associations).Save(admission).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(admissionID)
+}
+
+// FindByID retrieves an admission with its bed movements
+func (r *AdmissionRepository) FindByID(id uint) (*models.Admission, error) {
+	var admission models.Admission
+	err := r.db.Preload("Movements", func(db *gorm.DB) *gorm.DB { return db.Order("from_time") }).
+		First(&admission, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("admission not found")
+		}
+		return nil, err
+	}
+	return &admission, nil
+}
+
+// FindByPatient retrieves a patient's admissions, most recent first
+func (r *AdmissionRepository) FindByPatient(patientID uint) ([]models.Admission, error) {
+	var admissions []models.Admission
+	if err := r.db.Where("patient_id = ?", patientID).Order("admitted_at DESC").Find(&admissions).Error; err != nil {
+		return nil, err
+	}
+	return admissions, nil
+}
+
+// FindActiveByPatient retrieves a patient's current admission, or nil
+func (r *AdmissionRepository) FindActiveByPatient(patientID uint) (*models.Admission, error) {
+	var admission models.Admission
+	err := r.db.Where("patient_id = ? AND status = ?", patientID, models.AdmissionStatusAdmitted).First(&admission).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &admission, nil
+}
+
+// FindActive retrieves current admissions with their patients
+func (r *AdmissionRepository) FindActive() ([]models.Admission, error) {
+	var admissions []models.Admission
+	if err := r.db.Preload("Patient").Where("status = ?", models.AdmissionStatusAdmitted).Find(&admissions).Error; err != nil {
+		return nil, err
+	}
+	return admissions, nil
+}
+
+func lockAdmission(tx *gorm.DB, id uint) (*models.Admission, error) {
+	var admission models.Admission
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&admission, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("admission not found")
+		}
+		return nil, err
+	}
+	return &admission, nil
+}
+
+func lockBedPair(tx *gorm.DB, firstID, secondID uint) (*models.Bed, *models.Bed, error) {
+	if firstID == secondID {
+		bed, err := lockBed(tx, firstID)
+		return bed, bed, err
+	}
+	if firstID > secondID {
+		second, first, err := lockBedPair(tx, secondID, firstID)
+		return first, second, err
+	}
+	first, err := lockBed(tx, firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, err := lockBed(tx, secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return first, second, nil
+}
+
+func closeMovement(tx *gorm.DB, admissionID uint, at time.Time) error {
+	return tx.Model(&models.AdmissionBedMovement{}).
+		Where("admission_id = ? AND to_time IS NULL", admissionID).
+		Update("to_time", at).Error
+}
+
+func occupyBed(tx *gorm.DB, bed *models.Bed, patientID uint) error {
+	bed.Status = models.BedStatusOccupied
+	bed.StatusNote = ""
+	bed.PatientID = &patientID
+	return tx.Save(bed).Error
+}
+
+// releaseBed frees a bed; it needs cleaning before the next patient
+func releaseBed(tx *gorm.DB, bed *models.Bed) error {
+	bed.Status = models.BedStatusCleaning
+	bed.StatusNote = ""
+	bed.PatientID = nil
+	return tx.Save(bed).Error
+}
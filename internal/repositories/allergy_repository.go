@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// AllergyRepository handles database operations for allergies
+type AllergyRepository struct {
+	db *gorm.DB
+}
+
+// NewAllergyRepository creates a new allergy repository instance
+func NewAllergyRepository(db *gorm.DB) *AllergyRepository {
+	return &AllergyRepository{
+		db: db,
+	}
+}
+
+// Transaction runs fn with a repository bound to one database transaction.
+// Nothing fn writes is kept unless it returns nil.
+func (r *AllergyRepository) Transaction(fn func(allergyRepo *AllergyRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewAllergyRepository(tx))
+	})
+}
+
+// Create creates a new allergy record
+func (r *AllergyRepository) Create(allergy *models.Allergy) (*models.Allergy, error) {
+	if err := r.db.Create(allergy).Error; err != nil {
+		return nil, err
+	}
+	return allergy, nil
+}
+
+// FindByPatient retrieves all allergy records for a patient
+func (r *AllergyRepository) FindByPatient(patientID uint) ([]models.Allergy, error) {
+	var allergies []models.Allergy
+	if err := r.db.Where("patient_id = ?", patientID).Order("recorded_at DESC").Find(&allergies).Error; err != nil {
+		return nil, err
+	}
+	return allergies, nil
+}
+
+// FindActiveByPatient retrieves the active allergy records for a patient
+func (r *AllergyRepository) FindActiveByPatient(patientID uint) ([]models.Allergy, error) {
+	var allergies []models.Allergy
+	err := r.db.Where("patient_id = ? AND status = ?", patientID, models.AllergyStatusActive).
+		Order("recorded_at DESC").
+		Find(&allergies).Error
+	if err != nil {
+		return nil, err
+	}
+	return allergies, nil
+}
+
+// FindByID retrieves an allergy record by ID
+func (r *AllergyRepository) FindByID(id uint) (*models.Allergy, error) {
+	var allergy models.Allergy
+	if err := r.db.First(&allergy, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("allergy not found")
+		}
+		return nil, err
+	}
+	return &allergy, nil
+}
+
+// FindByPatientAndID retrieves an allergy record of a patient by ID. It
+// returns nil when the patient has no such record.
+func (r *AllergyRepository) FindByPatientAndID(patientID, id uint) (*models.Allergy, error) {
+	var allergies []models.Allergy
+	if err := r.db.Where("patient_id = ? AND id = ?", patientID, id).Limit(1).Find(&allergies).Error; err != nil {
+		return nil, err
+	}
+	if len(allergies) == 0 {
+		return nil, nil
+	}
+	return &allergies[0], nil
+}
+
+// Update updates an allergy record
+func (r *AllergyRepository) Update(allergy *models.Allergy) (*models.Allergy, error) {
+	if err := r.db.Save(allergy).Error; err != nil {
+		return nil, err
+	}
+	return allergy, nil
+}
+
+// DeactivateNKDA marks any active NKDA statement for a patient as inactive
+func (r *AllergyRepository) DeactivateNKDA(patientID uint) error {
+	return r.db.Model(&models.Allergy{}).
+		Where("patient_id = ? AND nkda = ? AND status = ?", patientID, true, models.AllergyStatusActive).
+		Update("status", models.AllergyStatusInactive).Error
+}
+
+// Delete deletes an allergy record
+func (r *AllergyRepository) Delete(id uint) error {
+	if err := r.db.Delete(&models.Allergy{}, id).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindUpdatedSinceInBatches walks the allergies updated after since, or all of
+// them when since is nil
+func (r *AllergyRepository) FindUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.Allergy) error) error {
+	var allergies []models.Allergy
+	return r.db.Scopes(updatedSince(since)).
+		FindInBatches(&allergies, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(allergies)
+		}).Error
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// AuditRepository handles database operations for the audit log
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new audit repository instance
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// Create records an audit log entry
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// Finish records the outcome of an audited action
+func (r *AuditRepository) Finish(id uint, outcome string, rowCount int, message string) error {
+	return r.db.Model(&models.AuditLog{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"outcome":     outcome,
+		"row_count":   rowCount,
+		"error":       message,
+		"finished_at": time.Now(),
+	}).Error
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// BedRepository handles database operations for wards, rooms and beds
+type BedRepository struct {
+	db *gorm.DB
+}
+
+// NewBedRepository creates a new bed repository instance
+func NewBedRepository(db *gorm.DB) *BedRepository {
+	return &BedRepository{
+		db: db,
+	}
+}
+
+// CreateWard creates a new ward
+func (r *BedRepository) CreateWard(ward *models.Ward) (*models.Ward, error) {
+	if err := r.db.Omit(clause.Associations).Create(ward).Error; err != nil {
+		return nil, err
+	}
+	return ward, nil
+}
+
+// FindWardByID retrieves a ward
+func (r *BedRepository) FindWardByID(id uint) (*models.Ward, error) {
+	var ward models.Ward
+	if err := r.db.First(&ward, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ward not found")
+		}
+		return nil, err
+	}
+	return &ward, nil
+}
+
+// FindWardsWithBeds retrieves wards with their rooms and beds, ordered for
+// display. A zero wardID returns every ward.
+func (r *BedRepository) FindWardsWithBeds(wardID uint) ([]models.Ward, error) {
+	var wards []models.Ward
+	query := r.db.
+		Preload("Rooms", func(db *gorm.DB) *gorm.DB { return db.Order("number") }).
+		Preload("Rooms.Beds", func(db *gorm.DB) *gorm.DB { return db.Order("label") }).
+		Order("code")
+	if wardID != 0 {
+		query = query.Where("id = ?", wardID)
+	}
+	if err := query.Find(&wards).Error; err != nil {
+		return nil, err
+	}
+	return wards, nil
+}
+
+// CreateRoom creates a new room
+func (r *BedRepository) CreateRoom(room *models.Room) (*models.Room, error) {
+	if err := r.db.Omit(clause.Associations).Create(room).Error; err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// FindRoomByID retrieves a room
+func (r *BedRepository) FindRoomByID(id uint) (*models.Room, error) {
+	var room models.Room
+	if err := r.db.First(&room, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("room not found")
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+// CreateBed creates a new bed
+func (r *BedRepository) CreateBed(bed *models.Bed) (*models.Bed, error) {
+	if err := r.db.Create(bed).Error; err != nil {
+		return nil, err
+	}
+	return bed, nil
+}
+
+// FindBedByID retrieves a bed
+func (r *BedRepository) FindBedByID(id uint) (*models.Bed, error) {
+	var bed models.Bed
+	if err := r.db.First(&bed, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("bed not found")
+		}
+		return nil, err
+	}
+	return &bed, nil
+}
+
+// UpdateBedStatus changes the housekeeping status of a bed. The bed row
+// is locked so the change cannot race an admission; check vetoes it.
+func (r *BedRepository) UpdateBedStatus(id uint, status, note string, check func(bed *models.Bed) error) (*models.Bed, error) {
+	var bed *models.Bed
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if bed, err = lockBed(tx, id); err != nil {
+			return err
+		}
+		if err := check(bed); err != nil {
+			return err
+		}
+		bed.Status = status
+		bed.StatusNote = note
+		return tx.Save(bed).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bed, nil
+}
+
+// lockBed reads a bed with a row lock inside a transaction
+func lockBed(tx *gorm.DB, id uint) (*models.Bed, error) {
+	var bed models.Bed
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bed, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("bed not found")
+		}
+		return nil, err
+	}
+	return &bed, nil
+}
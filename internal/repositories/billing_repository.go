@@ -0,0 +1,268 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// BillingRepository handles database operations for the charge master,
+// charges, invoices and payments
+type BillingRepository struct {
+	db *gorm.DB
+}
+
+// NewBillingRepository creates a new billing repository instance
+func NewBillingRepository(db *gorm.DB) *BillingRepository {
+	return &BillingRepository{
+		db: db,
+	}
+}
+
+// FindChargeItem retrieves a charge master item by code
+func (r *BillingRepository) FindChargeItem(code string) (*models.ChargeMasterItem, error) {
+	var item models.ChargeMasterItem
+	if err := r.db.First(&item, "code = ?", code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("charge master item not found")
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// FindChargeItems lists the charge master, optionally including inactive items
+func (r *BillingRepository) FindChargeItems(includeInactive bool) ([]models.ChargeMasterItem, error) {
+	var items []models.ChargeMasterItem
+	query := r.db.Order("code")
+	if !includeInactive {
+		query = query.Where("active = ?", true)
+	}
+	if err := query.Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SaveChargeItem creates or updates a charge master item
+func (r *BillingRepository) SaveChargeItem(item *models.ChargeMasterItem) (*models.ChargeMasterItem, error) {
+	if err := r.db.Save(item).Error; err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// CreateCharge creates a new charge
+func (r *BillingRepository) CreateCharge(charge *models.Charge) (*models.Charge, error) {
+	if err := r.db.Create(charge).Error; err != nil {
+		return nil, err
+	}
+	return charge, nil
+}
+
+// FindChargeByID retrieves a charge by ID
+func (r *BillingRepository) FindChargeByID(id uint) (*models.Charge, error) {
+	var charge models.Charge
+	if err := r.db.First(&charge, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("charge not found")
+		}
+		return nil, err
+	}
+	return &charge, nil
+}
+
+// FindChargesByEncounter retrieves the charges of an encounter
+func (r *BillingRepository) FindChargesByEncounter(encounterID uint) ([]models.Charge, error) {
+	var charges []models.Charge
+	if err := r.db.Where("encounter_id = ?", encounterID).Order("id").Find(&charges).Error; err != nil {
+		return nil, err
+	}
+	return charges, nil
+}
+
+// FindUninvoicedCharges retrieves a patient's posted charges that are not
+// on an invoice yet, limited to the given IDs when any are given
+func (r *BillingRepository) FindUninvoicedCharges(patientID uint, ids []uint) ([]models.Charge, error) {
+	var charges []models.Charge
+	query := r.db.Where("patient_id = ? AND status = ? AND invoice_id IS NULL", patientID, models.ChargeStatusPosted)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	if err := query.Order("service_date, id").Find(&charges).Error; err != nil {
+		return nil, err
+	}
+	return charges, nil
+}
+
+// UpdateCharge changes a charge. The charge row is locked while update
+// checks and changes it, so it cannot be invoiced in the meantime.
+func (r *BillingRepository) UpdateCharge(id uint, update func(charge *models.Charge) error) (*models.Charge, error) {
+	var charge models.Charge
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&charge, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("charge not found")
+			}
+			return err
+		}
+		if err := update(&charge); err != nil {
+			return err
+		}
+		return tx.Save(&charge).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
+}
+
+// CreateInvoice creates an invoice with its lines, numbers it and marks
+// its charges as invoiced in one transaction. It fails if any of the
+// charges was invoiced concurrently.
+func (r *BillingRepository) CreateInvoice(invoice *models.Invoice, chargeIDs []uint) (*models.Invoice, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(invoice).Error; err != nil {
+			return err
+		}
+		invoice.Number = fmt.Sprintf("INV-%06d", invoice.ID)
+		if err := tx.Model(invoice).Update("number", invoice.Number).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Charge{}).
+			Where("id IN ? AND invoice_id IS NULL AND status = ?", chargeIDs, models.ChargeStatusPosted).
+			Update("invoice_id", invoice.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(chargeIDs)) {
+			return errors.New("charges were invoiced or voided concurrently")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindInvoiceByID(invoice.ID)
+}
+
+// FindInvoiceByID retrieves an invoice with its lines and payments
+func (r *BillingRepository) FindInvoiceByID(id uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Payments", func(db *gorm.DB) *gorm.DB { return db.Order("received_at") }).
+		First(&invoice, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// FindInvoiceByEncounter retrieves the open invoice carrying an
+// encounter's charges, or nil when they are not invoiced
+func (r *BillingRepository) FindInvoiceByEncounter(encounterID uint) (*models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.Where("status <> ? AND id IN (?)", models.InvoiceStatusVoided,
+		r.db.Model(&models.Charge{}).Select("invoice_id").Where("encounter_id = ? AND invoice_id IS NOT NULL", encounterID)).
+		Order("id DESC").Limit(1).Find(&invoices).Error
+	if err != nil || len(invoices) == 0 {
+		return nil, err
+	}
+	return &invoices[0], nil
+}
+
+// FindInvoicesByPatient retrieves a patient's invoices, newest first
+func (r *BillingRepository) FindInvoicesByPatient(patientID uint) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	if err := r.db.Where("patient_id = ?", patientID).Order("issued_at DESC").Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// VoidInvoice voids an invoice and releases its charges so they can be
+// invoiced again. The invoice row is locked while check validates it and
+// marks it voided, so a concurrent payment cannot be lost.
+func (r *BillingRepository) VoidInvoice(id uint, check func(invoice *models.Invoice) error) (*models.Invoice, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var invoice models.Invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("invoice not found")
+			}
+			return err
+		}
+		if err := check(&invoice); err != nil {
+			return err
+		}
+		if err := tx.Omit(clause.Associations).Save(&invoice).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Charge{}).Where("invoice_id = ?", invoice.ID).Update("invoice_id", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindInvoiceByID(id)
+}
+
+// AddPayment records a payment or refund against an invoice. The invoice
+// row is locked while check validates the payment against it and apply
+// updates its totals, so concurrent payments cannot overdraw it.
+func (r *BillingRepository) AddPayment(payment *models.Payment, check func(invoice *models.Invoice) error) (*models.Invoice, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var invoice models.Invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, payment.InvoiceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("invoice not found")
+			}
+			return err
+		}
+		if err := check(&invoice); err != nil {
+			return err
+		}
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(&invoice).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindInvoiceByID(payment.InvoiceID)
+}
+
+// SumPatientBalance adds up the balances of a patient's open invoices and
+// the amount of charges not invoiced yet
+func (r *BillingRepository) SumPatientBalance(patientID uint) (invoiced int64, uninvoiced int64, err error) {
+	err = r.db.Model(&models.Invoice{}).
+		Where("patient_id = ? AND status <> ?", patientID, models.InvoiceStatusVoided).
+		Select("COALESCE(SUM(balance), 0)").Scan(&invoiced).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	err = r.db.Model(&models.Charge{}).
+		Where("patient_id = ? AND status = ? AND invoice_id IS NULL", patientID, models.ChargeStatusPosted).
+		Select("COALESCE(SUM(amount), 0)").Scan(&uninvoiced).Error
+	return invoiced, uninvoiced, err
+}
+
+// FindPaymentsBetween retrieves payments and refunds received in [from, to)
+func (r *BillingRepository) FindPaymentsBetween(from, to time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.Where("received_at >= ? AND received_at < ?", from, to).
+		Order("received_at").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// BulkExportRepository handles database operations for bulk export jobs
+type BulkExportRepository struct {
+	db *gorm.DB
+}
+
+// NewBulkExportRepository creates a new bulk export repository instance
+func NewBulkExportRepository(db *gorm.DB) *BulkExportRepository {
+	return &BulkExportRepository{
+		db: db,
+	}
+}
+
+// Create creates a bulk export job
+func (r *BulkExportRepository) Create(job *models.BulkExportJob) (*models.BulkExportJob, error) {
+	if err := r.db.Omit(clause.Associations).Create(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// FindByID retrieves a bulk export job with its files
+func (r *BulkExportRepository) FindByID(id uint) (*models.BulkExportJob, error) {
+	var job models.BulkExportJob
+	err := r.db.Preload("Files", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&job, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("bulk export not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CountInProgressByUser counts the exports a user has running
+func (r *BulkExportRepository) CountInProgressByUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.BulkExportJob{}).
+		Where("requested_by_id = ? AND status = ?", userID, models.BulkExportStatusInProgress).
+		Count(&count).Error
+	return count, err
+}
+
+// FindExpired retrieves the jobs, with their files, that expired before now
+func (r *BulkExportRepository) FindExpired(now time.Time) ([]models.BulkExportJob, error) {
+	var jobs []models.BulkExportJob
+	if err := r.db.Preload("Files").Where("expires_at < ?", now).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// AddFile records a file written by a job
+func (r *BulkExportRepository) AddFile(file *models.BulkExportFile) error {
+	return r.db.Create(file).Error
+}
+
+// Update applies change to the locked job and saves it; an error from
+// change aborts the update
+func (r *BulkExportRepository) Update(id uint, change func(job *models.BulkExportJob) error) (*models.BulkExportJob, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var job models.BulkExportJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("bulk export not found")
+			}
+			return err
+		}
+		if err := change(&job); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(&job).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(id)
+}
+
+// Delete removes a job and its file records
+func (r *BulkExportRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", id).Delete(&models.BulkExportFile{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.BulkExportJob{}, id).Error
+	})
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// CareTeamRepository handles database operations for care team members
+type CareTeamRepository struct {
+	db *gorm.DB
+}
+
+// NewCareTeamRepository creates a new care team repository instance
+func NewCareTeamRepository(db *gorm.DB) *CareTeamRepository {
+	return &CareTeamRepository{
+		db: db,
+	}
+}
+
+// Add adds a member to a patient's care team, ignoring duplicates
+func (r *CareTeamRepository) Add(member *models.CareTeamMember) (*models.CareTeamMember, error) {
+	var existing models.CareTeamMember
+	err := r.db.Where("patient_id = ? AND user_id = ?", member.PatientID, member.UserID).First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := r.db.Create(member).Error; err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// FindByPatient retrieves the care team of a patient
+func (r *CareTeamRepository) FindByPatient(patientID uint) ([]models.CareTeamMember, error) {
+	var members []models.CareTeamMember
+	if err := r.db.Preload("User").Where("patient_id = ?", patientID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// Remove removes a user from a patient's care team
+func (r *CareTeamRepository) Remove(patientID, userID uint) error {
+	result := r.db.Where("patient_id = ? AND user_id = ?", patientID, userID).Delete(&models.CareTeamMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("care team member not found")
+	}
+	return nil
+}
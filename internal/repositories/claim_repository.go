@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// ClaimRepository handles database operations for insurance claims and remittances
+type ClaimRepository struct {
+	db *gorm.DB
+}
+
+// NewClaimRepository creates a new claim repository instance
+func NewClaimRepository(db *gorm.DB) *ClaimRepository {
+	return &ClaimRepository{
+		db: db,
+	}
+}
+
+// Transaction runs fn with claim and billing repositories bound to one
+// database transaction. Nothing fn writes is kept unless it returns nil.
+func (r *ClaimRepository) Transaction(fn func(claimRepo *ClaimRepository, billingRepo *BillingRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewClaimRepository(tx), NewBillingRepository(tx))
+	})
+}
+
+// CreateBatch creates a batch with its claims and assigns the claim
+// control numbers in one transaction. Each claim's encounter row is locked
+// while check is given the encounter's open claims, so two batches cannot
+// both claim an encounter.
+func (r *ClaimRepository) CreateBatch(batch *models.ClaimBatch, check func(encounterID uint, openClaims int64) error) (*models.ClaimBatch, error) {
+	// Encounters are locked in ID order so that batches do not deadlock
+	encounterIDs := make([]uint, len(batch.Claims))
+	for i, claim := range batch.Claims {
+		encounterIDs[i] = claim.EncounterID
+	}
+	sort.Slice(encounterIDs, func(i, j int) bool { return encounterIDs[i] < encounterIDs[j] })
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, encounterID := range encounterIDs {
+			var encounter models.Encounter
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&encounter, encounterID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("encounter not found")
+				}
+				return err
+			}
+			open, err := NewClaimRepository(tx).CountOpenClaimsByEncounter(encounterID)
+			if err != nil {
+				return err
+			}
+			if err := check(encounterID, open); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		for i := range batch.Claims {
+			claim := &batch.Claims[i]
+			claim.ControlNumber = fmt.Sprintf("CLM%07d", claim.ID)
+			if err := tx.Model(claim).Update("control_number", claim.ControlNumber).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// UpdateBatch updates a batch without touching its claims
+func (r *ClaimRepository) UpdateBatch(batch *models.ClaimBatch) error {
+	return r.db.Omit(clause.Associations).Save(batch).Error
+}
+
+// DeleteBatch deletes a batch and its claims
+func (r *ClaimRepository) DeleteBatch(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("batch_id = ?", id).Delete(&models.InsuranceClaim{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ClaimBatch{}, id).Error
+	})
+}
+
+// FindBatchByID retrieves a batch with its claims
+func (r *ClaimRepository) FindBatchByID(id uint) (*models.ClaimBatch, error) {
+	var batch models.ClaimBatch
+	if err := r.db.Preload("Claims").First(&batch, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("claim batch not found")
+		}
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// FindClaims lists claims, newest first, optionally filtered by status and patient
+func (r *ClaimRepository) FindClaims(status string, patientID uint) ([]models.InsuranceClaim, error) {
+	var claims []models.InsuranceClaim
+	query := r.db.Order("id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if patientID != 0 {
+		query = query.Where("patient_id = ?", patientID)
+	}
+	if err := query.Find(&claims).Error; err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// FindClaimByControlNumber retrieves a claim by its CLM01 control number
+func (r *ClaimRepository) FindClaimByControlNumber(controlNumber string) (*models.InsuranceClaim, error) {
+	var claim models.InsuranceClaim
+	if err := r.db.First(&claim, "control_number = ?", controlNumber).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("claim not found")
+		}
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// CountOpenClaimsByEncounter counts the claims of an encounter that were
+// not denied
+func (r *ClaimRepository) CountOpenClaimsByEncounter(encounterID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.InsuranceClaim{}).
+		Where("encounter_id = ? AND status <> ?", encounterID, models.ClaimStatusDenied).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateClaim updates a claim and records its adjustments in one transaction
+func (r *ClaimRepository) UpdateClaim(claim *models.InsuranceClaim, adjustments []models.ClaimAdjustment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(claim).Error; err != nil {
+			return err
+		}
+		if len(adjustments) == 0 {
+			return nil
+		}
+		return tx.Create(&adjustments).Error
+	})
+}
+
+// CreateRemittance creates a remittance record
+func (r *ClaimRepository) CreateRemittance(remittance *models.Remittance) (*models.Remittance, error) {
+	if err := r.db.Create(remittance).Error; err != nil {
+		return nil, err
+	}
+	return remittance, nil
+}
+
+// UpdateRemittance updates a remittance record
+func (r *ClaimRepository) UpdateRemittance(remittance *models.Remittance) error {
+	return r.db.Save(remittance).Error
+}
+
+// FindRemittanceByID retrieves a remittance by ID
+func (r *ClaimRepository) FindRemittanceByID(id uint) (*models.Remittance, error) {
+	var remittance models.Remittance
+	if err := r.db.First(&remittance, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("remittance not found")
+		}
+		return nil, err
+	}
+	return &remittance, nil
+}
+
+// ExistsRemittanceTrace reports whether a remittance with the trace number was imported
+func (r *ClaimRepository) ExistsRemittanceTrace(traceNumber string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Remittance{}).Where("trace_number = ?", traceNumber).Count(&count).Error
+	return count > 0, err
+}
+
+// FindClaimsByRemittance retrieves the claims adjudicated by a remittance
+func (r *ClaimRepository) FindClaimsByRemittance(remittanceID uint) ([]models.InsuranceClaim, error) {
+	var claims []models.InsuranceClaim
+	if err := r.db.Where("remittance_id = ?", remittanceID).Order("id").Find(&claims).Error; err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// FindAdjustmentsByRemittance retrieves the adjustments reported by a remittance
+func (r *ClaimRepository) FindAdjustmentsByRemittance(remittanceID uint) ([]models.ClaimAdjustment, error) {
+	var adjustments []models.ClaimAdjustment
+	if err := r.db.Where("remittance_id = ?", remittanceID).Order("id").Find(&adjustments).Error; err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// ConsentRepository handles database operations for patient consents
+type ConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewConsentRepository creates a new consent repository instance
+func NewConsentRepository(db *gorm.DB) *ConsentRepository {
+	return &ConsentRepository{
+		db: db,
+	}
+}
+
+// Create creates a new consent record
+func (r *ConsentRepository) Create(consent *models.PatientConsent) (*models.PatientConsent, error) {
+	if err := r.db.Create(consent).Error; err != nil {
+		return nil, err
+	}
+	return consent, nil
+}
+
+// Update updates a consent record
+func (r *ConsentRepository) Update(consent *models.PatientConsent) (*models.PatientConsent, error) {
+	if err := r.db.Save(consent).Error; err != nil {
+		return nil, err
+	}
+	return consent, nil
+}
+
+// FindByPatient retrieves the consent history of a patient, newest first
+func (r *ConsentRepository) FindByPatient(patientID uint) ([]models.PatientConsent, error) {
+	var consents []models.PatientConsent
+	if err := r.db.Where("patient_id = ?", patientID).Order("id DESC").Find(&consents).Error; err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
+
+// FindCurrent retrieves the most recent consent record of a type for a patient
+func (r *ConsentRepository) FindCurrent(patientID uint, consentType string) (*models.PatientConsent, error) {
+	var consent models.PatientConsent
+	err := r.db.Where("patient_id = ? AND type = ?", patientID, consentType).Order("id DESC").First(&consent).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// FindPatientIDsWithCurrentStatus retrieves the patients whose most recent
+// consent record of a type has the given status
+func (r *ConsentRepository) FindPatientIDsWithCurrentStatus(consentType, status string) ([]uint, error) {
+	var patientIDs []uint
+	err := currentConsents(r.db, []string{consentType}, status).
+		Distinct().
+		Pluck("patient_id", &patientIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return patientIDs, nil
+}
+
+// currentConsents selects the consent records of the given types that are
+// the most recent of their type for their patient and have the given status
+func currentConsents(db *gorm.DB, consentTypes []string, status string) *gorm.DB {
+	return db.Model(&models.PatientConsent{}).
+		Where("type IN ? AND status = ?", consentTypes, status).
+		Where("NOT EXISTS (SELECT 1 FROM patient_consents newer WHERE newer.patient_id = patient_consents.patient_id AND newer.type = patient_consents.type AND newer.id > patient_consents.id AND newer.deleted_at IS NULL)")
+}
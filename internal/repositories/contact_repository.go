@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// ContactRepository handles database operations for patient contacts
+type ContactRepository struct {
+	db *gorm.DB
+}
+
+// NewContactRepository creates a new contact repository instance
+func NewContactRepository(db *gorm.DB) *ContactRepository {
+	return &ContactRepository{
+		db: db,
+	}
+}
+
+// Create creates a new contact
+func (r *ContactRepository) Create(contact *models.PatientContact) (*models.PatientContact, error) {
+	if err := r.db.Create(contact).Error; err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+// FindByID retrieves a contact by ID
+func (r *ContactRepository) FindByID(id uint) (*models.PatientContact, error) {
+	var contact models.PatientContact
+	if err := r.db.First(&contact, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("contact not found")
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// FindByPatient retrieves a patient's contacts, guardians first
+func (r *ContactRepository) FindByPatient(patientID uint) ([]models.PatientContact, error) {
+	var contacts []models.PatientContact
+	err := r.db.Where("patient_id = ?", patientID).
+		Order("is_guardian DESC, is_next_of_kin DESC, id").
+		Find(&contacts).Error
+	if err != nil {
+		return nil, err
+	}
+	return contacts, nil
+}
+
+// CountGuardians counts a patient's guardians, ignoring the given contact
+func (r *ContactRepository) CountGuardians(patientID uint, excludeID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PatientContact{}).
+		Where("patient_id = ? AND is_guardian = ? AND id <> ?", patientID, true, excludeID).
+		Count(&count).Error
+	return count, err
+}
+
+// Update updates a contact
+func (r *ContactRepository) Update(contact *models.PatientContact) (*models.PatientContact, error) {
+	if err := r.db.Save(contact).Error; err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+// Delete deletes a contact
+func (r *ContactRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PatientContact{}, id).Error
+}
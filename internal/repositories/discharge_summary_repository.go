@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// DischargeSummaryRepository handles database operations for discharge summaries
+type DischargeSummaryRepository struct {
+	db *gorm.DB
+}
+
+// NewDischargeSummaryRepository creates a new discharge summary repository instance
+func NewDischargeSummaryRepository(db *gorm.DB) *DischargeSummaryRepository {
+	return &DischargeSummaryRepository{
+		db: db,
+	}
+}
+
+// Create creates a summary with its items
+func (r *DischargeSummaryRepository) Create(summary *models.DischargeSummary) (*models.DischargeSummary, error) {
+	if err := r.db.Create(summary).Error; err != nil {
+		return nil, err
+	}
+	return r.FindByID(summary.ID)
+}
+
+// FindByID retrieves a summary with its items
+func (r *DischargeSummaryRepository) FindByID(id uint) (*models.DischargeSummary, error) {
+	var summary models.DischargeSummary
+	if err := r.withItems(r.db).First(&summary, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("discharge summary not found")
+		}
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// FindByAdmission retrieves the summary of an admission, or nil when none
+// was drafted yet
+func (r *DischargeSummaryRepository) FindByAdmission(admissionID uint) (*models.DischargeSummary, error) {
+	var summary models.DischargeSummary
+	if err := r.withItems(r.db).Where("admission_id = ?", admissionID).First(&summary).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// Update saves a summary and, when items is not nil, replaces its items.
+// The summary row is locked and check vetoes the change, so an edit
+// cannot slip in after signing.
+func (r *DischargeSummaryRepository) Update(summary *models.DischargeSummary, items []models.DischargeSummaryItem, check func(current *models.DischargeSummary) error) (*models.DischargeSummary, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.DischargeSummary
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, summary.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("discharge summary not found")
+			}
+			return err
+		}
+		if err := check(&current); err != nil {
+			return err
+		}
+		if items != nil {
+			if err := tx.Where("summary_id = ?", summary.ID).Delete(&models.DischargeSummaryItem{}).Error; err != nil {
+				return err
+			}
+			for i := range items {
+				items[i].ID = 0
+				items[i].SummaryID = summary.ID
+			}
+			if len(items) > 0 {
+				if err := tx.Create(&items).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Omit(clause.Associations).Save(summary).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(summary.ID)
+}
+
+func (r *DischargeSummaryRepository) withItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("section, position")
+	})
+}
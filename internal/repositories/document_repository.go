@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// DocumentRepository handles database operations for patient documents
+type DocumentRepository struct {
+	db *gorm.DB
+}
+
+// NewDocumentRepository creates a new document repository instance
+func NewDocumentRepository(db *gorm.DB) *DocumentRepository {
+	return &DocumentRepository{
+		db: db,
+	}
+}
+
+// Create creates a new document record
+func (r *DocumentRepository) Create(document *models.PatientDocument) (*models.PatientDocument, error) {
+	if err := r.db.Create(document).Error; err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// FindByID retrieves a document record by ID
+func (r *DocumentRepository) FindByID(id uint) (*models.PatientDocument, error) {
+	var document models.PatientDocument
+	if err := r.db.First(&document, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("document not found")
+		}
+		return nil, err
+	}
+	return &document, nil
+}
+
+// FindByPatient retrieves a patient's documents with one of the given visibilities
+func (r *DocumentRepository) FindByPatient(patientID uint, visibilities []string) ([]models.PatientDocument, error) {
+	var documents []models.PatientDocument
+	err := r.db.Where("patient_id = ? AND visibility IN ?", patientID, visibilities).
+		Order("created_at DESC").
+		Find(&documents).Error
+	if err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// Delete deletes a document record
+func (r *DocumentRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PatientDocument{}, id).Error
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// EncounterRepository handles database operations for encounters
+type EncounterRepository struct {
+	db *gorm.DB
+}
+
+// NewEncounterRepository creates a new encounter repository instance
+func NewEncounterRepository(db *gorm.DB) *EncounterRepository {
+	return &EncounterRepository{
+		db: db,
+	}
+}
+
+// Create creates a new encounter
+func (r *EncounterRepository) Create(encounter *models.Encounter) (*models.Encounter, error) {
+	if err := r.db.Create(encounter).Error; err != nil {
+		return nil, err
+	}
+	return encounter, nil
+}
+
+// FindByID retrieves an encounter by ID
+func (r *EncounterRepository) FindByID(id uint) (*models.Encounter, error) {
+	var encounter models.Encounter
+	if err := r.db.First(&encounter, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("encounter not found")
+		}
+		return nil, err
+	}
+	return &encounter, nil
+}
+
+// FindByPatient retrieves a patient's encounters, most recent first
+func (r *EncounterRepository) FindByPatient(patientID uint) ([]models.Encounter, error) {
+	var encounters []models.Encounter
+	if err := r.db.Where("patient_id = ?", patientID).Order("started_at DESC").Find(&encounters).Error; err != nil {
+		return nil, err
+	}
+	return encounters, nil
+}
+
+// Update updates an encounter
+func (r *EncounterRepository) Update(encounter *models.Encounter) (*models.Encounter, error) {
+	if err := r.db.Save(encounter).Error; err != nil {
+		return nil, err
+	}
+	return encounter, nil
+}
+
+// FindByPatientBetween retrieves a patient's encounters started in
+// [from, to], oldest first
+func (r *EncounterRepository) FindByPatientBetween(patientID uint, from, to time.Time) ([]models.Encounter, error) {
+	var encounters []models.Encounter
+	err := r.db.Where("patient_id = ? AND started_at >= ? AND started_at <= ?", patientID, from, to).
+		Order("started_at").Find(&encounters).Error
+	if err != nil {
+		return nil, err
+	}
+	return encounters, nil
+}
+
+// FindUpdatedSinceInBatches walks the encounters updated after since, or all of
+// them when since is nil
+func (r *EncounterRepository) FindUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.Encounter) error) error {
+	var encounters []models.Encounter
+	return r.db.Scopes(updatedSince(since)).
+		FindInBatches(&encounters, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(encounters)
+		}).Error
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// HL7Repository handles database operations for inbound HL7 messages
+type HL7Repository struct {
+	db *gorm.DB
+}
+
+// NewHL7Repository creates a new HL7 repository instance
+func NewHL7Repository(db *gorm.DB) *HL7Repository {
+	return &HL7Repository{
+		db: db,
+	}
+}
+
+// CreateDeadLetter stores a message that was not applied
+func (r *HL7Repository) CreateDeadLetter(letter *models.HL7DeadLetter) error {
+	return r.db.Create(letter).Error
+}
+
+// FindDeadLetters retrieves the newest dead letters, optionally only those
+// of one control ID
+func (r *HL7Repository) FindDeadLetters(controlID string, limit int) ([]models.HL7DeadLetter, error) {
+	var letters []models.HL7DeadLetter
+	query := r.db.Order("received_at DESC").Limit(limit)
+	if controlID != "" {
+		query = query.Where("control_id = ?", controlID)
+	}
+	if err := query.Find(&letters).Error; err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// FindDeadLetterByID retrieves a dead letter by ID
+func (r *HL7Repository) FindDeadLetterByID(id uint) (*models.HL7DeadLetter, error) {
+	var letter models.HL7DeadLetter
+	if err := r.db.First(&letter, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("dead letter not found")
+		}
+		return nil, err
+	}
+	return &letter, nil
+}
+
+// DeleteDeadLetter removes a dead letter once it has been dealt with
+func (r *HL7Repository) DeleteDeadLetter(id uint) error {
+	result := r.db.Delete(&models.HL7DeadLetter{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("dead letter not found")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// ICD10Repository handles database operations for the ICD-10 code table
+type ICD10Repository struct {
+	db *gorm.DB
+}
+
+// NewICD10Repository creates a new ICD-10 repository instance
+func NewICD10Repository(db *gorm.DB) *ICD10Repository {
+	return &ICD10Repository{
+		db: db,
+	}
+}
+
+// FindByCode retrieves a code entry
+func (r *ICD10Repository) FindByCode(code string) (*models.ICD10Code, error) {
+	var entry models.ICD10Code
+	if err := r.db.Where("code = ?", code).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("ICD-10 code not found")
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Search finds codes starting with the query, or whose description
+// contains every word of it. Code matches are listed first.
+func (r *ICD10Repository) Search(codePrefix, text string, limit int) ([]models.ICD10Code, error) {
+	var codes []models.ICD10Code
+	query := r.db.Model(&models.ICD10Code{})
+
+	conditions := r.db.Where("code LIKE ?", codePrefix+"%")
+	if words := strings.Fields(text); len(words) > 0 {
+		textMatch := r.db
+		for _, word := range words {
+			textMatch = textMatch.Where("description ILIKE ?", "%"+word+"%")
+		}
+		conditions = conditions.Or(textMatch)
+	}
+
+	err := query.Where(conditions).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE WHEN code LIKE ? THEN 0 ELSE 1 END, code",
+			Vars:               []interface{}{codePrefix + "%"},
+			WithoutParentheses: true,
+		}}).
+		Limit(limit).
+		Find(&codes).Error
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Import upserts code entries in batches inside one transaction
+func (r *ICD10Repository) Import(codes []models.ICD10Code) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"description", "updated_at"}),
+		}).CreateInBatches(codes, 1000).Error
+	})
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// ImmunizationRepository handles database operations for immunizations
+type ImmunizationRepository struct {
+	db *gorm.DB
+}
+
+// NewImmunizationRepository creates a new immunization repository instance
+func NewImmunizationRepository(db *gorm.DB) *ImmunizationRepository {
+	return &ImmunizationRepository{
+		db: db,
+	}
+}
+
+// Create creates a new immunization record
+func (r *ImmunizationRepository) Create(immunization *models.Immunization) (*models.Immunization, error) {
+	if err := r.db.Create(immunization).Error; err != nil {
+		return nil, err
+	}
+	return immunization, nil
+}
+
+// FindByID retrieves an immunization record by ID
+func (r *ImmunizationRepository) FindByID(id uint) (*models.Immunization, error) {
+	var immunization models.Immunization
+	if err := r.db.First(&immunization, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("immunization not found")
+		}
+		return nil, err
+	}
+	return &immunization, nil
+}
+
+// FindByPatient retrieves all immunization records for a patient, oldest first
+func (r *ImmunizationRepository) FindByPatient(patientID uint) ([]models.Immunization, error) {
+	var immunizations []models.Immunization
+	if err := r.db.Where("patient_id = ?", patientID).Order("administered_at, id").Find(&immunizations).Error; err != nil {
+		return nil, err
+	}
+	return immunizations, nil
+}
+
+// FindCompletedByPatients retrieves the doses given to any of the patients,
+// oldest first, leaving out records entered in error
+func (r *ImmunizationRepository) FindCompletedByPatients(patientIDs []uint) ([]models.Immunization, error) {
+	var immunizations []models.Immunization
+	err := r.db.Where("patient_id IN ? AND status = ?", patientIDs, models.ImmunizationStatusCompleted).
+		Order("administered_at, id").
+		Find(&immunizations).Error
+	if err != nil {
+		return nil, err
+	}
+	return immunizations, nil
+}
+
+// Update updates an immunization record
+func (r *ImmunizationRepository) Update(immunization *models.Immunization) (*models.Immunization, error) {
+	if err := r.db.Save(immunization).Error; err != nil {
+		return nil, err
+	}
+	return immunization, nil
+}
+
+// FindUpdatedSinceInBatches walks the immunizations updated after since, or all of
+// them when since is nil
+func (r *ImmunizationRepository) FindUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.Immunization) error) error {
+	var immunizations []models.Immunization
+	return r.db.Scopes(updatedSince(since)).
+		FindInBatches(&immunizations, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(immunizations)
+		}).Error
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// InsuranceRepository handles database operations for payers and coverages
+type InsuranceRepository struct {
+	db *gorm.DB
+}
+
+// NewInsuranceRepository creates a new insurance repository instance
+func NewInsuranceRepository(db *gorm.DB) *InsuranceRepository {
+	return &InsuranceRepository{
+		db: db,
+	}
+}
+
+// FindPayer retrieves a payer from the catalogue by code
+func (r *InsuranceRepository) FindPayer(code string) (*models.Payer, error) {
+	var payer models.Payer
+	if err := r.db.First(&payer, "code = ?", code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("payer not found")
+		}
+		return nil, err
+	}
+	return &payer, nil
+}
+
+// SearchPayers lists active payers, optionally filtered by name or code
+func (r *InsuranceRepository) SearchPayers(query string) ([]models.Payer, error) {
+	var payers []models.Payer
+	db := r.db.Where("active = ?", true)
+	if query != "" {
+		db = db.Where("code ILIKE ? OR name ILIKE ?", query+"%", "%"+query+"%")
+	}
+	if err := db.Order("name").Find(&payers).Error; err != nil {
+		return nil, err
+	}
+	return payers, nil
+}
+
+// ImportPayers upserts payer catalogue entries in one transaction
+func (r *InsuranceRepository) ImportPayers(payers []models.Payer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "edi_payer_id", "member_id_pattern", "claim_filing_code", "active", "updated_at"}),
+		}).CreateInBatches(payers, 500).Error
+	})
+}
+
+// Create creates a new coverage
+func (r *InsuranceRepository) Create(coverage *models.InsuranceCoverage) (*models.InsuranceCoverage, error) {
+	if err := r.db.Omit(clause.Associations).Create(coverage).Error; err != nil {
+		return nil, err
+	}
+	return r.FindByID(coverage.ID)
+}
+
+// FindByID retrieves a coverage by ID
+func (r *InsuranceRepository) FindByID(id uint) (*models.InsuranceCoverage, error) {
+	var coverage models.InsuranceCoverage
+	if err := r.db.Preload("Payer").First(&coverage, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coverage not found")
+		}
+		return nil, err
+	}
+	return &coverage, nil
+}
+
+// FindByPatient retrieves a patient's coverages in billing order
+func (r *InsuranceRepository) FindByPatient(patientID uint) ([]models.InsuranceCoverage, error) {
+	var coverages []models.InsuranceCoverage
+	err := r.db.Preload("Payer").
+		Where("patient_id = ?", patientID).
+		Order("priority, effective_from DESC").
+		Find(&coverages).Error
+	if err != nil {
+		return nil, err
+	}
+	return coverages, nil
+}
+
+// Update updates a coverage
+func (r *InsuranceRepository) Update(coverage *models.InsuranceCoverage) (*models.InsuranceCoverage, error) {
+	if err := r.db.Omit(clause.Associations).Save(coverage).Error; err != nil {
+		return nil, err
+	}
+	return r.FindByID(coverage.ID)
+}
+
+// Delete deletes a coverage
+func (r *InsuranceRepository) Delete(id uint) error {
+	return r.db.Delete(&models.InsuranceCoverage{}, id).Error
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// InteractionRepository handles database operations for the interaction dataset
+type InteractionRepository struct {
+	db *gorm.DB
+}
+
+// NewInteractionRepository creates a new interaction repository instance
+func NewInteractionRepository(db *gorm.DB) *InteractionRepository {
+	return &InteractionRepository{
+		db: db,
+	}
+}
+
+// FindMatches retrieves entries of the given kind pairing any of the
+// subjects in a with any of the subjects in b, in either order
+func (r *InteractionRepository) FindMatches(kind string, a, b []string) ([]models.DrugInteraction, error) {
+	var interactions []models.DrugInteraction
+	if len(a) == 0 || len(b) == 0 {
+		return interactions, nil
+	}
+	err := r.db.Where("kind = ?", kind).
+		Where(r.db.Where("subject_a IN ? AND subject_b IN ?", a, b).
+			Or("subject_a IN ? AND subject_b IN ?", b, a)).
+		Find(&interactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// Import loads a dataset. When replace is set, existing entries from the
+// same source are removed first. Everything happens in one transaction.
+func (r *InteractionRepository) Import(source string, interactions []models.DrugInteraction, replace bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := tx.Where("source = ?", source).Delete(&models.DrugInteraction{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(interactions) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(interactions, 500).Error
+	})
+}
+
+// Count returns the number of entries in the dataset
+func (r *InteractionRepository) Count() (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.DrugInteraction{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindAlertsByPrescription retrieves the alerts stored for a prescription
+func (r *InteractionRepository) FindAlertsByPrescription(prescriptionID uint) ([]models.InteractionAlert, error) {
+	var alerts []models.InteractionAlert
+	if err := r.db.Where("prescription_id = ?", prescriptionID).Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// LabRepository handles database operations for lab orders and results
+type LabRepository struct {
+	db *gorm.DB
+}
+
+// NewLabRepository creates a new lab repository instance
+func NewLabRepository(db *gorm.DB) *LabRepository {
+	return &LabRepository{
+		db: db,
+	}
+}
+
+// CreateOrder creates a new lab order
+func (r *LabRepository) CreateOrder(order *models.LabOrder) (*models.LabOrder, error) {
+	if err := r.db.Create(order).Error; err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// FindOrderByID retrieves a lab order and its results
+func (r *LabRepository) FindOrderByID(id uint) (*models.LabOrder, error) {
+	var order models.LabOrder
+	if err := r.db.Preload("Results").First(&order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("lab order not found")
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindOrdersByPatient retrieves a patient's lab orders with their results
+func (r *LabRepository) FindOrdersByPatient(patientID uint) ([]models.LabOrder, error) {
+	var orders []models.LabOrder
+	if err := r.db.Preload("Results").Where("patient_id = ?", patientID).Order("ordered_at DESC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// FindOrdersByStatus retrieves the lab worklist for a status, most urgent
+// and oldest first
+func (r *LabRepository) FindOrdersByStatus(status string) ([]models.LabOrder, error) {
+	var orders []models.LabOrder
+	err := r.db.Where("status = ?", status).
+		Order("CASE priority WHEN 'stat' THEN 0 WHEN 'urgent' THEN 1 ELSE 2 END, ordered_at ASC").
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// UpdateOrder changes a lab order without touching its results. The order
+// row is locked while update checks and changes it.
+func (r *LabRepository) UpdateOrder(id uint, update func(order *models.LabOrder) error) (*models.LabOrder, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.LabOrder
+		if err := lockOrder(tx, id, &order); err != nil {
+			return err
+		}
+		if err := update(&order); err != nil {
+			return err
+		}
+		return tx.Omit("Results").Save(&order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindOrderByID(id)
+}
+
+// SaveResults replaces the results of an order and updates the order in
+// one transaction. The order row is locked while check validates and
+// updates it, so concurrent result entries or a verification in between
+// cannot be overwritten.
+func (r *LabRepository) SaveResults(id uint, results []models.LabResult, check func(order *models.LabOrder) error) (*models.LabOrder, error) {
+	var order models.LabOrder
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockOrder(tx, id, &order); err != nil {
+			return err
+		}
+		if err := check(&order); err != nil {
+			return err
+		}
+		if err := tx.Where("lab_order_id = ?", order.ID).Delete(&models.LabResult{}).Error; err != nil {
+			return err
+		}
+		for i := range results {
+			results[i].LabOrderID = order.ID
+		}
+		if err := tx.Create(&results).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Results").Save(&order).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	order.Results = results
+	return &order, nil
+}
+
+func lockOrder(tx *gorm.DB, id uint, order *models.LabOrder) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("lab order not found")
+		}
+		return err
+	}
+	return nil
+}
+
+// FindResultedUpdatedSinceInBatches walks the resulted and verified lab
+// orders, with their results, updated after since, or all of them when
+// since is nil
+func (r *LabRepository) FindResultedUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.LabOrder) error) error {
+	var orders []models.LabOrder
+	return r.db.Scopes(updatedSince(since)).
+		Preload("Results").
+		Where("status IN ?", []string{models.LabOrderStatusResulted, models.LabOrderStatusVerified}).
+		FindInBatches(&orders, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(orders)
+		}).Error
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// PatientImportRepository handles database operations for patient import jobs
+type PatientImportRepository struct {
+	db *gorm.DB
+}
+
+// NewPatientImportRepository creates a new patient import repository instance
+func NewPatientImportRepository(db *gorm.DB) *PatientImportRepository {
+	return &PatientImportRepository{
+		db: db,
+	}
+}
+
+// Create creates a patient import job
+func (r *PatientImportRepository) Create(job *models.PatientImportJob) (*models.PatientImportJob, error) {
+	if err := r.db.Omit(clause.Associations).Create(job).Error; err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// FindByID retrieves a patient import job without its row errors
+func (r *PatientImportRepository) FindByID(id uint) (*models.PatientImportJob, error) {
+	var job models.PatientImportJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("patient import not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindByUser retrieves the newest imports a user started
+func (r *PatientImportRepository) FindByUser(userID uint, limit int) ([]models.PatientImportJob, error) {
+	var jobs []models.PatientImportJob
+	err := r.db.Where("requested_by_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FindErrors retrieves a page of a job's row errors in row order
+func (r *PatientImportRepository) FindErrors(jobID uint, offset, limit int) ([]models.PatientImportError, error) {
+	var rowErrors []models.PatientImportError
+	err := r.db.Where("job_id = ?", jobID).
+		Order("row, id").
+		Offset(offset).
+		Limit(limit).
+		Find(&rowErrors).Error
+	if err != nil {
+		return nil, err
+	}
+	return rowErrors, nil
+}
+
+// CountInProgressByUser counts the imports a user has running
+func (r *PatientImportRepository) CountInProgressByUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PatientImportJob{}).
+		Where("requested_by_id = ? AND status = ?", userID, models.PatientImportStatusInProgress).
+		Count(&count).Error
+	return count, err
+}
+
+// AddErrors records row errors of a job
+func (r *PatientImportRepository) AddErrors(rowErrors []models.PatientImportError) error {
+	if len(rowErrors) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(rowErrors, 500).Error
+}
+
+// Update applies change to the locked job and saves it; an error from
+// change aborts the update
+func (r *PatientImportRepository) Update(id uint, change func(job *models.PatientImportJob) error) (*models.PatientImportJob, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var job models.PatientImportJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("patient import not found")
+			}
+			return err
+		}
+		if err := change(&job); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(&job).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(id)
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"strings"
+	"time"
 
 	"hospital-portal/internal/models"
 )
@@ -29,6 +31,14 @@ func (r *PatientRepository) Create(patient *models.Patient) (*models.Patient, er
 	return patient, nil
 }
 
+// CreateBatch creates patients, with their contacts and identifiers, in
+// one transaction
+func (r *PatientRepository) CreateBatch(patients []models.Patient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&patients).Error
+	})
+}
+
 // FindAll retrieves all patients
 func (r *PatientRepository) FindAll() ([]models.Patient, error) {
 	var patients []models.Patient
@@ -38,6 +48,41 @@ func (r *PatientRepository) FindAll() ([]models.Patient, error) {
 	return patients, nil
 }
 
+// FindContactReviewRequired retrieves patients flagged for contact review
+func (r *PatientRepository) FindContactReviewRequired() ([]models.Patient, error) {
+	var patients []models.Patient
+	if err := r.db.Where("contact_review_required = ?", true).Order("id").Find(&patients).Error; err != nil {
+		return nil, err
+	}
+	return patients, nil
+}
+
+// FindUnstructuredInBatches walks patients that still only have a
+// free-text address and have not been flagged for review yet
+func (r *PatientRepository) FindUnstructuredInBatches(batchSize int, fn func([]models.Patient) error) error {
+	var patients []models.Patient
+	return r.db.Where("address <> '' AND (address_line1 IS NULL OR address_line1 = '') AND contact_review_required = ?", false).
+		FindInBatches(&patients, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(patients)
+		}).Error
+}
+
+// FindBornAfterInBatches walks the patients born after cutoff, or all of
+// them when cutoff is zero. Without a recorded date of birth, the age given
+// at registration is counted from the registration date, since the stored
+// age does not advance.
+func (r *PatientRepository) FindBornAfterInBatches(cutoff time.Time, batchSize int, fn func([]models.Patient) error) error {
+	query := r.db
+	if !cutoff.IsZero() {
+		query = query.Where("date_of_birth > ? OR (date_of_birth IS NULL AND created_at - age * interval '1 year' > ?)", cutoff, cutoff)
+	}
+	var patients []models.Patient
+	return query.
+		FindInBatches(&patients, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(patients)
+		}).Error
+}
+
 // FindByID retrieves a patient by ID
 func (r *PatientRepository) FindByID(id uint) (*models.Patient, error) {
 	var patient models.Patient
@@ -50,6 +95,13 @@ func (r *PatientRepository) FindByID(id uint) (*models.Patient, error) {
 	return &patient, nil
 }
 
+// Exists reports whether a patient exists
+func (r *PatientRepository) Exists(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Patient{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
 // FindByName retrieve a patient by name
 // if needed
 func (r *PatientRepository) FindByName(name string) (*models.Patient, error) {
@@ -104,3 +156,313 @@ func (r *PatientRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+// Name match modes of a patient search
+const (
+	NameMatchPrefix   = "prefix" // the start of any word of the name
+	NameMatchExact    = "exact"
+	NameMatchContains = "contains"
+)
+
+// NameMatch matches patients whose name matches any of the values
+type NameMatch struct {
+	Values []string
+	Mode   string
+}
+
+// PatientSearch are the criteria of a patient search; empty criteria
+// match every patient
+type PatientSearch struct {
+	IDs                   []uint            // any of
+	Identifiers           []IdentifierMatch // all of
+	Names                 []NameMatch       // all of
+	Genders               []string          // any of
+	BirthDateFrom         *time.Time        // inclusive
+	BirthDateTo           *time.Time        // exclusive
+	MinAge                *int
+	MaxAge                *int
+	City                  string // case-insensitive
+	Country               string
+	CreatedFrom           *time.Time // inclusive
+	CreatedTo             *time.Time // exclusive
+	ContactReviewRequired *bool
+	WithdrawnConsents     []string // none of; consent types the patients must not have withdrawn
+}
+
+// IdentifierMatch matches the patients with any of the portal IDs or any of
+// the identifiers other systems know them by
+type IdentifierMatch struct {
+	IDs    []uint
+	Values []IdentifierValue
+}
+
+// IdentifierValue is another system's identifier; an empty System matches
+// the value in any system
+type IdentifierValue struct {
+	System string
+	Value  string
+}
+
+// Search retrieves a page of the patients matching the search, in ID
+// order, with the total number of matches
+func (r *PatientRepository) Search(search PatientSearch, offset, limit int) ([]models.Patient, int64, error) {
+	query := r.searchQuery(search)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var patients []models.Patient
+	if err := query.Order("id").Offset(offset).Limit(limit).Find(&patients).Error; err != nil {
+		return nil, 0, err
+	}
+	return patients, total, nil
+}
+
+// CountMatching counts the patients matching the search
+func (r *PatientRepository) CountMatching(search PatientSearch) (int64, error) {
+	var total int64
+	err := r.searchQuery(search).Count(&total).Error
+	return total, err
+}
+
+// SearchInBatches walks the patients matching the search in ID order
+func (r *PatientRepository) SearchInBatches(search PatientSearch, batchSize int, fn func([]models.Patient) error) error {
+	var patients []models.Patient
+	return r.searchQuery(search).
+		FindInBatches(&patients, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(patients)
+		}).Error
+}
+
+// searchQuery builds the query for the patients matching a search
+func (r *PatientRepository) searchQuery(search PatientSearch) *gorm.DB {
+	query := r.db.Model(&models.Patient{})
+	if len(search.IDs) > 0 {
+		query = query.Where("id IN ?", search.IDs)
+	}
+	for _, match := range search.Identifiers {
+		conditions := r.db
+		if len(match.IDs) > 0 {
+			conditions = conditions.Or("id IN ?", match.IDs)
+		}
+		for _, value := range match.Values {
+			identifiers := r.db.Model(&models.PatientIdentifier{}).Select("patient_id").Where("value = ?", value.Value)
+			if value.System != "" {
+				identifiers = identifiers.Where("system = ?", value.System)
+			}
+			conditions = conditions.Or("id IN (?)", identifiers)
+		}
+		query = query.Where(conditions)
+	}
+	for _, match := range search.Names {
+		conditions := r.db
+		for _, value := range match.Values {
+			pattern := escapeLike(value)
+			switch match.Mode {
+			case NameMatchExact:
+				conditions = conditions.Or("LOWER(name) = LOWER(?)", value)
+			case NameMatchContains:
+				conditions = conditions.Or("name ILIKE ?", "%"+pattern+"%")
+			default:
+				conditions = conditions.Or("name ILIKE ? OR name ILIKE ?", pattern+"%", "% "+pattern+"%")
+			}
+		}
+		query = query.Where(conditions)
+	}
+	if len(search.Genders) > 0 {
+		query = query.Where("gender IN ?", search.Genders)
+	}
+	if search.BirthDateFrom != nil {
+		query = query.Where("date_of_birth >= ?", *search.BirthDateFrom)
+	}
+	if search.BirthDateTo != nil {
+		query = query.Where("date_of_birth < ?", *search.BirthDateTo)
+	}
+	if search.MinAge != nil {
+		query = query.Where("age >= ?", *search.MinAge)
+	}
+	if search.MaxAge != nil {
+		query = query.Where("age <= ?", *search.MaxAge)
+	}
+	if search.City != "" {
+		query = query.Where("LOWER(address_city) = LOWER(?)", search.City)
+	}
+	if search.Country != "" {
+		query = query.Where("address_country = ?", search.Country)
+	}
+	if search.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *search.CreatedFrom)
+	}
+	if search.CreatedTo != nil {
+		query = query.Where("created_at < ?", *search.CreatedTo)
+	}
+	if search.ContactReviewRequired != nil {
+		query = query.Where("contact_review_required = ?", *search.ContactReviewRequired)
+	}
+	if len(search.WithdrawnConsents) > 0 {
+		withdrawn := currentConsents(r.db, search.WithdrawnConsents, models.ConsentStatusWithdrawn).Select("patient_id")
+		query = query.Where("id NOT IN (?)", withdrawn)
+	}
+	return query
+}
+
+// FindByIdentifier retrieves the patient another system knows by the
+// given identifier; it returns nil when there is none
+func (r *PatientRepository) FindByIdentifier(system, value string) (*models.Patient, error) {
+	var patient models.Patient
+	err := r.db.Joins("JOIN patient_identifiers ON patient_identifiers.patient_id = patients.id").
+		Where("patient_identifiers.system = ? AND patient_identifiers.value = ?", system, value).
+		First(&patient).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &patient, nil
+}
+
+// FindExistingIdentifiers returns which of the values are already
+// recorded as identifiers in the system
+func (r *PatientRepository) FindExistingIdentifiers(system string, values []string) ([]string, error) {
+	var existing []string
+	if len(values) == 0 {
+		return existing, nil
+	}
+	err := r.db.Model(&models.PatientIdentifier{}).
+		Where("system = ? AND value IN ?", system, values).
+		Pluck("value", &existing).Error
+	return existing, err
+}
+
+// LoadIdentifiers fills in the identifiers other systems know the patients by
+func (r *PatientRepository) LoadIdentifiers(patients []models.Patient) error {
+	if len(patients) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(patients))
+	for i := range patients {
+		ids[i] = patients[i].ID
+	}
+	var identifiers []models.PatientIdentifier
+	if err := r.db.Where("patient_id IN ?", ids).Order("id").Find(&identifiers).Error; err != nil {
+		return err
+	}
+	byPatient := make(map[uint][]models.PatientIdentifier, len(patients))
+	for _, identifier := range identifiers {
+		byPatient[identifier.PatientID] = append(byPatient[identifier.PatientID], identifier)
+	}
+	for i := range patients {
+		patients[i].Identifiers = byPatient[patients[i].ID]
+	}
+	return nil
+}
+
+// AddIdentifier records an identifier another system knows a patient by
+func (r *PatientRepository) AddIdentifier(patientID uint, system, value string) error {
+	return r.db.Create(&models.PatientIdentifier{PatientID: patientID, System: system, Value: value}).Error
+}
+
+// FindActiveVisits reports whether a patient is currently admitted and
+// whether they are waiting in or being seen from the front-desk queue
+func (r *PatientRepository) FindActiveVisits(patientID uint) (admitted bool, queued bool, err error) {
+	var admissions, entries int64
+	err = r.db.Model(&models.Admission{}).
+		Where("patient_id = ? AND status = ?", patientID, models.AdmissionStatusAdmitted).
+		Count(&admissions).Error
+	if err != nil {
+		return false, false, err
+	}
+	err = r.db.Model(&models.QueueEntry{}).
+		Where("patient_id = ? AND status IN ?", patientID, []string{models.QueueStatusWaiting, models.QueueStatusInProgress}).
+		Count(&entries).Error
+	if err != nil {
+		return false, false, err
+	}
+	return admissions > 0, entries > 0, nil
+}
+
+// patientRecordTables are the tables whose rows belong to a patient; a
+// merge moves them to the surviving record
+var patientRecordTables = []string{
+	"patient_identifiers",
+	"patient_contacts",
+	"patient_consents",
+	"patient_documents",
+	"allergies",
+	"prescriptions",
+	"encounters",
+	"vital_signs",
+	"problems",
+	"lab_orders",
+	"immunizations",
+	"notifications",
+	"insurance_coverages",
+	"invoices",
+	"charges",
+	"payments",
+	"insurance_claims",
+	"admissions",
+	"beds",
+	"discharge_summaries",
+	"queue_entries",
+	"referrals",
+	"care_team_members",
+}
+
+// Merge moves every record of the source patient to the target and
+// deletes the source. Care team members already on the target's team are
+// dropped from the source's rather than duplicated.
+func (r *PatientRepository) Merge(sourceID, targetID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var patients []models.Patient
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []uint{sourceID, targetID}).
+			Find(&patients).Error
+		if err != nil {
+			return err
+		}
+		if len(patients) != 2 {
+			return errors.New("patient not found")
+		}
+
+		err = tx.Exec("DELETE FROM care_team_members WHERE patient_id = ? AND user_id IN (SELECT user_id FROM care_team_members WHERE patient_id = ?)",
+			sourceID, targetID).Error
+		if err != nil {
+			return err
+		}
+		for _, table := range patientRecordTables {
+			if err := tx.Table(table).Where("patient_id = ?", sourceID).Update("patient_id", targetID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Patient{}, sourceID).Error
+	})
+}
+
+// escapeLike escapes the LIKE wildcards in a search value
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// FindUpdatedSinceInBatches walks the patients updated after since, or all of
+// them when since is nil
+func (r *PatientRepository) FindUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.Patient) error) error {
+	var patients []models.Patient
+	return r.db.Scopes(updatedSince(since)).
+		FindInBatches(&patients, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(patients)
+		}).Error
+}
+
+// updatedSince limits a query to the rows updated after since; a nil since
+// leaves it unfiltered
+func updatedSince(since *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if since == nil {
+			return db
+		}
+		return db.Where("updated_at > ?", *since)
+	}
+}
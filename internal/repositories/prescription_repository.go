@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// PrescriptionRepository handles database operations for prescriptions.
+// Active prescriptions past their end date are marked completed as they
+// are read, since nobody acts on a prescription running out.
+type PrescriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewPrescriptionRepository creates a new prescription repository instance
+func NewPrescriptionRepository(db *gorm.DB) *PrescriptionRepository {
+	return &PrescriptionRepository{
+		db: db,
+	}
+}
+
+// Create creates a new prescription together with its interaction alerts
+func (r *PrescriptionRepository) Create(prescription *models.Prescription, alerts []models.InteractionAlert) (*models.Prescription, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(prescription).Error; err != nil {
+			return err
+		}
+		return createAlerts(tx, prescription.ID, alerts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prescription, nil
+}
+
+// FindByID retrieves a prescription by ID
+func (r *PrescriptionRepository) FindByID(id uint) (*models.Prescription, error) {
+	if err := completeExpired(r.db.Where("id = ?", id)); err != nil {
+		return nil, err
+	}
+	var prescription models.Prescription
+	if err := r.db.First(&prescription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("prescription not found")
+		}
+		return nil, err
+	}
+	return &prescription, nil
+}
+
+// FindByPatient retrieves a patient's prescriptions, optionally filtered by status
+func (r *PrescriptionRepository) FindByPatient(patientID uint, status string) ([]models.Prescription, error) {
+	if err := completeExpired(r.db.Where("patient_id = ?", patientID)); err != nil {
+		return nil, err
+	}
+	var prescriptions []models.Prescription
+	query := r.db.Where("patient_id = ?", patientID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("start_date DESC").Find(&prescriptions).Error; err != nil {
+		return nil, err
+	}
+	return prescriptions, nil
+}
+
+// Update changes a prescription. The prescription row is locked while
+// update checks and changes it, so it cannot be renewed in the meantime.
+func (r *PrescriptionRepository) Update(id uint, update func(prescription *models.Prescription) error) (*models.Prescription, error) {
+	var prescription models.Prescription
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPrescription(tx, id, &prescription); err != nil {
+			return err
+		}
+		if err := update(&prescription); err != nil {
+			return err
+		}
+		return tx.Save(&prescription).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &prescription, nil
+}
+
+// Renew completes the original prescription and creates its renewal in one
+// transaction. check vetoes the renewal once the original is locked.
+func (r *PrescriptionRepository) Renew(originalID uint, renewal *models.Prescription, alerts []models.InteractionAlert, check func(original *models.Prescription) error) (*models.Prescription, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var original models.Prescription
+		if err := lockPrescription(tx, originalID, &original); err != nil {
+			return err
+		}
+		if err := check(&original); err != nil {
+			return err
+		}
+		original.Status = models.PrescriptionStatusCompleted
+		if err := tx.Save(&original).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(renewal).Error; err != nil {
+			return err
+		}
+		return createAlerts(tx, renewal.ID, alerts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return renewal, nil
+}
+
+// lockPrescription reads a prescription and locks its row for the rest of
+// the transaction. One that has run out is seen as completed.
+func lockPrescription(tx *gorm.DB, id uint, prescription *models.Prescription) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(prescription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("prescription not found")
+		}
+		return err
+	}
+	if prescription.Status == models.PrescriptionStatusActive && !prescription.EndDate.IsZero() && !prescription.EndDate.After(time.Now()) {
+		prescription.Status = models.PrescriptionStatusCompleted
+	}
+	return nil
+}
+
+// completeExpired marks the active prescriptions of query whose end date
+// has passed as completed
+func completeExpired(query *gorm.DB) error {
+	return query.Model(&models.Prescription{}).
+		Where("status = ? AND end_date <= ?", models.PrescriptionStatusActive, time.Now()).
+		Update("status", models.PrescriptionStatusCompleted).Error
+}
+
+func createAlerts(tx *gorm.DB, prescriptionID uint, alerts []models.InteractionAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	for i := range alerts {
+		alerts[i].PrescriptionID = prescriptionID
+	}
+	return tx.Create(&alerts).Error
+}
+
+// FindUpdatedSinceInBatches walks the prescriptions updated after since, or all of
+// them when since is nil
+func (r *PrescriptionRepository) FindUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.Prescription) error) error {
+	if err := completeExpired(r.db); err != nil {
+		return err
+	}
+	var prescriptions []models.Prescription
+	return r.db.Scopes(updatedSince(since)).
+		FindInBatches(&prescriptions, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(prescriptions)
+		}).Error
+}
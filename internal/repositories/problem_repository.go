@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// ProblemRepository handles database operations for problem list entries
+type ProblemRepository struct {
+	db *gorm.DB
+}
+
+// NewProblemRepository creates a new problem repository instance
+func NewProblemRepository(db *gorm.DB) *ProblemRepository {
+	return &ProblemRepository{
+		db: db,
+	}
+}
+
+// Save creates or updates a problem. When the problem is primary, any
+// other primary problem of the patient is demoted in the same transaction.
+func (r *ProblemRepository) Save(problem *models.Problem) (*models.Problem, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if problem.IsPrimary {
+			err := tx.Model(&models.Problem{}).
+				Where("patient_id = ? AND is_primary = ? AND id <> ?", problem.PatientID, true, problem.ID).
+				Update("is_primary", false).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Save(problem).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return problem, nil
+}
+
+// FindByID retrieves a problem by ID
+func (r *ProblemRepository) FindByID(id uint) (*models.Problem, error) {
+	var problem models.Problem
+	if err := r.db.First(&problem, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("problem not found")
+		}
+		return nil, err
+	}
+	return &problem, nil
+}
+
+// FindByPatient retrieves a patient's problem list, optionally filtered by status
+func (r *ProblemRepository) FindByPatient(patientID uint, status string) ([]models.Problem, error) {
+	var problems []models.Problem
+	query := r.db.Where("patient_id = ?", patientID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("is_primary DESC, onset_date DESC").Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// FindByEncounter retrieves the problems recorded during an encounter,
+// primary first
+func (r *ProblemRepository) FindByEncounter(encounterID uint) ([]models.Problem, error) {
+	var problems []models.Problem
+	if err := r.db.Where("encounter_id = ?", encounterID).Order("is_primary DESC, id").Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// FindByStatus retrieves every problem with the given status across all patients
+func (r *ProblemRepository) FindByStatus(status string) ([]models.Problem, error) {
+	var problems []models.Problem
+	if err := r.db.Where("status = ?", status).Order("patient_id, icd10_code").Find(&problems).Error; err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// Delete deletes a problem
+func (r *ProblemRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Problem{}, id).Error
+}
+
+// FindUpdatedSinceInBatches walks the problems updated after since, or all of
+// them when since is nil
+func (r *ProblemRepository) FindUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.Problem) error) error {
+	var problems []models.Problem
+	return r.db.Scopes(updatedSince(since)).
+		FindInBatches(&problems, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(problems)
+		}).Error
+}
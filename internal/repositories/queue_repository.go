@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// QueueWaitStats are the wait times of the patients called in from one
+// triage level
+type QueueWaitStats struct {
+	TriageLevel        *int    `json:"triage_level"`
+	Seen               int     `json:"seen"`
+	AverageWaitMinutes float64 `json:"average_wait_minutes"`
+	MaxWaitMinutes     float64 `json:"max_wait_minutes"`
+}
+
+// QueueRepository handles database operations for the waiting-room queue
+type QueueRepository struct {
+	db *gorm.DB
+}
+
+// NewQueueRepository creates a new queue repository instance
+func NewQueueRepository(db *gorm.DB) *QueueRepository {
+	return &QueueRepository{
+		db: db,
+	}
+}
+
+// Create creates a new queue entry
+func (r *QueueRepository) Create(entry *models.QueueEntry) (*models.QueueEntry, error) {
+	if err := r.db.Omit(clause.Associations).Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return r.FindByID(entry.ID)
+}
+
+// FindByID retrieves a queue entry with its patient
+func (r *QueueRepository) FindByID(id uint) (*models.QueueEntry, error) {
+	var entry models.QueueEntry
+	if err := r.db.Preload("Patient").First(&entry, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("queue entry not found")
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindActiveByPatient retrieves a patient's waiting or in-progress entry, or nil
+func (r *QueueRepository) FindActiveByPatient(patientID uint) (*models.QueueEntry, error) {
+	var entry models.QueueEntry
+	err := r.db.Where("patient_id = ? AND status IN ?", patientID, []string{models.QueueStatusWaiting, models.QueueStatusInProgress}).
+		First(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindByStatuses retrieves entries with their patients in queue order:
+// by triage level, untriaged last, then by arrival
+func (r *QueueRepository) FindByStatuses(statuses []string) ([]models.QueueEntry, error) {
+	var entries []models.QueueEntry
+	err := r.db.Preload("Patient").
+		Where("status IN ?", statuses).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "COALESCE(triage_level, 99), checked_in_at",
+			WithoutParentheses: true,
+		}}).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Update applies change to the locked entry and saves it; an error from
+// change aborts the update
+func (r *QueueRepository) Update(id uint, change func(entry *models.QueueEntry) error) (*models.QueueEntry, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var entry models.QueueEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&entry, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("queue entry not found")
+			}
+			return err
+		}
+		if err := change(&entry); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(&entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(id)
+}
+
+// ClaimNext calls in the most urgent triaged patient, longest waiting
+// first, and opens their encounter. Rows other doctors are claiming are
+// skipped, so two doctors never get the same patient. It returns nil when
+// nobody triaged is waiting.
+func (r *QueueRepository) ClaimNext(doctorID uint, encounterType string, calledAt time.Time) (*models.QueueEntry, error) {
+	var claimedID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var entry models.QueueEntry
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND triage_level IS NOT NULL", models.QueueStatusWaiting).
+			Order("triage_level, checked_in_at").
+			First(&entry).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		encounter := models.Encounter{
+			PatientID: entry.PatientID,
+			DoctorID:  doctorID,
+			Type:      encounterType,
+			Status:    models.EncounterStatusInProgress,
+			Reason:    entry.Reason,
+			StartedAt: calledAt,
+		}
+		if err := tx.Create(&encounter).Error; err != nil {
+			return err
+		}
+
+		entry.Status = models.QueueStatusInProgress
+		entry.CalledAt = &calledAt
+		entry.DoctorID = &doctorID
+		entry.EncounterID = &encounter.ID
+		if err := tx.Omit(clause.Associations).Save(&entry).Error; err != nil {
+			return err
+		}
+		claimedID = entry.ID
+		return nil
+	})
+	if err != nil || claimedID == 0 {
+		return nil, err
+	}
+	return r.FindByID(claimedID)
+}
+
+// WaitStats reports wait times, from check-in to being called in, of the
+// patients checked in during [from, to), per triage level
+func (r *QueueRepository) WaitStats(from, to time.Time) ([]QueueWaitStats, error) {
+	var stats []QueueWaitStats
+	err := r.db.Model(&models.QueueEntry{}).
+		Select("triage_level, COUNT(*) AS seen, "+
+			"AVG(EXTRACT(EPOCH FROM called_at - checked_in_at)) / 60 AS average_wait_minutes, "+
+			"MAX(EXTRACT(EPOCH FROM called_at - checked_in_at)) / 60 AS max_wait_minutes").
+		Where("checked_in_at >= ? AND checked_in_at < ? AND called_at IS NOT NULL", from, to).
+		Group("triage_level").
+		Order("triage_level").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// AverageTriageMinutes reports the average time from check-in to triage of
+// the patients checked in during [from, to)
+func (r *QueueRepository) AverageTriageMinutes(from, to time.Time) (float64, error) {
+	var minutes float64
+	err := r.db.Model(&models.QueueEntry{}).
+		Select("COALESCE(AVG(EXTRACT(EPOCH FROM triaged_at - checked_in_at)) / 60, 0)").
+		Where("checked_in_at >= ? AND checked_in_at < ? AND triaged_at IS NOT NULL", from, to).
+		Scan(&minutes).Error
+	return minutes, err
+}
+
+// CountByStatus counts the entries checked in during [from, to) per status
+func (r *QueueRepository) CountByStatus(from, to time.Time) (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	err := r.db.Model(&models.QueueEntry{}).
+		Select("status, COUNT(*) AS count").
+		Where("checked_in_at >= ? AND checked_in_at < ?", from, to).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
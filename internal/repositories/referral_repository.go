@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// ReferralRepository handles database operations for referrals
+type ReferralRepository struct {
+	db *gorm.DB
+}
+
+// NewReferralRepository creates a new referral repository instance
+func NewReferralRepository(db *gorm.DB) *ReferralRepository {
+	return &ReferralRepository{
+		db: db,
+	}
+}
+
+// Create creates a referral and links its documents
+func (r *ReferralRepository) Create(referral *models.Referral) (*models.Referral, error) {
+	if err := r.db.Omit("Patient", "Documents.*").Create(referral).Error; err != nil {
+		return nil, err
+	}
+	return r.FindByID(referral.ID)
+}
+
+// FindByID retrieves a referral with its patient and documents
+func (r *ReferralRepository) FindByID(id uint) (*models.Referral, error) {
+	var referral models.Referral
+	if err := r.db.Preload("Patient").Preload("Documents").First(&referral, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("referral not found")
+		}
+		return nil, err
+	}
+	return &referral, nil
+}
+
+// FindByPatient retrieves the referrals of a patient, newest first
+func (r *ReferralRepository) FindByPatient(patientID uint) ([]models.Referral, error) {
+	var referrals []models.Referral
+	if err := r.db.Where("patient_id = ?", patientID).Order("sent_at DESC").Find(&referrals).Error; err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+// FindByReceiver retrieves the referrals sent to a doctor with one of the
+// given statuses, most urgent and then oldest first
+func (r *ReferralRepository) FindByReceiver(doctorID uint, statuses []string) ([]models.Referral, error) {
+	var referrals []models.Referral
+	err := r.db.Preload("Patient").
+		Where("receiving_doctor_id = ? AND status IN ?", doctorID, statuses).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "CASE urgency WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END, sent_at",
+			Vars:               []interface{}{models.ReferralUrgencyEmergency, models.ReferralUrgencyUrgent},
+			WithoutParentheses: true,
+		}}).
+		Find(&referrals).Error
+	if err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+// FindByReferrer retrieves the referrals a doctor has sent, newest first
+func (r *ReferralRepository) FindByReferrer(doctorID uint, statuses []string) ([]models.Referral, error) {
+	var referrals []models.Referral
+	err := r.db.Preload("Patient").
+		Where("referring_doctor_id = ? AND status IN ?", doctorID, statuses).
+		Order("sent_at DESC").
+		Find(&referrals).Error
+	if err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+// Update applies change to the locked referral and saves it; an error from
+// change aborts the update
+func (r *ReferralRepository) Update(id uint, change func(referral *models.Referral) error) (*models.Referral, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var referral models.Referral
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&referral, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("referral not found")
+			}
+			return err
+		}
+		if err := change(&referral); err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(&referral).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindByID(id)
+}
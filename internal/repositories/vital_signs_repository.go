@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"hospital-portal/internal/models"
+)
+
+// VitalSignsRepository handles database operations for vital signs
+type VitalSignsRepository struct {
+	db *gorm.DB
+}
+
+// NewVitalSignsRepository creates a new vital signs repository instance
+func NewVitalSignsRepository(db *gorm.DB) *VitalSignsRepository {
+	return &VitalSignsRepository{
+		db: db,
+	}
+}
+
+// Create creates a new vital signs record
+func (r *VitalSignsRepository) Create(vitals *models.VitalSigns) (*models.VitalSigns, error) {
+	if err := r.db.Create(vitals).Error; err != nil {
+		return nil, err
+	}
+	return vitals, nil
+}
+
+// FindByPatientInRange retrieves a patient's vital signs recorded within
+// [from, to], oldest first. Zero times leave that end of the range open.
+func (r *VitalSignsRepository) FindByPatientInRange(patientID uint, from, to time.Time, limit int) ([]models.VitalSigns, error) {
+	var vitals []models.VitalSigns
+	query := r.db.Where("patient_id = ?", patientID)
+	if !from.IsZero() {
+		query = query.Where("recorded_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("recorded_at <= ?", to)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Order("recorded_at ASC").Find(&vitals).Error; err != nil {
+		return nil, err
+	}
+	return vitals, nil
+}
+
+// FindLatestScored retrieves the patient's most recent record with a
+// NEWS2 score recorded before the given time
+func (r *VitalSignsRepository) FindLatestScored(patientID uint, before time.Time) (*models.VitalSigns, error) {
+	var vitals models.VitalSigns
+	err := r.db.Where("patient_id = ? AND news2_score IS NOT NULL AND recorded_at < ?", patientID, before).
+		Order("recorded_at DESC").
+		Limit(1).
+		Find(&vitals).Error
+	if err != nil {
+		return nil, err
+	}
+	if vitals.ID == 0 {
+		return nil, nil
+	}
+	return &vitals, nil
+}
+
+// FindUpdatedSinceInBatches walks the vital signs records updated after since, or all of
+// them when since is nil
+func (r *VitalSignsRepository) FindUpdatedSinceInBatches(since *time.Time, batchSize int, fn func([]models.VitalSigns) error) error {
+	var records []models.VitalSigns
+	return r.db.Scopes(updatedSince(since)).
+		FindInBatches(&records, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(records)
+		}).Error
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"hospital-portal/internal/models"
+)
+
+// WebhookRepository handles database operations for webhook subscriptions
+// and their deliveries
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository instance
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+// CreateSubscription creates a webhook subscription
+func (r *WebhookRepository) CreateSubscription(subscription *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	if err := r.db.Create(subscription).Error; err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// FindSubscriptions retrieves all webhook subscriptions
+func (r *WebhookRepository) FindSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// FindActiveSubscriptions retrieves the subscriptions that are switched on
+func (r *WebhookRepository) FindActiveSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.Where("active = ?", true).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// FindSubscriptionByID retrieves a webhook subscription by ID
+func (r *WebhookRepository) FindSubscriptionByID(id uint) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := r.db.First(&subscription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook subscription not found")
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// UpdateSubscription applies change to the locked subscription and saves
+// it; an error from change aborts the update
+func (r *WebhookRepository) UpdateSubscription(id uint, change func(subscription *models.WebhookSubscription) error) (*models.WebhookSubscription, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var subscription models.WebhookSubscription
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("webhook subscription not found")
+			}
+			return err
+		}
+		if err := change(&subscription); err != nil {
+			return err
+		}
+		return tx.Save(&subscription).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindSubscriptionByID(id)
+}
+
+// DeleteSubscription soft deletes a subscription. Its delivery log is kept;
+// pending deliveries fail when they next come up.
+func (r *WebhookRepository) DeleteSubscription(id uint) error {
+	result := r.db.Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("webhook subscription not found")
+	}
+	return nil
+}
+
+// CreateDeliveries queues deliveries
+func (r *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).CreateInBatches(deliveries, 500).Error
+}
+
+// FindDeliveries retrieves a page of a subscription's deliveries, newest
+// first, optionally only those with the given status
+func (r *WebhookRepository) FindDeliveries(subscriptionID uint, status string, offset, limit int) ([]models.WebhookDelivery, error) {
+	query := r.db.Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindDeliveryByID retrieves a delivery by ID
+func (r *WebhookRepository) FindDeliveryByID(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook delivery not found")
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ClaimDueDeliveries takes up to limit pending deliveries that are due and
+// holds them for lease by moving their next attempt on, so that other
+// server instances skip them while they are being sent. The deliveries come
+// with their subscription, even one that has been deleted.
+func (r *WebhookRepository) ClaimDueDeliveries(now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var due []models.WebhookDelivery
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id").
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&due).Error
+		if err != nil || len(due) == 0 {
+			return err
+		}
+		for _, delivery := range due {
+			ids = append(ids, delivery.ID)
+		}
+		return tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	err = r.db.Preload("Subscription", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Order("id").Find(&deliveries, ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// SaveDelivery records the outcome of a delivery attempt
+func (r *WebhookRepository) SaveDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Omit(clause.Associations).Save(delivery).Error
+}
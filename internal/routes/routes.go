@@ -1,15 +1,19 @@
 package routes
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"hospital-portal/internal/auth"
 	"hospital-portal/internal/controllers"
+	"hospital-portal/internal/eligibility"
 	"hospital-portal/internal/middlewares"
 	"hospital-portal/internal/repositories"
 	"hospital-portal/internal/services"
+	"hospital-portal/internal/storage"
 )
 
 // SetupRoutes configures all the routes for the application
@@ -18,22 +22,155 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, logger *zap.Logger) {
 	r.Use(middlewares.LoggerMiddleware(logger))
 	r.Use(gin.Recovery())
 
+	// Initialize blob storage for patient documents and claim files
+	blobStorage, err := storage.NewFromConfig()
+	if err != nil {
+		logger.Fatal("Failed to initialize blob storage", zap.Error(err))
+	}
+
+	// Initialize the insurance eligibility checker
+	eligibilityChecker, err := eligibility.NewFromConfig()
+	if err != nil {
+		logger.Fatal("Failed to initialize eligibility checker", zap.Error(err))
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	patientRepo := repositories.NewPatientRepository(db)
+	allergyRepo := repositories.NewAllergyRepository(db)
+	prescriptionRepo := repositories.NewPrescriptionRepository(db)
+	interactionRepo := repositories.NewInteractionRepository(db)
+	encounterRepo := repositories.NewEncounterRepository(db)
+	vitalsRepo := repositories.NewVitalSignsRepository(db)
+	careTeamRepo := repositories.NewCareTeamRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	icd10Repo := repositories.NewICD10Repository(db)
+	problemRepo := repositories.NewProblemRepository(db)
+	labRepo := repositories.NewLabRepository(db)
+	documentRepo := repositories.NewDocumentRepository(db)
+	consentRepo := repositories.NewConsentRepository(db)
+	contactRepo := repositories.NewContactRepository(db)
+	insuranceRepo := repositories.NewInsuranceRepository(db)
+	billingRepo := repositories.NewBillingRepository(db)
+	claimRepo := repositories.NewClaimRepository(db)
+	bedRepo := repositories.NewBedRepository(db)
+	admissionRepo := repositories.NewAdmissionRepository(db)
+	summaryRepo := repositories.NewDischargeSummaryRepository(db)
+	queueRepo := repositories.NewQueueRepository(db)
+	immunizationRepo := repositories.NewImmunizationRepository(db)
+	referralRepo := repositories.NewReferralRepository(db)
+	bulkExportRepo := repositories.NewBulkExportRepository(db)
+	hl7Repo := repositories.NewHL7Repository(db)
+	importRepo := repositories.NewPatientImportRepository(db)
+	auditRepo := repositories.NewAuditRepository(db)
+	webhookRepo := repositories.NewWebhookRepository(db)
 
 	// Initialize services
 	authService := services.NewAuthService(userRepo, logger)
-	patientService := services.NewPatientService(patientRepo, logger)
+	consentService := services.NewConsentService(consentRepo, patientRepo, logger)
+	webhookService := services.NewWebhookService(webhookRepo, consentService, logger)
+	patientService := services.NewPatientService(patientRepo, contactRepo, webhookService, consentService, logger)
+	allergyService := services.NewAllergyService(allergyRepo, patientRepo, logger)
+	interactionService := services.NewInteractionService(interactionRepo, prescriptionRepo, allergyRepo, logger)
+	prescriptionService := services.NewPrescriptionService(prescriptionRepo, patientRepo, userRepo, interactionRepo, interactionService, logger)
+	notificationService := services.NewNotificationService(notificationRepo, careTeamRepo, logger)
+	careTeamService := services.NewCareTeamService(careTeamRepo, patientRepo, userRepo, logger)
+	encounterService := services.NewEncounterService(encounterRepo, patientRepo, logger)
+	vitalsService := services.NewVitalSignsService(vitalsRepo, patientRepo, encounterRepo, notificationService, logger)
+	icd10Service := services.NewICD10Service(icd10Repo, logger)
+	problemService := services.NewProblemService(problemRepo, patientRepo, encounterRepo, icd10Service, logger)
+	labService := services.NewLabService(labRepo, patientRepo, encounterRepo, notificationService, logger)
+	documentService := services.NewDocumentService(documentRepo, patientRepo, blobStorage, logger)
+	contactService := services.NewContactService(patientRepo, consentService, services.NewLogSMSSender(logger), logger)
+	researchService := services.NewResearchService(patientRepo, problemRepo, logger)
+	patientContactService := services.NewPatientContactService(contactRepo, patientRepo, logger)
+	insuranceService := services.NewInsuranceService(insuranceRepo, patientRepo, eligibilityChecker, logger)
+	billingService := services.NewBillingService(billingRepo, patientRepo, encounterRepo, logger)
+	summaryService := services.NewDischargeSummaryService(summaryRepo, admissionRepo, patientRepo, encounterRepo, problemRepo, billingRepo, prescriptionRepo, userRepo, logger)
+	admissionService := services.NewAdmissionService(bedRepo, admissionRepo, patientRepo, userRepo, summaryService, logger)
+	queueService := services.NewQueueService(queueRepo, patientRepo, logger)
+	immunizationService := services.NewImmunizationService(immunizationRepo, patientRepo, logger)
+	fhirPatientService := services.NewFHIRPatientService(patientService, patientRepo, auditRepo, logger)
+	bulkExportService := services.NewBulkExportService(bulkExportRepo, patientRepo, encounterRepo, problemRepo, allergyRepo, prescriptionRepo, vitalsRepo, labRepo, immunizationRepo, consentService, blobStorage, logger)
+	referralService := services.NewReferralService(referralRepo, patientRepo, userRepo, documentRepo, careTeamService, notificationService, logger)
+	printService := services.NewPatientPrintService(patientRepo, problemRepo, allergyRepo, prescriptionRepo, logger)
+	exportService := services.NewPatientExportService(patientRepo, auditRepo, logger)
+	importService := services.NewPatientImportService(importRepo, patientRepo, patientService, logger)
+	adtService := services.NewADTService(patientService, patientRepo, hl7Repo, logger)
+	claimService := services.NewClaimService(claimRepo, billingRepo, encounterRepo, patientRepo, problemRepo, insuranceService, billingService, blobStorage, logger)
 
 	// Initialize controllers
 	authController := controllers.NewAuthController(authService, logger)
-	patientController := controllers.NewPatientController(patientService, logger)
+	patientController := controllers.NewPatientController(patientService, allergyService, exportService, logger)
+	printController := controllers.NewPatientPrintController(printService, logger)
+	allergyController := controllers.NewAllergyController(allergyService, logger)
+	prescriptionController := controllers.NewPrescriptionController(prescriptionService, logger)
+	notificationController := controllers.NewNotificationController(notificationService, logger)
+	careTeamController := controllers.NewCareTeamController(careTeamService, logger)
+	encounterController := controllers.NewEncounterController(encounterService, logger)
+	vitalsController := controllers.NewVitalSignsController(vitalsService, logger)
+	problemController := controllers.NewProblemController(problemService, icd10Service, logger)
+	labController := controllers.NewLabController(labService, logger)
+	documentController := controllers.NewDocumentController(documentService, logger)
+	consentController := controllers.NewConsentController(consentService, contactService, researchService, logger)
+	contactController := controllers.NewContactController(patientContactService, logger)
+	insuranceController := controllers.NewInsuranceController(insuranceService, logger)
+	billingController := controllers.NewBillingController(billingService, logger)
+	claimController := controllers.NewClaimController(claimService, logger)
+	admissionController := controllers.NewAdmissionController(admissionService, logger)
+	summaryController := controllers.NewDischargeSummaryController(summaryService, logger)
+	queueController := controllers.NewQueueController(queueService, logger)
+	immunizationController := controllers.NewImmunizationController(immunizationService, logger)
+	referralController := controllers.NewReferralController(referralService, logger)
+	fhirController := controllers.NewFHIRController(fhirPatientService, bulkExportService, logger)
+	hl7Controller := controllers.NewHL7Controller(adtService, logger)
+	importController := controllers.NewPatientImportController(importService, logger)
+	webhookController := controllers.NewWebhookController(webhookService, logger)
+
+	// Send queued webhook deliveries, including events raised by the MLLP
+	// listener and other commands
+	webhookService.Start(context.Background())
+
+	// Patient records are read by staff who look after or bill patients
+	patientReaders := middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist, auth.RoleBilling)
+
+	// Patient documents are handled by clinical and front desk staff
+	documentStaff := middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleLabTechnician, auth.RoleReceptionist)
 
 	// Auth routes
 	r.POST("/api/login", authController.Login)
 	r.POST("/api/register", authController.Register)
 
+	// FHIR R4 routes; the CapabilityStatement is public
+	fhirR4 := r.Group("/fhir/r4")
+	{
+		fhirR4.GET("/metadata", fhirController.Metadata)
+
+		// Patients are read by the same roles as through the portal's own
+		// patient routes
+		fhirPatients := fhirR4.Group("/Patient")
+		fhirPatients.Use(middlewares.AuthMiddleware(logger))
+		{
+			fhirPatients.GET("", patientReaders, fhirController.SearchPatients)
+			fhirPatients.POST("/_search", patientReaders, fhirController.SearchPatients)
+			fhirPatients.GET("/$export", middlewares.RoleMiddleware(auth.RoleDoctor), fhirController.ExportKickoff)
+			fhirPatients.GET("/:id", patientReaders, fhirController.ReadPatient)
+			fhirPatients.POST("", middlewares.RoleMiddleware(auth.RoleReceptionist), fhirController.CreatePatient)
+			fhirPatients.PUT("/:id", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist), fhirController.UpdatePatient)
+		}
+
+		// Bulk data export; the files are only served to the doctor who
+		// started the export
+		fhirBulk := fhirR4.Group("")
+		fhirBulk.Use(middlewares.AuthMiddleware(logger), middlewares.RoleMiddleware(auth.RoleDoctor))
+		{
+			fhirBulk.GET("/$export", fhirController.ExportKickoff)
+			fhirBulk.GET("/$export-status/:id", fhirController.ExportStatus)
+			fhirBulk.DELETE("/$export-status/:id", fhirController.CancelExport)
+			fhirBulk.GET("/$export-file/:id/:fileId", fhirController.DownloadExportFile)
+		}
+	}
+
 	// API v1 routes
 	v1 := r.Group("/api/v1")
 	{
@@ -43,8 +180,9 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, logger *zap.Logger) {
 		// Patient routes
 		patients := v1.Group("/patients")
 		{
-			// Routes available to both doctors and receptionists
-			patients.GET("", patientController.GetAllPatients)
+			// Routes available to both doctors and receptionists; the list
+			// is limited like its exports
+			patients.GET("", patientReaders, patientController.GetAllPatients)
 			patients.GET("/:id", patientController.GetPatientByID)
 			patients.GET("/users/{name}", patientController.GetPatientByName)
 
@@ -56,8 +194,332 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, logger *zap.Logger) {
 			receptionistGroup.Use(middlewares.RoleMiddleware(auth.RoleReceptionist))
 			{
 				receptionistGroup.POST("", patientController.CreatePatient)
+				receptionistGroup.POST("/import", importController.StartImport)
+				receptionistGroup.GET("/import", importController.GetImports)
+				receptionistGroup.GET("/import/:id", importController.GetImport)
+				receptionistGroup.GET("/contact-review", patientController.GetContactReviewQueue)
+				receptionistGroup.PUT("/:id/contact-details", patientController.UpdateContactDetails)
 				receptionistGroup.DELETE("/:id", patientController.DeletePatient)
 			}
+
+			// Allergy routes, only available to doctors
+			allergies := patients.Group("/:id/allergies")
+			allergies.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+			{
+				allergies.GET("", allergyController.GetAllergies)
+				allergies.POST("", allergyController.CreateAllergy)
+				allergies.POST("/nkda", allergyController.RecordNKDA)
+				allergies.PUT("/:allergyId", allergyController.UpdateAllergy)
+				allergies.DELETE("/:allergyId", allergyController.DeleteAllergy)
+			}
+
+			// Prescription routes, only available to doctors
+			patientPrescriptions := patients.Group("/:id/prescriptions")
+			patientPrescriptions.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+			{
+				patientPrescriptions.GET("", prescriptionController.GetPatientPrescriptions)
+				patientPrescriptions.POST("", prescriptionController.CreatePrescription)
+				patientPrescriptions.POST("/check", prescriptionController.CheckInteractions)
+			}
+
+			// Encounter routes, only available to doctors
+			patientEncounters := patients.Group("/:id/encounters")
+			patientEncounters.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+			{
+				patientEncounters.GET("", encounterController.GetPatientEncounters)
+				patientEncounters.POST("", encounterController.StartEncounter)
+			}
+
+			// Vital signs routes, available to doctors and nurses
+			vitals := patients.Group("/:id/vitals")
+			vitals.Use(middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse))
+			{
+				vitals.GET("", vitalsController.GetVitalsSeries)
+				vitals.POST("", vitalsController.RecordVitals)
+			}
+
+			// Care team routes, only available to doctors
+			careTeam := patients.Group("/:id/care-team")
+			careTeam.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+			{
+				careTeam.GET("", careTeamController.GetCareTeam)
+				careTeam.POST("", careTeamController.AddMember)
+				careTeam.DELETE("/:userId", careTeamController.RemoveMember)
+			}
+
+			// Problem list routes, only available to doctors
+			problems := patients.Group("/:id/problems")
+			problems.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+			{
+				problems.GET("", problemController.GetProblemList)
+				problems.POST("", problemController.AddProblem)
+				problems.PUT("/:problemId", problemController.UpdateProblem)
+				problems.DELETE("/:problemId", problemController.DeleteProblem)
+			}
+
+			// Lab order routes
+			patientLabOrders := patients.Group("/:id/lab-orders")
+			{
+				patientLabOrders.GET("", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleLabTechnician), labController.GetPatientOrders)
+				patientLabOrders.POST("", middlewares.RoleMiddleware(auth.RoleDoctor), labController.PlaceOrder)
+			}
+
+			// Guardian, next of kin and emergency contact routes
+			contacts := patients.Group("/:id/contacts")
+			{
+				// Routes available to both doctors and receptionists
+				contacts.GET("", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist), contactController.GetContacts)
+
+				// Routes only available to receptionists
+				contacts.POST("", middlewares.RoleMiddleware(auth.RoleReceptionist), contactController.CreateContact)
+				contacts.PUT("/:contactId", middlewares.RoleMiddleware(auth.RoleReceptionist), contactController.UpdateContact)
+				contacts.DELETE("/:contactId", middlewares.RoleMiddleware(auth.RoleReceptionist), contactController.DeleteContact)
+			}
+
+			// Insurance coverage routes
+			insurance := patients.Group("/:id/insurance")
+			{
+				// Routes available to both doctors and receptionists
+				insurance.GET("", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist), insuranceController.GetCoverages)
+
+				// Routes only available to receptionists
+				insurance.POST("", middlewares.RoleMiddleware(auth.RoleReceptionist), insuranceController.CreateCoverage)
+				insurance.PUT("/:coverageId", middlewares.RoleMiddleware(auth.RoleReceptionist), insuranceController.UpdateCoverage)
+				insurance.DELETE("/:coverageId", middlewares.RoleMiddleware(auth.RoleReceptionist), insuranceController.DeleteCoverage)
+				insurance.POST("/:coverageId/eligibility", middlewares.RoleMiddleware(auth.RoleReceptionist), insuranceController.CheckEligibility)
+			}
+
+			// Billing routes
+			patients.GET("/:id/invoices", middlewares.RoleMiddleware(auth.RoleBilling), billingController.GetPatientInvoices)
+			patients.POST("/:id/invoices", middlewares.RoleMiddleware(auth.RoleBilling), billingController.GenerateInvoice)
+			patients.GET("/:id/balance", middlewares.RoleMiddleware(auth.RoleBilling, auth.RoleReceptionist), billingController.GetPatientBalance)
+
+			// Consent routes, available to both doctors and receptionists
+			consents := patients.Group("/:id/consents")
+			consents.Use(middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist))
+			{
+				consents.GET("", consentController.GetConsents)
+				consents.POST("", consentController.GrantConsent)
+				consents.POST("/withdraw", consentController.WithdrawConsent)
+			}
+
+			// Admission routes, available to both doctors and receptionists
+			admissions := patients.Group("/:id/admissions")
+			admissions.Use(middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist))
+			{
+				admissions.GET("", admissionController.GetPatientAdmissions)
+				admissions.POST("", admissionController.Admit)
+			}
+
+			// Immunization routes; the forecast is also open to receptionists
+			immunizations := patients.Group("/:id/immunizations")
+			{
+				immunizations.GET("", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse), immunizationController.GetImmunizations)
+				immunizations.POST("", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse), immunizationController.RecordImmunization)
+				immunizations.POST("/:immunizationId/entered-in-error", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse), immunizationController.MarkEnteredInError)
+				immunizations.GET("/forecast", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist), immunizationController.GetForecast)
+			}
+
+			// Referral routes, only available to doctors
+			patientReferrals := patients.Group("/:id/referrals")
+			patientReferrals.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+			{
+				patientReferrals.GET("", referralController.GetPatientReferrals)
+				patientReferrals.POST("", referralController.CreateReferral)
+			}
+
+			// Patient SMS messages, sent only with SMS contact consent
+			patients.POST("/:id/messages", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist), consentController.SendMessage)
+
+			// Printable summary and wristband/label
+			patients.GET("/:id/summary.pdf", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse), printController.DownloadSummary)
+			patients.GET("/:id/label.pdf", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist), printController.DownloadLabel)
+
+			// Document routes, visibility is enforced per document
+			patients.GET("/:id/documents", documentStaff, documentController.GetPatientDocuments)
+			patients.POST("/:id/documents", documentStaff, documentController.UploadDocument)
+		}
+
+		// Document routes, visibility is enforced per document
+		documents := v1.Group("/documents")
+		documents.Use(documentStaff)
+		{
+			documents.GET("/:id", documentController.GetDocument)
+			documents.GET("/:id/download", documentController.DownloadDocument)
+			documents.DELETE("/:id", documentController.DeleteDocument)
+		}
+
+		// Immunization schedule and the overdue call list
+		v1.GET("/immunizations/schedule", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist), immunizationController.GetSchedule)
+		v1.GET("/immunizations/overdue", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist), immunizationController.GetOverduePatients)
+
+		// Referral inbox and responses, only available to doctors
+		referrals := v1.Group("/referrals")
+		referrals.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+		{
+			referrals.GET("/inbox", referralController.GetInbox)
+			referrals.GET("/sent", referralController.GetSent)
+			referrals.GET("/:id", referralController.GetReferral)
+			referrals.POST("/:id/accept", referralController.AcceptReferral)
+			referrals.POST("/:id/decline", referralController.DeclineReferral)
+			referrals.POST("/:id/complete", referralController.CompleteReferral)
+		}
+
+		// Rejected HL7 ADT messages, worked by receptionists
+		hl7DeadLetters := v1.Group("/hl7/dead-letters")
+		hl7DeadLetters.Use(middlewares.RoleMiddleware(auth.RoleReceptionist))
+		{
+			hl7DeadLetters.GET("", hl7Controller.GetDeadLetters)
+			hl7DeadLetters.GET("/:id", hl7Controller.GetDeadLetter)
+			hl7DeadLetters.DELETE("/:id", hl7Controller.DismissDeadLetter)
+		}
+
+		// Outbound webhook subscriptions and their delivery logs, for admins
+		webhooks := v1.Group("/webhooks")
+		webhooks.Use(middlewares.RoleMiddleware(auth.RoleAdmin))
+		{
+			webhooks.GET("", webhookController.GetSubscriptions)
+			webhooks.POST("", webhookController.CreateSubscription)
+			webhooks.GET("/:id", webhookController.GetSubscription)
+			webhooks.PUT("/:id", webhookController.UpdateSubscription)
+			webhooks.DELETE("/:id", webhookController.DeleteSubscription)
+			webhooks.POST("/:id/rotate-secret", webhookController.RotateSecret)
+			webhooks.GET("/:id/deliveries", webhookController.GetDeliveries)
+			webhooks.GET("/:id/deliveries/:deliveryId", webhookController.GetDelivery)
+			webhooks.POST("/:id/deliveries/:deliveryId/redeliver", webhookController.Redeliver)
+		}
+
+		// Waiting-room queue routes
+		queue := v1.Group("/queue")
+		{
+			// Routes available to doctors, nurses and receptionists
+			queue.GET("", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist), queueController.GetQueue)
+			queue.GET("/metrics", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist), queueController.GetMetrics)
+			queue.GET("/:id", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist), queueController.GetEntry)
+
+			// Receptionists check patients in; nurses triage them
+			queue.POST("", middlewares.RoleMiddleware(auth.RoleReceptionist), queueController.CheckIn)
+			queue.POST("/:id/triage", middlewares.RoleMiddleware(auth.RoleNurse), queueController.Triage)
+			queue.POST("/:id/remove", middlewares.RoleMiddleware(auth.RoleReceptionist, auth.RoleNurse), queueController.Remove)
+
+			// Doctors call patients in and close them once seen
+			queue.POST("/next", middlewares.RoleMiddleware(auth.RoleDoctor), queueController.CallNext)
+			queue.POST("/:id/complete", middlewares.RoleMiddleware(auth.RoleDoctor), queueController.Complete)
+		}
+
+		// Admission, transfer and discharge routes, available to both
+		// doctors and receptionists
+		admissions := v1.Group("/admissions")
+		admissions.Use(middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist))
+		{
+			admissions.GET("/:id", admissionController.GetAdmission)
+			admissions.POST("/:id/transfer", admissionController.Transfer)
+			admissions.POST("/:id/discharge", admissionController.Discharge)
+		}
+
+		// Discharge summary routes, only available to doctors
+		summaries := v1.Group("/admissions/:id/discharge-summary")
+		summaries.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+		{
+			summaries.GET("", summaryController.GetSummary)
+			summaries.POST("", summaryController.DraftSummary)
+			summaries.PUT("", summaryController.UpdateSummary)
+			summaries.POST("/sign", summaryController.SignSummary)
+			summaries.GET("/pdf", summaryController.DownloadSummaryPDF)
+		}
+
+		// Ward, room and bed routes
+		v1.GET("/bed-board", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist), admissionController.GetBedBoard)
+		v1.PUT("/beds/:id/status", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleReceptionist), admissionController.SetBedStatus)
+		wards := v1.Group("")
+		wards.Use(middlewares.RoleMiddleware(auth.RoleReceptionist))
+		{
+			wards.POST("/wards", admissionController.CreateWard)
+			wards.POST("/wards/:id/rooms", admissionController.CreateRoom)
+			wards.POST("/rooms/:id/beds", admissionController.CreateBed)
+		}
+
+		// Lab order routes
+		labOrders := v1.Group("/lab-orders")
+		{
+			// Routes available to both doctors and lab technicians
+			labOrders.GET("", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleLabTechnician), labController.GetWorklist)
+			labOrders.GET("/:id", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleLabTechnician), labController.GetOrderByID)
+
+			// Routes only available to doctors
+			labOrders.POST("/:id/cancel", middlewares.RoleMiddleware(auth.RoleDoctor), labController.CancelOrder)
+
+			// Routes only available to lab technicians
+			labGroup := labOrders.Group("")
+			labGroup.Use(middlewares.RoleMiddleware(auth.RoleLabTechnician))
+			{
+				labGroup.POST("/:id/collect", labController.CollectSpecimen)
+				labGroup.POST("/:id/results", labController.EnterResults)
+				labGroup.POST("/:id/verify", labController.VerifyResults)
+			}
+		}
+
+		// De-identified research extract, only available to doctors
+		v1.GET("/research/extract", middlewares.RoleMiddleware(auth.RoleDoctor), consentController.GetResearchExtract)
+
+		// Payer catalogue lookup
+		v1.GET("/payers", insuranceController.SearchPayers)
+
+		// ICD-10 code typeahead
+		v1.GET("/icd10", problemController.SearchICD10)
+
+		// Encounter routes, only available to doctors
+		encounters := v1.Group("/encounters")
+		encounters.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+		{
+			encounters.GET("/:id", encounterController.GetEncounterByID)
+			encounters.POST("/:id/finish", encounterController.FinishEncounter)
+		}
+
+		// Encounter charges, posted by doctors or billing staff
+		v1.GET("/encounters/:id/charges", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleBilling), billingController.GetEncounterCharges)
+		v1.POST("/encounters/:id/charges", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleBilling), billingController.PostCharge)
+
+		// Charge master routes
+		v1.GET("/charge-master", middlewares.RoleMiddleware(auth.RoleDoctor, auth.RoleBilling), billingController.GetChargeMaster)
+		v1.PUT("/charge-master/:code", middlewares.RoleMiddleware(auth.RoleBilling), billingController.SaveChargeItem)
+
+		// Billing routes, only available to billing staff
+		billing := v1.Group("")
+		billing.Use(middlewares.RoleMiddleware(auth.RoleBilling))
+		{
+			billing.POST("/charges/:id/void", billingController.VoidCharge)
+			billing.GET("/invoices/:id", billingController.GetInvoice)
+			billing.POST("/invoices/:id/void", billingController.VoidInvoice)
+			billing.POST("/invoices/:id/payments", billingController.RecordPayment)
+			billing.POST("/invoices/:id/refunds", billingController.RecordRefund)
+			billing.POST("/invoices/:id/adjustments", billingController.RecordAdjustment)
+			billing.GET("/billing/reports/collections", billingController.GetCollectionsReport)
+
+			// Insurance claims (837P) and remittances (835)
+			billing.POST("/claims/batches", claimController.CreateClaimBatch)
+			billing.GET("/claims/batches/:id", claimController.GetClaimBatch)
+			billing.GET("/claims/batches/:id/file", claimController.DownloadClaimBatch)
+			billing.GET("/claims", claimController.GetClaims)
+			billing.POST("/remittances", claimController.ImportRemittance)
+			billing.GET("/remittances/:id", claimController.GetRemittance)
+		}
+
+		// Notification routes for the current user
+		notifications := v1.Group("/notifications")
+		{
+			notifications.GET("", notificationController.GetMyNotifications)
+			notifications.POST("/:id/read", notificationController.MarkRead)
+		}
+
+		// Prescription routes, only available to doctors
+		prescriptions := v1.Group("/prescriptions")
+		prescriptions.Use(middlewares.RoleMiddleware(auth.RoleDoctor))
+		{
+			prescriptions.GET("/:id", prescriptionController.GetPrescriptionByID)
+			prescriptions.GET("/:id/print", prescriptionController.PrintPrescription)
+			prescriptions.POST("/:id/discontinue", prescriptionController.DiscontinuePrescription)
+			prescriptions.POST("/:id/renew", prescriptionController.RenewPrescription)
 		}
 	}
 
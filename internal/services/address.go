@@ -0,0 +1,120 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/viper"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/phone"
+)
+
+// defaultCountry is the country assumed for national phone numbers and
+// addresses without a country
+func defaultCountry() string {
+	if country := viper.GetString("contacts.default_country"); country != "" {
+		return strings.ToUpper(country)
+	}
+	return "US"
+}
+
+// normalizeAddress trims an address and checks its required components
+func normalizeAddress(address *models.Address) error {
+	address.Line1 = strings.TrimSpace(address.Line1)
+	address.Line2 = strings.TrimSpace(address.Line2)
+	address.City = strings.TrimSpace(address.City)
+	address.Region = strings.TrimSpace(address.Region)
+	address.PostalCode = strings.ToUpper(strings.TrimSpace(address.PostalCode))
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	if address.Country == "" {
+		address.Country = defaultCountry()
+	}
+
+	if address.Line1 == "" || address.City == "" {
+		return fmt.Errorf("%w: address line1 and city are required", ErrInvalidInput)
+	}
+	if len(address.Country) != 2 || !isLetters(address.Country) {
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidInput)
+	}
+	return nil
+}
+
+// normalizePhone converts a phone number to E.164, reading national
+// numbers as numbers of the given country
+func normalizePhone(number, country string) (string, error) {
+	if country == "" {
+		country = defaultCountry()
+	}
+	normalized, err := phone.Normalize(number, country)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return normalized, nil
+}
+
+// ParseLegacyAddress splits a free-text address of the form
+// "line1[, line2], city, [region] [postal code][, country]" into its
+// components. It errors when the text does not have at least a street
+// line and a city.
+func ParseLegacyAddress(text string) (models.Address, error) {
+	var parts []string
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\n' || r == ';' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	var address models.Address
+	// A trailing country may be a name, or an ISO code following a postal
+	// code; a bare two-letter code is more likely a US state
+	if n := len(parts); n > 0 {
+		if region, ok := phone.LookupRegion(parts[n-1]); ok && (len(parts[n-1]) > 2 || n > 1 && hasDigit(parts[n-2])) {
+			address.Country = region.Code
+			parts = parts[:n-1]
+		}
+	}
+	if len(parts) < 2 {
+		return address, errors.New("address needs at least a street and a city")
+	}
+
+	// The last part is "region postal" ("CA 94105"), a bare postal code, or
+	// the city itself when there is neither
+	last := strings.Fields(parts[len(parts)-1])
+	postalStart := len(last)
+	for postalStart > 0 && hasDigit(last[postalStart-1]) {
+		postalStart--
+	}
+	regionOnly := len(parts) > 2 && len(last) == 1 && len(last[0]) <= 3 && strings.ToUpper(last[0]) == last[0]
+	if postalStart < len(last) || regionOnly {
+		address.Region = strings.Join(last[:postalStart], " ")
+		address.PostalCode = strings.Join(last[postalStart:], " ")
+		parts = parts[:len(parts)-1]
+		// "London SW1A 2AA": a town rather than a region before the postcode
+		if len(parts) == 1 && address.Region != "" {
+			parts = append(parts, address.Region)
+			address.Region = ""
+		}
+	}
+	if len(parts) < 2 {
+		return address, errors.New("address needs at least a street and a city")
+	}
+
+	address.City = parts[len(parts)-1]
+	address.Line1 = parts[0]
+	address.Line2 = strings.Join(parts[1:len(parts)-1], ", ")
+	if address.Country == "" {
+		address.Country = defaultCountry()
+	}
+	return address, nil
+}
+
+func hasDigit(value string) bool {
+	return strings.IndexFunc(value, unicode.IsDigit) >= 0
+}
+
+func isLetters(value string) bool {
+	return strings.IndexFunc(value, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+}
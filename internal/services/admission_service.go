@@ -0,0 +1,344 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/auth"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// Statuses a bed can be set to by hand; occupied is only set by admissions
+var manualBedStatuses = []string{models.BedStatusAvailable, models.BedStatusCleaning, models.BedStatusBlocked}
+
+// AdmissionInput is the data needed to admit a patient
+type AdmissionInput struct {
+	BedID             uint
+	AttendingDoctorID uint
+	Reason            string
+	AdmittedAt        *time.Time // defaults to now
+}
+
+// TransferInput is the data needed to move an admitted patient
+type TransferInput struct {
+	BedID  uint
+	Reason string
+}
+
+// DischargeInput is the data needed to discharge a patient
+type DischargeInput struct {
+	Disposition  string
+	DischargedAt *time.Time // defaults to now
+}
+
+// BoardBed is a bed on the bed board with its current occupant
+type BoardBed struct {
+	models.Bed
+	Room              string     `json:"room"`
+	AdmissionID       uint       `json:"admission_id,omitempty"`
+	PatientName       string     `json:"patient_name,omitempty"`
+	AttendingDoctorID uint       `json:"attending_doctor_id,omitempty"`
+	AdmittedAt        *time.Time `json:"admitted_at,omitempty"`
+}
+
+// WardBoard is the occupancy of one ward
+type WardBoard struct {
+	WardID           uint           `json:"ward_id"`
+	Code             string         `json:"code"`
+	Name             string         `json:"name"`
+	TotalBeds        int            `json:"total_beds"`
+	StatusCounts     map[string]int `json:"status_counts"`
+	OccupancyPercent float64        `json:"occupancy_percent"`
+	Beds             []BoardBed     `json:"beds"`
+}
+
+// AdmissionService handles ward, room and bed configuration and patient
+// admissions, transfers and discharges
+type AdmissionService struct {
+	bedRepo       *repositories.BedRepository
+	admissionRepo *repositories.AdmissionRepository
+	patientRepo   *repositories.PatientRepository
+	userRepo      *repositories.UserRepository
+	summaries     *DischargeSummaryService
+	logger        *zap.Logger
+}
+
+// NewAdmissionService creates a new admission service instance
+func NewAdmissionService(bedRepo *repositories.BedRepository, admissionRepo *repositories.AdmissionRepository, patientRepo *repositories.PatientRepository, userRepo *repositories.UserRepository, summaries *DischargeSummaryService, logger *zap.Logger) *AdmissionService {
+	return &AdmissionService{
+		bedRepo:       bedRepo,
+		admissionRepo: admissionRepo,
+		patientRepo:   patientRepo,
+		userRepo:      userRepo,
+		summaries:     summaries,
+		logger:        logger,
+	}
+}
+
+// CreateWard creates a new ward
+func (s *AdmissionService) CreateWard(ward *models.Ward) (*models.Ward, error) {
+	ward.Code = strings.ToUpper(strings.TrimSpace(ward.Code))
+	if ward.Code == "" {
+		return nil, fmt.Errorf("%w: ward code is required", ErrInvalidInput)
+	}
+	return s.bedRepo.CreateWard(ward)
+}
+
+// CreateRoom adds a room to a ward
+func (s *AdmissionService) CreateRoom(wardID uint, room *models.Room) (*models.Room, error) {
+	if _, err := s.bedRepo.FindWardByID(wardID); err != nil {
+		return nil, err
+	}
+	room.WardID = wardID
+	return s.bedRepo.CreateRoom(room)
+}
+
+// CreateBed adds a bed to a room. New beds are available unless blocked.
+func (s *AdmissionService) CreateBed(roomID uint, bed *models.Bed) (*models.Bed, error) {
+	room, err := s.bedRepo.FindRoomByID(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if bed.Status == "" {
+		bed.Status = models.BedStatusAvailable
+	}
+	if !contains(manualBedStatuses, bed.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %v", ErrInvalidInput, manualBedStatuses)
+	}
+	bed.RoomID = room.ID
+	bed.WardID = room.WardID
+	bed.PatientID = nil
+	return s.bedRepo.CreateBed(bed)
+}
+
+// SetBedStatus changes the housekeeping status of an unoccupied bed, e.g.
+// from cleaning back to available
+func (s *AdmissionService) SetBedStatus(bedID uint, status, note string) (*models.Bed, error) {
+	if !contains(manualBedStatuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of %v", ErrInvalidInput, manualBedStatuses)
+	}
+	bed, err := s.bedRepo.UpdateBedStatus(bedID, status, note, func(bed *models.Bed) error {
+		if bed.Status == models.BedStatusOccupied {
+			return fmt.Errorf("%w: bed is occupied; transfer or discharge the patient first", ErrConflict)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Bed status changed", zap.Uint("bed_id", bedID), zap.String("status", status))
+	return bed, nil
+}
+
+// GetBedBoard reports bed occupancy per ward. A zero wardID covers every ward.
+func (s *AdmissionService) GetBedBoard(wardID uint) ([]WardBoard, error) {
+	wards, err := s.bedRepo.FindWardsWithBeds(wardID)
+	if err != nil {
+		return nil, err
+	}
+	if wardID != 0 && len(wards) == 0 {
+		return nil, errors.New("ward not found")
+	}
+	admissions, err := s.admissionRepo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+	byBed := make(map[uint]models.Admission, len(admissions))
+	for _, admission := range admissions {
+		if admission.BedID != nil {
+			byBed[*admission.BedID] = admission
+		}
+	}
+
+	boards := make([]WardBoard, 0, len(wards))
+	for _, ward := range wards {
+		board := WardBoard{
+			WardID:       ward.ID,
+			Code:         ward.Code,
+			Name:         ward.Name,
+			StatusCounts: map[string]int{},
+			Beds:         []BoardBed{},
+		}
+		for _, status := range []string{models.BedStatusAvailable, models.BedStatusOccupied, models.BedStatusCleaning, models.BedStatusBlocked} {
+			board.StatusCounts[status] = 0
+		}
+		for _, room := range ward.Rooms {
+			for _, bed := range room.Beds {
+				entry := BoardBed{Bed: bed, Room: room.Number}
+				if admission, ok := byBed[bed.ID]; ok {
+					admittedAt := admission.AdmittedAt
+					entry.AdmissionID = admission.ID
+					entry.AttendingDoctorID = admission.AttendingDoctorID
+					entry.AdmittedAt = &admittedAt
+					if admission.Patient != nil {
+						entry.PatientName = admission.Patient.Name
+					}
+				}
+				board.Beds = append(board.Beds, entry)
+				board.StatusCounts[bed.Status]++
+				board.TotalBeds++
+			}
+		}
+		// Blocked beds cannot take patients, so they do not count as capacity
+		if capacity := board.TotalBeds - board.StatusCounts[models.BedStatusBlocked]; capacity > 0 {
+			board.OccupancyPercent = float64(board.StatusCounts[models.BedStatusOccupied]*10000/capacity) / 100
+		}
+		boards = append(boards, board)
+	}
+	return boards, nil
+}
+
+// Admit admits a patient to an available bed under an attending doctor.
+// The stay is recorded on a new inpatient encounter.
+func (s *AdmissionService) Admit(patientID uint, input AdmissionInput, userID uint) (*models.Admission, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	if err := s.requireDoctor(input.AttendingDoctorID); err != nil {
+		return nil, err
+	}
+	if active, err := s.admissionRepo.FindActiveByPatient(patientID); err != nil {
+		return nil, err
+	} else if active != nil {
+		return nil, fmt.Errorf("%w: patient is already admitted (admission %d)", ErrConflict, active.ID)
+	}
+
+	admittedAt := time.Now()
+	if input.AdmittedAt != nil {
+		if input.AdmittedAt.After(admittedAt) {
+			return nil, fmt.Errorf("%w: admitted_at cannot be in the future", ErrInvalidInput)
+		}
+		admittedAt = *input.AdmittedAt
+	}
+
+	bedID := input.BedID
+	admission := &models.Admission{
+		PatientID:         patientID,
+		AttendingDoctorID: input.AttendingDoctorID,
+		BedID:             &bedID,
+		Status:            models.AdmissionStatusAdmitted,
+		Reason:            input.Reason,
+		AdmittedAt:        admittedAt,
+		AdmittedByID:      userID,
+	}
+	encounter := &models.Encounter{
+		PatientID: patientID,
+		DoctorID:  input.AttendingDoctorID,
+		Type:      "inpatient",
+		Status:    models.EncounterStatusInProgress,
+		Reason:    input.Reason,
+		StartedAt: admittedAt,
+	}
+
+	admission, err := s.admissionRepo.Admit(admission, encounter, func(bed *models.Bed) error {
+		if bed.Status != models.BedStatusAvailable {
+			return fmt.Errorf("%w: bed is %s", ErrConflict, bed.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Patient admitted",
+		zap.Uint("admission_id", admission.ID),
+		zap.Uint("patient_id", patientID),
+		zap.Uint("bed_id", bedID),
+	)
+	return admission, nil
+}
+
+// Transfer moves an admitted patient to another available bed
+func (s *AdmissionService) Transfer(admissionID uint, input TransferInput, userID uint) (*models.Admission, error) {
+	movement := &models.AdmissionBedMovement{
+		BedID:     input.BedID,
+		FromTime:  time.Now(),
+		Reason:    input.Reason,
+		MovedByID: userID,
+	}
+	admission, err := s.admissionRepo.Transfer(admissionID, movement, func(admission *models.Admission, target *models.Bed) error {
+		if admission.Status != models.AdmissionStatusAdmitted || admission.BedID == nil {
+			return fmt.Errorf("%w: admission is %s", ErrConflict, admission.Status)
+		}
+		if target.ID == *admission.BedID {
+			return fmt.Errorf("%w: patient is already in this bed", ErrConflict)
+		}
+		if target.Status != models.BedStatusAvailable {
+			return fmt.Errorf("%w: bed is %s", ErrConflict, target.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Patient transferred", zap.Uint("admission_id", admissionID), zap.Uint("bed_id", input.BedID))
+	return admission, nil
+}
+
+// Discharge ends an admission; the bed goes to cleaning and a discharge
+// summary is drafted for the attending doctor
+func (s *AdmissionService) Discharge(admissionID uint, input DischargeInput, userID uint) (*models.Admission, error) {
+	dischargedAt := time.Now()
+	if input.DischargedAt != nil {
+		if input.DischargedAt.After(dischargedAt) {
+			return nil, fmt.Errorf("%w: discharged_at cannot be in the future", ErrInvalidInput)
+		}
+		dischargedAt = *input.DischargedAt
+	}
+
+	admission, err := s.admissionRepo.Discharge(admissionID, dischargedAt, userID, input.Disposition, func(admission *models.Admission) error {
+		if admission.Status != models.AdmissionStatusAdmitted {
+			return fmt.Errorf("%w: admission is %s", ErrConflict, admission.Status)
+		}
+		if dischargedAt.Before(admission.AdmittedAt) {
+			return fmt.Errorf("%w: discharged_at is before the admission", ErrInvalidInput)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Patient discharged", zap.Uint("admission_id", admissionID), zap.String("disposition", input.Disposition))
+
+	// The discharge stands even if drafting fails; the doctor can draft
+	// the summary again from the admission
+	if _, err := s.summaries.Draft(admissionID, userID); err != nil {
+		s.logger.Warn("Failed to draft discharge summary", zap.Error(err), zap.Uint("admission_id", admissionID))
+	}
+	return admission, nil
+}
+
+// GetAdmission retrieves an admission with its bed movements
+func (s *AdmissionService) GetAdmission(id uint) (*models.Admission, error) {
+	return s.admissionRepo.FindByID(id)
+}
+
+// GetPatientAdmissions retrieves a patient's admissions
+func (s *AdmissionService) GetPatientAdmissions(patientID uint) ([]models.Admission, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.admissionRepo.FindByPatient(patientID)
+}
+
+// requireDoctor checks that a user exists and is a doctor
+func (s *AdmissionService) requireDoctor(userID uint) error {
+	if userID == 0 {
+		return fmt.Errorf("%w: an attending doctor is required", ErrInvalidInput)
+	}
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("%w: attending doctor: %v", ErrInvalidInput, err)
+	}
+	if user.Role != string(auth.RoleDoctor) {
+		return fmt.Errorf("%w: attending user %d is not a doctor", ErrInvalidInput, userID)
+	}
+	return nil
+}
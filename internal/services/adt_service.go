@@ -0,0 +1,497 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/hl7"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// maxDeadLetters bounds a dead letter listing
+const maxDeadLetters = 200
+
+// hl7Sexes maps PID-8 administrative sex codes to patient genders
+var hl7Sexes = map[string]string{
+	"M": "male",
+	"F": "female",
+	"O": "other",
+	"A": "other",
+	"N": "other",
+}
+
+// hl7Relationships maps HL7 table 0063 relationship codes to the
+// relationship text of patient contacts
+var hl7Relationships = map[string]string{
+	"MTH": "mother",
+	"FTH": "father",
+	"PAR": "parent",
+	"GRD": "guardian",
+	"SPO": "spouse",
+	"DOM": "domestic partner",
+	"CHD": "child",
+	"SIB": "sibling",
+	"BRO": "brother",
+	"SIS": "sister",
+	"GRP": "grandparent",
+	"FND": "friend",
+	"EMC": "emergency contact",
+	"OTH": "other",
+}
+
+// hl7GuardianRelationships are the relationship codes that make a contact
+// the patient's guardian
+var hl7GuardianRelationships = []string{"MTH", "FTH", "PAR", "GRD"}
+
+// hl7Countries maps the ISO 3166 alpha-3 codes HL7 addresses usually carry
+// to the alpha-2 codes patient addresses use
+var hl7Countries = map[string]string{
+	"USA": "US",
+	"CAN": "CA",
+	"MEX": "MX",
+	"GBR": "GB",
+	"IRL": "IE",
+	"AUS": "AU",
+	"NZL": "NZ",
+	"IND": "IN",
+	"DEU": "DE",
+	"FRA": "FR",
+	"ESP": "ES",
+	"ITA": "IT",
+	"NLD": "NL",
+}
+
+// adtError is a reason to refuse an ADT message, reported in the NAK
+type adtError struct {
+	ackCode   string
+	errorCode string
+	location  string
+	text      string
+}
+
+func (e *adtError) Error() string {
+	return e.text
+}
+
+func rejectMessage(errorCode, location, format string, args ...interface{}) error {
+	return &adtError{ackCode: hl7.AckReject, errorCode: errorCode, location: location, text: fmt.Sprintf(format, args...)}
+}
+
+func invalidMessage(errorCode, location, format string, args ...interface{}) error {
+	return &adtError{ackCode: hl7.AckError, errorCode: errorCode, location: location, text: fmt.Sprintf(format, args...)}
+}
+
+// ADTService applies HL7 v2 ADT messages from the registration system.
+// A01, A04 and A08 register or update the patient named in PID-3, through
+// PatientService so the usual validation applies; A40 merges the MRG-1
+// patient into the PID-3 one. Messages that are not applied are stored as
+// dead letters.
+type ADTService struct {
+	patientService *PatientService
+	patientRepo    *repositories.PatientRepository
+	hl7Repo        *repositories.HL7Repository
+	logger         *zap.Logger
+	ackSequence    uint64
+}
+
+// NewADTService creates a new ADT service instance
+func NewADTService(patientService *PatientService, patientRepo *repositories.PatientRepository, hl7Repo *repositories.HL7Repository, logger *zap.Logger) *ADTService {
+	return &ADTService{
+		patientService: patientService,
+		patientRepo:    patientRepo,
+		hl7Repo:        hl7Repo,
+		logger:         logger,
+	}
+}
+
+// HandleMessage applies a raw ADT message and returns its acknowledgement:
+// AA when applied, AE when it could not be applied and AR when it is not a
+// message this interface accepts
+func (s *ADTService) HandleMessage(raw []byte, remoteAddr string) []byte {
+	now := time.Now()
+	message, err := hl7.Parse(raw)
+	if err == nil {
+		err = s.apply(message)
+	} else {
+		err = rejectMessage(hl7.ErrSegmentSequence, "MSH", "%s", err.Error())
+	}
+
+	code := hl7.AckAccept
+	var detail *hl7.ErrorDetail
+	if err != nil {
+		var refusal *adtError
+		switch {
+		case errors.As(err, &refusal):
+			code, detail = refusal.ackCode, &hl7.ErrorDetail{Code: refusal.errorCode, Location: refusal.location, Text: refusal.text}
+		case errors.Is(err, ErrInvalidInput):
+			code, detail = hl7.AckError, &hl7.ErrorDetail{Code: hl7.ErrDataType, Location: "PID", Text: err.Error()}
+		default:
+			code, detail = hl7.AckError, &hl7.ErrorDetail{Code: hl7.ErrApplicationInternal, Text: err.Error()}
+		}
+		s.deadLetter(message, raw, remoteAddr, code, detail, now)
+	}
+
+	controlID := fmt.Sprintf("%s%04d", now.Format("20060102150405"), atomic.AddUint64(&s.ackSequence, 1)%10000)
+	return hl7.Ack(message, code, hl7Application(), hl7Facility(), controlID, detail, now)
+}
+
+// GetDeadLetters retrieves the newest messages that were not applied,
+// optionally only those with the given control ID
+func (s *ADTService) GetDeadLetters(controlID string) ([]models.HL7DeadLetter, error) {
+	return s.hl7Repo.FindDeadLetters(controlID, maxDeadLetters)
+}
+
+// GetDeadLetter retrieves a message that was not applied
+func (s *ADTService) GetDeadLetter(id uint) (*models.HL7DeadLetter, error) {
+	return s.hl7Repo.FindDeadLetterByID(id)
+}
+
+// DismissDeadLetter removes a dead letter once it has been dealt with
+func (s *ADTService) DismissDeadLetter(id, userID uint) error {
+	if err := s.hl7Repo.DeleteDeadLetter(id); err != nil {
+		return err
+	}
+	s.logger.Info("HL7 dead letter dismissed", zap.Uint("id", id), zap.Uint("user_id", userID))
+	return nil
+}
+
+func (s *ADTService) apply(message *hl7.Message) error {
+	messageCode, trigger := message.Type()
+	if messageCode != "ADT" {
+		return rejectMessage(hl7.ErrUnsupportedMessage, "MSH^1^9", "message type %s is not supported", messageCode)
+	}
+	switch trigger {
+	case "A01", "A04", "A08":
+		return s.register(message, trigger)
+	case "A40":
+		return s.merge(message)
+	default:
+		return rejectMessage(hl7.ErrUnsupportedEvent, "MSH^1^9", "event %s is not supported", trigger)
+	}
+}
+
+// register creates the PID patient, or updates them when already known
+func (s *ADTService) register(message *hl7.Message, trigger string) error {
+	if message.Segment("PID") == nil {
+		return invalidMessage(hl7.ErrSegmentSequence, "PID", "PID segment is required")
+	}
+	system, value, err := s.identifier(message, message.Segment("PID").Field(3), "PID^1^3")
+	if err != nil {
+		return err
+	}
+
+	patient, err := s.patientRepo.FindByIdentifier(system, value)
+	if err != nil {
+		return err
+	}
+	if patient != nil {
+		if err := applyPID(message, patient); err != nil {
+			return err
+		}
+		if _, err := s.patientService.UpdatePatient(patient); err != nil {
+			return err
+		}
+		s.logger.Info("Patient updated from ADT", zap.String("event", trigger), zap.Uint("patient_id", patient.ID), zap.String("control_id", message.ControlID()))
+		return nil
+	}
+
+	patient = &models.Patient{}
+	if err := applyPID(message, patient); err != nil {
+		return err
+	}
+	switch {
+	case patient.Name == "":
+		return invalidMessage(hl7.ErrRequiredFieldMissing, "PID^1^5", "PID-5 patient name is required")
+	case patient.DateOfBirth == nil:
+		return invalidMessage(hl7.ErrRequiredFieldMissing, "PID^1^7", "PID-7 date of birth is required")
+	case patient.Gender == "":
+		return invalidMessage(hl7.ErrRequiredFieldMissing, "PID^1^8", "PID-8 administrative sex must be M, F or O")
+	}
+	contacts, err := contactsFromNK1(message)
+	if err != nil {
+		return err
+	}
+	patient.Contacts = contacts
+	patient.Identifiers = []models.PatientIdentifier{{System: system, Value: value}}
+
+	created, err := s.patientService.CreatePatient(patient)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("Patient registered from ADT", zap.String("event", trigger), zap.Uint("patient_id", created.ID), zap.String("control_id", message.ControlID()))
+	return nil
+}
+
+// merge folds the MRG-1 patient into the PID-3 patient. When the PID-3
+// identifier is new, the MRG-1 patient simply takes it on. A resent A40
+// finds both identifiers on the same patient and changes nothing.
+func (s *ADTService) merge(message *hl7.Message) error {
+	if message.Segment("PID") == nil || message.Segment("MRG") == nil {
+		return invalidMessage(hl7.ErrSegmentSequence, "MRG", "PID and MRG segments are required")
+	}
+	targetSystem, targetValue, err := s.identifier(message, message.Segment("PID").Field(3), "PID^1^3")
+	if err != nil {
+		return err
+	}
+	sourceSystem, sourceValue, err := s.identifier(message, message.Segment("MRG").Field(1), "MRG^1^1")
+	if err != nil {
+		return err
+	}
+
+	source, err := s.patientRepo.FindByIdentifier(sourceSystem, sourceValue)
+	if err != nil {
+		return err
+	}
+	target, err := s.patientRepo.FindByIdentifier(targetSystem, targetValue)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case source == nil:
+		return invalidMessage(hl7.ErrUnknownKey, "MRG^1^1", "no patient has the prior identifier %s", sourceValue)
+	case target == nil:
+		if err := s.patientRepo.AddIdentifier(source.ID, targetSystem, targetValue); err != nil {
+			return err
+		}
+		target = source
+	case source.ID != target.ID:
+		merged, err := s.patientService.MergePatients(source.ID, target.ID)
+		if err != nil {
+			return err
+		}
+		target = merged
+	}
+
+	// The PID segment carries the surviving patient's demographics
+	if err := applyPID(message, target); err != nil {
+		return err
+	}
+	if _, err := s.patientService.UpdatePatient(target); err != nil {
+		return err
+	}
+	s.logger.Info("Patients merged from ADT", zap.Uint("source_id", source.ID), zap.Uint("target_id", target.ID), zap.String("control_id", message.ControlID()))
+	return nil
+}
+
+// identifier picks the patient identifier from a CX field: the one of the
+// configured assigning authority or, without one, the first MR identifier
+// and else the first. The assigning authority is the identifier's system,
+// defaulting to the sending application.
+func (s *ADTService) identifier(message *hl7.Message, field, location string) (string, string, error) {
+	authority := viper.GetString("hl7.assigning_authority")
+	var system, value string
+	for _, repetition := range message.Repetitions(field) {
+		id := message.Component(repetition, 1)
+		if id == "" {
+			continue
+		}
+		repetitionSystem := message.Component(repetition, 4)
+		if authority != "" {
+			if repetitionSystem == authority {
+				return repetitionSystem, id, nil
+			}
+			continue
+		}
+		if message.Component(repetition, 5) == "MR" {
+			system, value = repetitionSystem, id
+			break
+		}
+		if value == "" {
+			system, value = repetitionSystem, id
+		}
+	}
+	if authority != "" {
+		return "", "", invalidMessage(hl7.ErrRequiredFieldMissing, location, "no identifier assigned by %s", authority)
+	}
+	if value == "" {
+		return "", "", invalidMessage(hl7.ErrRequiredFieldMissing, location, "a patient identifier is required")
+	}
+	if system == "" {
+		system = message.Get("MSH", 3, 1)
+	}
+	return system, value, nil
+}
+
+func (s *ADTService) deadLetter(message *hl7.Message, raw []byte, remoteAddr, code string, detail *hl7.ErrorDetail, receivedAt time.Time) {
+	letter := &models.HL7DeadLetter{
+		RemoteAddr: remoteAddr,
+		AckCode:    code,
+		ErrorCode:  detail.Code,
+		Error:      detail.Text,
+		Message:    strings.ToValidUTF8(string(raw), "�"),
+		ReceivedAt: receivedAt,
+	}
+	if message != nil {
+		messageCode, trigger := message.Type()
+		letter.ControlID = message.ControlID()
+		letter.MessageType = strings.Trim(messageCode+"^"+trigger, "^")
+		letter.Sender = strings.Trim(message.Get("MSH", 3, 1)+"^"+message.Get("MSH", 4, 1), "^")
+	}
+	s.logger.Warn("ADT message not applied", zap.String("control_id", letter.ControlID), zap.String("type", letter.MessageType),
+		zap.String("ack", code), zap.String("error", detail.Text))
+	if err := s.hl7Repo.CreateDeadLetter(letter); err != nil {
+		s.logger.Error("Failed to store HL7 dead letter", zap.Error(err), zap.String("control_id", letter.ControlID))
+	}
+}
+
+// applyPID copies the demographics in the PID segment onto a patient.
+// Empty fields leave the patient's values as they are.
+func applyPID(message *hl7.Message, patient *models.Patient) error {
+	pid := message.Segment("PID")
+
+	if name := hl7Name(message, pid.Field(5)); name != "" {
+		patient.Name = name
+	}
+
+	if value := message.Get("PID", 7, 1); value != "" {
+		birth, err := hl7.ParseDate(value)
+		if err != nil {
+			return invalidMessage(hl7.ErrDataType, "PID^1^7", "PID-7 date of birth %q is not a valid date", value)
+		}
+		patient.DateOfBirth = &birth
+	}
+
+	if value := message.Get("PID", 8, 1); value != "" {
+		gender, ok := hl7Sexes[strings.ToUpper(value)]
+		if !ok {
+			return invalidMessage(hl7.ErrDataType, "PID^1^8", "PID-8 administrative sex must be M, F or O")
+		}
+		patient.Gender = gender
+	}
+
+	if repetitions := message.Repetitions(pid.Field(11)); len(repetitions) > 0 {
+		address := repetitions[0]
+		patient.Address = models.Address{
+			Line1:      message.Component(address, 1),
+			Line2:      message.Component(address, 2),
+			City:       message.Component(address, 3),
+			Region:     message.Component(address, 4),
+			PostalCode: message.Component(address, 5),
+			Country:    hl7Country(message.Component(address, 6)),
+		}
+	}
+
+	if number := hl7Phone(message, pid.Field(13)); number != "" {
+		patient.PhoneNumber = number
+	}
+	return nil
+}
+
+// contactsFromNK1 maps the next of kin segments to patient contacts
+func contactsFromNK1(message *hl7.Message) ([]models.PatientContact, error) {
+	var contacts []models.PatientContact
+	for i, nk1 := range message.SegmentsNamed("NK1") {
+		relationshipCode := strings.ToUpper(message.Component(nk1.Field(3), 1))
+		relationship := message.Component(nk1.Field(3), 2)
+		if relationship == "" {
+			relationship = hl7Relationships[relationshipCode]
+		}
+		if relationship == "" {
+			return nil, invalidMessage(hl7.ErrRequiredFieldMissing, fmt.Sprintf("NK1^%d^3", i+1), "NK1-3 relationship is required")
+		}
+
+		contact := models.PatientContact{
+			Name:         hl7Name(message, nk1.Field(2)),
+			Relationship: relationship,
+			PhoneNumber:  hl7Phone(message, nk1.Field(5)),
+			IsGuardian:   contains(hl7GuardianRelationships, relationshipCode),
+		}
+		if repetitions := message.Repetitions(nk1.Field(4)); len(repetitions) > 0 {
+			var parts []string
+			for c := 1; c <= 6; c++ {
+				if part := message.Component(repetitions[0], c); part != "" {
+					parts = append(parts, part)
+				}
+			}
+			contact.Address = strings.Join(parts, ", ")
+		}
+		switch message.Component(nk1.Field(7), 1) {
+		case "C":
+			contact.IsEmergencyContact = true
+		case "N", "":
+			contact.IsNextOfKin = true
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, nil
+}
+
+// hl7Name reads an XPN field, family^given^middle^suffix, preferring the
+// legal name, as a full name
+func hl7Name(message *hl7.Message, field string) string {
+	repetitions := message.Repetitions(field)
+	if len(repetitions) == 0 {
+		return ""
+	}
+	name := repetitions[0]
+	for _, repetition := range repetitions {
+		if message.Component(repetition, 7) == "L" {
+			name = repetition
+			break
+		}
+	}
+	var parts []string
+	for _, c := range []int{2, 3, 1, 4} {
+		if part := strings.TrimSpace(message.Component(name, c)); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// hl7Phone reads an XTN field, preferring the primary residence number:
+// the formatted number, or else country code, area code and local number
+func hl7Phone(message *hl7.Message, field string) string {
+	repetitions := message.Repetitions(field)
+	if len(repetitions) == 0 {
+		return ""
+	}
+	number := repetitions[0]
+	for _, repetition := range repetitions {
+		if message.Component(repetition, 2) == "PRN" {
+			number = repetition
+			break
+		}
+	}
+	if formatted := message.Component(number, 1); formatted != "" {
+		return formatted
+	}
+	local := message.Component(number, 6) + message.Component(number, 7)
+	if local == "" {
+		return ""
+	}
+	if country := message.Component(number, 5); country != "" {
+		return "+" + strings.TrimPrefix(country, "+") + local
+	}
+	return local
+}
+
+// hl7Country converts an alpha-3 country code to alpha-2; other values are
+// left for address validation to judge
+func hl7Country(country string) string {
+	if code, ok := hl7Countries[strings.ToUpper(country)]; ok {
+		return code
+	}
+	return country
+}
+
+// hl7Application is MSH-3 of the acknowledgements this system sends
+func hl7Application() string {
+	if app := viper.GetString("hl7.application"); app != "" {
+		return app
+	}
+	return "HOSPITAL-PORTAL"
+}
+
+// hl7Facility is MSH-4 of the acknowledgements this system sends
+func hl7Facility() string {
+	return viper.GetString("hl7.facility")
+}
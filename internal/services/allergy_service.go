@@ -0,0 +1,260 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var (
+	allergyCategories = []string{"drug", "food", "environment", "other"}
+	allergySeverities = []string{"mild", "moderate", "severe", "life_threatening"}
+	allergyStatuses   = []string{
+		models.AllergyStatusActive,
+		models.AllergyStatusInactive,
+		models.AllergyStatusResolved,
+		models.AllergyStatusEnteredInError,
+	}
+)
+
+// Allergy summary states shown alongside a patient
+const (
+	AllergyStatusUnknown   = "unknown"
+	AllergyStatusNKDA      = "nkda"
+	AllergyStatusAllergies = "allergies"
+)
+
+// AllergySummary is the allergy warning block attached to a patient
+type AllergySummary struct {
+	Status    string           `json:"status"`
+	Allergies []models.Allergy `json:"allergies"`
+}
+
+// AllergyService handles allergy business logic
+type AllergyService struct {
+	allergyRepo *repositories.AllergyRepository
+	patientRepo *repositories.PatientRepository
+	logger      *zap.Logger
+}
+
+// NewAllergyService creates a new allergy service instance
+func NewAllergyService(allergyRepo *repositories.AllergyRepository, patientRepo *repositories.PatientRepository, logger *zap.Logger) *AllergyService {
+	return &AllergyService{
+		allergyRepo: allergyRepo,
+		patientRepo: patientRepo,
+		logger:      logger,
+	}
+}
+
+// GetPatientAllergies retrieves all allergy records for a patient
+func (s *AllergyService) GetPatientAllergies(patientID uint) ([]models.Allergy, error) {
+	if err := s.requirePatient(patientID); err != nil {
+		return nil, err
+	}
+	return s.allergyRepo.FindByPatient(patientID)
+}
+
+// GetAllergySummary builds the allergy warnings for a patient
+func (s *AllergyService) GetAllergySummary(patientID uint) (*AllergySummary, error) {
+	active, err := s.allergyRepo.FindActiveByPatient(patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &AllergySummary{Status: AllergyStatusUnknown, Allergies: []models.Allergy{}}
+	for _, allergy := range active {
+		if allergy.NKDA {
+			if summary.Status == AllergyStatusUnknown {
+				summary.Status = AllergyStatusNKDA
+			}
+			continue
+		}
+		summary.Status = AllergyStatusAllergies
+		summary.Allergies = append(summary.Allergies, allergy)
+	}
+	return summary, nil
+}
+
+// RecordAllergy records a new allergy for a patient. Recording an active
+// drug allergy withdraws any NKDA statement on file.
+func (s *AllergyService) RecordAllergy(patientID uint, allergy *models.Allergy, recordedByID uint) (*models.Allergy, error) {
+	if err := s.requirePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	allergy.PatientID = patientID
+	allergy.NKDA = false
+	allergy.RecordedByID = recordedByID
+	allergy.RecordedAt = time.Now()
+	if allergy.Status == "" {
+		allergy.Status = models.AllergyStatusActive
+	}
+	if err := validateAllergy(allergy); err != nil {
+		return nil, err
+	}
+
+	err := s.allergyRepo.Transaction(func(allergyRepo *repositories.AllergyRepository) error {
+		if withdrawsNKDA(allergy) {
+			if err := allergyRepo.DeactivateNKDA(patientID); err != nil {
+				s.logger.Error("Failed to withdraw NKDA statement", zap.Error(err), zap.Uint("patient_id", patientID))
+				return err
+			}
+		}
+		_, err := allergyRepo.Create(allergy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allergy, nil
+}
+
+// RecordNKDA records that the patient has no known drug allergies
+func (s *AllergyService) RecordNKDA(patientID uint, notes string, recordedByID uint) (*models.Allergy, error) {
+	if err := s.requirePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	active, err := s.allergyRepo.FindActiveByPatient(patientID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range active {
+		if active[i].NKDA {
+			return &active[i], nil
+		}
+		if active[i].Category == "drug" {
+			return nil, fmt.Errorf("%w: patient has an active drug allergy (%s)", ErrConflict, active[i].Substance)
+		}
+	}
+
+	return s.allergyRepo.Create(&models.Allergy{
+		PatientID:    patientID,
+		NKDA:         true,
+		Category:     "drug",
+		Status:       models.AllergyStatusActive,
+		Notes:        notes,
+		RecordedByID: recordedByID,
+		RecordedAt:   time.Now(),
+	})
+}
+
+// UpdateAllergy updates an allergy record belonging to a patient
+func (s *AllergyService) UpdateAllergy(patientID uint, allergy *models.Allergy) (*models.Allergy, error) {
+	if err := s.requirePatient(patientID); err != nil {
+		return nil, err
+	}
+	existing, err := s.findPatientAllergy(patientID, allergy.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.NKDA {
+		// Only the status and notes of an NKDA statement can change; it
+		// has no substance or category to send
+		if allergy.Status != "" {
+			existing.Status = allergy.Status
+		}
+		existing.Notes = allergy.Notes
+		if !contains(allergyStatuses, existing.Status) {
+			return nil, fmt.Errorf("%w: status must be one of %v", ErrInvalidInput, allergyStatuses)
+		}
+		return s.allergyRepo.Update(existing)
+	}
+
+	allergy.PatientID = existing.PatientID
+	allergy.RecordedByID = existing.RecordedByID
+	allergy.RecordedAt = existing.RecordedAt
+	allergy.CreatedAt = existing.CreatedAt
+	if allergy.Status == "" {
+		allergy.Status = existing.Status
+	}
+	if err := validateAllergy(allergy); err != nil {
+		return nil, err
+	}
+
+	err = s.allergyRepo.Transaction(func(allergyRepo *repositories.AllergyRepository) error {
+		if withdrawsNKDA(allergy) {
+			if err := allergyRepo.DeactivateNKDA(patientID); err != nil {
+				return err
+			}
+		}
+		_, err := allergyRepo.Update(allergy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allergy, nil
+}
+
+// DeleteAllergy deletes an allergy record belonging to a patient
+func (s *AllergyService) DeleteAllergy(patientID, allergyID uint) error {
+	if err := s.requirePatient(patientID); err != nil {
+		return err
+	}
+	if _, err := s.findPatientAllergy(patientID, allergyID); err != nil {
+		return err
+	}
+	return s.allergyRepo.Delete(allergyID)
+}
+
+// requirePatient fails with ErrNotFound when the patient does not exist
+func (s *AllergyService) requirePatient(patientID uint) error {
+	exists, err := s.patientRepo.Exists(patientID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: patient %d does not exist", ErrNotFound, patientID)
+	}
+	return nil
+}
+
+func (s *AllergyService) findPatientAllergy(patientID, allergyID uint) (*models.Allergy, error) {
+	allergy, err := s.allergyRepo.FindByPatientAndID(patientID, allergyID)
+	if err != nil {
+		return nil, err
+	}
+	if allergy == nil {
+		return nil, fmt.Errorf("%w: allergy %d does not exist", ErrNotFound, allergyID)
+	}
+	return allergy, nil
+}
+
+// withdrawsNKDA reports whether recording the allergy contradicts an NKDA
+// statement
+func withdrawsNKDA(allergy *models.Allergy) bool {
+	return allergy.Category == "drug" && allergy.Status == models.AllergyStatusActive
+}
+
+func validateAllergy(allergy *models.Allergy) error {
+	if allergy.Substance == "" {
+		return fmt.Errorf("%w: substance is required", ErrInvalidInput)
+	}
+	if allergy.SubstanceCode != "" && allergy.CodeSystem == "" {
+		return fmt.Errorf("%w: code_system is required with substance_code", ErrInvalidInput)
+	}
+	if !contains(allergyCategories, allergy.Category) {
+		return fmt.Errorf("%w: category must be one of %v", ErrInvalidInput, allergyCategories)
+	}
+	if allergy.Severity != "" && !contains(allergySeverities, allergy.Severity) {
+		return fmt.Errorf("%w: severity must be one of %v", ErrInvalidInput, allergySeverities)
+	}
+	if !contains(allergyStatuses, allergy.Status) {
+		return fmt.Errorf("%w: status must be one of %v", ErrInvalidInput, allergyStatuses)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var (
+	paymentKinds   = []string{models.PaymentKindPayment, models.PaymentKindRefund, models.PaymentKindAdjustment}
+	paymentMethods = []string{
+		models.PaymentMethodCash,
+		models.PaymentMethodCard,
+		models.PaymentMethodCheck,
+		models.PaymentMethodInsurance,
+		models.PaymentMethodOther,
+	}
+)
+
+// InvoiceOptions controls invoice generation
+type InvoiceOptions struct {
+	ChargeIDs       []uint // all uninvoiced charges of the patient when empty
+	DiscountPercent float64
+}
+
+// PaymentInput holds the details of a payment, refund or adjustment
+type PaymentInput struct {
+	Kind       string
+	Method     string
+	Amount     int64
+	Reference  string
+	Notes      string
+	ReceivedAt time.Time
+}
+
+// PatientBalance is what a patient owes
+type PatientBalance struct {
+	PatientID  uint  `json:"patient_id"`
+	Invoiced   int64 `json:"invoiced"`   // open invoice balances
+	Uninvoiced int64 `json:"uninvoiced"` // charges not invoiced yet
+	Total      int64 `json:"total"`
+}
+
+// DailyCollections sums the money received on one day
+type DailyCollections struct {
+	Date     string           `json:"date"`
+	Payments int64            `json:"payments"`
+	Refunds  int64            `json:"refunds"`
+	Net      int64            `json:"net"`
+	ByMethod map[string]int64 `json:"by_method"` // net per payment method
+	Count    int              `json:"count"`
+}
+
+// CollectionsReport sums the money received over a period, day by day
+type CollectionsReport struct {
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Days     []DailyCollections `json:"days"`
+	Payments int64              `json:"payments"`
+	Refunds  int64              `json:"refunds"`
+	Net      int64              `json:"net"`
+}
+
+// BillingService handles charges, invoices and payments
+type BillingService struct {
+	billingRepo   *repositories.BillingRepository
+	patientRepo   *repositories.PatientRepository
+	encounterRepo *repositories.EncounterRepository
+	logger        *zap.Logger
+}
+
+// NewBillingService creates a new billing service instance
+func NewBillingService(billingRepo *repositories.BillingRepository, patientRepo *repositories.PatientRepository, encounterRepo *repositories.EncounterRepository, logger *zap.Logger) *BillingService {
+	return &BillingService{
+		billingRepo:   billingRepo,
+		patientRepo:   patientRepo,
+		encounterRepo: encounterRepo,
+		logger:        logger,
+	}
+}
+
+// GetChargeMaster lists the charge master
+func (s *BillingService) GetChargeMaster(includeInactive bool) ([]models.ChargeMasterItem, error) {
+	return s.billingRepo.FindChargeItems(includeInactive)
+}
+
+// SaveChargeItem creates or updates a charge master item. Price changes
+// only apply to charges posted afterwards.
+func (s *BillingService) SaveChargeItem(item *models.ChargeMasterItem) (*models.ChargeMasterItem, error) {
+	item.Code = strings.ToUpper(strings.TrimSpace(item.Code))
+	item.Description = strings.TrimSpace(item.Description)
+	item.CPTCode = strings.ToUpper(strings.TrimSpace(item.CPTCode))
+	if item.Code == "" || item.Description == "" {
+		return nil, fmt.Errorf("%w: code and description are required", ErrInvalidInput)
+	}
+	if item.Price < 0 {
+		return nil, fmt.Errorf("%w: price cannot be negative", ErrInvalidInput)
+	}
+	if existing, err := s.billingRepo.FindChargeItem(item.Code); err == nil {
+		item.CreatedAt = existing.CreatedAt
+	}
+	return s.billingRepo.SaveChargeItem(item)
+}
+
+// PostCharge posts a charge master service against an encounter
+func (s *BillingService) PostCharge(encounterID uint, serviceCode string, quantity int, serviceDate *time.Time, postedByID uint) (*models.Charge, error) {
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidInput)
+	}
+	encounter, err := s.encounterRepo.FindByID(encounterID)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.Status == models.EncounterStatusCancelled {
+		return nil, fmt.Errorf("%w: cannot charge a cancelled encounter", ErrConflict)
+	}
+	item, err := s.billingRepo.FindChargeItem(strings.ToUpper(strings.TrimSpace(serviceCode)))
+	if err != nil || !item.Active {
+		return nil, fmt.Errorf("%w: unknown or inactive service code %q", ErrInvalidInput, serviceCode)
+	}
+
+	charge := &models.Charge{
+		PatientID:   encounter.PatientID,
+		EncounterID: encounter.ID,
+		ServiceCode: item.Code,
+		Description: item.Description,
+		CPTCode:     item.CPTCode,
+		Quantity:    quantity,
+		UnitPrice:   item.Price,
+		Amount:      item.Price * int64(quantity),
+		Taxable:     item.Taxable,
+		ServiceDate: encounter.StartedAt,
+		Status:      models.ChargeStatusPosted,
+		PostedByID:  postedByID,
+	}
+	if serviceDate != nil {
+		charge.ServiceDate = *serviceDate
+	}
+	return s.billingRepo.CreateCharge(charge)
+}
+
+// GetEncounterCharges retrieves the charges posted against an encounter
+func (s *BillingService) GetEncounterCharges(encounterID uint) ([]models.Charge, error) {
+	if _, err := s.encounterRepo.FindByID(encounterID); err != nil {
+		return nil, err
+	}
+	return s.billingRepo.FindChargesByEncounter(encounterID)
+}
+
+// VoidCharge voids a charge posted in error. Invoiced charges must have
+// their invoice voided first.
+func (s *BillingService) VoidCharge(chargeID uint, reason string) (*models.Charge, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required to void a charge", ErrInvalidInput)
+	}
+	return s.billingRepo.UpdateCharge(chargeID, func(charge *models.Charge) error {
+		if charge.Status == models.ChargeStatusVoided {
+			return fmt.Errorf("%w: charge is already voided", ErrConflict)
+		}
+		if charge.InvoiceID != nil {
+			return fmt.Errorf("%w: charge is on an invoice, void the invoice first", ErrConflict)
+		}
+		charge.Status = models.ChargeStatusVoided
+		charge.VoidReason = reason
+		return nil
+	})
+}
+
+// GenerateInvoice invoices a patient's uninvoiced charges, applying the
+// discount to every line and the configured tax rate to taxable lines
+func (s *BillingService) GenerateInvoice(patientID uint, options InvoiceOptions, issuedByID uint) (*models.Invoice, error) {
+	if options.DiscountPercent < 0 || options.DiscountPercent > 100 {
+		return nil, fmt.Errorf("%w: discount_percent must be between 0 and 100", ErrInvalidInput)
+	}
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+
+	charges, err := s.billingRepo.FindUninvoicedCharges(patientID, options.ChargeIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(charges) == 0 {
+		return nil, fmt.Errorf("%w: no uninvoiced charges to invoice", ErrInvalidInput)
+	}
+	if len(options.ChargeIDs) > 0 && len(charges) != len(options.ChargeIDs) {
+		return nil, fmt.Errorf("%w: some charges are voided, already invoiced or belong to another patient", ErrInvalidInput)
+	}
+
+	now := time.Now()
+	invoice := &models.Invoice{
+		PatientID:       patientID,
+		Status:          models.InvoiceStatusIssued,
+		IssuedAt:        now,
+		DueDate:         now.AddDate(0, 0, paymentTermsDays()),
+		DiscountPercent: options.DiscountPercent,
+		TaxRatePercent:  taxRatePercent(),
+		IssuedByID:      issuedByID,
+	}
+	chargeIDs := make([]uint, 0, len(charges))
+	for _, charge := range charges {
+		line := models.InvoiceLine{
+			ChargeID:    charge.ID,
+			Description: charge.Description,
+			Quantity:    charge.Quantity,
+			UnitPrice:   charge.UnitPrice,
+			Amount:      charge.Amount,
+			Discount:    percentOf(charge.Amount, invoice.DiscountPercent),
+		}
+		if charge.Taxable {
+			line.Tax = percentOf(line.Amount-line.Discount, invoice.TaxRatePercent)
+		}
+		line.Total = line.Amount - line.Discount + line.Tax
+
+		invoice.Lines = append(invoice.Lines, line)
+		invoice.Subtotal += line.Amount
+		invoice.DiscountTotal += line.Discount
+		invoice.TaxTotal += line.Tax
+		invoice.Total += line.Total
+		chargeIDs = append(chargeIDs, charge.ID)
+	}
+	invoice.Balance = invoice.Total
+
+	created, err := s.billingRepo.CreateInvoice(invoice, chargeIDs)
+	if err != nil {
+		s.logger.Error("Failed to create invoice", zap.Error(err), zap.Uint("patient_id", patientID))
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetPatientInvoices retrieves a patient's invoices
+func (s *BillingService) GetPatientInvoices(patientID uint) ([]models.Invoice, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.billingRepo.FindInvoicesByPatient(patientID)
+}
+
+// GetInvoice retrieves an invoice with its lines and payments
+func (s *BillingService) GetInvoice(id uint) (*models.Invoice, error) {
+	return s.billingRepo.FindInvoiceByID(id)
+}
+
+// VoidInvoice voids an invoice without payments and releases its charges
+func (s *BillingService) VoidInvoice(id uint) (*models.Invoice, error) {
+	return s.billingRepo.VoidInvoice(id, func(invoice *models.Invoice) error {
+		if invoice.Status == models.InvoiceStatusVoided {
+			return fmt.Errorf("%w: invoice is already voided", ErrConflict)
+		}
+		if invoice.AmountPaid != 0 || invoice.AmountAdjusted != 0 {
+			return fmt.Errorf("%w: cannot void an invoice with payments or adjustments", ErrConflict)
+		}
+		invoice.Status = models.InvoiceStatusVoided
+		invoice.Balance = 0
+		return nil
+	})
+}
+
+// RecordPayment records a payment, refund or adjustment against an
+// invoice. Payments and adjustments cannot exceed the balance and refunds
+// cannot exceed what was paid.
+func (s *BillingService) RecordPayment(invoiceID uint, input PaymentInput, recordedByID uint) (*models.Invoice, error) {
+	if !contains(paymentKinds, input.Kind) {
+		return nil, fmt.Errorf("%w: kind must be one of %v", ErrInvalidInput, paymentKinds)
+	}
+	if !contains(paymentMethods, input.Method) {
+		return nil, fmt.Errorf("%w: method must be one of %v", ErrInvalidInput, paymentMethods)
+	}
+	if input.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidInput)
+	}
+	if input.ReceivedAt.IsZero() {
+		input.ReceivedAt = time.Now()
+	}
+
+	payment := &models.Payment{
+		InvoiceID:    invoiceID,
+		Kind:         input.Kind,
+		Method:       input.Method,
+		Amount:       input.Amount,
+		Reference:    input.Reference,
+		Notes:        input.Notes,
+		ReceivedAt:   input.ReceivedAt,
+		RecordedByID: recordedByID,
+	}
+	invoice, err := s.billingRepo.AddPayment(payment, func(invoice *models.Invoice) error {
+		if invoice.Status == models.InvoiceStatusVoided {
+			return fmt.Errorf("%w: invoice is voided", ErrConflict)
+		}
+		payment.PatientID = invoice.PatientID
+		return applyPayment(invoice, payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Payment recorded",
+		zap.Uint("invoice_id", invoiceID),
+		zap.String("kind", payment.Kind),
+		zap.Int64("amount", payment.Amount),
+	)
+	return invoice, nil
+}
+
+// withRepository returns a copy of the service that works through
+// billingRepo, such as one bound to a transaction
+func (s *BillingService) withRepository(billingRepo *repositories.BillingRepository) *BillingService {
+	bound := *s
+	bound.billingRepo = billingRepo
+	return &bound
+}
+
+// GetPatientBalance computes what a patient owes
+func (s *BillingService) GetPatientBalance(patientID uint) (*PatientBalance, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	invoiced, uninvoiced, err := s.billingRepo.SumPatientBalance(patientID)
+	if err != nil {
+		return nil, err
+	}
+	return &PatientBalance{
+		PatientID:  patientID,
+		Invoiced:   invoiced,
+		Uninvoiced: uninvoiced,
+		Total:      invoiced + uninvoiced,
+	}, nil
+}
+
+// GetCollectionsReport sums payments and refunds received between two
+// days (inclusive), broken down by day and payment method. Adjustments
+// are not money collected and are left out.
+func (s *BillingService) GetCollectionsReport(from, to time.Time) (*CollectionsReport, error) {
+	from = startOfDay(from)
+	to = startOfDay(to).AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidInput)
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return nil, fmt.Errorf("%w: the report covers at most one year", ErrInvalidInput)
+	}
+
+	payments, err := s.billingRepo.FindPaymentsBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CollectionsReport{
+		From: from.Format("2006-01-02"),
+		To:   to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Days: []DailyCollections{},
+	}
+	days := make(map[string]*DailyCollections)
+	for _, payment := range payments {
+		if payment.Kind == models.PaymentKindAdjustment {
+			continue
+		}
+		date := payment.ReceivedAt.In(from.Location()).Format("2006-01-02")
+		day, ok := days[date]
+		if !ok {
+			day = &DailyCollections{Date: date, ByMethod: map[string]int64{}}
+			days[date] = day
+		}
+		amount := payment.Amount
+		if payment.Kind == models.PaymentKindRefund {
+			day.Refunds += amount
+			report.Refunds += amount
+			amount = -amount
+		} else {
+			day.Payments += amount
+			report.Payments += amount
+		}
+		day.Net += amount
+		day.ByMethod[payment.Method] += amount
+		day.Count++
+	}
+	for _, day := range days {
+		report.Days = append(report.Days, *day)
+	}
+	sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Date < report.Days[j].Date })
+	report.Net = report.Payments - report.Refunds
+	return report, nil
+}
+
+// applyPayment updates an invoice's totals for a payment or refund
+func applyPayment(invoice *models.Invoice, payment *models.Payment) error {
+	switch payment.Kind {
+	case models.PaymentKindPayment:
+		if payment.Amount > invoice.Balance {
+			return fmt.Errorf("%w: payment of %d exceeds the balance of %d", ErrInvalidInput, payment.Amount, invoice.Balance)
+		}
+		invoice.AmountPaid += payment.Amount
+	case models.PaymentKindRefund:
+		if payment.Amount > invoice.AmountPaid {
+			return fmt.Errorf("%w: refund of %d exceeds the %d paid", ErrInvalidInput, payment.Amount, invoice.AmountPaid)
+		}
+		invoice.AmountPaid -= payment.Amount
+	case models.PaymentKindAdjustment:
+		if payment.Amount > invoice.Balance {
+			return fmt.Errorf("%w: adjustment of %d exceeds the balance of %d", ErrInvalidInput, payment.Amount, invoice.Balance)
+		}
+		invoice.AmountAdjusted += payment.Amount
+	default:
+		return errors.New("unknown payment kind")
+	}
+
+	invoice.Balance = invoice.Total - invoice.AmountPaid - invoice.AmountAdjusted
+	if invoice.Balance == 0 {
+		invoice.Status = models.InvoiceStatusPaid
+	} else {
+		invoice.Status = models.InvoiceStatusIssued
+	}
+	return nil
+}
+
+// percentOf computes a percentage of an amount, rounded to the nearest cent
+func percentOf(amount int64, percent float64) int64 {
+	return int64(math.Round(float64(amount) * percent / 100))
+}
+
+func taxRatePercent() float64 {
+	return viper.GetFloat64("billing.tax_rate_percent")
+}
+
+func paymentTermsDays() int {
+	if days := viper.GetInt("billing.payment_terms_days"); days > 0 {
+		return days
+	}
+	return 30
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,626 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/fhir"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/storage"
+)
+
+// Bulk export tuning
+const (
+	bulkExportBatchSize = 500
+	// bulkExportHeartbeat is how often a running job refreshes its row
+	bulkExportHeartbeat = time.Minute
+	// bulkExportStaleAfter is how long a job may go without a heartbeat
+	// before it counts as interrupted, e.g. by a restart
+	bulkExportStaleAfter = 10 * time.Minute
+)
+
+// bulkExportTypes are the resource types a bulk export can include, in the
+// order they are written
+var bulkExportTypes = []string{
+	"Patient",
+	"Encounter",
+	"Condition",
+	"AllergyIntolerance",
+	"MedicationRequest",
+	"Observation",
+	"Immunization",
+}
+
+// BulkExportService runs FHIR Bulk Data ($export) jobs. A job writes one
+// NDJSON file per resource type to blob storage in the background; patients
+// who have withdrawn research consent are left out, as in research extracts.
+type BulkExportService struct {
+	exportRepo       *repositories.BulkExportRepository
+	patientRepo      *repositories.PatientRepository
+	encounterRepo    *repositories.EncounterRepository
+	problemRepo      *repositories.ProblemRepository
+	allergyRepo      *repositories.AllergyRepository
+	prescriptionRepo *repositories.PrescriptionRepository
+	vitalsRepo       *repositories.VitalSignsRepository
+	labRepo          *repositories.LabRepository
+	immunizationRepo *repositories.ImmunizationRepository
+	consentService   *ConsentService
+	blobs            storage.BlobStorage
+	logger           *zap.Logger
+
+	mu      sync.Mutex
+	running map[uint]context.CancelFunc
+}
+
+// NewBulkExportService creates a new bulk export service instance
+func NewBulkExportService(
+	exportRepo *repositories.BulkExportRepository,
+	patientRepo *repositories.PatientRepository,
+	encounterRepo *repositories.EncounterRepository,
+	problemRepo *repositories.ProblemRepository,
+	allergyRepo *repositories.AllergyRepository,
+	prescriptionRepo *repositories.PrescriptionRepository,
+	vitalsRepo *repositories.VitalSignsRepository,
+	labRepo *repositories.LabRepository,
+	immunizationRepo *repositories.ImmunizationRepository,
+	consentService *ConsentService,
+	blobs storage.BlobStorage,
+	logger *zap.Logger,
+) *BulkExportService {
+	return &BulkExportService{
+		exportRepo:       exportRepo,
+		patientRepo:      patientRepo,
+		encounterRepo:    encounterRepo,
+		problemRepo:      problemRepo,
+		allergyRepo:      allergyRepo,
+		prescriptionRepo: prescriptionRepo,
+		vitalsRepo:       vitalsRepo,
+		labRepo:          labRepo,
+		immunizationRepo: immunizationRepo,
+		consentService:   consentService,
+		blobs:            blobs,
+		logger:           logger,
+		running:          make(map[uint]context.CancelFunc),
+	}
+}
+
+// Kickoff validates the export parameters, records the job and starts it in
+// the background. request is the kick-off URL, echoed in the manifest.
+func (s *BulkExportService) Kickoff(params url.Values, request string, userID uint) (*models.BulkExportJob, error) {
+	types, since, err := parseBulkExportParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.purgeExpired()
+
+	running, err := s.exportRepo.CountInProgressByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if running >= int64(maxConcurrentExports()) {
+		return nil, fmt.Errorf("%w: an export is already running; wait for it to finish or cancel it", ErrConflict)
+	}
+
+	job, err := s.exportRepo.Create(&models.BulkExportJob{
+		RequestedByID:   userID,
+		Request:         request,
+		ResourceTypes:   strings.Join(types, ","),
+		Since:           since,
+		TransactionTime: time.Now(),
+		Status:          models.BulkExportStatusInProgress,
+		Progress:        "Queued",
+	})
+	if err != nil {
+		s.logger.Error("Failed to create bulk export job", zap.Error(err))
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.running[job.ID] = cancel
+	s.mu.Unlock()
+	go s.run(ctx, job.ID, types, since)
+
+	s.logger.Info("Bulk export started", zap.Uint("job_id", job.ID), zap.Uint("user_id", userID), zap.Strings("types", types))
+	return job, nil
+}
+
+// GetJob retrieves an export for status polling. A job that stopped
+// reporting progress without finishing is marked failed.
+func (s *BulkExportService) GetJob(id, userID uint) (*models.BulkExportJob, error) {
+	job, err := s.ownJob(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == models.BulkExportStatusInProgress && !s.isRunning(job.ID) && time.Since(job.UpdatedAt) > bulkExportStaleAfter {
+		s.logger.Warn("Bulk export was interrupted", zap.Uint("job_id", job.ID))
+		return s.finish(job.ID, models.BulkExportStatusFailed, "the export was interrupted; start a new one")
+	}
+	return job, nil
+}
+
+// Cancel stops a running export, or discards a finished one, and deletes
+// its files
+func (s *BulkExportService) Cancel(id, userID uint) error {
+	job, err := s.ownJob(id, userID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.running[job.ID]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	if err := s.removeJob(job); err != nil {
+		s.logger.Error("Failed to delete bulk export", zap.Error(err), zap.Uint("job_id", job.ID))
+		return err
+	}
+	s.logger.Info("Bulk export cancelled", zap.Uint("job_id", job.ID), zap.String("status", job.Status))
+	return nil
+}
+
+// Manifest lists the files of a completed export. baseURL is the FHIR
+// service base the download links are built on.
+func (s *BulkExportService) Manifest(job *models.BulkExportJob, baseURL string) *fhir.ExportManifest {
+	manifest := &fhir.ExportManifest{
+		TransactionTime:     fhirDateTime(job.TransactionTime),
+		Request:             job.Request,
+		RequiresAccessToken: true,
+		Output:              []fhir.ExportFile{},
+		Error:               []fhir.ExportFile{},
+	}
+	for _, file := range job.Files {
+		manifest.Output = append(manifest.Output, fhir.ExportFile{
+			Type:  file.ResourceType,
+			URL:   fmt.Sprintf("%s/$export-file/%d/%d", baseURL, job.ID, file.ID),
+			Count: file.Count,
+		})
+	}
+	return manifest
+}
+
+// OpenFile opens a file of a completed export for download. The caller
+// must close the returned reader.
+func (s *BulkExportService) OpenFile(ctx context.Context, jobID, fileID, userID uint) (*models.BulkExportFile, io.ReadCloser, error) {
+	job, err := s.ownJob(jobID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != models.BulkExportStatusCompleted {
+		return nil, nil, fmt.Errorf("%w: the export is %s", ErrConflict, strings.ReplaceAll(job.Status, "_", " "))
+	}
+
+	for i := range job.Files {
+		if job.Files[i].ID != fileID {
+			continue
+		}
+		body, err := s.blobs.Get(ctx, job.Files[i].StorageKey)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, nil, errors.New("export file not found")
+			}
+			s.logger.Error("Failed to open export file", zap.Error(err), zap.Uint("job_id", jobID), zap.Uint("file_id", fileID))
+			return nil, nil, err
+		}
+		return &job.Files[i], body, nil
+	}
+	return nil, nil, errors.New("export file not found")
+}
+
+// ownJob loads a job the user started; other users' jobs and expired ones
+// are not theirs to see
+func (s *BulkExportService) ownJob(id, userID uint) (*models.BulkExportJob, error) {
+	job, err := s.exportRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.RequestedByID != userID {
+		return nil, fmt.Errorf("%w: the export belongs to another user", ErrForbidden)
+	}
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("bulk export not found")
+	}
+	return job, nil
+}
+
+func (s *BulkExportService) isRunning(id uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.running[id]
+	return ok
+}
+
+// run writes the export's files, one resource type after the other
+func (s *BulkExportService) run(ctx context.Context, jobID uint, types []string, since *time.Time) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, jobID)
+		s.mu.Unlock()
+	}()
+
+	withdrawn, err := s.consentService.WithdrawnPatientIDs(models.ConsentTypeResearch)
+	if err != nil {
+		s.finish(jobID, models.BulkExportStatusFailed, "failed to load consents")
+		return
+	}
+
+	for i, resourceType := range types {
+		progress := fmt.Sprintf("Exporting %s (%d of %d)", resourceType, i+1, len(types))
+		if err := s.exportType(ctx, jobID, resourceType, since, withdrawn, progress); err != nil {
+			if ctx.Err() != nil {
+				// Cancelled; Cancel has removed the job
+				return
+			}
+			s.logger.Error("Bulk export failed", zap.Error(err), zap.Uint("job_id", jobID), zap.String("type", resourceType))
+			s.finish(jobID, models.BulkExportStatusFailed, fmt.Sprintf("failed to export %s resources", resourceType))
+			return
+		}
+	}
+
+	if _, err := s.finish(jobID, models.BulkExportStatusCompleted, ""); err == nil {
+		s.logger.Info("Bulk export completed", zap.Uint("job_id", jobID))
+	}
+}
+
+// exportType writes the resources of one type to an NDJSON file and stores
+// it. Types without any resources produce no file.
+func (s *BulkExportService) exportType(ctx context.Context, jobID uint, resourceType string, since *time.Time, withdrawn map[uint]bool, progress string) error {
+	if err := s.setProgress(jobID, progress); err != nil {
+		return err
+	}
+
+	spool, err := newNDJSONSpool()
+	if err != nil {
+		return err
+	}
+	defer spool.close()
+
+	lastBeat := time.Now()
+	batch := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if time.Since(lastBeat) > bulkExportHeartbeat {
+			lastBeat = time.Now()
+			return s.setProgress(jobID, progress)
+		}
+		return nil
+	}
+	emit := func(patientID uint, resource fhir.Resource) error {
+		if withdrawn[patientID] {
+			return nil
+		}
+		return spool.write(resource)
+	}
+	if err := s.walkResources(resourceType, since, batch, emit); err != nil {
+		return err
+	}
+	if spool.count == 0 {
+		return nil
+	}
+
+	size, checksum, err := spool.finish()
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("bulk-exports/%d/%s.ndjson", jobID, resourceType)
+	if err := s.blobs.Put(ctx, key, spool.file, size, fhir.NDJSONContentType, checksum); err != nil {
+		return err
+	}
+	err = s.exportRepo.AddFile(&models.BulkExportFile{
+		JobID:        jobID,
+		ResourceType: resourceType,
+		StorageKey:   key,
+		Count:        spool.count,
+		Size:         size,
+	})
+	if err != nil {
+		// Do not leave an orphaned blob behind
+		if delErr := s.blobs.Delete(context.Background(), key); delErr != nil {
+			s.logger.Warn("Failed to remove orphaned export blob", zap.Error(delErr), zap.String("key", key))
+		}
+		return err
+	}
+	return nil
+}
+
+// walkResources maps the records behind a resource type in batches, calling
+// batch before each one and emit for every resource
+func (s *BulkExportService) walkResources(resourceType string, since *time.Time, batch func() error, emit func(patientID uint, resource fhir.Resource) error) error {
+	switch resourceType {
+	case "Patient":
+		return s.patientRepo.FindUpdatedSinceInBatches(since, bulkExportBatchSize, func(patients []models.Patient) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			if err := s.patientRepo.LoadIdentifiers(patients); err != nil {
+				return err
+			}
+			for i := range patients {
+				if err := emit(patients[i].ID, ToFHIRPatient(&patients[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "Encounter":
+		return s.encounterRepo.FindUpdatedSinceInBatches(since, bulkExportBatchSize, func(encounters []models.Encounter) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			for i := range encounters {
+				if err := emit(encounters[i].PatientID, toFHIREncounter(&encounters[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "Condition":
+		return s.problemRepo.FindUpdatedSinceInBatches(since, bulkExportBatchSize, func(problems []models.Problem) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			for i := range problems {
+				if err := emit(problems[i].PatientID, toFHIRCondition(&problems[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "AllergyIntolerance":
+		return s.allergyRepo.FindUpdatedSinceInBatches(since, bulkExportBatchSize, func(allergies []models.Allergy) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			for i := range allergies {
+				if err := emit(allergies[i].PatientID, toFHIRAllergyIntolerance(&allergies[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "MedicationRequest":
+		return s.prescriptionRepo.FindUpdatedSinceInBatches(since, bulkExportBatchSize, func(prescriptions []models.Prescription) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			for i := range prescriptions {
+				if err := emit(prescriptions[i].PatientID, toFHIRMedicationRequest(&prescriptions[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "Observation":
+		// Vital signs first, then lab results
+		err := s.vitalsRepo.FindUpdatedSinceInBatches(since, bulkExportBatchSize, func(records []models.VitalSigns) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			for i := range records {
+				for _, observation := range toFHIRVitalSignObservations(&records[i]) {
+					if err := emit(records[i].PatientID, observation); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return s.labRepo.FindResultedUpdatedSinceInBatches(since, bulkExportBatchSize, func(orders []models.LabOrder) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			for i := range orders {
+				for _, observation := range toFHIRLabObservations(&orders[i]) {
+					if err := emit(orders[i].PatientID, observation); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	case "Immunization":
+		return s.immunizationRepo.FindUpdatedSinceInBatches(since, bulkExportBatchSize, func(immunizations []models.Immunization) error {
+			if err := batch(); err != nil {
+				return err
+			}
+			for i := range immunizations {
+				if err := emit(immunizations[i].PatientID, toFHIRImmunization(&immunizations[i])); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	default:
+		return fmt.Errorf("unsupported resource type %s", resourceType)
+	}
+}
+
+func (s *BulkExportService) setProgress(jobID uint, progress string) error {
+	_, err := s.exportRepo.Update(jobID, func(job *models.BulkExportJob) error {
+		if job.Status != models.BulkExportStatusInProgress {
+			return fmt.Errorf("%w: the export is %s", ErrConflict, job.Status)
+		}
+		job.Progress = progress
+		return nil
+	})
+	return err
+}
+
+// finish records the outcome of a job; its files are kept until the
+// retention period ends
+func (s *BulkExportService) finish(jobID uint, status, message string) (*models.BulkExportJob, error) {
+	job, err := s.exportRepo.Update(jobID, func(job *models.BulkExportJob) error {
+		now := time.Now()
+		expires := now.Add(exportRetention())
+		job.Status = status
+		job.Progress = ""
+		job.Error = message
+		job.CompletedAt = &now
+		job.ExpiresAt = &expires
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to record bulk export outcome", zap.Error(err), zap.Uint("job_id", jobID), zap.String("status", status))
+		return nil, err
+	}
+	return job, nil
+}
+
+// purgeExpired deletes the jobs past their retention period along with
+// their files. Failures are logged and retried on the next kick-off.
+func (s *BulkExportService) purgeExpired() {
+	jobs, err := s.exportRepo.FindExpired(time.Now())
+	if err != nil {
+		s.logger.Error("Failed to fetch expired bulk exports", zap.Error(err))
+		return
+	}
+	for i := range jobs {
+		if err := s.removeJob(&jobs[i]); err != nil {
+			s.logger.Error("Failed to purge bulk export", zap.Error(err), zap.Uint("job_id", jobs[i].ID))
+		}
+	}
+}
+
+func (s *BulkExportService) removeJob(job *models.BulkExportJob) error {
+	for _, file := range job.Files {
+		if err := s.blobs.Delete(context.Background(), file.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return s.exportRepo.Delete(job.ID)
+}
+
+// parseBulkExportParams reads the kick-off parameters. Unsupported
+// parameters are rejected rather than ignored, so that a client never gets
+// more data than it asked for.
+func parseBulkExportParams(params url.Values) ([]string, *time.Time, error) {
+	for name := range params {
+		switch name {
+		case "_outputFormat", "_since", "_type":
+		default:
+			return nil, nil, fmt.Errorf("%w: parameter %s is not supported", ErrInvalidInput, name)
+		}
+	}
+
+	if format := params.Get("_outputFormat"); format != "" && !contains(fhir.ExportOutputFormats, format) {
+		return nil, nil, fmt.Errorf("%w: _outputFormat must be one of %v", ErrInvalidInput, fhir.ExportOutputFormats)
+	}
+
+	var since *time.Time
+	if value := params.Get("_since"); value != "" {
+		// An unencoded + in the offset arrives as a space
+		t, err := time.Parse(time.RFC3339, strings.ReplaceAll(value, " ", "+"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: _since must be a FHIR instant, e.g. 2024-01-31T00:00:00Z", ErrInvalidInput)
+		}
+		since = &t
+	}
+
+	requested := map[string]bool{}
+	for _, value := range params["_type"] {
+		for _, resourceType := range strings.Split(value, ",") {
+			resourceType = strings.TrimSpace(resourceType)
+			if resourceType == "" {
+				continue
+			}
+			if !contains(bulkExportTypes, resourceType) {
+				return nil, nil, fmt.Errorf("%w: _type %s is not supported; use %v", ErrInvalidInput, resourceType, bulkExportTypes)
+			}
+			requested[resourceType] = true
+		}
+	}
+	if len(requested) == 0 {
+		return bulkExportTypes, since, nil
+	}
+	types := make([]string, 0, len(requested))
+	for _, resourceType := range bulkExportTypes {
+		if requested[resourceType] {
+			types = append(types, resourceType)
+		}
+	}
+	return types, since, nil
+}
+
+// maxConcurrentExports is how many exports a user may run at once
+func maxConcurrentExports() int {
+	if limit := viper.GetInt("fhir.bulk_export.max_concurrent"); limit > 0 {
+		return limit
+	}
+	return 1
+}
+
+// exportRetention is how long the files of a finished export are kept
+func exportRetention() time.Duration {
+	if hours := viper.GetInt("fhir.bulk_export.retention_hours"); hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// ndjsonSpool buffers an NDJSON file on disk while it is written, as blob
+// storage needs the size and checksum up front
+type ndjsonSpool struct {
+	file    *os.File
+	hash    hash.Hash
+	encoder *json.Encoder
+	count   int
+}
+
+func newNDJSONSpool() (*ndjsonSpool, error) {
+	file, err := os.CreateTemp("", "bulk-export-*.ndjson")
+	if err != nil {
+		return nil, err
+	}
+	spool := &ndjsonSpool{file: file, hash: sha256.New()}
+	spool.encoder = json.NewEncoder(io.MultiWriter(file, spool.hash))
+	spool.encoder.SetEscapeHTML(false)
+	return spool, nil
+}
+
+// write appends a resource as one line
+func (s *ndjsonSpool) write(resource fhir.Resource) error {
+	if err := s.encoder.Encode(resource); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+// finish rewinds the file for reading and returns its size and checksum
+func (s *ndjsonSpool) finish() (int64, string, error) {
+	size, err := s.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, "", err
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(s.hash.Sum(nil)), nil
+}
+
+func (s *ndjsonSpool) close() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var careTeamRoles = []string{"attending", "consulting", "nursing", "other"}
+
+// CareTeamService handles care team business logic
+type CareTeamService struct {
+	careTeamRepo *repositories.CareTeamRepository
+	patientRepo  *repositories.PatientRepository
+	userRepo     *repositories.UserRepository
+	logger       *zap.Logger
+}
+
+// NewCareTeamService creates a new care team service instance
+func NewCareTeamService(careTeamRepo *repositories.CareTeamRepository, patientRepo *repositories.PatientRepository, userRepo *repositories.UserRepository, logger *zap.Logger) *CareTeamService {
+	return &CareTeamService{
+		careTeamRepo: careTeamRepo,
+		patientRepo:  patientRepo,
+		userRepo:     userRepo,
+		logger:       logger,
+	}
+}
+
+// GetCareTeam retrieves the care team of a patient
+func (s *CareTeamService) GetCareTeam(patientID uint) ([]models.CareTeamMember, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.careTeamRepo.FindByPatient(patientID)
+}
+
+// AddMember adds a staff user to a patient's care team
+func (s *CareTeamService) AddMember(patientID, userID uint, role string, addedByID uint) (*models.CareTeamMember, error) {
+	if !contains(careTeamRoles, role) {
+		return nil, fmt.Errorf("%w: role must be one of %v", ErrInvalidInput, careTeamRoles)
+	}
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	return s.careTeamRepo.Add(&models.CareTeamMember{
+		PatientID: patientID,
+		UserID:    userID,
+		Role:      role,
+		AddedByID: addedByID,
+	})
+}
+
+// RemoveMember removes a staff user from a patient's care team
+func (s *CareTeamService) RemoveMember(patientID, userID uint) error {
+	return s.careTeamRepo.Remove(patientID, userID)
+}
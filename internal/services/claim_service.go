@@ -0,0 +1,627 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/storage"
+	"hospital-portal/internal/x12"
+)
+
+// maxClaimDiagnoses is the number of diagnoses an 837P claim can carry
+const maxClaimDiagnoses = 12
+
+// MaxRemittanceSize bounds the size of an uploaded 835 file
+const MaxRemittanceSize = 10 << 20
+
+// placesOfService maps encounter types to CMS place of service codes
+var placesOfService = map[string]string{
+	"outpatient": "11", // office
+	"inpatient":  "21", // inpatient hospital
+	"emergency":  "23", // emergency room
+	"telehealth": "02", // telehealth other than the patient's home
+}
+
+// subscriberRelationshipCodes maps subscriber relationships to 837 PAT01 codes
+var subscriberRelationshipCodes = map[string]string{
+	models.SubscriberRelationshipSelf:   "18",
+	models.SubscriberRelationshipSpouse: "01",
+	models.SubscriberRelationshipChild:  "19",
+	models.SubscriberRelationshipOther:  "G8",
+}
+
+// RemittancePosting reports what happened to one claim of an 835
+type RemittancePosting struct {
+	ControlNumber string `json:"control_number"`
+	ClaimID       uint   `json:"claim_id,omitempty"`
+	Status        string `json:"status"` // claim status, or "unmatched"
+	Paid          int64  `json:"paid"`
+	Adjusted      int64  `json:"adjusted"`
+	InvoiceID     uint   `json:"invoice_id,omitempty"`
+	Note          string `json:"note,omitempty"`
+}
+
+// RemittanceResult is the outcome of importing an 835 file
+type RemittanceResult struct {
+	Remittances []models.Remittance `json:"remittances"`
+	Postings    []RemittancePosting `json:"postings"`
+}
+
+// ClaimService generates 837P claim files and posts 835 remittances
+type ClaimService struct {
+	claimRepo        *repositories.ClaimRepository
+	billingRepo      *repositories.BillingRepository
+	encounterRepo    *repositories.EncounterRepository
+	patientRepo      *repositories.PatientRepository
+	problemRepo      *repositories.ProblemRepository
+	insuranceService *InsuranceService
+	billingService   *BillingService
+	storage          storage.BlobStorage
+	logger           *zap.Logger
+}
+
+// NewClaimService creates a new claim service instance
+func NewClaimService(
+	claimRepo *repositories.ClaimRepository,
+	billingRepo *repositories.BillingRepository,
+	encounterRepo *repositories.EncounterRepository,
+	patientRepo *repositories.PatientRepository,
+	problemRepo *repositories.ProblemRepository,
+	insuranceService *InsuranceService,
+	billingService *BillingService,
+	blobStorage storage.BlobStorage,
+	logger *zap.Logger,
+) *ClaimService {
+	return &ClaimService{
+		claimRepo:        claimRepo,
+		billingRepo:      billingRepo,
+		encounterRepo:    encounterRepo,
+		patientRepo:      patientRepo,
+		problemRepo:      problemRepo,
+		insuranceService: insuranceService,
+		billingService:   billingService,
+		storage:          blobStorage,
+		logger:           logger,
+	}
+}
+
+// GenerateBatch creates claims for finished encounters and writes them to
+// one 837P file. Every encounter must be claimable; the problems found
+// are reported together so the batch can be fixed in one pass.
+func (s *ClaimService) GenerateBatch(ctx context.Context, encounterIDs []uint, userID uint) (*models.ClaimBatch, error) {
+	if len(encounterIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one encounter is required", ErrInvalidInput)
+	}
+
+	batch := &models.ClaimBatch{CreatedByID: userID}
+	var claims []x12.Claim
+	var problems []string
+	seen := make(map[uint]bool)
+	for _, encounterID := range encounterIDs {
+		if seen[encounterID] {
+			continue
+		}
+		seen[encounterID] = true
+
+		record, claim, err := s.buildClaim(encounterID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("encounter %d: %v", encounterID, err))
+			continue
+		}
+		batch.Claims = append(batch.Claims, *record)
+		batch.TotalCharge += record.TotalCharge
+		claims = append(claims, *claim)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, strings.Join(problems, "; "))
+	}
+	batch.ClaimCount = len(batch.Claims)
+
+	// Checked again under lock, in case another batch claimed an
+	// encounter meanwhile
+	_, err := s.claimRepo.CreateBatch(batch, func(encounterID uint, openClaims int64) error {
+		if openClaims > 0 {
+			return fmt.Errorf("%w: encounter %d was already claimed", ErrConflict, encounterID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range claims {
+		claims[i].ControlNumber = batch.Claims[i].ControlNumber
+	}
+
+	var file bytes.Buffer
+	err = x12.Write837P(&file, x12.Batch837{
+		Sender:          claimParty("billing.claims.sender"),
+		Receiver:        claimParty("billing.claims.receiver"),
+		Provider:        claimProvider(),
+		ControlNumber:   int(batch.ID),
+		ReferenceID:     fmt.Sprintf("BATCH%d", batch.ID),
+		CreatedAt:       batch.CreatedAt,
+		ProductionUsage: viper.GetBool("billing.claims.production"),
+		Claims:          claims,
+	})
+	if err == nil {
+		batch.FileName = fmt.Sprintf("837P-%06d.x12", batch.ID)
+		batch.StorageKey = "claims/" + batch.FileName
+		err = s.storeFile(ctx, batch.StorageKey, file.Bytes())
+	}
+	if err == nil {
+		err = s.claimRepo.UpdateBatch(batch)
+	}
+	if err != nil {
+		s.logger.Error("Failed to write claim file", zap.Error(err), zap.Uint("batch_id", batch.ID))
+		if deleteErr := s.claimRepo.DeleteBatch(batch.ID); deleteErr != nil {
+			s.logger.Error("Failed to remove incomplete claim batch", zap.Error(deleteErr), zap.Uint("batch_id", batch.ID))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Claim batch generated", zap.Uint("batch_id", batch.ID), zap.Int("claims", batch.ClaimCount))
+	return batch, nil
+}
+
+// GetBatch retrieves a claim batch with its claims
+func (s *ClaimService) GetBatch(id uint) (*models.ClaimBatch, error) {
+	return s.claimRepo.FindBatchByID(id)
+}
+
+// OpenBatchFile opens the 837P file of a batch
+func (s *ClaimService) OpenBatchFile(ctx context.Context, id uint) (*models.ClaimBatch, io.ReadCloser, error) {
+	batch, err := s.claimRepo.FindBatchByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := s.storage.Get(ctx, batch.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, file, nil
+}
+
+// GetClaims lists claims, optionally filtered by status and patient
+func (s *ClaimService) GetClaims(status string, patientID uint) ([]models.InsuranceClaim, error) {
+	return s.claimRepo.FindClaims(status, patientID)
+}
+
+// ImportRemittance parses an 835 file and posts its payments and
+// adjustments. Claim payments go to the invoice carrying the encounter's
+// charges; contractual (CO), other (OA) and payer initiated (PI)
+// adjustments are written off, patient responsibility (PR) stays on the
+// balance. The file is posted in one transaction, so a file that fails
+// part way can be imported again.
+func (s *ClaimService) ImportRemittance(ctx context.Context, fileName string, content []byte, userID uint) (*RemittanceResult, error) {
+	interchange, err := x12.Parse(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	remittances, err := x12.Parse835(interchange)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	for i, remittance := range remittances {
+		if remittance.TraceNumber == "" {
+			return nil, fmt.Errorf("%w: remittance %d has no TRN trace number", ErrInvalidInput, i+1)
+		}
+		exists, err := s.claimRepo.ExistsRemittanceTrace(remittance.TraceNumber)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("%w: remittance %s was already imported", ErrConflict, remittance.TraceNumber)
+		}
+	}
+
+	sum := sha256.Sum256(content)
+	storageKey := "remittances/" + hex.EncodeToString(sum[:]) + ".x12"
+	if err := s.storeFile(ctx, storageKey, content); err != nil {
+		return nil, err
+	}
+
+	var result *RemittanceResult
+	err = s.claimRepo.Transaction(func(claimRepo *repositories.ClaimRepository, billingRepo *repositories.BillingRepository) error {
+		result = &RemittanceResult{}
+		bound := *s
+		bound.claimRepo = claimRepo
+		bound.billingRepo = billingRepo
+		bound.billingService = s.billingService.withRepository(billingRepo)
+		return bound.postRemittances(remittances, fileName, storageKey, userID, result)
+	})
+	if err != nil {
+		s.logger.Error("Failed to import remittance file", zap.Error(err), zap.String("file_name", fileName))
+		return nil, err
+	}
+
+	for _, record := range result.Remittances {
+		s.logger.Info("Remittance imported",
+			zap.String("trace_number", record.TraceNumber),
+			zap.Int("matched", record.ClaimsMatched),
+			zap.Int("unmatched", record.ClaimsUnmatched),
+		)
+	}
+	return result, nil
+}
+
+// postRemittances records the remittances of a file and posts their claim
+// payments
+func (s *ClaimService) postRemittances(remittances []x12.Remittance, fileName, storageKey string, userID uint, result *RemittanceResult) error {
+	for _, remittance := range remittances {
+		record, err := s.claimRepo.CreateRemittance(&models.Remittance{
+			FileName:     fileName,
+			StorageKey:   storageKey,
+			TraceNumber:  remittance.TraceNumber,
+			PayerName:    remittance.PayerName,
+			PaymentDate:  remittance.PaymentDate,
+			TotalPaid:    remittance.TotalPaid,
+			ImportedByID: userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, payment := range remittance.Claims {
+			posting, err := s.postClaimPayment(record, payment, userID)
+			if err != nil {
+				return fmt.Errorf("claim %s: %w", payment.ControlNumber, err)
+			}
+			if posting.ClaimID == 0 {
+				record.ClaimsUnmatched++
+			} else {
+				record.ClaimsMatched++
+			}
+			result.Postings = append(result.Postings, posting)
+		}
+		if err := s.claimRepo.UpdateRemittance(record); err != nil {
+			return err
+		}
+		result.Remittances = append(result.Remittances, *record)
+	}
+	return nil
+}
+
+// GetRemittance retrieves a remittance with the claims it adjudicated and
+// their adjustments
+func (s *ClaimService) GetRemittance(id uint) (*models.Remittance, []models.InsuranceClaim, []models.ClaimAdjustment, error) {
+	remittance, err := s.claimRepo.FindRemittanceByID(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	claims, err := s.claimRepo.FindClaimsByRemittance(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	adjustments, err := s.claimRepo.FindAdjustmentsByRemittance(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return remittance, claims, adjustments, nil
+}
+
+// postClaimPayment applies one CLP loop. A payment that cannot be matched
+// or posted to an invoice is reported in the posting rather than aborting
+// the file, since the payer has already paid; a database failure aborts it.
+func (s *ClaimService) postClaimPayment(remittance *models.Remittance, payment x12.ClaimPayment, userID uint) (RemittancePosting, error) {
+	posting := RemittancePosting{ControlNumber: payment.ControlNumber, Status: "unmatched", Paid: payment.Paid}
+	claim, err := s.claimRepo.FindClaimByControlNumber(payment.ControlNumber)
+	if err != nil {
+		posting.Note = "no claim with this control number"
+		return posting, nil
+	}
+	posting.ClaimID = claim.ID
+
+	var adjustments []models.ClaimAdjustment
+	var writeOff int64
+	for _, adjustment := range payment.Adjustments {
+		adjustments = append(adjustments, models.ClaimAdjustment{
+			ClaimID:      claim.ID,
+			RemittanceID: remittance.ID,
+			GroupCode:    adjustment.GroupCode,
+			ReasonCode:   adjustment.ReasonCode,
+			Amount:       adjustment.Amount,
+		})
+		if adjustment.GroupCode != "PR" {
+			writeOff += adjustment.Amount
+		}
+	}
+
+	now := time.Now()
+	// A later remittance for the same claim, such as a reversal or a
+	// correction, adds to what earlier ones reported
+	claim.PaidAmount += payment.Paid
+	claim.AdjustmentAmount += writeOff
+	claim.PatientResponsibility += payment.PatientResponsibility
+	claim.PayerClaimNumber = payment.PayerClaimNumber
+	claim.RemittanceID = &remittance.ID
+	claim.AdjudicatedAt = &now
+	// Only the payer's status code denies a claim; one that pays nothing
+	// because the patient owes it all, e.g. to the deductible, is processed
+	switch {
+	case payment.StatusCode == x12.ClaimStatusDenied:
+		claim.Status = models.ClaimStatusDenied
+	case claim.PaidAmount+claim.AdjustmentAmount+claim.PatientResponsibility >= claim.TotalCharge:
+		claim.Status = models.ClaimStatusPaid
+	default:
+		claim.Status = models.ClaimStatusPartiallyPaid
+	}
+	posting.Status = claim.Status
+	if err := s.claimRepo.UpdateClaim(claim, adjustments); err != nil {
+		s.logger.Error("Failed to update claim from remittance", zap.Error(err), zap.Uint("claim_id", claim.ID))
+		return posting, err
+	}
+
+	invoice, err := s.billingRepo.FindInvoiceByEncounter(claim.EncounterID)
+	if err != nil {
+		return posting, err
+	}
+	if invoice == nil {
+		posting.Note = "encounter charges are not invoiced; post the payment once they are"
+		return posting, nil
+	}
+	posting.InvoiceID = invoice.ID
+
+	var notes []string
+	if payment.Paid > 0 {
+		amount := minAmount(payment.Paid, invoice.Balance)
+		if amount < payment.Paid {
+			notes = append(notes, fmt.Sprintf("payment exceeds the invoice balance by %d", payment.Paid-amount))
+		}
+		if amount > 0 {
+			updated, err := s.billingService.RecordPayment(invoice.ID, PaymentInput{
+				Kind:       models.PaymentKindPayment,
+				Method:     models.PaymentMethodInsurance,
+				Amount:     amount,
+				Reference:  remittance.TraceNumber,
+				Notes:      "835 remittance for claim " + claim.ControlNumber,
+				ReceivedAt: remittance.PaymentDate,
+			}, userID)
+			if err != nil {
+				if !isPostingRefusal(err) {
+					return posting, err
+				}
+				notes = append(notes, "payment not posted: "+err.Error())
+			} else {
+				invoice = updated
+			}
+		}
+	}
+	if writeOff > 0 {
+		amount := minAmount(writeOff, invoice.Balance)
+		if amount > 0 {
+			if _, err := s.billingService.RecordPayment(invoice.ID, PaymentInput{
+				Kind:       models.PaymentKindAdjustment,
+				Method:     models.PaymentMethodInsurance,
+				Amount:     amount,
+				Reference:  remittance.TraceNumber,
+				Notes:      "835 adjustments for claim " + claim.ControlNumber,
+				ReceivedAt: remittance.PaymentDate,
+			}, userID); err != nil {
+				if !isPostingRefusal(err) {
+					return posting, err
+				}
+				notes = append(notes, "adjustment not posted: "+err.Error())
+			} else {
+				posting.Adjusted = amount
+			}
+		}
+	}
+	posting.Note = strings.Join(notes, "; ")
+	return posting, nil
+}
+
+// isPostingRefusal reports whether a payment was refused by the billing
+// rules, e.g. because the invoice is voided, rather than by a failure
+func isPostingRefusal(err error) bool {
+	return errors.Is(err, ErrConflict) || errors.Is(err, ErrInvalidInput)
+}
+
+// buildClaim collects the claim record and 837P content of an encounter
+func (s *ClaimService) buildClaim(encounterID uint) (*models.InsuranceClaim, *x12.Claim, error) {
+	encounter, err := s.encounterRepo.FindByID(encounterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if encounter.Status != models.EncounterStatusFinished {
+		return nil, nil, errors.New("encounter is not finished")
+	}
+	if open, err := s.claimRepo.CountOpenClaimsByEncounter(encounterID); err != nil {
+		return nil, nil, err
+	} else if open > 0 {
+		return nil, nil, errors.New("encounter was already claimed")
+	}
+	patient, err := s.patientRepo.FindByID(encounter.PatientID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coverages, err := s.insuranceService.CoverageOn(patient.ID, encounter.StartedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(coverages) == 0 {
+		return nil, nil, errors.New("patient has no coverage on the date of service")
+	}
+	coverage := coverages[0]
+	if coverage.Payer == nil || coverage.Payer.EDIPayerID == "" {
+		return nil, nil, fmt.Errorf("payer %s has no electronic payer ID", coverage.PayerCode)
+	}
+
+	diagnoses, err := s.claimDiagnoses(encounter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	charges, err := s.billingRepo.FindChargesByEncounter(encounterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pointers []int
+	for i := range diagnoses {
+		if i == 4 {
+			break // SV107 points at no more than four diagnoses
+		}
+		pointers = append(pointers, i+1)
+	}
+	claim := &x12.Claim{
+		PlaceOfService:  placesOfService[encounter.Type],
+		Diagnoses:       diagnoses,
+		PayerName:       coverage.Payer.Name,
+		PayerID:         coverage.Payer.EDIPayerID,
+		ClaimFilingCode: coverage.Payer.ClaimFilingCode,
+		GroupNumber:     coverage.GroupNumber,
+		Relationship:    subscriberRelationshipCodes[coverage.SubscriberRelationship],
+	}
+	for _, charge := range charges {
+		if charge.Status != models.ChargeStatusPosted {
+			continue
+		}
+		if charge.CPTCode == "" {
+			return nil, nil, fmt.Errorf("charge %d (%s) has no CPT code", charge.ID, charge.ServiceCode)
+		}
+		claim.Lines = append(claim.Lines, x12.ServiceLine{
+			ProcedureCode:     charge.CPTCode,
+			Charge:            charge.Amount,
+			Units:             charge.Quantity,
+			ServiceDate:       charge.ServiceDate,
+			DiagnosisPointers: pointers,
+			LineControlNumber: fmt.Sprint(charge.ID),
+		})
+		claim.TotalCharge += charge.Amount
+	}
+	if len(claim.Lines) == 0 {
+		return nil, nil, errors.New("encounter has no posted charges")
+	}
+
+	patientPerson := claimPerson(patient.Name, patient.Address)
+	patientPerson.DateOfBirth = patient.DateOfBirth
+	patientPerson.Gender = claimGender(patient.Gender)
+	if coverage.SubscriberRelationship == models.SubscriberRelationshipSelf {
+		claim.Subscriber = patientPerson
+	} else {
+		claim.Subscriber = claimPerson(coverage.SubscriberName, models.Address{})
+		claim.Patient = &patientPerson
+	}
+	claim.Subscriber.MemberID = coverage.MemberID
+
+	record := &models.InsuranceClaim{
+		EncounterID: encounter.ID,
+		PatientID:   patient.ID,
+		CoverageID:  coverage.ID,
+		PayerCode:   coverage.PayerCode,
+		TotalCharge: claim.TotalCharge,
+		Status:      models.ClaimStatusSubmitted,
+	}
+	return record, claim, nil
+}
+
+// claimDiagnoses lists the ICD-10 codes of the problems recorded during
+// an encounter, falling back to the patient's active problem list
+func (s *ClaimService) claimDiagnoses(encounter *models.Encounter) ([]string, error) {
+	problems, err := s.problemRepo.FindByEncounter(encounter.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) == 0 {
+		if problems, err = s.problemRepo.FindByPatient(encounter.PatientID, models.ProblemStatusActive); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].IsPrimary && !problems[j].IsPrimary })
+
+	var codes []string
+	for _, problem := range problems {
+		if len(codes) == maxClaimDiagnoses {
+			break
+		}
+		if !contains(codes, problem.ICD10Code) {
+			codes = append(codes, problem.ICD10Code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("no diagnoses recorded for the encounter or on the problem list")
+	}
+	return codes, nil
+}
+
+func (s *ClaimService) storeFile(ctx context.Context, key string, content []byte) error {
+	sum := sha256.Sum256(content)
+	return s.storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/edi-x12", hex.EncodeToString(sum[:]))
+}
+
+// claimGender maps a patient's gender to the DMG03 code
+func claimGender(gender string) string {
+	switch gender {
+	case "male":
+		return "M"
+	case "female":
+		return "F"
+	default:
+		return "U"
+	}
+}
+
+// claimPerson splits a full name into first and last name; the last word
+// is taken as the last name
+func claimPerson(name string, address models.Address) x12.Person {
+	fields := strings.Fields(name)
+	person := x12.Person{
+		Address: x12.Address{
+			Line1:      address.Line1,
+			Line2:      address.Line2,
+			City:       address.City,
+			State:      address.Region,
+			PostalCode: address.PostalCode,
+			Country:    address.Country,
+		},
+	}
+	if len(fields) > 0 {
+		person.LastName = fields[len(fields)-1]
+		person.FirstName = strings.Join(fields[:len(fields)-1], " ")
+	}
+	return person
+}
+
+func claimParty(key string) x12.Party {
+	return x12.Party{
+		Name:         viper.GetString(key + ".name"),
+		ID:           viper.GetString(key + ".id"),
+		ContactName:  viper.GetString(key + ".contact_name"),
+		ContactPhone: viper.GetString(key + ".contact_phone"),
+	}
+}
+
+func claimProvider() x12.Provider {
+	return x12.Provider{
+		Name:  viper.GetString("billing.claims.provider.name"),
+		NPI:   viper.GetString("billing.claims.provider.npi"),
+		TaxID: viper.GetString("billing.claims.provider.tax_id"),
+		Address: x12.Address{
+			Line1:      viper.GetString("billing.claims.provider.address.line1"),
+			City:       viper.GetString("billing.claims.provider.address.city"),
+			State:      viper.GetString("billing.claims.provider.address.state"),
+			PostalCode: viper.GetString("billing.claims.provider.address.postal_code"),
+		},
+	}
+}
+
+func minAmount(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var consentTypes = []string{
+	models.ConsentTypeDataSharing,
+	models.ConsentTypeResearch,
+	models.ConsentTypeSMSContact,
+	models.ConsentTypeTreatment,
+}
+
+// ConsentInput holds the details of a consent decision
+type ConsentInput struct {
+	Type          string
+	Version       string
+	WitnessName   string
+	WitnessUserID *uint
+	DocumentID    *uint
+	Notes         string
+}
+
+// ConsentService records patient consents and answers whether an action
+// is permitted by them
+type ConsentService struct {
+	consentRepo *repositories.ConsentRepository
+	patientRepo *repositories.PatientRepository
+	logger      *zap.Logger
+}
+
+// NewConsentService creates a new consent service instance
+func NewConsentService(consentRepo *repositories.ConsentRepository, patientRepo *repositories.PatientRepository, logger *zap.Logger) *ConsentService {
+	return &ConsentService{
+		consentRepo: consentRepo,
+		patientRepo: patientRepo,
+		logger:      logger,
+	}
+}
+
+// GetConsents retrieves a patient's consent history and the current
+// status of each consent type ("unknown" when never recorded)
+func (s *ConsentService) GetConsents(patientID uint) ([]models.PatientConsent, map[string]string, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, nil, err
+	}
+	history, err := s.consentRepo.FindByPatient(patientID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := make(map[string]string, len(consentTypes))
+	for _, consentType := range consentTypes {
+		current[consentType] = "unknown"
+	}
+	// History is newest first, so the first record of each type wins
+	seen := make(map[string]bool)
+	for _, consent := range history {
+		if !seen[consent.Type] {
+			seen[consent.Type] = true
+			current[consent.Type] = consent.Status
+		}
+	}
+	return history, current, nil
+}
+
+// Grant records that a patient has given a consent
+func (s *ConsentService) Grant(patientID uint, input ConsentInput, recordedByID uint) (*models.PatientConsent, error) {
+	if err := validateConsentInput(input); err != nil {
+		return nil, err
+	}
+	if input.Version == "" {
+		return nil, fmt.Errorf("%w: version is required", ErrInvalidInput)
+	}
+	if input.Type == models.ConsentTypeTreatment && input.WitnessName == "" && input.WitnessUserID == nil {
+		return nil, fmt.Errorf("%w: treatment consent must be witnessed", ErrInvalidInput)
+	}
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.consentRepo.FindCurrent(patientID, input.Type)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.Status == models.ConsentStatusGranted && current.Version == input.Version {
+		return nil, fmt.Errorf("%w: %s consent version %s is already granted", ErrConflict, input.Type, input.Version)
+	}
+
+	now := time.Now()
+	return s.consentRepo.Create(&models.PatientConsent{
+		PatientID:     patientID,
+		Type:          input.Type,
+		Version:       input.Version,
+		Status:        models.ConsentStatusGranted,
+		GrantedAt:     &now,
+		WitnessName:   input.WitnessName,
+		WitnessUserID: input.WitnessUserID,
+		DocumentID:    input.DocumentID,
+		RecordedByID:  recordedByID,
+		Notes:         input.Notes,
+	})
+}
+
+// Withdraw records that a patient has withdrawn (or refused) a consent
+func (s *ConsentService) Withdraw(patientID uint, input ConsentInput, recordedByID uint) (*models.PatientConsent, error) {
+	if err := validateConsentInput(input); err != nil {
+		return nil, err
+	}
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+
+	current, err := s.consentRepo.FindCurrent(patientID, input.Type)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.Status == models.ConsentStatusWithdrawn {
+		return nil, fmt.Errorf("%w: %s consent is already withdrawn", ErrConflict, input.Type)
+	}
+
+	now := time.Now()
+	if current != nil {
+		current.Status = models.ConsentStatusWithdrawn
+		current.WithdrawnAt = &now
+		if input.Notes != "" {
+			current.Notes = input.Notes
+		}
+		return s.consentRepo.Update(current)
+	}
+
+	// Nothing granted yet: record the refusal
+	return s.consentRepo.Create(&models.PatientConsent{
+		PatientID:     patientID,
+		Type:          input.Type,
+		Version:       input.Version,
+		Status:        models.ConsentStatusWithdrawn,
+		WithdrawnAt:   &now,
+		WitnessName:   input.WitnessName,
+		WitnessUserID: input.WitnessUserID,
+		DocumentID:    input.DocumentID,
+		RecordedByID:  recordedByID,
+		Notes:         input.Notes,
+	})
+}
+
+// IsGranted reports whether the patient's current decision for a consent
+// type is a grant. Opt-in uses such as SMS contact depend on this.
+func (s *ConsentService) IsGranted(patientID uint, consentType string) (bool, error) {
+	current, err := s.consentRepo.FindCurrent(patientID, consentType)
+	if err != nil {
+		return false, err
+	}
+	return current != nil && current.Status == models.ConsentStatusGranted, nil
+}
+
+// IsWithdrawn reports whether the patient's current decision for a consent
+// type is a withdrawal. Uses that go ahead until a patient opts out, such
+// as data sharing with other systems, depend on this.
+func (s *ConsentService) IsWithdrawn(patientID uint, consentType string) (bool, error) {
+	current, err := s.consentRepo.FindCurrent(patientID, consentType)
+	if err != nil {
+		return false, err
+	}
+	return current != nil && current.Status == models.ConsentStatusWithdrawn, nil
+}
+
+// WithdrawnPatientIDs returns the set of patients who have withdrawn a consent type
+func (s *ConsentService) WithdrawnPatientIDs(consentType string) (map[uint]bool, error) {
+	ids, err := s.consentRepo.FindPatientIDsWithCurrentStatus(consentType, models.ConsentStatusWithdrawn)
+	if err != nil {
+		s.logger.Error("Failed to fetch withdrawn consents", zap.Error(err), zap.String("type", consentType))
+		return nil, err
+	}
+	withdrawn := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		withdrawn[id] = true
+	}
+	return withdrawn, nil
+}
+
+func validateConsentInput(input ConsentInput) error {
+	if !contains(consentTypes, input.Type) {
+		return fmt.Errorf("%w: type must be one of %v", ErrInvalidInput, consentTypes)
+	}
+	return nil
+}
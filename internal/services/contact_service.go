@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// SMSSender delivers text messages to patients
+type SMSSender interface {
+	Send(phoneNumber, message string) error
+}
+
+// LogSMSSender is an SMSSender that only logs messages. It stands in for
+// a real SMS gateway in development.
+type LogSMSSender struct {
+	logger *zap.Logger
+}
+
+// NewLogSMSSender creates a new logging SMS sender
+func NewLogSMSSender(logger *zap.Logger) *LogSMSSender {
+	return &LogSMSSender{logger: logger}
+}
+
+// Send logs the message instead of sending it
+func (s *LogSMSSender) Send(phoneNumber, message string) error {
+	s.logger.Info("SMS message", zap.String("to", phoneNumber), zap.Int("length", len(message)))
+	return nil
+}
+
+// ContactService sends messages to patients, respecting their contact
+// preferences
+type ContactService struct {
+	patientRepo    *repositories.PatientRepository
+	consentService *ConsentService
+	sms            SMSSender
+	logger         *zap.Logger
+}
+
+// NewContactService creates a new contact service instance
+func NewContactService(patientRepo *repositories.PatientRepository, consentService *ConsentService, sms SMSSender, logger *zap.Logger) *ContactService {
+	return &ContactService{
+		patientRepo:    patientRepo,
+		consentService: consentService,
+		sms:            sms,
+		logger:         logger,
+	}
+}
+
+// SendSMS texts a patient who has granted SMS contact consent
+func (s *ContactService) SendSMS(patientID uint, message string) error {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return err
+	}
+
+	granted, err := s.consentService.IsGranted(patientID, models.ConsentTypeSMSContact)
+	if err != nil {
+		return err
+	}
+	if !granted {
+		s.logger.Info("SMS suppressed by contact preferences", zap.Uint("patient_id", patientID))
+		return fmt.Errorf("%w: patient has not consented to SMS contact", ErrConflict)
+	}
+
+	if err := s.sms.Send(patient.PhoneNumber, message); err != nil {
+		s.logger.Error("Failed to send SMS", zap.Error(err), zap.Uint("patient_id", patientID))
+		return err
+	}
+	return nil
+}
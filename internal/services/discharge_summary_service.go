@@ -0,0 +1,455 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/pdf"
+	"hospital-portal/internal/repositories"
+)
+
+var summarySections = []string{models.SummarySectionDiagnosis, models.SummarySectionProcedure, models.SummarySectionMedication}
+
+// DischargeSummaryEdit is a doctor's change to a draft summary. Nil
+// fields are left unchanged; Items replaces every item when set.
+type DischargeSummaryEdit struct {
+	HospitalCourse *string
+	FollowUp       *string
+	Items          []models.DischargeSummaryItem
+}
+
+// DischargeSummaryService drafts, edits, signs and prints discharge summaries
+type DischargeSummaryService struct {
+	summaryRepo      *repositories.DischargeSummaryRepository
+	admissionRepo    *repositories.AdmissionRepository
+	patientRepo      *repositories.PatientRepository
+	encounterRepo    *repositories.EncounterRepository
+	problemRepo      *repositories.ProblemRepository
+	billingRepo      *repositories.BillingRepository
+	prescriptionRepo *repositories.PrescriptionRepository
+	userRepo         *repositories.UserRepository
+	logger           *zap.Logger
+}
+
+// NewDischargeSummaryService creates a new discharge summary service instance
+func NewDischargeSummaryService(
+	summaryRepo *repositories.DischargeSummaryRepository,
+	admissionRepo *repositories.AdmissionRepository,
+	patientRepo *repositories.PatientRepository,
+	encounterRepo *repositories.EncounterRepository,
+	problemRepo *repositories.ProblemRepository,
+	billingRepo *repositories.BillingRepository,
+	prescriptionRepo *repositories.PrescriptionRepository,
+	userRepo *repositories.UserRepository,
+	logger *zap.Logger,
+) *DischargeSummaryService {
+	return &DischargeSummaryService{
+		summaryRepo:      summaryRepo,
+		admissionRepo:    admissionRepo,
+		patientRepo:      patientRepo,
+		encounterRepo:    encounterRepo,
+		problemRepo:      problemRepo,
+		billingRepo:      billingRepo,
+		prescriptionRepo: prescriptionRepo,
+		userRepo:         userRepo,
+		logger:           logger,
+	}
+}
+
+// GetSummary retrieves the summary of an admission
+func (s *DischargeSummaryService) GetSummary(admissionID uint) (*models.DischargeSummary, error) {
+	summary, err := s.summaryRepo.FindByAdmission(admissionID)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		return nil, fmt.Errorf("no discharge summary for admission %d", admissionID)
+	}
+	return summary, nil
+}
+
+// Draft assembles a summary from the records of a discharged stay: the
+// diagnoses recorded on its encounters, the procedures charged, the
+// prescriptions active at discharge and the encounter reasons as a
+// starting point for the hospital course. Drafting again rebuilds an
+// unsigned summary from the records and drops manual edits.
+func (s *DischargeSummaryService) Draft(admissionID uint, userID uint) (*models.DischargeSummary, error) {
+	admission, err := s.admissionRepo.FindByID(admissionID)
+	if err != nil {
+		return nil, err
+	}
+	if admission.Status != models.AdmissionStatusDischarged || admission.DischargedAt == nil {
+		return nil, fmt.Errorf("%w: the patient has not been discharged yet", ErrConflict)
+	}
+
+	draft, err := s.assemble(admission)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.summaryRepo.FindByAdmission(admissionID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		draft.CreatedByID = userID
+		summary, err := s.summaryRepo.Create(draft)
+		if err != nil {
+			return nil, err
+		}
+		s.logger.Info("Discharge summary drafted", zap.Uint("summary_id", summary.ID), zap.Uint("admission_id", admissionID))
+		return summary, nil
+	}
+
+	draft.ID = existing.ID
+	draft.CreatedByID = existing.CreatedByID
+	draft.CreatedAt = existing.CreatedAt
+	items := draft.Items
+	draft.Items = nil
+	summary, err := s.summaryRepo.Update(draft, items, requireDraft)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Discharge summary redrafted", zap.Uint("summary_id", summary.ID), zap.Uint("admission_id", admissionID))
+	return summary, nil
+}
+
+// Edit changes the narrative or items of an unsigned summary
+func (s *DischargeSummaryService) Edit(admissionID uint, edit DischargeSummaryEdit) (*models.DischargeSummary, error) {
+	summary, err := s.GetSummary(admissionID)
+	if err != nil {
+		return nil, err
+	}
+	if edit.HospitalCourse != nil {
+		summary.HospitalCourse = strings.TrimSpace(*edit.HospitalCourse)
+	}
+	if edit.FollowUp != nil {
+		summary.FollowUp = strings.TrimSpace(*edit.FollowUp)
+	}
+	if edit.Items != nil {
+		if err := normalizeSummaryItems(edit.Items); err != nil {
+			return nil, err
+		}
+	}
+	summary.Items = nil
+	return s.summaryRepo.Update(summary, edit.Items, requireDraft)
+}
+
+// Sign freezes a summary. It needs at least one diagnosis and follow-up
+// instructions; the signed content is fingerprinted so later changes made
+// outside the application can be detected.
+func (s *DischargeSummaryService) Sign(admissionID uint, doctorID uint) (*models.DischargeSummary, error) {
+	summary, err := s.GetSummary(admissionID)
+	if err != nil {
+		return nil, err
+	}
+	hasDiagnosis := false
+	for _, item := range summary.Items {
+		if item.Section == models.SummarySectionDiagnosis {
+			hasDiagnosis = true
+			break
+		}
+	}
+	if !hasDiagnosis {
+		return nil, fmt.Errorf("%w: a discharge summary needs at least one diagnosis", ErrInvalidInput)
+	}
+	if summary.FollowUp == "" {
+		return nil, fmt.Errorf("%w: follow-up instructions are required before signing", ErrInvalidInput)
+	}
+
+	hash, err := summaryContentHash(summary)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	summary.Status = models.DischargeSummaryStatusSigned
+	summary.SignedAt = &now
+	summary.SignedByID = &doctorID
+	summary.ContentHash = hash
+	summary.Items = nil
+
+	signed, err := s.summaryRepo.Update(summary, nil, requireDraft)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Discharge summary signed", zap.Uint("summary_id", signed.ID), zap.Uint("signed_by", doctorID))
+	return signed, nil
+}
+
+// WritePDF renders a signed summary as PDF
+func (s *DischargeSummaryService) WritePDF(admissionID uint, w io.Writer) (*models.DischargeSummary, error) {
+	summary, err := s.GetSummary(admissionID)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Status != models.DischargeSummaryStatusSigned {
+		return nil, fmt.Errorf("%w: only signed discharge summaries can be exported", ErrConflict)
+	}
+	patient, err := s.patientRepo.FindByID(summary.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	attending := s.userName(summary.AttendingDoctorID)
+	signer := ""
+	if summary.SignedByID != nil {
+		signer = s.userName(*summary.SignedByID)
+	}
+
+	doc := pdf.New()
+	doc.Title = "Discharge summary - " + patient.Name
+	doc.Author = signer
+	layout := pdf.NewLayout(doc, pdf.A4, 50)
+	layout.Footer = fmt.Sprintf("%s - discharge summary %d", viper.GetString("hospital.name"), summary.ID)
+
+	layout.Paragraph(pdf.HelveticaBold, 14, viper.GetString("hospital.name"))
+	layout.Paragraph(pdf.Helvetica, 9, viper.GetString("hospital.address"))
+	layout.Rule()
+	layout.Heading("Discharge summary", 16)
+	layout.Field("Patient", fmt.Sprintf("%s (ID %d)", patient.Name, patient.ID), 10)
+	layout.Field("Admitted", summary.AdmittedAt.Format("2006-01-02 15:04"), 10)
+	layout.Field("Discharged", summary.DischargedAt.Format("2006-01-02 15:04"), 10)
+	layout.Field("Disposition", summary.Disposition, 10)
+	layout.Field("Attending", attending, 10)
+	if summary.AdmissionReason != "" {
+		layout.Field("Reason for admission", summary.AdmissionReason, 10)
+	}
+
+	titles := map[string]string{
+		models.SummarySectionDiagnosis:  "Diagnoses",
+		models.SummarySectionProcedure:  "Procedures",
+		models.SummarySectionMedication: "Medications on discharge",
+	}
+	for _, section := range summarySections {
+		layout.Heading(titles[section], 12)
+		written := false
+		for _, item := range summary.Items {
+			if item.Section != section {
+				continue
+			}
+			layout.Bullet(summaryItemLine(item), 10)
+			written = true
+		}
+		if !written {
+			layout.Paragraph(pdf.Helvetica, 10, "None")
+		}
+	}
+
+	layout.Heading("Hospital course", 12)
+	layout.Paragraph(pdf.Helvetica, 10, orNone(summary.HospitalCourse))
+	layout.Heading("Follow-up", 12)
+	layout.Paragraph(pdf.Helvetica, 10, orNone(summary.FollowUp))
+
+	layout.Space(10)
+	layout.Rule()
+	layout.Paragraph(pdf.Helvetica, 9, fmt.Sprintf("Electronically signed by %s on %s. Content SHA-256 %s",
+		signer, summary.SignedAt.Format("2006-01-02 15:04 MST"), summary.ContentHash))
+
+	if _, err := doc.WriteTo(w); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// assemble builds an unsaved draft from the records of a stay
+func (s *DischargeSummaryService) assemble(admission *models.Admission) (*models.DischargeSummary, error) {
+	encounters, err := s.encounterRepo.FindByPatientBetween(admission.PatientID, admission.AdmittedAt, *admission.DischargedAt)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, encounter := range encounters {
+		if encounter.ID == admission.EncounterID {
+			found = true
+		}
+	}
+	if !found {
+		encounter, err := s.encounterRepo.FindByID(admission.EncounterID)
+		if err != nil {
+			return nil, err
+		}
+		encounters = append([]models.Encounter{*encounter}, encounters...)
+	}
+
+	summary := &models.DischargeSummary{
+		AdmissionID:       admission.ID,
+		PatientID:         admission.PatientID,
+		AttendingDoctorID: admission.AttendingDoctorID,
+		Status:            models.DischargeSummaryStatusDraft,
+		AdmittedAt:        admission.AdmittedAt,
+		DischargedAt:      *admission.DischargedAt,
+		Disposition:       admission.Disposition,
+		AdmissionReason:   admission.Reason,
+	}
+
+	var course []string
+	var problems []models.Problem
+	for _, encounter := range encounters {
+		if encounter.Reason != "" && encounter.ID != admission.EncounterID {
+			course = append(course, fmt.Sprintf("%s (%s): %s", encounter.StartedAt.Format("2006-01-02"), encounter.Type, encounter.Reason))
+		}
+		encounterProblems, err := s.problemRepo.FindByEncounter(encounter.ID)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, encounterProblems...)
+
+		charges, err := s.billingRepo.FindChargesByEncounter(encounter.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, charge := range charges {
+			if charge.Status != models.ChargeStatusPosted || charge.CPTCode == "" {
+				continue
+			}
+			summary.Items = append(summary.Items, models.DischargeSummaryItem{
+				Section:     models.SummarySectionProcedure,
+				Code:        charge.CPTCode,
+				Description: charge.Description,
+				Detail:      charge.ServiceDate.Format("2006-01-02"),
+			})
+		}
+	}
+	summary.HospitalCourse = strings.Join(course, "\n")
+
+	// Diagnoses recorded during the stay, falling back to the active
+	// problem list; the primary diagnosis goes first
+	if len(problems) == 0 {
+		if problems, err = s.problemRepo.FindByPatient(admission.PatientID, models.ProblemStatusActive); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].IsPrimary && !problems[j].IsPrimary })
+	var codes []string
+	for _, problem := range problems {
+		if contains(codes, problem.ICD10Code) {
+			continue
+		}
+		codes = append(codes, problem.ICD10Code)
+		detail := problem.Status
+		if problem.IsPrimary {
+			detail = "primary, " + detail
+		}
+		summary.Items = append(summary.Items, models.DischargeSummaryItem{
+			Section:     models.SummarySectionDiagnosis,
+			Code:        problem.ICD10Code,
+			Description: problem.Description,
+			Detail:      detail,
+		})
+	}
+
+	prescriptions, err := s.prescriptionRepo.FindByPatient(admission.PatientID, models.PrescriptionStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	for _, prescription := range prescriptions {
+		summary.Items = append(summary.Items, models.DischargeSummaryItem{
+			Section:     models.SummarySectionMedication,
+			Code:        prescription.DrugCode,
+			Description: prescription.Drug,
+			Detail:      strings.TrimSpace(fmt.Sprintf("%s %s %s. %s", prescription.Strength, prescription.Route, prescription.Frequency, prescription.Instructions)),
+		})
+	}
+
+	if err := normalizeSummaryItems(summary.Items); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+func (s *DischargeSummaryService) userName(id uint) string {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		return fmt.Sprintf("user %d", id)
+	}
+	return user.Name
+}
+
+// normalizeSummaryItems validates items and numbers them within their section
+func normalizeSummaryItems(items []models.DischargeSummaryItem) error {
+	positions := make(map[string]int)
+	for i := range items {
+		item := &items[i]
+		item.Description = strings.TrimSpace(item.Description)
+		if !contains(summarySections, item.Section) {
+			return fmt.Errorf("%w: item section must be one of %v", ErrInvalidInput, summarySections)
+		}
+		if item.Description == "" && item.Code == "" {
+			return fmt.Errorf("%w: item %d needs a description or code", ErrInvalidInput, i+1)
+		}
+		if item.Description == "" {
+			item.Description = item.Code
+		}
+		positions[item.Section]++
+		item.Position = positions[item.Section]
+	}
+	return nil
+}
+
+// requireDraft vetoes changes to signed summaries
+func requireDraft(current *models.DischargeSummary) error {
+	if current.Status != models.DischargeSummaryStatusDraft {
+		return fmt.Errorf("%w: the discharge summary is signed and can no longer change", ErrConflict)
+	}
+	return nil
+}
+
+// summaryContentHash fingerprints the clinical content of a summary
+func summaryContentHash(summary *models.DischargeSummary) (string, error) {
+	type item struct {
+		Section, Code, Description, Detail string
+	}
+	content := struct {
+		AdmissionID, PatientID, AttendingDoctorID uint
+		AdmittedAt, DischargedAt                  time.Time
+		Disposition, AdmissionReason              string
+		HospitalCourse, FollowUp                  string
+		Items                                     []item
+	}{
+		AdmissionID:       summary.AdmissionID,
+		PatientID:         summary.PatientID,
+		AttendingDoctorID: summary.AttendingDoctorID,
+		AdmittedAt:        summary.AdmittedAt.UTC(),
+		DischargedAt:      summary.DischargedAt.UTC(),
+		Disposition:       summary.Disposition,
+		AdmissionReason:   summary.AdmissionReason,
+		HospitalCourse:    summary.HospitalCourse,
+		FollowUp:          summary.FollowUp,
+	}
+	for _, i := range summary.Items {
+		content.Items = append(content.Items, item{i.Section, i.Code, i.Description, i.Detail})
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func summaryItemLine(item models.DischargeSummaryItem) string {
+	line := item.Description
+	if item.Code != "" && item.Code != item.Description {
+		line = item.Code + " " + line
+	}
+	if item.Detail != "" {
+		line += " (" + item.Detail + ")"
+	}
+	return line
+}
+
+func orNone(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return "None"
+	}
+	return text
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/auth"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/storage"
+)
+
+var (
+	documentCategories = []string{"referral", "consent", "imaging_report", "lab_report", "other"}
+
+	defaultDocumentTypes = []string{"application/pdf", "image/jpeg", "image/png", "image/gif", "text/plain; charset=utf-8"}
+)
+
+// DocumentUpload describes a file being attached to a patient
+type DocumentUpload struct {
+	FileName   string
+	Category   string
+	Title      string
+	Visibility string
+	Body       io.Reader
+}
+
+// DocumentService handles patient document storage
+type DocumentService struct {
+	documentRepo *repositories.DocumentRepository
+	patientRepo  *repositories.PatientRepository
+	blobs        storage.BlobStorage
+	logger       *zap.Logger
+}
+
+// NewDocumentService creates a new document service instance
+func NewDocumentService(documentRepo *repositories.DocumentRepository, patientRepo *repositories.PatientRepository, blobs storage.BlobStorage, logger *zap.Logger) *DocumentService {
+	return &DocumentService{
+		documentRepo: documentRepo,
+		patientRepo:  patientRepo,
+		blobs:        blobs,
+		logger:       logger,
+	}
+}
+
+// MaxDocumentSize returns the configured upload limit in bytes
+func MaxDocumentSize() int64 {
+	limit := viper.GetInt64("documents.max_upload_mb")
+	if limit <= 0 {
+		limit = 25
+	}
+	return limit << 20
+}
+
+// documentVisibilities lists the visibility levels a role may see
+func documentVisibilities(role auth.Role) []string {
+	switch role {
+	case auth.RoleDoctor:
+		return []string{models.DocumentVisibilityAllStaff, models.DocumentVisibilityClinical, models.DocumentVisibilityDoctors}
+	case auth.RoleNurse, auth.RoleLabTechnician:
+		return []string{models.DocumentVisibilityAllStaff, models.DocumentVisibilityClinical}
+	default:
+		return []string{models.DocumentVisibilityAllStaff}
+	}
+}
+
+// Upload stores a document for a patient. The content is spooled to a
+// temporary file to enforce the size limit, compute its SHA-256 and sniff
+// its MIME type before anything reaches blob storage.
+func (s *DocumentService) Upload(ctx context.Context, patientID uint, upload DocumentUpload, uploaderID uint, role auth.Role) (*models.PatientDocument, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	if !contains(documentCategories, upload.Category) {
+		return nil, fmt.Errorf("%w: category must be one of %v", ErrInvalidInput, documentCategories)
+	}
+	if upload.Visibility == "" {
+		upload.Visibility = models.DocumentVisibilityClinical
+	}
+	if !contains(documentVisibilities(role), upload.Visibility) {
+		return nil, fmt.Errorf("%w: visibility must be one of %v", ErrInvalidInput, documentVisibilities(role))
+	}
+
+	spool, err := os.CreateTemp("", "document-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	maxSize := MaxDocumentSize()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, hash), io.LimitReader(upload.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidInput)
+	}
+	if size > maxSize {
+		return nil, fmt.Errorf("%w: file exceeds the %d MB limit", ErrInvalidInput, maxSize>>20)
+	}
+
+	head := make([]byte, 512)
+	n, err := spool.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !allowedDocumentType(contentType) {
+		return nil, fmt.Errorf("%w: file type %s is not allowed", ErrInvalidInput, contentType)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	key, err := documentKey(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.blobs.Put(ctx, key, spool, size, contentType, checksum); err != nil {
+		s.logger.Error("Failed to store document", zap.Error(err), zap.Uint("patient_id", patientID))
+		return nil, err
+	}
+
+	title := upload.Title
+	if title == "" {
+		title = upload.FileName
+	}
+	document, err := s.documentRepo.Create(&models.PatientDocument{
+		PatientID:    patientID,
+		Category:     upload.Category,
+		Title:        title,
+		FileName:     filepath.Base(upload.FileName),
+		ContentType:  contentType,
+		Size:         size,
+		SHA256:       checksum,
+		StorageKey:   key,
+		Visibility:   upload.Visibility,
+		UploadedByID: uploaderID,
+	})
+	if err != nil {
+		// Do not leave an orphaned blob behind
+		if delErr := s.blobs.Delete(ctx, key); delErr != nil {
+			s.logger.Warn("Failed to remove orphaned document blob", zap.Error(delErr), zap.String("key", key))
+		}
+		return nil, err
+	}
+	return document, nil
+}
+
+// GetPatientDocuments lists the documents of a patient visible to a role
+func (s *DocumentService) GetPatientDocuments(patientID uint, role auth.Role) ([]models.PatientDocument, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.documentRepo.FindByPatient(patientID, documentVisibilities(role))
+}
+
+// GetDocument retrieves a document record visible to a role
+func (s *DocumentService) GetDocument(id uint, role auth.Role) (*models.PatientDocument, error) {
+	document, err := s.documentRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(documentVisibilities(role), document.Visibility) {
+		return nil, fmt.Errorf("%w: document is restricted", ErrForbidden)
+	}
+	return document, nil
+}
+
+// OpenDocument opens the content of a document for streaming
+func (s *DocumentService) OpenDocument(ctx context.Context, id uint, role auth.Role) (*models.PatientDocument, io.ReadCloser, error) {
+	document, err := s.GetDocument(id, role)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := s.blobs.Get(ctx, document.StorageKey)
+	if err != nil {
+		s.logger.Error("Failed to open document", zap.Error(err), zap.Uint("id", id))
+		return nil, nil, err
+	}
+	return document, body, nil
+}
+
+// DeleteDocument removes a document from the patient's record. Doctors may
+// delete any document they can see; other staff only their own uploads.
+// The record is soft-deleted and the blob kept for retention.
+func (s *DocumentService) DeleteDocument(id, userID uint, role auth.Role) error {
+	document, err := s.GetDocument(id, role)
+	if err != nil {
+		return err
+	}
+	if role != auth.RoleDoctor && document.UploadedByID != userID {
+		return fmt.Errorf("%w: only the uploader or a doctor may delete this document", ErrForbidden)
+	}
+	return s.documentRepo.Delete(id)
+}
+
+func allowedDocumentType(contentType string) bool {
+	allowed := viper.GetStringSlice("documents.allowed_types")
+	if len(allowed) == 0 {
+		allowed = defaultDocumentTypes
+	}
+	for _, t := range allowed {
+		if strings.EqualFold(t, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// documentKey generates an unguessable storage key for a patient's document
+func documentKey(patientID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("patients/%d/%s", patientID, hex.EncodeToString(random)), nil
+}
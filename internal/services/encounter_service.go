@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var encounterTypes = []string{"outpatient", "inpatient", "emergency", "telehealth"}
+
+// EncounterService handles encounter business logic
+type EncounterService struct {
+	encounterRepo *repositories.EncounterRepository
+	patientRepo   *repositories.PatientRepository
+	logger        *zap.Logger
+}
+
+// NewEncounterService creates a new encounter service instance
+func NewEncounterService(encounterRepo *repositories.EncounterRepository, patientRepo *repositories.PatientRepository, logger *zap.Logger) *EncounterService {
+	return &EncounterService{
+		encounterRepo: encounterRepo,
+		patientRepo:   patientRepo,
+		logger:        logger,
+	}
+}
+
+// StartEncounter opens a new encounter for a patient
+func (s *EncounterService) StartEncounter(patientID uint, encounter *models.Encounter, doctorID uint) (*models.Encounter, error) {
+	if !contains(encounterTypes, encounter.Type) {
+		return nil, fmt.Errorf("%w: type must be one of %v", ErrInvalidInput, encounterTypes)
+	}
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+
+	encounter.PatientID = patientID
+	encounter.DoctorID = doctorID
+	encounter.Status = models.EncounterStatusInProgress
+	if encounter.StartedAt.IsZero() {
+		encounter.StartedAt = time.Now()
+	}
+	return s.encounterRepo.Create(encounter)
+}
+
+// GetPatientEncounters retrieves a patient's encounters
+func (s *EncounterService) GetPatientEncounters(patientID uint) ([]models.Encounter, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.encounterRepo.FindByPatient(patientID)
+}
+
+// GetEncounterByID retrieves an encounter by ID
+func (s *EncounterService) GetEncounterByID(id uint) (*models.Encounter, error) {
+	return s.encounterRepo.FindByID(id)
+}
+
+// FinishEncounter closes an in-progress encounter
+func (s *EncounterService) FinishEncounter(id uint) (*models.Encounter, error) {
+	encounter, err := s.encounterRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if encounter.Status != models.EncounterStatusInProgress {
+		return nil, fmt.Errorf("%w: encounter is %s", ErrConflict, encounter.Status)
+	}
+
+	now := time.Now()
+	encounter.Status = models.EncounterStatusFinished
+	encounter.EndedAt = &now
+	return s.encounterRepo.Update(encounter)
+}
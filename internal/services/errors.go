@@ -0,0 +1,19 @@
+package services
+
+import "errors"
+
+// ErrInvalidInput is returned when a request fails business validation.
+// Services wrap it with a description of the offending field so that
+// controllers can answer with 400 instead of 500.
+var ErrInvalidInput = errors.New("invalid input")
+
+// ErrConflict is returned when a request is valid but clashes with the
+// current state of a record (e.g. an illegal status transition).
+var ErrConflict = errors.New("conflict")
+
+// ErrForbidden is returned when the caller's role may not access a record
+var ErrForbidden = errors.New("forbidden")
+
+// ErrNotFound is returned when a record the request refers to, such as the
+// patient in the URL, does not exist
+var ErrNotFound = errors.New("not found")
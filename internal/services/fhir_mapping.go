@@ -0,0 +1,399 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hospital-portal/internal/fhir"
+	"hospital-portal/internal/models"
+)
+
+// Code systems used in the clinical resource mappings
+const (
+	actCodeSystem                   = "http://terminology.hl7.org/CodeSystem/v3-ActCode"
+	actSiteSystem                   = "http://terminology.hl7.org/CodeSystem/v3-ActSite"
+	conditionClinicalSystem         = "http://terminology.hl7.org/CodeSystem/condition-clinical"
+	conditionVerificationSystem     = "http://terminology.hl7.org/CodeSystem/condition-ver-status"
+	conditionCategorySystem         = "http://terminology.hl7.org/CodeSystem/condition-category"
+	allergyClinicalSystem           = "http://terminology.hl7.org/CodeSystem/allergyintolerance-clinical"
+	allergyVerificationSystem       = "http://terminology.hl7.org/CodeSystem/allergyintolerance-verification"
+	observationCategorySystem       = "http://terminology.hl7.org/CodeSystem/observation-category"
+	observationInterpretationSystem = "http://terminology.hl7.org/CodeSystem/v3-ObservationInterpretation"
+	icd10CMSystem                   = "http://hl7.org/fhir/sid/icd-10-cm"
+	cvxSystem                       = "http://hl7.org/fhir/sid/cvx"
+	loincSystem                     = "http://loinc.org"
+	snomedSystem                    = "http://snomed.info/sct"
+	rxNormSystem                    = "http://www.nlm.nih.gov/research/umls/rxnorm"
+	ucumSystem                      = "http://unitsofmeasure.org"
+)
+
+// encounterClasses maps encounter types to v3 ActCode encounter classes
+var encounterClasses = map[string]fhir.Coding{
+	"outpatient": {System: actCodeSystem, Code: "AMB", Display: "ambulatory"},
+	"inpatient":  {System: actCodeSystem, Code: "IMP", Display: "inpatient encounter"},
+	"emergency":  {System: actCodeSystem, Code: "EMER", Display: "emergency"},
+	"telehealth": {System: actCodeSystem, Code: "VR", Display: "virtual"},
+}
+
+// fhirAllergyCategories maps allergy categories to FHIR ones; "other" has none
+var fhirAllergyCategories = map[string]string{
+	"drug":        "medication",
+	"food":        "food",
+	"environment": "environment",
+}
+
+// labInterpretations maps lab result flags to v3 interpretation codes
+var labInterpretations = map[string]string{
+	models.LabFlagNormal:       "N",
+	models.LabFlagLow:          "L",
+	models.LabFlagHigh:         "H",
+	models.LabFlagCriticalLow:  "LL",
+	models.LabFlagCriticalHigh: "HH",
+	models.LabFlagAbnormal:     "A",
+}
+
+// fhirImmunizationSites maps administration sites to v3 ActSite codes
+var fhirImmunizationSites = map[string]string{
+	"left_arm":    "LA",
+	"right_arm":   "RA",
+	"left_thigh":  "LT",
+	"right_thigh": "RT",
+}
+
+// vitalSign is an observation taken from a vital signs record
+type vitalSign struct {
+	key     string
+	loinc   string
+	display string
+	unit    string
+	value   func(v *models.VitalSigns) *float64
+}
+
+// vitalSigns are the measurements of a vital signs record, each exported as
+// its own Observation
+var vitalSigns = []vitalSign{
+	{"systolic", "8480-6", "Systolic blood pressure", "mm[Hg]", func(v *models.VitalSigns) *float64 { return intValue(v.SystolicBP) }},
+	{"diastolic", "8462-4", "Diastolic blood pressure", "mm[Hg]", func(v *models.VitalSigns) *float64 { return intValue(v.DiastolicBP) }},
+	{"heart-rate", "8867-4", "Heart rate", "/min", func(v *models.VitalSigns) *float64 { return intValue(v.HeartRate) }},
+	{"respiratory-rate", "9279-1", "Respiratory rate", "/min", func(v *models.VitalSigns) *float64 { return intValue(v.RespiratoryRate) }},
+	{"temperature", "8310-5", "Body temperature", "Cel", func(v *models.VitalSigns) *float64 { return v.Temperature }},
+	{"spo2", "2708-6", "Oxygen saturation in Arterial blood", "%", func(v *models.VitalSigns) *float64 { return intValue(v.SpO2) }},
+	{"weight", "29463-7", "Body weight", "kg", func(v *models.VitalSigns) *float64 { return v.WeightKg }},
+	{"height", "8302-2", "Body height", "cm", func(v *models.VitalSigns) *float64 { return v.HeightCm }},
+	{"bmi", "39156-5", "Body mass index (BMI) [Ratio]", "kg/m2", func(v *models.VitalSigns) *float64 { return v.BMI }},
+}
+
+func intValue(value *int) *float64 {
+	if value == nil {
+		return nil
+	}
+	f := float64(*value)
+	return &f
+}
+
+func fhirID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func patientReference(patientID uint) fhir.Reference {
+	return fhir.Reference{Reference: "Patient/" + fhirID(patientID)}
+}
+
+func encounterReference(encounterID *uint) *fhir.Reference {
+	if encounterID == nil {
+		return nil
+	}
+	return &fhir.Reference{Reference: "Encounter/" + fhirID(*encounterID)}
+}
+
+func fhirMeta(updatedAt time.Time) *fhir.Meta {
+	updated := updatedAt.UTC()
+	return &fhir.Meta{LastUpdated: &updated}
+}
+
+func fhirDateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func fhirDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func fhirNotes(notes string) []fhir.Annotation {
+	if notes == "" {
+		return nil
+	}
+	return []fhir.Annotation{{Text: notes}}
+}
+
+func codedConcept(system, code, display string) *fhir.CodeableConcept {
+	return &fhir.CodeableConcept{Coding: []fhir.Coding{{System: system, Code: code, Display: display}}}
+}
+
+// fhirCodeSystem resolves the code system names used in the portal to their
+// FHIR URIs; URIs and unknown names are passed through
+func fhirCodeSystem(name string) string {
+	switch strings.ToLower(strings.ReplaceAll(name, " ", "")) {
+	case "rxnorm":
+		return rxNormSystem
+	case "snomed", "snomedct", "sct":
+		return snomedSystem
+	case "loinc":
+		return loincSystem
+	}
+	return name
+}
+
+// icd10CMCode adds the dot the ICD-10-CM system uses after the category,
+// as codes are stored without it
+func icd10CMCode(code string) string {
+	if len(code) <= 3 {
+		return code
+	}
+	return code[:3] + "." + code[3:]
+}
+
+func toFHIREncounter(encounter *models.Encounter) *fhir.Encounter {
+	resource := &fhir.Encounter{
+		ID:      fhirID(encounter.ID),
+		Meta:    fhirMeta(encounter.UpdatedAt),
+		Status:  strings.ReplaceAll(encounter.Status, "_", "-"),
+		Class:   encounterClasses[encounter.Type],
+		Type:    []fhir.CodeableConcept{{Text: encounter.Type}},
+		Subject: &fhir.Reference{Reference: "Patient/" + fhirID(encounter.PatientID)},
+		Period:  &fhir.Period{Start: fhirDateTime(encounter.StartedAt)},
+	}
+	if resource.Class.Code == "" {
+		resource.Class = fhir.Coding{Display: encounter.Type}
+	}
+	if encounter.EndedAt != nil {
+		resource.Period.End = fhirDateTime(*encounter.EndedAt)
+	}
+	if encounter.Reason != "" {
+		resource.ReasonCode = []fhir.CodeableConcept{{Text: encounter.Reason}}
+	}
+	return resource
+}
+
+func toFHIRCondition(problem *models.Problem) *fhir.Condition {
+	code := codedConcept(icd10CMSystem, icd10CMCode(problem.ICD10Code), problem.Description)
+	code.Text = problem.Description
+	return &fhir.Condition{
+		ID:                 fhirID(problem.ID),
+		Meta:               fhirMeta(problem.UpdatedAt),
+		ClinicalStatus:     codedConcept(conditionClinicalSystem, problem.Status, ""),
+		VerificationStatus: codedConcept(conditionVerificationSystem, "confirmed", ""),
+		Category:           []fhir.CodeableConcept{*codedConcept(conditionCategorySystem, "problem-list-item", "Problem List Item")},
+		Code:               code,
+		Subject:            patientReference(problem.PatientID),
+		Encounter:          encounterReference(problem.EncounterID),
+		OnsetDateTime:      fhirDate(problem.OnsetDate),
+		AbatementDateTime:  fhirDate(problem.ResolvedDate),
+		RecordedDate:       fhirDateTime(problem.CreatedAt),
+		Note:               fhirNotes(problem.Notes),
+	}
+}
+
+func toFHIRAllergyIntolerance(allergy *models.Allergy) *fhir.AllergyIntolerance {
+	resource := &fhir.AllergyIntolerance{
+		ID:           fhirID(allergy.ID),
+		Meta:         fhirMeta(allergy.UpdatedAt),
+		Patient:      patientReference(allergy.PatientID),
+		RecordedDate: fhirDateTime(allergy.RecordedAt),
+		Note:         fhirNotes(allergy.Notes),
+	}
+
+	// An entered-in-error record must not carry a clinical status
+	if allergy.Status == models.AllergyStatusEnteredInError {
+		resource.VerificationStatus = codedConcept(allergyVerificationSystem, "entered-in-error", "")
+	} else {
+		resource.ClinicalStatus = codedConcept(allergyClinicalSystem, allergy.Status, "")
+		resource.VerificationStatus = codedConcept(allergyVerificationSystem, "confirmed", "")
+	}
+
+	if allergy.NKDA {
+		resource.Code = codedConcept(snomedSystem, "409137002", "No known drug allergy")
+		return resource
+	}
+
+	if category, ok := fhirAllergyCategories[allergy.Category]; ok {
+		resource.Category = []string{category}
+	}
+	resource.Code = &fhir.CodeableConcept{Text: allergy.Substance}
+	if allergy.SubstanceCode != "" {
+		resource.Code.Coding = []fhir.Coding{{System: fhirCodeSystem(allergy.CodeSystem), Code: allergy.SubstanceCode, Display: allergy.Substance}}
+	}
+
+	severity := allergy.Severity
+	switch severity {
+	case "severe", "life_threatening":
+		resource.Criticality = "high"
+		severity = "severe"
+	case "mild", "moderate":
+		resource.Criticality = "low"
+	}
+	if allergy.Reaction != "" {
+		resource.Reaction = []fhir.AllergyReaction{{
+			Manifestation: []fhir.CodeableConcept{{Text: allergy.Reaction}},
+			Severity:      severity,
+		}}
+	}
+	return resource
+}
+
+func toFHIRMedicationRequest(prescription *models.Prescription) *fhir.MedicationRequest {
+	status := prescription.Status
+	if status == models.PrescriptionStatusDiscontinued {
+		status = "stopped"
+	}
+
+	medication := &fhir.CodeableConcept{Text: strings.TrimSpace(prescription.Drug + " " + prescription.Strength)}
+	if prescription.DrugCode != "" {
+		medication.Coding = []fhir.Coding{{System: rxNormSystem, Code: prescription.DrugCode, Display: prescription.Drug}}
+	}
+
+	dosage := fmt.Sprintf("%s %s %s for %d days", prescription.Strength, prescription.Route, prescription.Frequency, prescription.DurationDays)
+	if prescription.Instructions != "" {
+		dosage += ". " + prescription.Instructions
+	}
+
+	quantity := float64(prescription.Quantity)
+	resource := &fhir.MedicationRequest{
+		ID:                        fhirID(prescription.ID),
+		Meta:                      fhirMeta(prescription.UpdatedAt),
+		Status:                    status,
+		Intent:                    "order",
+		MedicationCodeableConcept: medication,
+		Subject:                   patientReference(prescription.PatientID),
+		AuthoredOn:                fhirDateTime(prescription.CreatedAt),
+		DosageInstruction: []fhir.Dosage{{
+			Text:  dosage,
+			Route: &fhir.CodeableConcept{Text: prescription.Route},
+		}},
+		DispenseRequest: &fhir.DispenseRequest{
+			ValidityPeriod: &fhir.Period{
+				Start: fhirDate(&prescription.StartDate),
+				End:   fhirDate(&prescription.EndDate),
+			},
+			NumberOfRepeatsAllowed: prescription.Refills,
+			Quantity:               &fhir.Quantity{Value: &quantity},
+		},
+	}
+	if prescription.DiscontinueReason != "" {
+		resource.StatusReason = &fhir.CodeableConcept{Text: prescription.DiscontinueReason}
+	}
+	if prescription.RenewedFromID != nil {
+		resource.PriorPrescription = &fhir.Reference{Reference: "MedicationRequest/" + fhirID(*prescription.RenewedFromID)}
+	}
+	return resource
+}
+
+// toFHIRVitalSignObservations splits a vital signs record into one
+// Observation per measurement it holds
+func toFHIRVitalSignObservations(vitals *models.VitalSigns) []*fhir.Observation {
+	category := *codedConcept(observationCategorySystem, "vital-signs", "Vital Signs")
+	var observations []*fhir.Observation
+	for _, sign := range vitalSigns {
+		value := sign.value(vitals)
+		if value == nil {
+			continue
+		}
+		observations = append(observations, &fhir.Observation{
+			ID:                "vitals-" + fhirID(vitals.ID) + "-" + sign.key,
+			Meta:              fhirMeta(vitals.UpdatedAt),
+			Status:            "final",
+			Category:          []fhir.CodeableConcept{category},
+			Code:              fhir.CodeableConcept{Coding: []fhir.Coding{{System: loincSystem, Code: sign.loinc, Display: sign.display}}, Text: sign.display},
+			Subject:           patientReference(vitals.PatientID),
+			Encounter:         encounterReference(vitals.EncounterID),
+			EffectiveDateTime: fhirDateTime(vitals.RecordedAt),
+			ValueQuantity:     &fhir.Quantity{Value: value, Unit: sign.unit, System: ucumSystem, Code: sign.unit},
+		})
+	}
+	return observations
+}
+
+// toFHIRLabObservations maps the results of a resulted lab order, one
+// Observation per analyte
+func toFHIRLabObservations(order *models.LabOrder) []*fhir.Observation {
+	status := "preliminary"
+	if order.Status == models.LabOrderStatusVerified {
+		status = "final"
+	}
+	effective := order.OrderedAt
+	if order.CollectedAt != nil {
+		effective = *order.CollectedAt
+	}
+
+	category := *codedConcept(observationCategorySystem, "laboratory", "Laboratory")
+	observations := make([]*fhir.Observation, 0, len(order.Results))
+	for _, result := range order.Results {
+		observation := &fhir.Observation{
+			ID:                "lab-" + fhirID(result.ID),
+			Meta:              fhirMeta(order.UpdatedAt),
+			Status:            status,
+			Category:          []fhir.CodeableConcept{category},
+			Code:              fhir.CodeableConcept{Text: result.AnalyteName},
+			Subject:           patientReference(order.PatientID),
+			Encounter:         encounterReference(order.EncounterID),
+			EffectiveDateTime: fhirDateTime(effective),
+			Note:              fhirNotes(result.Comment),
+		}
+		if result.AnalyteCode != "" {
+			observation.Code.Coding = []fhir.Coding{{System: loincSystem, Code: result.AnalyteCode, Display: result.AnalyteName}}
+		}
+		if result.NumericValue != nil {
+			observation.ValueQuantity = &fhir.Quantity{Value: result.NumericValue, Unit: result.Unit}
+		} else {
+			observation.ValueString = result.Value
+		}
+		if code, ok := labInterpretations[result.Flag]; ok {
+			observation.Interpretation = []fhir.CodeableConcept{*codedConcept(observationInterpretationSystem, code, "")}
+		}
+		if result.RefLow != nil || result.RefHigh != nil {
+			var reference fhir.ObservationRange
+			if result.RefLow != nil {
+				reference.Low = &fhir.Quantity{Value: result.RefLow, Unit: result.Unit}
+			}
+			if result.RefHigh != nil {
+				reference.High = &fhir.Quantity{Value: result.RefHigh, Unit: result.Unit}
+			}
+			observation.ReferenceRange = []fhir.ObservationRange{reference}
+		}
+		observations = append(observations, observation)
+	}
+	return observations
+}
+
+func toFHIRImmunization(immunization *models.Immunization) *fhir.Immunization {
+	resource := &fhir.Immunization{
+		ID:                 fhirID(immunization.ID),
+		Meta:               fhirMeta(immunization.UpdatedAt),
+		Status:             strings.ReplaceAll(immunization.Status, "_", "-"),
+		VaccineCode:        *codedConcept(cvxSystem, immunization.VaccineCode, immunization.VaccineName),
+		Patient:            patientReference(immunization.PatientID),
+		OccurrenceDateTime: fhirDateTime(immunization.AdministeredAt),
+		PrimarySource:      !immunization.Historical,
+		LotNumber:          immunization.LotNumber,
+		Note:               fhirNotes(immunization.Notes),
+	}
+	resource.VaccineCode.Text = immunization.VaccineName
+	if immunization.StatusReason != "" {
+		resource.StatusReason = &fhir.CodeableConcept{Text: immunization.StatusReason}
+	}
+	if immunization.Site != "" {
+		resource.Site = &fhir.CodeableConcept{Text: immunization.Site}
+		if code, ok := fhirImmunizationSites[immunization.Site]; ok {
+			resource.Site.Coding = []fhir.Coding{{System: actSiteSystem, Code: code}}
+		}
+	}
+	if immunization.DoseNumber > 0 {
+		resource.ProtocolApplied = []fhir.ImmunizationProtocol{{DoseNumberPositiveInt: immunization.DoseNumber}}
+	}
+	return resource
+}
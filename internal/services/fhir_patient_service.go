@@ -0,0 +1,612 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/auth"
+	"hospital-portal/internal/fhir"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// FHIR search page sizes
+const (
+	fhirDefaultCount = 50
+	fhirMaxCount     = 200
+)
+
+// Code systems used in the Patient mapping
+const (
+	identifierTypeSystem   = "http://terminology.hl7.org/CodeSystem/v2-0203"
+	contactRoleSystem      = "http://terminology.hl7.org/CodeSystem/v2-0131"
+	contactRoleCodeSystem  = "http://terminology.hl7.org/CodeSystem/v3-RoleCode"
+	defaultPatientIDSystem = "urn:hospital-portal:patient-id"
+)
+
+// fhirGenders are the FHIR administrative genders the portal records
+var fhirGenders = []string{"male", "female", "other"}
+
+// FHIRPatientService exposes patients as FHIR R4 Patient resources. Writes
+// go through PatientService, so they are validated like any other.
+// Searches are audited like patient list exports.
+type FHIRPatientService struct {
+	patientService *PatientService
+	patientRepo    *repositories.PatientRepository
+	auditRepo      *repositories.AuditRepository
+	logger         *zap.Logger
+}
+
+// NewFHIRPatientService creates a new FHIR patient service instance
+func NewFHIRPatientService(patientService *PatientService, patientRepo *repositories.PatientRepository, auditRepo *repositories.AuditRepository, logger *zap.Logger) *FHIRPatientService {
+	return &FHIRPatientService{
+		patientService: patientService,
+		patientRepo:    patientRepo,
+		auditRepo:      auditRepo,
+		logger:         logger,
+	}
+}
+
+// Read retrieves a patient as a FHIR resource
+func (s *FHIRPatientService) Read(id string) (*fhir.Patient, error) {
+	patientID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, errors.New("patient not found")
+	}
+	patient, err := s.patientService.GetPatientByID(uint(patientID))
+	if err != nil {
+		return nil, err
+	}
+	return s.toFHIR(patient)
+}
+
+// Search runs a Patient search and returns a page of the matches as a
+// search set. baseURL is the service base used in the bundle's links. The
+// search is recorded in the audit log before any patient is read.
+func (s *FHIRPatientService) Search(params url.Values, baseURL string, userID uint, role auth.Role, remoteAddr string) (*fhir.Bundle, error) {
+	search, applied, noMatch, err := parsePatientSearch(params)
+	if err != nil {
+		return nil, err
+	}
+	count, offset, err := parseFHIRPaging(params)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := json.Marshal(map[string]interface{}{
+		"search": applied.Encode(),
+		"count":  count,
+		"offset": offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entry := &models.AuditLog{
+		UserID:     userID,
+		Role:       string(role),
+		Action:     models.AuditActionFHIRPatientSearch,
+		Details:    string(details),
+		Outcome:    models.AuditOutcomeStarted,
+		RemoteAddr: remoteAddr,
+	}
+	if err := s.auditRepo.Create(entry); err != nil {
+		s.logger.Error("Failed to audit FHIR patient search", zap.Error(err), zap.Uint("user_id", userID))
+		return nil, err
+	}
+
+	var patients []models.Patient
+	var total int64
+	if !noMatch {
+		patients, total, err = s.patientRepo.Search(search, offset, count)
+		if err == nil {
+			err = s.patientRepo.LoadIdentifiers(patients)
+		}
+		if err != nil {
+			if auditErr := s.auditRepo.Finish(entry.ID, models.AuditOutcomeFailed, 0, err.Error()); auditErr != nil {
+				s.logger.Error("Failed to complete FHIR patient search audit", zap.Error(auditErr), zap.Uint("audit_id", entry.ID))
+			}
+			return nil, err
+		}
+	}
+	if err := s.auditRepo.Finish(entry.ID, models.AuditOutcomeCompleted, len(patients), ""); err != nil {
+		s.logger.Error("Failed to complete FHIR patient search audit", zap.Error(err), zap.Uint("audit_id", entry.ID))
+	}
+
+	bundle := fhir.NewSearchSet(int(total))
+	link := func(offset int) string {
+		query := url.Values{}
+		for key, values := range applied {
+			query[key] = values
+		}
+		query.Set("_count", strconv.Itoa(count))
+		if offset > 0 {
+			query.Set("_offset", strconv.Itoa(offset))
+		}
+		return baseURL + "/Patient?" + query.Encode()
+	}
+	bundle.AddLink("self", link(offset))
+	if count > 0 && int64(offset+count) < total {
+		bundle.AddLink("next", link(offset+count))
+	}
+	if offset > 0 {
+		previous := offset - count
+		if previous < 0 {
+			previous = 0
+		}
+		bundle.AddLink("previous", link(previous))
+	}
+	for i := range patients {
+		bundle.AddMatch(fmt.Sprintf("%s/Patient/%d", baseURL, patients[i].ID), ToFHIRPatient(&patients[i]))
+	}
+	return bundle, nil
+}
+
+// Create registers a patient from a FHIR resource. As with the portal's own
+// API, contacts are only read on create.
+func (s *FHIRPatientService) Create(resource *fhir.Patient) (*fhir.Patient, error) {
+	if resource.ID != "" {
+		return nil, fmt.Errorf("%w: a created Patient must not carry an id", ErrInvalidInput)
+	}
+	patient := &models.Patient{}
+	if err := applyFHIRPatient(patient, resource); err != nil {
+		return nil, err
+	}
+	if patient.DateOfBirth == nil {
+		return nil, fmt.Errorf("%w: birthDate is required", ErrInvalidInput)
+	}
+	for _, contact := range resource.Contact {
+		converted, err := fromFHIRContact(contact)
+		if err != nil {
+			return nil, err
+		}
+		patient.Contacts = append(patient.Contacts, converted)
+	}
+
+	created, err := s.patientService.CreatePatient(patient)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Patient created through FHIR", zap.Uint("patient_id", created.ID))
+	return ToFHIRPatient(created), nil
+}
+
+// Update replaces the demographics of a patient from a FHIR resource. The
+// clinical free-text fields, which FHIR Patient does not carry, are kept.
+func (s *FHIRPatientService) Update(id string, resource *fhir.Patient) (*fhir.Patient, error) {
+	if resource.ID != id {
+		return nil, fmt.Errorf("%w: the resource id must match the id in the URL", ErrInvalidInput)
+	}
+	patientID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, errors.New("patient not found")
+	}
+	patient, err := s.patientService.GetPatientByID(uint(patientID))
+	if err != nil {
+		return nil, err
+	}
+	if err := applyFHIRPatient(patient, resource); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.patientService.UpdatePatient(patient)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Patient updated through FHIR", zap.Uint("patient_id", updated.ID))
+	return s.toFHIR(updated)
+}
+
+// toFHIR maps a patient to a FHIR resource with their identifiers loaded
+func (s *FHIRPatientService) toFHIR(patient *models.Patient) (*fhir.Patient, error) {
+	patients := []models.Patient{*patient}
+	if err := s.patientRepo.LoadIdentifiers(patients); err != nil {
+		return nil, err
+	}
+	return ToFHIRPatient(&patients[0]), nil
+}
+
+// PatientIdentifierSystem is the identifier system of the portal's patient IDs
+func PatientIdentifierSystem() string {
+	if system := viper.GetString("fhir.identifier_system"); system != "" {
+		return system
+	}
+	return defaultPatientIDSystem
+}
+
+// ToFHIRPatient maps a patient to the FHIR Patient resource. Identifiers
+// from other systems are included when they have been loaded.
+func ToFHIRPatient(patient *models.Patient) *fhir.Patient {
+	id := strconv.FormatUint(uint64(patient.ID), 10)
+	active := true
+	updated := patient.UpdatedAt.UTC()
+	resource := &fhir.Patient{
+		ID:     id,
+		Meta:   &fhir.Meta{LastUpdated: &updated},
+		Active: &active,
+		Identifier: []fhir.Identifier{{
+			Use: "usual",
+			Type: &fhir.CodeableConcept{
+				Coding: []fhir.Coding{{System: identifierTypeSystem, Code: "MR", Display: "Medical record number"}},
+			},
+			System: PatientIdentifierSystem(),
+			Value:  id,
+		}},
+		Name:   []fhir.HumanName{fhirName(patient.Name, "official")},
+		Gender: patient.Gender,
+	}
+	for _, identifier := range patient.Identifiers {
+		resource.Identifier = append(resource.Identifier, fhir.Identifier{
+			Use:    "secondary",
+			System: identifier.System,
+			Value:  identifier.Value,
+		})
+	}
+	if patient.DateOfBirth != nil {
+		resource.BirthDate = patient.DateOfBirth.Format("2006-01-02")
+	}
+	if patient.PhoneNumber != "" {
+		resource.Telecom = []fhir.ContactPoint{{System: "phone", Value: patient.PhoneNumber}}
+	}
+	if patient.Address.Line1 != "" {
+		address := fhir.Address{
+			Use:        "home",
+			Line:       []string{patient.Address.Line1},
+			City:       patient.Address.City,
+			State:      patient.Address.Region,
+			PostalCode: patient.Address.PostalCode,
+			Country:    patient.Address.Country,
+		}
+		if patient.Address.Line2 != "" {
+			address.Line = append(address.Line, patient.Address.Line2)
+		}
+		resource.Address = []fhir.Address{address}
+	} else if patient.LegacyAddress != "" {
+		resource.Address = []fhir.Address{{Use: "home", Text: patient.LegacyAddress}}
+	}
+	return resource
+}
+
+// fhirName splits a full name into given names and a family name at the
+// last space
+func fhirName(name, use string) fhir.HumanName {
+	human := fhir.HumanName{Use: use, Text: name}
+	parts := strings.Fields(name)
+	if len(parts) > 0 {
+		human.Family = parts[len(parts)-1]
+		human.Given = parts[:len(parts)-1]
+	}
+	return human
+}
+
+// nameText is the full name of a FHIR name, from its text or its parts
+func nameText(name fhir.HumanName) string {
+	if text := strings.TrimSpace(name.Text); text != "" {
+		return text
+	}
+	return strings.TrimSpace(strings.Join(append(append([]string{}, name.Given...), name.Family), " "))
+}
+
+// applyFHIRPatient copies the demographics of a FHIR Patient onto a patient.
+// A resource without a birthDate leaves the date of birth on record.
+func applyFHIRPatient(patient *models.Patient, resource *fhir.Patient) error {
+	var name string
+	for _, candidate := range resource.Name {
+		if text := nameText(candidate); text != "" && (name == "" || candidate.Use == "official") {
+			name = text
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if !contains(fhirGenders, resource.Gender) {
+		return fmt.Errorf("%w: gender must be one of %v", ErrInvalidInput, fhirGenders)
+	}
+
+	if resource.BirthDate != "" {
+		birth, err := time.Parse("2006-01-02", resource.BirthDate)
+		if err != nil {
+			return fmt.Errorf("%w: birthDate must be a full YYYY-MM-DD date", ErrInvalidInput)
+		}
+		patient.DateOfBirth = &birth
+	}
+
+	var phone string
+	for _, telecom := range resource.Telecom {
+		if telecom.System == "phone" && telecom.Value != "" {
+			phone = telecom.Value
+			break
+		}
+	}
+	if phone == "" {
+		return fmt.Errorf("%w: a phone telecom is required", ErrInvalidInput)
+	}
+
+	var address *fhir.Address
+	for i := range resource.Address {
+		if address == nil || resource.Address[i].Use == "home" {
+			address = &resource.Address[i]
+		}
+	}
+	if address == nil || len(address.Line) == 0 {
+		return fmt.Errorf("%w: an address with at least one line is required", ErrInvalidInput)
+	}
+
+	patient.Name = name
+	patient.Gender = resource.Gender
+	patient.PhoneNumber = phone
+	patient.Address = models.Address{
+		Line1:      address.Line[0],
+		Line2:      strings.Join(address.Line[1:], ", "),
+		City:       address.City,
+		Region:     address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+	return nil
+}
+
+// fromFHIRContact maps a FHIR patient contact. The relationship codes set
+// the guardian (GUARD), next of kin (N) and emergency contact (C) flags;
+// the relationship text, or else the first code's display, describes it.
+func fromFHIRContact(contact fhir.PatientContact) (models.PatientContact, error) {
+	converted := models.PatientContact{}
+	if contact.Name != nil {
+		converted.Name = nameText(*contact.Name)
+	}
+	for _, telecom := range contact.Telecom {
+		if telecom.System == "phone" && telecom.Value != "" {
+			converted.PhoneNumber = telecom.Value
+			break
+		}
+	}
+	if contact.Address != nil {
+		converted.Address = contact.Address.Text
+		if converted.Address == "" {
+			converted.Address = strings.Join(append(append([]string{}, contact.Address.Line...), contact.Address.City), ", ")
+		}
+	}
+	for _, relationship := range contact.Relationship {
+		if converted.Relationship == "" {
+			converted.Relationship = relationship.Text
+		}
+		for _, coding := range relationship.Coding {
+			switch {
+			case coding.System == contactRoleCodeSystem && coding.Code == "GUARD":
+				converted.IsGuardian = true
+			case coding.System == contactRoleSystem && coding.Code == "N":
+				converted.IsNextOfKin = true
+			case coding.System == contactRoleSystem && coding.Code == "C":
+				converted.IsEmergencyContact = true
+			}
+			if converted.Relationship == "" {
+				converted.Relationship = coding.Display
+			}
+		}
+	}
+	if converted.Relationship == "" {
+		return converted, fmt.Errorf("%w: contact relationship text is required", ErrInvalidInput)
+	}
+	return converted, nil
+}
+
+// parsePatientSearch turns Patient search parameters into repository
+// criteria. It also returns the parameters it applied, for the bundle's
+// self link, and whether the search can match nothing. Unknown parameters
+// are ignored, as FHIR allows.
+func parsePatientSearch(params url.Values) (repositories.PatientSearch, url.Values, bool, error) {
+	var search repositories.PatientSearch
+	applied := url.Values{}
+	noMatch := false
+	var ids []uint
+	idsSet := false
+
+	for key, values := range params {
+		name, modifier := key, ""
+		if i := strings.IndexByte(key, ':'); i >= 0 {
+			name, modifier = key[:i], key[i+1:]
+		}
+		if modifier != "" && name != "name" {
+			return search, nil, false, fmt.Errorf("%w: modifier :%s is not supported on %s", ErrInvalidInput, modifier, name)
+		}
+
+		switch name {
+		case "_id":
+			for _, value := range values {
+				var matched []uint
+				for _, token := range strings.Split(value, ",") {
+					if id, err := strconv.ParseUint(token, 10, 32); err == nil {
+						matched = append(matched, uint(id))
+					}
+				}
+				if idsSet {
+					matched = intersectIDs(ids, matched)
+				}
+				ids, idsSet = matched, true
+			}
+		case "identifier":
+			for _, value := range values {
+				match := parseIdentifierMatch(value)
+				if len(match.IDs) == 0 && len(match.Values) == 0 {
+					noMatch = true
+				}
+				search.Identifiers = append(search.Identifiers, match)
+			}
+		case "name":
+			mode := repositories.NameMatchPrefix
+			switch modifier {
+			case "":
+			case "exact":
+				mode = repositories.NameMatchExact
+			case "contains":
+				mode = repositories.NameMatchContains
+			default:
+				return search, nil, false, fmt.Errorf("%w: modifier :%s is not supported on name", ErrInvalidInput, modifier)
+			}
+			for _, value := range values {
+				search.Names = append(search.Names, repositories.NameMatch{Values: strings.Split(value, ","), Mode: mode})
+			}
+		case "gender":
+			for _, value := range values {
+				genders := strings.Split(value, ",")
+				if search.Genders != nil {
+					genders = intersectStrings(search.Genders, genders)
+				}
+				search.Genders = genders
+				if len(genders) == 0 {
+					noMatch = true
+				}
+			}
+		case "birthdate":
+			for _, value := range values {
+				if err := narrowBirthDate(&search, value); err != nil {
+					return search, nil, false, err
+				}
+			}
+		default:
+			// _count and _offset are read by parseFHIRPaging
+			continue
+		}
+		applied[key] = values
+	}
+
+	if idsSet {
+		if len(ids) == 0 {
+			noMatch = true
+		}
+		search.IDs = ids
+	}
+	if search.BirthDateFrom != nil && search.BirthDateTo != nil && !search.BirthDateFrom.Before(*search.BirthDateTo) {
+		noMatch = true
+	}
+	return search, applied, noMatch, nil
+}
+
+// parseIdentifierMatch reads an identifier parameter: comma-separated
+// tokens of system|value, |value or a bare value. The portal's own system,
+// or none, matches patient IDs; other systems match the identifiers
+// recorded from them, and a bare value matches either.
+func parseIdentifierMatch(value string) repositories.IdentifierMatch {
+	var match repositories.IdentifierMatch
+	for _, token := range strings.Split(value, ",") {
+		system, hasSystem := "", false
+		if i := strings.IndexByte(token, '|'); i >= 0 {
+			system, token, hasSystem = token[:i], token[i+1:], true
+		}
+		if token == "" {
+			continue
+		}
+		if !hasSystem || system == "" || system == PatientIdentifierSystem() {
+			if id, err := strconv.ParseUint(token, 10, 32); err == nil {
+				match.IDs = append(match.IDs, uint(id))
+			}
+		}
+		if !hasSystem || system != "" && system != PatientIdentifierSystem() {
+			match.Values = append(match.Values, repositories.IdentifierValue{System: system, Value: token})
+		}
+	}
+	return match
+}
+
+// narrowBirthDate narrows the birth date range of a search by one
+// birthdate parameter, such as 2015, ge2015-06 or lt2020-01-01
+func narrowBirthDate(search *repositories.PatientSearch, value string) error {
+	prefix := "eq"
+	if len(value) > 2 && value[0] >= 'a' && value[0] <= 'z' {
+		prefix, value = value[:2], value[2:]
+	}
+
+	var start, end time.Time
+	var err error
+	switch len(value) {
+	case 4:
+		start, err = time.Parse("2006", value)
+		end = start.AddDate(1, 0, 0)
+	case 7:
+		start, err = time.Parse("2006-01", value)
+		end = start.AddDate(0, 1, 0)
+	case 10:
+		start, err = time.Parse("2006-01-02", value)
+		end = start.AddDate(0, 0, 1)
+	default:
+		err = errors.New("bad length")
+	}
+	if err != nil {
+		return fmt.Errorf("%w: birthdate must be a YYYY, YYYY-MM or YYYY-MM-DD date", ErrInvalidInput)
+	}
+
+	from, to := &start, &end
+	switch prefix {
+	case "eq":
+	case "lt":
+		from, to = nil, &start
+	case "le":
+		from = nil
+	case "gt":
+		from, to = &end, nil
+	case "ge":
+		to = nil
+	default:
+		return fmt.Errorf("%w: birthdate prefix %s is not supported", ErrInvalidInput, prefix)
+	}
+	if from != nil && (search.BirthDateFrom == nil || from.After(*search.BirthDateFrom)) {
+		search.BirthDateFrom = from
+	}
+	if to != nil && (search.BirthDateTo == nil || to.Before(*search.BirthDateTo)) {
+		search.BirthDateTo = to
+	}
+	return nil
+}
+
+// parseFHIRPaging reads the _count page size and _offset of a search
+func parseFHIRPaging(params url.Values) (int, int, error) {
+	count, offset := fhirDefaultCount, 0
+	if value := params.Get("_count"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("%w: _count must be a non-negative number", ErrInvalidInput)
+		}
+		count = n
+		if count > fhirMaxCount {
+			count = fhirMaxCount
+		}
+	}
+	if value := params.Get("_offset"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("%w: _offset must be a non-negative number", ErrInvalidInput)
+		}
+		offset = n
+	}
+	return count, offset, nil
+}
+
+func intersectIDs(a, b []uint) []uint {
+	out := []uint{}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				out = append(out, x)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func intersectStrings(a, b []string) []string {
+	out := []string{}
+	for _, x := range a {
+		if contains(b, x) {
+			out = append(out, x)
+		}
+	}
+	return out
+}
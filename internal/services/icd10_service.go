@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// ICD10Service handles the ICD-10 code table
+type ICD10Service struct {
+	icd10Repo *repositories.ICD10Repository
+	logger    *zap.Logger
+}
+
+// NewICD10Service creates a new ICD-10 service instance
+func NewICD10Service(icd10Repo *repositories.ICD10Repository, logger *zap.Logger) *ICD10Service {
+	return &ICD10Service{
+		icd10Repo: icd10Repo,
+		logger:    logger,
+	}
+}
+
+// NormalizeICD10Code upper-cases a code and strips the dot, so that
+// "e11.9" and "E119" are the same code
+func NormalizeICD10Code(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), ".", ""))
+}
+
+// FormatICD10Code inserts the dot after the category, e.g. E119 -> E11.9
+func FormatICD10Code(code string) string {
+	if len(code) <= 3 {
+		return code
+	}
+	return code[:3] + "." + code[3:]
+}
+
+// LookupCode retrieves a code entry, rejecting unknown codes
+func (s *ICD10Service) LookupCode(code string) (*models.ICD10Code, error) {
+	entry, err := s.icd10Repo.FindByCode(NormalizeICD10Code(code))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown ICD-10 code %q", ErrInvalidInput, code)
+	}
+	return entry, nil
+}
+
+// Search finds codes for a typeahead query
+func (s *ICD10Service) Search(query string, limit int) ([]models.ICD10Code, error) {
+	query = strings.TrimSpace(query)
+	if len(query) < 2 {
+		return nil, fmt.Errorf("%w: query must be at least 2 characters", ErrInvalidInput)
+	}
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+	return s.icd10Repo.Search(NormalizeICD10Code(query), query, limit)
+}
+
+// ImportFile loads ICD-10 codes from a CSV file with code and description
+// columns, or from a CMS text file: either the codes file (code, whitespace,
+// description) or the fixed-width order file, of which only the billable
+// codes are kept
+func (s *ICD10Service) ImportFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var codes []models.ICD10Code
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		codes, err = parseICD10CSV(file)
+	case ".txt":
+		codes, err = parseICD10Text(file)
+	default:
+		return 0, fmt.Errorf("%w: unsupported code file format %q", ErrInvalidInput, filepath.Ext(path))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.icd10Repo.Import(codes); err != nil {
+		s.logger.Error("Failed to import ICD-10 codes", zap.Error(err), zap.String("path", path))
+		return 0, err
+	}
+	return len(codes), nil
+}
+
+func parseICD10CSV(r io.Reader) ([]models.ICD10Code, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	codeCol, descCol := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "code":
+			codeCol = i
+		case "description":
+			descCol = i
+		}
+	}
+	if codeCol < 0 || descCol < 0 {
+		return nil, fmt.Errorf("%w: code and description columns are required", ErrInvalidInput)
+	}
+
+	var codes []models.ICD10Code
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		code := NormalizeICD10Code(record[codeCol])
+		if code == "" {
+			return nil, fmt.Errorf("%w: line %d has no code", ErrInvalidInput, line)
+		}
+		codes = append(codes, models.ICD10Code{Code: code, Description: strings.TrimSpace(record[descCol])})
+	}
+	return codes, nil
+}
+
+func parseICD10Text(r io.Reader) ([]models.ICD10Code, error) {
+	var codes []models.ICD10Code
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \r")
+		if isICD10OrderLine(text) {
+			code, ok, err := parseICD10OrderLine(text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidInput, line, err)
+			}
+			if ok {
+				codes = append(codes, code)
+			}
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 {
+			continue
+		}
+		codes = append(codes, models.ICD10Code{
+			Code:        NormalizeICD10Code(fields[0]),
+			Description: strings.Join(fields[1:], " "),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CMS order file columns (1-based): order number 1-5, code 7-13, billable
+// flag 15, short description 17-76, long description from 78
+const (
+	icd10OrderCodeStart  = 6
+	icd10OrderCodeEnd    = 13
+	icd10OrderFlag       = 14
+	icd10OrderShortStart = 16
+	icd10OrderShortEnd   = 76
+	icd10OrderLongStart  = 77
+)
+
+// isICD10OrderLine tells an order file line, which starts with a five-digit
+// order number, from a codes file line, which starts with the code
+func isICD10OrderLine(text string) bool {
+	if len(text) <= icd10OrderShortStart || text[5] != ' ' {
+		return false
+	}
+	for _, c := range text[:5] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseICD10OrderLine reads one order file line. ok is false for header
+// rows, which are categories rather than billable codes.
+func parseICD10OrderLine(text string) (code models.ICD10Code, ok bool, err error) {
+	switch text[icd10OrderFlag] {
+	case '0':
+		return code, false, nil
+	case '1':
+	default:
+		return code, false, fmt.Errorf("billable flag must be 0 or 1, got %q", text[icd10OrderFlag])
+	}
+
+	code.Code = NormalizeICD10Code(text[icd10OrderCodeStart:icd10OrderCodeEnd])
+	if code.Code == "" {
+		return code, false, errors.New("no code")
+	}
+	if len(text) > icd10OrderLongStart {
+		code.Description = strings.TrimSpace(text[icd10OrderLongStart:])
+	}
+	if code.Description == "" {
+		end := icd10OrderShortEnd
+		if len(text) < end {
+			end = len(text)
+		}
+		code.Description = strings.TrimSpace(text[icd10OrderShortStart:end])
+	}
+	return code, true, nil
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var immunizationSites = []string{"left_arm", "right_arm", "left_thigh", "right_thigh", "oral", "nasal"}
+
+// Forecast statuses of a vaccine series
+const (
+	ForecastStatusComplete = "complete"
+	ForecastStatusUpcoming = "upcoming"
+	ForecastStatusDue      = "due"
+	ForecastStatusOverdue  = "overdue"
+	ForecastStatusAgedOut  = "aged_out" // past the age the series is recommended for
+)
+
+// overdueBatchSize is how many patients the overdue list forecasts at a time
+const overdueBatchSize = 500
+
+// ScheduledVaccine is one vaccine series of the immunization schedule, read
+// from the immunizations.schedule config
+type ScheduledVaccine struct {
+	Vaccine string          `json:"vaccine" mapstructure:"vaccine"`
+	Name    string          `json:"name" mapstructure:"name"`
+	Codes   []string        `json:"codes" mapstructure:"codes"` // CVX codes that count towards the series
+	MaxAge  string          `json:"max_age,omitempty" mapstructure:"max_age"`
+	Doses   []ScheduledDose `json:"doses" mapstructure:"doses"`
+
+	maxAge *scheduleAge
+}
+
+// ScheduledDose is one dose of a series. Ages are counted from birth and
+// written as a number and unit, e.g. 6w, 2m, 4y or 11y6m.
+type ScheduledDose struct {
+	Age         string `json:"age" mapstructure:"age"`
+	OverdueAge  string `json:"overdue_age" mapstructure:"overdue_age"`
+	MinInterval string `json:"min_interval,omitempty" mapstructure:"min_interval"` // since the previous dose
+
+	age, overdueAge scheduleAge
+	minInterval     *scheduleAge
+}
+
+// VaccineForecast is where a patient stands in one vaccine series
+type VaccineForecast struct {
+	Vaccine     string     `json:"vaccine"`
+	Name        string     `json:"name"`
+	DosesGiven  int        `json:"doses_given"`
+	SeriesDoses int        `json:"series_doses"`
+	NextDose    int        `json:"next_dose,omitempty"`
+	Status      string     `json:"status"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	OverdueDate *time.Time `json:"overdue_date,omitempty"`
+	LastGivenAt *time.Time `json:"last_given_at,omitempty"`
+}
+
+// ImmunizationForecast is the due and overdue doses of a patient. Without
+// a recorded date of birth the forecast assumes the latest birth date the
+// patient's age allows, so no dose is reported overdue too early.
+type ImmunizationForecast struct {
+	PatientID          uint              `json:"patient_id"`
+	DateOfBirth        time.Time         `json:"date_of_birth"`
+	BirthDateEstimated bool              `json:"birth_date_estimated"`
+	AsOf               time.Time         `json:"as_of"`
+	Vaccines           []VaccineForecast `json:"vaccines"`
+}
+
+// OverduePatient is a patient with overdue doses, for the front desk to call
+type OverduePatient struct {
+	PatientID          uint              `json:"patient_id"`
+	Name               string            `json:"name"`
+	Age                int               `json:"age"`
+	PhoneNumber        string            `json:"phone_number"`
+	BirthDateEstimated bool              `json:"birth_date_estimated"`
+	Overdue            []VaccineForecast `json:"overdue"`
+}
+
+// ImmunizationService handles vaccine administration records and forecasting
+type ImmunizationService struct {
+	immunizationRepo *repositories.ImmunizationRepository
+	patientRepo      *repositories.PatientRepository
+	logger           *zap.Logger
+}
+
+// NewImmunizationService creates a new immunization service instance
+func NewImmunizationService(immunizationRepo *repositories.ImmunizationRepository, patientRepo *repositories.PatientRepository, logger *zap.Logger) *ImmunizationService {
+	return &ImmunizationService{
+		immunizationRepo: immunizationRepo,
+		patientRepo:      patientRepo,
+		logger:           logger,
+	}
+}
+
+// GetSchedule returns the configured immunization schedule
+func (s *ImmunizationService) GetSchedule() ([]ScheduledVaccine, error) {
+	return loadImmunizationSchedule()
+}
+
+// GetPatientImmunizations retrieves all immunization records for a patient
+func (s *ImmunizationService) GetPatientImmunizations(patientID uint) ([]models.Immunization, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.immunizationRepo.FindByPatient(patientID)
+}
+
+// RecordImmunization records a dose. Unless the record is historical, the
+// dose was given by the recording user and needs its lot and site. The
+// dose number defaults to the next dose of the series.
+func (s *ImmunizationService) RecordImmunization(patientID uint, immunization *models.Immunization, userID uint) (*models.Immunization, error) {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	immunization.PatientID = patientID
+	immunization.Status = models.ImmunizationStatusCompleted
+	immunization.RecordedByID = userID
+	immunization.AdministeredByID = nil
+	if !immunization.Historical {
+		immunization.AdministeredByID = &userID
+	}
+	if immunization.AdministeredAt.IsZero() {
+		immunization.AdministeredAt = time.Now()
+	}
+	if err := validateImmunization(immunization, patient); err != nil {
+		return nil, err
+	}
+
+	schedule, err := loadImmunizationSchedule()
+	if err != nil {
+		return nil, err
+	}
+	series := scheduledVaccineForCode(schedule, immunization.VaccineCode)
+	if immunization.VaccineName == "" && series != nil {
+		immunization.VaccineName = series.Name
+	}
+	if immunization.DoseNumber == 0 {
+		existing, err := s.immunizationRepo.FindCompletedByPatients([]uint{patientID})
+		if err != nil {
+			return nil, err
+		}
+		immunization.DoseNumber = 1
+		for _, dose := range existing {
+			if dose.VaccineCode == immunization.VaccineCode || (series != nil && contains(series.Codes, dose.VaccineCode)) {
+				immunization.DoseNumber++
+			}
+		}
+	}
+
+	created, err := s.immunizationRepo.Create(immunization)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Immunization recorded",
+		zap.Uint("immunization_id", created.ID),
+		zap.Uint("patient_id", patientID),
+		zap.String("vaccine_code", created.VaccineCode),
+		zap.Int("dose_number", created.DoseNumber),
+	)
+	return created, nil
+}
+
+// MarkEnteredInError retracts an immunization record; it stays on file
+// but no longer counts towards the forecast
+func (s *ImmunizationService) MarkEnteredInError(patientID, immunizationID uint, reason string) (*models.Immunization, error) {
+	immunization, err := s.immunizationRepo.FindByID(immunizationID)
+	if err != nil {
+		return nil, err
+	}
+	if immunization.PatientID != patientID {
+		return nil, errors.New("immunization not found")
+	}
+	if immunization.Status == models.ImmunizationStatusEnteredInError {
+		return nil, fmt.Errorf("%w: immunization is already marked as entered in error", ErrConflict)
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidInput)
+	}
+
+	immunization.Status = models.ImmunizationStatusEnteredInError
+	immunization.StatusReason = reason
+	return s.immunizationRepo.Update(immunization)
+}
+
+// GetForecast forecasts the due and overdue doses of a patient as of today
+func (s *ImmunizationService) GetForecast(patientID uint) (*ImmunizationForecast, error) {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := loadImmunizationSchedule()
+	if err != nil {
+		return nil, err
+	}
+	doses, err := s.immunizationRepo.FindCompletedByPatients([]uint{patientID})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	birth, estimated := birthDate(patient, now)
+	return &ImmunizationForecast{
+		PatientID:          patientID,
+		DateOfBirth:        birth,
+		BirthDateEstimated: estimated,
+		AsOf:               now,
+		Vaccines:           forecastSchedule(schedule, birth, doses, now),
+	}, nil
+}
+
+// GetOverduePatients lists the patients with overdue doses, optionally of
+// a single vaccine series
+func (s *ImmunizationService) GetOverduePatients(vaccine string) ([]OverduePatient, error) {
+	schedule, err := loadImmunizationSchedule()
+	if err != nil {
+		return nil, err
+	}
+	if vaccine != "" {
+		var filtered []ScheduledVaccine
+		for _, series := range schedule {
+			if series.Vaccine == vaccine {
+				filtered = append(filtered, series)
+			}
+		}
+		if len(filtered) == 0 {
+			return nil, fmt.Errorf("%w: vaccine %q is not on the immunization schedule", ErrInvalidInput, vaccine)
+		}
+		schedule = filtered
+	}
+
+	now := time.Now()
+	overdue := []OverduePatient{}
+	var cutoff time.Time
+	if limit := scheduleAgeLimit(schedule); limit < math.MaxInt32 {
+		cutoff = now.AddDate(-limit, 0, 0)
+	}
+	err = s.patientRepo.FindBornAfterInBatches(cutoff, overdueBatchSize, func(patients []models.Patient) error {
+		ids := make([]uint, 0, len(patients))
+		for _, patient := range patients {
+			ids = append(ids, patient.ID)
+		}
+		doses, err := s.immunizationRepo.FindCompletedByPatients(ids)
+		if err != nil {
+			return err
+		}
+		byPatient := make(map[uint][]models.Immunization, len(patients))
+		for _, dose := range doses {
+			byPatient[dose.PatientID] = append(byPatient[dose.PatientID], dose)
+		}
+
+		for i := range patients {
+			birth, estimated := birthDate(&patients[i], now)
+			var late []VaccineForecast
+			for _, forecast := range forecastSchedule(schedule, birth, byPatient[patients[i].ID], now) {
+				if forecast.Status == ForecastStatusOverdue {
+					late = append(late, forecast)
+				}
+			}
+			if len(late) > 0 {
+				overdue = append(overdue, OverduePatient{
+					PatientID:          patients[i].ID,
+					Name:               patients[i].Name,
+					Age:                ageOn(birth, now),
+					PhoneNumber:        patients[i].PhoneNumber,
+					BirthDateEstimated: estimated,
+					Overdue:            late,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return overdue, nil
+}
+
+// forecastSchedule works out where the patient stands in each series from
+// the doses given, oldest first
+func forecastSchedule(schedule []ScheduledVaccine, birth time.Time, doses []models.Immunization, asOf time.Time) []VaccineForecast {
+	forecasts := make([]VaccineForecast, 0, len(schedule))
+	for _, series := range schedule {
+		forecast := VaccineForecast{
+			Vaccine:     series.Vaccine,
+			Name:        series.Name,
+			SeriesDoses: len(series.Doses),
+		}
+		var last *time.Time
+		for i := range doses {
+			if contains(series.Codes, doses[i].VaccineCode) {
+				forecast.DosesGiven++
+				last = &doses[i].AdministeredAt
+			}
+		}
+		forecast.LastGivenAt = last
+
+		if forecast.DosesGiven >= len(series.Doses) {
+			forecast.Status = ForecastStatusComplete
+			forecasts = append(forecasts, forecast)
+			continue
+		}
+		if series.maxAge != nil && !asOf.Before(series.maxAge.after(birth)) {
+			forecast.Status = ForecastStatusAgedOut
+			forecasts = append(forecasts, forecast)
+			continue
+		}
+
+		dose := series.Doses[forecast.DosesGiven]
+		due := dose.age.after(birth)
+		if last != nil && dose.minInterval != nil {
+			if earliest := dose.minInterval.after(*last); earliest.After(due) {
+				due = earliest
+			}
+		}
+		// A dose held back by the interval is overdue as soon as it is due
+		overdueAt := dose.overdueAge.after(birth)
+		if overdueAt.Before(due) {
+			overdueAt = due
+		}
+
+		forecast.NextDose = forecast.DosesGiven + 1
+		forecast.DueDate = &due
+		forecast.OverdueDate = &overdueAt
+		switch {
+		case asOf.Before(due):
+			forecast.Status = ForecastStatusUpcoming
+		case asOf.Before(overdueAt):
+			forecast.Status = ForecastStatusDue
+		default:
+			forecast.Status = ForecastStatusOverdue
+		}
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts
+}
+
+// birthDate is the patient's date of birth or, when none is recorded, the
+// latest one the age they were registered with allows. The stored age is
+// not kept up to date, so it is counted from the registration date.
+func birthDate(patient *models.Patient, now time.Time) (time.Time, bool) {
+	if patient.DateOfBirth != nil {
+		return *patient.DateOfBirth, false
+	}
+	registered := patient.CreatedAt.In(now.Location())
+	if patient.CreatedAt.IsZero() {
+		registered = now
+	}
+	day := time.Date(registered.Year(), registered.Month(), registered.Day(), 0, 0, 0, 0, now.Location())
+	return day.AddDate(-patient.Age, 0, 0), true
+}
+
+// scheduleAgeLimit is the age in whole years past which no series in the
+// schedule applies; without a max_age on every series it covers everyone
+func scheduleAgeLimit(schedule []ScheduledVaccine) int {
+	limit := 0
+	for _, series := range schedule {
+		if series.maxAge == nil {
+			return math.MaxInt32
+		}
+		age := series.maxAge
+		years := age.years + age.months/12
+		if age.months%12 != 0 || age.days != 0 {
+			years++
+		}
+		if years > limit {
+			limit = years
+		}
+	}
+	return limit
+}
+
+func scheduledVaccineForCode(schedule []ScheduledVaccine, code string) *ScheduledVaccine {
+	for i := range schedule {
+		if contains(schedule[i].Codes, code) {
+			return &schedule[i]
+		}
+	}
+	return nil
+}
+
+// loadImmunizationSchedule reads and checks the immunizations.schedule config
+func loadImmunizationSchedule() ([]ScheduledVaccine, error) {
+	var schedule []ScheduledVaccine
+	if err := viper.UnmarshalKey("immunizations.schedule", &schedule); err != nil {
+		return nil, fmt.Errorf("immunization schedule: %w", err)
+	}
+
+	seen := make(map[string]bool, len(schedule))
+	for i := range schedule {
+		series := &schedule[i]
+		if series.Vaccine == "" || len(series.Codes) == 0 || len(series.Doses) == 0 {
+			return nil, errors.New("immunization schedule: every vaccine needs a key, codes and doses")
+		}
+		if seen[series.Vaccine] {
+			return nil, fmt.Errorf("immunization schedule: vaccine %s is listed twice", series.Vaccine)
+		}
+		seen[series.Vaccine] = true
+
+		if series.MaxAge != "" {
+			age, err := parseScheduleAge(series.MaxAge)
+			if err != nil {
+				return nil, fmt.Errorf("immunization schedule: %s: %w", series.Vaccine, err)
+			}
+			series.maxAge = &age
+		}
+		for j := range series.Doses {
+			dose := &series.Doses[j]
+			var err error
+			if dose.age, err = parseScheduleAge(dose.Age); err != nil {
+				return nil, fmt.Errorf("immunization schedule: %s dose %d: %w", series.Vaccine, j+1, err)
+			}
+			if dose.overdueAge, err = parseScheduleAge(dose.OverdueAge); err != nil {
+				return nil, fmt.Errorf("immunization schedule: %s dose %d: %w", series.Vaccine, j+1, err)
+			}
+			if dose.MinInterval != "" {
+				interval, err := parseScheduleAge(dose.MinInterval)
+				if err != nil {
+					return nil, fmt.Errorf("immunization schedule: %s dose %d: %w", series.Vaccine, j+1, err)
+				}
+				dose.minInterval = &interval
+			}
+		}
+	}
+	return schedule, nil
+}
+
+// scheduleAge is an age or interval of the immunization schedule
+type scheduleAge struct {
+	years, months, days int
+}
+
+// after is the date the age or interval is reached, counting from start
+func (a scheduleAge) after(start time.Time) time.Time {
+	return start.AddDate(a.years, a.months, a.days)
+}
+
+// parseScheduleAge parses ages such as 0d, 6w, 2m, 4y or 11y6m
+func parseScheduleAge(value string) (scheduleAge, error) {
+	if value == "" {
+		return scheduleAge{}, errors.New("age is required")
+	}
+	var age scheduleAge
+	rest := value
+	for rest != "" {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return scheduleAge{}, fmt.Errorf("invalid age %q", value)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return scheduleAge{}, fmt.Errorf("invalid age %q", value)
+		}
+		switch rest[i] {
+		case 'y':
+			age.years += n
+		case 'm':
+			age.months += n
+		case 'w':
+			age.days += 7 * n
+		case 'd':
+			age.days += n
+		default:
+			return scheduleAge{}, fmt.Errorf("invalid age %q", value)
+		}
+		rest = rest[i+1:]
+	}
+	return age, nil
+}
+
+func validateImmunization(immunization *models.Immunization, patient *models.Patient) error {
+	if immunization.VaccineCode == "" {
+		return fmt.Errorf("%w: vaccine_code is required", ErrInvalidInput)
+	}
+	if immunization.DoseNumber < 0 {
+		return fmt.Errorf("%w: dose_number must be positive", ErrInvalidInput)
+	}
+	if immunization.Site != "" && !contains(immunizationSites, immunization.Site) {
+		return fmt.Errorf("%w: site must be one of %v", ErrInvalidInput, immunizationSites)
+	}
+	if !immunization.Historical && (immunization.LotNumber == "" || immunization.Site == "") {
+		return fmt.Errorf("%w: lot_number and site are required for doses given here", ErrInvalidInput)
+	}
+	if immunization.AdministeredAt.After(time.Now()) {
+		return fmt.Errorf("%w: administered_at cannot be in the future", ErrInvalidInput)
+	}
+	if patient.DateOfBirth != nil && immunization.AdministeredAt.Before(*patient.DateOfBirth) {
+		return fmt.Errorf("%w: administered_at is before the patient's date of birth", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/eligibility"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var subscriberRelationships = []string{
+	models.SubscriberRelationshipSelf,
+	models.SubscriberRelationshipSpouse,
+	models.SubscriberRelationshipChild,
+	models.SubscriberRelationshipOther,
+}
+
+// InsuranceService handles the payer catalogue and patient coverages
+type InsuranceService struct {
+	insuranceRepo *repositories.InsuranceRepository
+	patientRepo   *repositories.PatientRepository
+	checker       eligibility.Checker
+	logger        *zap.Logger
+}
+
+// NewInsuranceService creates a new insurance service instance
+func NewInsuranceService(insuranceRepo *repositories.InsuranceRepository, patientRepo *repositories.PatientRepository, checker eligibility.Checker, logger *zap.Logger) *InsuranceService {
+	return &InsuranceService{
+		insuranceRepo: insuranceRepo,
+		patientRepo:   patientRepo,
+		checker:       checker,
+		logger:        logger,
+	}
+}
+
+// SearchPayers lists active payers of the catalogue
+func (s *InsuranceService) SearchPayers(query string) ([]models.Payer, error) {
+	return s.insuranceRepo.SearchPayers(strings.TrimSpace(query))
+}
+
+// GetCoverages retrieves a patient's coverages, primary first
+func (s *InsuranceService) GetCoverages(patientID uint) ([]models.InsuranceCoverage, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.insuranceRepo.FindByPatient(patientID)
+}
+
+// CoverageOn returns the patient's coverages in effect on a day, in
+// billing order
+func (s *InsuranceService) CoverageOn(patientID uint, day time.Time) ([]models.InsuranceCoverage, error) {
+	coverages, err := s.insuranceRepo.FindByPatient(patientID)
+	if err != nil {
+		return nil, err
+	}
+	var active []models.InsuranceCoverage
+	for _, coverage := range coverages {
+		if coverage.ActiveOn(day) {
+			active = append(active, coverage)
+		}
+	}
+	return active, nil
+}
+
+// AddCoverage records a new coverage for a patient
+func (s *InsuranceService) AddCoverage(patientID uint, coverage *models.InsuranceCoverage, recordedByID uint) (*models.InsuranceCoverage, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	coverage.ID = 0
+	coverage.PatientID = patientID
+	coverage.RecordedByID = recordedByID
+	coverage.EligibilityStatus = models.EligibilityStatusUnknown
+	if err := s.validateCoverage(coverage); err != nil {
+		return nil, err
+	}
+	return s.insuranceRepo.Create(coverage)
+}
+
+// UpdateCoverage updates a coverage. Changing the payer or member ID
+// resets the eligibility status.
+func (s *InsuranceService) UpdateCoverage(patientID, coverageID uint, updated *models.InsuranceCoverage) (*models.InsuranceCoverage, error) {
+	coverage, err := s.findPatientCoverage(patientID, coverageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if coverage.PayerCode != updated.PayerCode || coverage.MemberID != updated.MemberID {
+		coverage.EligibilityStatus = models.EligibilityStatusUnknown
+		coverage.EligibilityMessage = ""
+		coverage.EligibilityCheckedAt = nil
+	}
+	coverage.PayerCode = updated.PayerCode
+	coverage.PlanName = updated.PlanName
+	coverage.MemberID = updated.MemberID
+	coverage.GroupNumber = updated.GroupNumber
+	coverage.SubscriberRelationship = updated.SubscriberRelationship
+	coverage.SubscriberName = updated.SubscriberName
+	coverage.Priority = updated.Priority
+	coverage.EffectiveFrom = updated.EffectiveFrom
+	coverage.EffectiveTo = updated.EffectiveTo
+	if err := s.validateCoverage(coverage); err != nil {
+		return nil, err
+	}
+	coverage.Payer = nil
+	return s.insuranceRepo.Update(coverage)
+}
+
+// DeleteCoverage removes a coverage entered in error. Ended coverage
+// should be given an end date instead.
+func (s *InsuranceService) DeleteCoverage(patientID, coverageID uint) error {
+	coverage, err := s.findPatientCoverage(patientID, coverageID)
+	if err != nil {
+		return err
+	}
+	return s.insuranceRepo.Delete(coverage.ID)
+}
+
+// CheckEligibility verifies a coverage with the payer and records the answer
+func (s *InsuranceService) CheckEligibility(ctx context.Context, patientID, coverageID uint) (*models.InsuranceCoverage, error) {
+	coverage, err := s.findPatientCoverage(patientID, coverageID)
+	if err != nil {
+		return nil, err
+	}
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	req := eligibility.Request{
+		PayerCode:   coverage.PayerCode,
+		MemberID:    coverage.MemberID,
+		GroupNumber: coverage.GroupNumber,
+		PatientName: patient.Name,
+		ServiceDate: now,
+	}
+	if coverage.Payer != nil {
+		req.EDIPayerID = coverage.Payer.EDIPayerID
+	}
+
+	if !coverage.ActiveOn(now) {
+		coverage.EligibilityStatus = models.EligibilityStatusIneligible
+		coverage.EligibilityMessage = "coverage is not in effect today"
+	} else if result, err := s.checker.Check(ctx, req); err != nil {
+		s.logger.Warn("Eligibility check failed", zap.Error(err), zap.Uint("coverage_id", coverage.ID))
+		coverage.EligibilityStatus = models.EligibilityStatusError
+		coverage.EligibilityMessage = err.Error()
+	} else {
+		coverage.EligibilityStatus = result.Status
+		coverage.EligibilityMessage = result.Message
+	}
+	coverage.EligibilityCheckedAt = &now
+	coverage.Payer = nil
+	return s.insuranceRepo.Update(coverage)
+}
+
+// ImportPayersFile loads the payer catalogue from a CSV file with code,
+// name, edi_payer_id, member_id_pattern and optional claim_filing_code
+// and active columns
+func (s *InsuranceService) ImportPayersFile(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return 0, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["code"]; !ok {
+		return 0, fmt.Errorf("%w: code and name columns are required", ErrInvalidInput)
+	}
+	if _, ok := columns["name"]; !ok {
+		return 0, fmt.Errorf("%w: code and name columns are required", ErrInvalidInput)
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var payers []models.Payer
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		payer := models.Payer{
+			Code:            strings.ToUpper(field(record, "code")),
+			Name:            field(record, "name"),
+			EDIPayerID:      field(record, "edi_payer_id"),
+			MemberIDPattern: field(record, "member_id_pattern"),
+			ClaimFilingCode: strings.ToUpper(field(record, "claim_filing_code")),
+			Active:          true,
+		}
+		if payer.ClaimFilingCode == "" {
+			payer.ClaimFilingCode = "CI"
+		}
+		if payer.Code == "" || payer.Name == "" {
+			return 0, fmt.Errorf("%w: line %d needs a code and a name", ErrInvalidInput, line)
+		}
+		if payer.MemberIDPattern != "" {
+			if _, err := regexp.Compile(payer.MemberIDPattern); err != nil {
+				return 0, fmt.Errorf("%w: line %d has an invalid member_id_pattern: %v", ErrInvalidInput, line, err)
+			}
+		}
+		if active := field(record, "active"); active != "" {
+			if payer.Active, err = strconv.ParseBool(active); err != nil {
+				return 0, fmt.Errorf("%w: line %d has an invalid active flag", ErrInvalidInput, line)
+			}
+		}
+		payers = append(payers, payer)
+	}
+
+	if err := s.insuranceRepo.ImportPayers(payers); err != nil {
+		s.logger.Error("Failed to import payers", zap.Error(err), zap.String("path", path))
+		return 0, err
+	}
+	return len(payers), nil
+}
+
+func (s *InsuranceService) findPatientCoverage(patientID, coverageID uint) (*models.InsuranceCoverage, error) {
+	coverage, err := s.insuranceRepo.FindByID(coverageID)
+	if err != nil {
+		return nil, err
+	}
+	if coverage.PatientID != patientID {
+		return nil, errors.New("coverage not found")
+	}
+	return coverage, nil
+}
+
+// validateCoverage checks a coverage against the payer catalogue and the
+// patient's other coverages; two coverages with the same priority may not
+// overlap in time
+func (s *InsuranceService) validateCoverage(coverage *models.InsuranceCoverage) error {
+	coverage.PayerCode = strings.ToUpper(strings.TrimSpace(coverage.PayerCode))
+	coverage.MemberID = strings.TrimSpace(coverage.MemberID)
+	coverage.GroupNumber = strings.TrimSpace(coverage.GroupNumber)
+	coverage.PlanName = strings.TrimSpace(coverage.PlanName)
+	if coverage.SubscriberRelationship == "" {
+		coverage.SubscriberRelationship = models.SubscriberRelationshipSelf
+	}
+
+	if coverage.PlanName == "" || coverage.MemberID == "" {
+		return fmt.Errorf("%w: plan name and member ID are required", ErrInvalidInput)
+	}
+	if !contains(subscriberRelationships, coverage.SubscriberRelationship) {
+		return fmt.Errorf("%w: subscriber_relationship must be one of %v", ErrInvalidInput, subscriberRelationships)
+	}
+	if coverage.SubscriberRelationship != models.SubscriberRelationshipSelf && strings.TrimSpace(coverage.SubscriberName) == "" {
+		return fmt.Errorf("%w: subscriber name is required when the patient is not the subscriber", ErrInvalidInput)
+	}
+	if coverage.Priority < models.CoveragePriorityPrimary || coverage.Priority > models.CoveragePriorityTertiary {
+		return fmt.Errorf("%w: priority must be 1 (primary), 2 (secondary) or 3 (tertiary)", ErrInvalidInput)
+	}
+	if coverage.EffectiveFrom.IsZero() {
+		return fmt.Errorf("%w: effective_from is required", ErrInvalidInput)
+	}
+	if coverage.EffectiveTo != nil && coverage.EffectiveTo.Before(coverage.EffectiveFrom) {
+		return fmt.Errorf("%w: effective_to cannot be before effective_from", ErrInvalidInput)
+	}
+
+	payer, err := s.insuranceRepo.FindPayer(coverage.PayerCode)
+	if err != nil || !payer.Active {
+		return fmt.Errorf("%w: unknown or inactive payer %q", ErrInvalidInput, coverage.PayerCode)
+	}
+	if payer.MemberIDPattern != "" {
+		pattern, err := regexp.Compile("^(?:" + payer.MemberIDPattern + ")$")
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(coverage.MemberID) {
+			return fmt.Errorf("%w: member ID does not match the format used by %s", ErrInvalidInput, payer.Name)
+		}
+	}
+
+	others, err := s.insuranceRepo.FindByPatient(coverage.PatientID)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.ID != coverage.ID && other.Priority == coverage.Priority && coveragesOverlap(other, *coverage) {
+			return fmt.Errorf("%w: the patient already has coverage with priority %d in this period", ErrConflict, coverage.Priority)
+		}
+	}
+	return nil
+}
+
+func coveragesOverlap(a, b models.InsuranceCoverage) bool {
+	aEndsBeforeB := a.EffectiveTo != nil && a.EffectiveTo.Before(b.EffectiveFrom)
+	bEndsBeforeA := b.EffectiveTo != nil && b.EffectiveTo.Before(a.EffectiveFrom)
+	return !aEndsBeforeB && !bEndsBeforeA
+}
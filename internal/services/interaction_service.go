@@ -0,0 +1,347 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// Actions taken for an interaction finding
+const (
+	InteractionActionInform   = "inform"
+	InteractionActionOverride = "override"
+	InteractionActionBlock    = "block"
+)
+
+var interactionSeverityRank = map[string]int{
+	models.InteractionSeverityMinor:           1,
+	models.InteractionSeverityModerate:        2,
+	models.InteractionSeverityMajor:           3,
+	models.InteractionSeverityContraindicated: 4,
+}
+
+// InteractionFinding is a single interaction found by a check
+type InteractionFinding struct {
+	Kind        string `json:"kind"`
+	Severity    string `json:"severity"`
+	Interacting string `json:"interacting"`
+	Description string `json:"description"`
+	Action      string `json:"action"`
+}
+
+// InteractionCheck is the outcome of checking a drug against a patient
+type InteractionCheck struct {
+	Findings         []InteractionFinding `json:"findings"`
+	Blocked          bool                 `json:"blocked"`
+	RequiresOverride bool                 `json:"requires_override"`
+}
+
+// InteractionError is returned when a prescription is blocked by an
+// interaction or needs an override reason that was not given
+type InteractionError struct {
+	Check *InteractionCheck
+}
+
+func (e *InteractionError) Error() string {
+	if e.Check.Blocked {
+		return "prescription blocked by a contraindicated interaction"
+	}
+	return "prescription has interactions that require an override reason"
+}
+
+func (e *InteractionError) Unwrap() error {
+	return ErrConflict
+}
+
+// InteractionService checks prescriptions against the local interaction dataset
+type InteractionService struct {
+	interactionRepo  *repositories.InteractionRepository
+	prescriptionRepo *repositories.PrescriptionRepository
+	allergyRepo      *repositories.AllergyRepository
+	logger           *zap.Logger
+}
+
+// NewInteractionService creates a new interaction service instance
+func NewInteractionService(interactionRepo *repositories.InteractionRepository, prescriptionRepo *repositories.PrescriptionRepository, allergyRepo *repositories.AllergyRepository, logger *zap.Logger) *InteractionService {
+	return &InteractionService{
+		interactionRepo:  interactionRepo,
+		prescriptionRepo: prescriptionRepo,
+		allergyRepo:      allergyRepo,
+		logger:           logger,
+	}
+}
+
+// Check compares a drug with the patient's active prescriptions and
+// allergies. excludeID skips a prescription, e.g. the one being renewed.
+func (s *InteractionService) Check(patientID uint, drug, drugCode string, excludeID uint) (*InteractionCheck, error) {
+	check := &InteractionCheck{Findings: []InteractionFinding{}}
+	subjects := interactionSubjects(drug, drugCode)
+
+	// Drug-drug interactions with active medications
+	active, err := s.prescriptionRepo.FindByPatient(patientID, models.PrescriptionStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	activeByName := make(map[string]string)
+	var activeSubjects []string
+	for _, p := range active {
+		if p.ID == excludeID {
+			continue
+		}
+		for _, subject := range interactionSubjects(p.Drug, p.DrugCode) {
+			activeByName[subject] = p.Drug
+			activeSubjects = append(activeSubjects, subject)
+		}
+	}
+	drugDrug, err := s.interactionRepo.FindMatches(models.InteractionKindDrugDrug, subjects, activeSubjects)
+	if err != nil {
+		return nil, err
+	}
+	for _, interaction := range drugDrug {
+		other := interaction.SubjectB
+		if contains(activeSubjects, interaction.SubjectA) && !contains(subjects, interaction.SubjectA) {
+			other = interaction.SubjectA
+		}
+		check.add(models.InteractionKindDrugDrug, interaction.Severity, activeByName[other], interaction.Description)
+	}
+
+	// Drug-allergy interactions with recorded allergies
+	allergies, err := s.allergyRepo.FindActiveByPatient(patientID)
+	if err != nil {
+		return nil, err
+	}
+	allergenByName := make(map[string]string)
+	var allergenSubjects []string
+	for _, allergy := range allergies {
+		if allergy.NKDA {
+			continue
+		}
+		for _, subject := range interactionSubjects(allergy.Substance, allergy.SubstanceCode) {
+			allergenByName[subject] = allergy.Substance
+			allergenSubjects = append(allergenSubjects, subject)
+			// The drug itself is a recorded allergen
+			if contains(subjects, subject) {
+				check.add(models.InteractionKindDrugAllergy, models.InteractionSeverityContraindicated,
+					allergy.Substance, "Patient has a recorded allergy to this drug: "+allergy.Reaction)
+			}
+		}
+	}
+	drugAllergy, err := s.interactionRepo.FindMatches(models.InteractionKindDrugAllergy, subjects, allergenSubjects)
+	if err != nil {
+		return nil, err
+	}
+	for _, interaction := range drugAllergy {
+		allergen := interaction.SubjectB
+		if contains(allergenSubjects, interaction.SubjectA) && !contains(subjects, interaction.SubjectA) {
+			allergen = interaction.SubjectA
+		}
+		check.add(models.InteractionKindDrugAllergy, interaction.Severity, allergenByName[allergen], interaction.Description)
+	}
+
+	return check, nil
+}
+
+func (c *InteractionCheck) add(kind, severity, interacting, description string) {
+	for _, finding := range c.Findings {
+		if finding.Kind == kind && finding.Interacting == interacting && finding.Description == description {
+			return
+		}
+	}
+
+	action := interactionAction(severity)
+	switch action {
+	case InteractionActionBlock:
+		c.Blocked = true
+	case InteractionActionOverride:
+		c.RequiresOverride = true
+	}
+	c.Findings = append(c.Findings, InteractionFinding{
+		Kind:        kind,
+		Severity:    severity,
+		Interacting: interacting,
+		Description: description,
+		Action:      action,
+	})
+}
+
+// interactionAction decides what a finding of the given severity does,
+// using the interactions.block_at and interactions.override_at settings
+func interactionAction(severity string) string {
+	blockAt := viper.GetString("interactions.block_at")
+	if blockAt == "" {
+		blockAt = models.InteractionSeverityContraindicated
+	}
+	overrideAt := viper.GetString("interactions.override_at")
+	if overrideAt == "" {
+		overrideAt = models.InteractionSeverityModerate
+	}
+
+	rank := interactionSeverityRank[severity]
+	switch {
+	case rank >= interactionSeverityRank[blockAt]:
+		return InteractionActionBlock
+	case rank >= interactionSeverityRank[overrideAt]:
+		return InteractionActionOverride
+	default:
+		return InteractionActionInform
+	}
+}
+
+// alertsFor turns the findings of a check into alerts to store with a prescription
+func (c *InteractionCheck) alertsFor(overrideReason string, doctorID uint) []models.InteractionAlert {
+	alerts := make([]models.InteractionAlert, 0, len(c.Findings))
+	for _, finding := range c.Findings {
+		alert := models.InteractionAlert{
+			Kind:        finding.Kind,
+			Severity:    finding.Severity,
+			Interacting: finding.Interacting,
+			Description: finding.Description,
+		}
+		if finding.Action == InteractionActionOverride {
+			alert.OverrideReason = overrideReason
+			alert.AcknowledgedByID = doctorID
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// ImportFile loads an interaction dataset from a CSV or JSON file. Rows
+// carry kind, subject_a, subject_b, severity and description.
+func (s *InteractionService) ImportFile(path, source string, replace bool) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if source == "" {
+		source = filepath.Base(path)
+	}
+
+	var interactions []models.DrugInteraction
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		interactions, err = parseInteractionCSV(file)
+	case ".json":
+		interactions, err = parseInteractionJSON(file)
+	default:
+		return 0, fmt.Errorf("%w: unsupported dataset format %q", ErrInvalidInput, filepath.Ext(path))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range interactions {
+		interactions[i].Source = source
+		if err := normalizeInteraction(&interactions[i]); err != nil {
+			return 0, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+	}
+
+	if err := s.interactionRepo.Import(source, interactions, replace); err != nil {
+		s.logger.Error("Failed to import interaction dataset", zap.Error(err), zap.String("path", path))
+		return 0, err
+	}
+	return len(interactions), nil
+}
+
+func parseInteractionCSV(r io.Reader) ([]models.DrugInteraction, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"subject_a", "subject_b", "severity"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidInput, required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var interactions []models.DrugInteraction
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		interactions = append(interactions, models.DrugInteraction{
+			Kind:        field(record, "kind"),
+			SubjectA:    field(record, "subject_a"),
+			SubjectB:    field(record, "subject_b"),
+			Severity:    field(record, "severity"),
+			Description: field(record, "description"),
+		})
+	}
+	return interactions, nil
+}
+
+func parseInteractionJSON(r io.Reader) ([]models.DrugInteraction, error) {
+	var interactions []models.DrugInteraction
+	if err := json.NewDecoder(r).Decode(&interactions); err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+func normalizeInteraction(interaction *models.DrugInteraction) error {
+	interaction.Kind = strings.ToLower(strings.TrimSpace(interaction.Kind))
+	if interaction.Kind == "" {
+		interaction.Kind = models.InteractionKindDrugDrug
+	}
+	interaction.SubjectA = normalizeSubject(interaction.SubjectA)
+	interaction.SubjectB = normalizeSubject(interaction.SubjectB)
+	interaction.Severity = strings.ToLower(strings.TrimSpace(interaction.Severity))
+
+	switch {
+	case interaction.Kind != models.InteractionKindDrugDrug && interaction.Kind != models.InteractionKindDrugAllergy:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidInput, interaction.Kind)
+	case interaction.SubjectA == "" || interaction.SubjectB == "":
+		return fmt.Errorf("%w: subject_a and subject_b are required", ErrInvalidInput)
+	case interactionSeverityRank[interaction.Severity] == 0:
+		return fmt.Errorf("%w: unknown severity %q", ErrInvalidInput, interaction.Severity)
+	}
+	return nil
+}
+
+func normalizeSubject(subject string) string {
+	return strings.ToLower(strings.TrimSpace(subject))
+}
+
+// interactionSubjects returns the lookup keys for a drug or allergen
+func interactionSubjects(name, code string) []string {
+	var subjects []string
+	if n := normalizeSubject(name); n != "" {
+		subjects = append(subjects, n)
+	}
+	if c := normalizeSubject(code); c != "" {
+		subjects = append(subjects, c)
+	}
+	return subjects
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var (
+	labPriorities = []string{"routine", "urgent", "stat"}
+	labStatuses   = []string{
+		models.LabOrderStatusOrdered,
+		models.LabOrderStatusCollected,
+		models.LabOrderStatusResulted,
+		models.LabOrderStatusVerified,
+		models.LabOrderStatusCancelled,
+	}
+)
+
+// LabService handles lab order and result business logic
+type LabService struct {
+	labRepo             *repositories.LabRepository
+	patientRepo         *repositories.PatientRepository
+	encounterRepo       *repositories.EncounterRepository
+	notificationService *NotificationService
+	logger              *zap.Logger
+}
+
+// NewLabService creates a new lab service instance
+func NewLabService(labRepo *repositories.LabRepository, patientRepo *repositories.PatientRepository, encounterRepo *repositories.EncounterRepository, notificationService *NotificationService, logger *zap.Logger) *LabService {
+	return &LabService{
+		labRepo:             labRepo,
+		patientRepo:         patientRepo,
+		encounterRepo:       encounterRepo,
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// PlaceOrder creates a lab order for a patient
+func (s *LabService) PlaceOrder(patientID uint, order *models.LabOrder, doctorID uint) (*models.LabOrder, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	if order.Priority == "" {
+		order.Priority = "routine"
+	}
+	if !contains(labPriorities, order.Priority) {
+		return nil, fmt.Errorf("%w: priority must be one of %v", ErrInvalidInput, labPriorities)
+	}
+	if order.EncounterID != nil {
+		encounter, err := s.encounterRepo.FindByID(*order.EncounterID)
+		if err != nil || encounter.PatientID != patientID {
+			return nil, fmt.Errorf("%w: encounter does not belong to this patient", ErrInvalidInput)
+		}
+	}
+
+	order.ID = 0
+	order.PatientID = patientID
+	order.OrderingDoctorID = doctorID
+	order.Status = models.LabOrderStatusOrdered
+	order.OrderedAt = time.Now()
+	order.Results = nil
+	return s.labRepo.CreateOrder(order)
+}
+
+// GetOrderByID retrieves a lab order with its results
+func (s *LabService) GetOrderByID(id uint) (*models.LabOrder, error) {
+	return s.labRepo.FindOrderByID(id)
+}
+
+// GetPatientOrders retrieves a patient's lab orders
+func (s *LabService) GetPatientOrders(patientID uint) ([]models.LabOrder, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.labRepo.FindOrdersByPatient(patientID)
+}
+
+// GetWorklist retrieves the orders in a given status
+func (s *LabService) GetWorklist(status string) ([]models.LabOrder, error) {
+	if status == "" {
+		status = models.LabOrderStatusOrdered
+	}
+	if !contains(labStatuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of %v", ErrInvalidInput, labStatuses)
+	}
+	return s.labRepo.FindOrdersByStatus(status)
+}
+
+// CollectSpecimen marks the specimen of an order as collected
+func (s *LabService) CollectSpecimen(id, userID uint) (*models.LabOrder, error) {
+	return s.labRepo.UpdateOrder(id, func(order *models.LabOrder) error {
+		if err := transition(order, models.LabOrderStatusOrdered, models.LabOrderStatusCollected); err != nil {
+			return err
+		}
+		now := time.Now()
+		order.CollectedAt = &now
+		order.CollectedByID = &userID
+		return nil
+	})
+}
+
+// EnterResults records the results of a collected order, flagging each
+// value against its reference and critical ranges. Results may be
+// corrected until the order is verified. The ordering doctor is notified
+// of any critical value.
+func (s *LabService) EnterResults(id uint, results []models.LabResult, userID uint) (*models.LabOrder, error) {
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%w: at least one result is required", ErrInvalidInput)
+	}
+
+	var critical []models.LabResult
+	for i := range results {
+		results[i].ID = 0
+		if err := flagLabResult(&results[i]); err != nil {
+			return nil, err
+		}
+		if results[i].Flag == models.LabFlagCriticalLow || results[i].Flag == models.LabFlagCriticalHigh {
+			critical = append(critical, results[i])
+		}
+	}
+
+	saved, err := s.labRepo.SaveResults(id, results, func(order *models.LabOrder) error {
+		if order.Status != models.LabOrderStatusCollected && order.Status != models.LabOrderStatusResulted {
+			return fmt.Errorf("%w: results cannot be entered for a %s order", ErrConflict, order.Status)
+		}
+		now := time.Now()
+		order.Status = models.LabOrderStatusResulted
+		order.ResultedAt = &now
+		order.ResultedByID = &userID
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to save lab results", zap.Error(err), zap.Uint("order_id", id))
+		return nil, err
+	}
+
+	if len(critical) > 0 {
+		s.notifyCritical(saved, critical)
+	}
+	return saved, nil
+}
+
+// VerifyResults marks the results of an order as verified, freezing them
+func (s *LabService) VerifyResults(id, userID uint) (*models.LabOrder, error) {
+	return s.labRepo.UpdateOrder(id, func(order *models.LabOrder) error {
+		if err := transition(order, models.LabOrderStatusResulted, models.LabOrderStatusVerified); err != nil {
+			return err
+		}
+		now := time.Now()
+		order.VerifiedAt = &now
+		order.VerifiedByID = &userID
+		return nil
+	})
+}
+
+// CancelOrder cancels an order whose results have not been entered
+func (s *LabService) CancelOrder(id uint, reason string) (*models.LabOrder, error) {
+	return s.labRepo.UpdateOrder(id, func(order *models.LabOrder) error {
+		if order.Status != models.LabOrderStatusOrdered && order.Status != models.LabOrderStatusCollected {
+			return fmt.Errorf("%w: a %s order cannot be cancelled", ErrConflict, order.Status)
+		}
+		now := time.Now()
+		order.Status = models.LabOrderStatusCancelled
+		order.CancelledAt = &now
+		order.CancelReason = reason
+		return nil
+	})
+}
+
+// transition moves an order from one status to another
+func transition(order *models.LabOrder, from, to string) error {
+	if order.Status != from {
+		return fmt.Errorf("%w: order is %s, expected %s before %s", ErrConflict, order.Status, from, to)
+	}
+	order.Status = to
+	return nil
+}
+
+func (s *LabService) notifyCritical(order *models.LabOrder, critical []models.LabResult) {
+	values := make([]string, 0, len(critical))
+	for _, result := range critical {
+		values = append(values, fmt.Sprintf("%s %s %s (%s)", result.AnalyteName, result.Value, result.Unit, result.Flag))
+	}
+	title := fmt.Sprintf("Critical result: %s", order.TestName)
+	message := fmt.Sprintf("Lab order #%d for patient #%d has critical values: %s",
+		order.ID, order.PatientID, strings.Join(values, "; "))
+
+	patientID := order.PatientID
+	err := s.notificationService.Notify(order.OrderingDoctorID, &patientID, models.NotificationTypeCriticalResult, title, message)
+	if err != nil {
+		s.logger.Error("Failed to notify ordering doctor of critical result",
+			zap.Error(err), zap.Uint("order_id", order.ID), zap.Uint("doctor_id", order.OrderingDoctorID))
+	}
+}
+
+// flagLabResult sets the flag of a result from its ranges. Critical
+// ranges take precedence over the reference range.
+func flagLabResult(result *models.LabResult) error {
+	if result.AnalyteName == "" || strings.TrimSpace(result.Value) == "" {
+		return fmt.Errorf("%w: analyte_name and value are required", ErrInvalidInput)
+	}
+	if result.RefLow != nil && result.RefHigh != nil && *result.RefLow > *result.RefHigh {
+		return fmt.Errorf("%w: ref_low must not exceed ref_high for %s", ErrInvalidInput, result.AnalyteName)
+	}
+
+	if result.NumericValue == nil {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(result.Value), 64); err == nil {
+			result.NumericValue = &v
+		}
+	}
+	if result.NumericValue == nil {
+		// Qualitative results keep a flag set by the technician
+		if result.Flag != models.LabFlagAbnormal {
+			result.Flag = models.LabFlagNormal
+		}
+		return nil
+	}
+
+	v := *result.NumericValue
+	switch {
+	case result.CriticalLow != nil && v <= *result.CriticalLow:
+		result.Flag = models.LabFlagCriticalLow
+	case result.CriticalHigh != nil && v >= *result.CriticalHigh:
+		result.Flag = models.LabFlagCriticalHigh
+	case result.RefLow != nil && v < *result.RefLow:
+		result.Flag = models.LabFlagLow
+	case result.RefHigh != nil && v > *result.RefHigh:
+		result.Flag = models.LabFlagHigh
+	default:
+		result.Flag = models.LabFlagNormal
+	}
+	return nil
+}
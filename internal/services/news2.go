@@ -0,0 +1,161 @@
+package services
+
+import (
+	"hospital-portal/internal/models"
+)
+
+// News2Result is a National Early Warning Score 2 calculation
+type News2Result struct {
+	Score     int            `json:"score"`
+	Risk      string         `json:"risk"`
+	RedScore  bool           `json:"red_score"` // a single parameter scored 3
+	Complete  bool           `json:"complete"`
+	Breakdown map[string]int `json:"breakdown"`
+}
+
+// CalculateNEWS2 scores a set of vital signs following the Royal College of
+// Physicians NEWS2 chart. All seven parameters are needed for a score;
+// with any missing the result is returned with Complete unset.
+func CalculateNEWS2(v *models.VitalSigns) News2Result {
+	result := News2Result{Breakdown: make(map[string]int)}
+	if v.RespiratoryRate == nil || v.SpO2 == nil || v.SystolicBP == nil ||
+		v.HeartRate == nil || v.Temperature == nil || v.Consciousness == "" {
+		return result
+	}
+	result.Complete = true
+
+	result.Breakdown["respiratory_rate"] = scoreRespiratoryRate(*v.RespiratoryRate)
+	if v.HypercapnicFailure {
+		result.Breakdown["spo2"] = scoreSpO2Scale2(*v.SpO2, v.OnSupplementalOxygen)
+	} else {
+		result.Breakdown["spo2"] = scoreSpO2Scale1(*v.SpO2)
+	}
+	if v.OnSupplementalOxygen {
+		result.Breakdown["air_or_oxygen"] = 2
+	} else {
+		result.Breakdown["air_or_oxygen"] = 0
+	}
+	result.Breakdown["systolic_bp"] = scoreSystolicBP(*v.SystolicBP)
+	result.Breakdown["heart_rate"] = scoreHeartRate(*v.HeartRate)
+	if v.Consciousness == "alert" {
+		result.Breakdown["consciousness"] = 0
+	} else {
+		result.Breakdown["consciousness"] = 3
+	}
+	result.Breakdown["temperature"] = scoreTemperature(*v.Temperature)
+
+	for _, score := range result.Breakdown {
+		result.Score += score
+		if score == 3 {
+			result.RedScore = true
+		}
+	}
+
+	switch {
+	case result.Score >= 7:
+		result.Risk = models.News2RiskHigh
+	case result.Score >= 5:
+		result.Risk = models.News2RiskMedium
+	case result.RedScore:
+		result.Risk = models.News2RiskLowMedium
+	default:
+		result.Risk = models.News2RiskLow
+	}
+	return result
+}
+
+func scoreRespiratoryRate(rate int) int {
+	switch {
+	case rate <= 8:
+		return 3
+	case rate <= 11:
+		return 1
+	case rate <= 20:
+		return 0
+	case rate <= 24:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func scoreSpO2Scale1(spo2 int) int {
+	switch {
+	case spo2 <= 91:
+		return 3
+	case spo2 <= 93:
+		return 2
+	case spo2 <= 95:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// scoreSpO2Scale2 is used for patients with hypercapnic respiratory
+// failure, whose target saturation is 88-92%
+func scoreSpO2Scale2(spo2 int, onOxygen bool) int {
+	switch {
+	case spo2 <= 83:
+		return 3
+	case spo2 <= 85:
+		return 2
+	case spo2 <= 87:
+		return 1
+	case spo2 <= 92 || !onOxygen:
+		return 0
+	case spo2 <= 94:
+		return 1
+	case spo2 <= 96:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func scoreSystolicBP(systolic int) int {
+	switch {
+	case systolic <= 90:
+		return 3
+	case systolic <= 100:
+		return 2
+	case systolic <= 110:
+		return 1
+	case systolic <= 219:
+		return 0
+	default:
+		return 3
+	}
+}
+
+func scoreHeartRate(rate int) int {
+	switch {
+	case rate <= 40:
+		return 3
+	case rate <= 50:
+		return 1
+	case rate <= 90:
+		return 0
+	case rate <= 110:
+		return 1
+	case rate <= 130:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func scoreTemperature(celsius float64) int {
+	switch {
+	case celsius <= 35.0:
+		return 3
+	case celsius <= 36.0:
+		return 1
+	case celsius <= 38.0:
+		return 0
+	case celsius <= 39.0:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// NotificationService handles in-app notifications for staff
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+	careTeamRepo     *repositories.CareTeamRepository
+	logger           *zap.Logger
+}
+
+// NewNotificationService creates a new notification service instance
+func NewNotificationService(notificationRepo *repositories.NotificationRepository, careTeamRepo *repositories.CareTeamRepository, logger *zap.Logger) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		careTeamRepo:     careTeamRepo,
+		logger:           logger,
+	}
+}
+
+// Notify sends a notification to a single user. Failures are logged and
+// returned; callers treat notifications as best effort.
+func (s *NotificationService) Notify(userID uint, patientID *uint, notificationType, title, message string) error {
+	_, err := s.notificationRepo.Create(&models.Notification{
+		UserID:    userID,
+		PatientID: patientID,
+		Type:      notificationType,
+		Title:     title,
+		Message:   message,
+	})
+	if err != nil {
+		s.logger.Error("Failed to create notification", zap.Error(err), zap.Uint("user_id", userID), zap.String("type", notificationType))
+	}
+	return err
+}
+
+// NotifyCareTeam notifies every member of a patient's care team plus any
+// extra users given, each user at most once
+func (s *NotificationService) NotifyCareTeam(patientID uint, notificationType, title, message string, extraUserIDs ...uint) error {
+	members, err := s.careTeamRepo.FindByPatient(patientID)
+	if err != nil {
+		s.logger.Error("Failed to fetch care team", zap.Error(err), zap.Uint("patient_id", patientID))
+		return err
+	}
+
+	recipients := make([]uint, 0, len(members)+len(extraUserIDs))
+	seen := make(map[uint]bool)
+	for _, member := range members {
+		if !seen[member.UserID] {
+			seen[member.UserID] = true
+			recipients = append(recipients, member.UserID)
+		}
+	}
+	for _, userID := range extraUserIDs {
+		if userID != 0 && !seen[userID] {
+			seen[userID] = true
+			recipients = append(recipients, userID)
+		}
+	}
+
+	var firstErr error
+	for _, userID := range recipients {
+		if err := s.Notify(userID, &patientID, notificationType, title, message); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// GetUserNotifications retrieves a user's notifications
+func (s *NotificationService) GetUserNotifications(userID uint, unreadOnly bool) ([]models.Notification, error) {
+	return s.notificationRepo.FindByUser(userID, unreadOnly)
+}
+
+// MarkRead marks one of a user's notifications as read
+func (s *NotificationService) MarkRead(id, userID uint) error {
+	return s.notificationRepo.MarkRead(id, userID)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// PatientContactService handles guardians, next of kin and emergency contacts
+type PatientContactService struct {
+	contactRepo *repositories.ContactRepository
+	patientRepo *repositories.PatientRepository
+	logger      *zap.Logger
+}
+
+// NewPatientContactService creates a new patient contact service instance
+func NewPatientContactService(contactRepo *repositories.ContactRepository, patientRepo *repositories.PatientRepository, logger *zap.Logger) *PatientContactService {
+	return &PatientContactService{
+		contactRepo: contactRepo,
+		patientRepo: patientRepo,
+		logger:      logger,
+	}
+}
+
+// GetContacts retrieves a patient's contacts
+func (s *PatientContactService) GetContacts(patientID uint) ([]models.PatientContact, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.contactRepo.FindByPatient(patientID)
+}
+
+// AddContact adds a contact to a patient
+func (s *PatientContactService) AddContact(patientID uint, contact *models.PatientContact) (*models.PatientContact, error) {
+	if err := validateContact(contact); err != nil {
+		return nil, err
+	}
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+
+	contact.ID = 0
+	contact.PatientID = patientID
+	return s.contactRepo.Create(contact)
+}
+
+// UpdateContact updates a contact. A minor's last guardian cannot stop
+// being a guardian.
+func (s *PatientContactService) UpdateContact(patientID, contactID uint, updated *models.PatientContact) (*models.PatientContact, error) {
+	if err := validateContact(updated); err != nil {
+		return nil, err
+	}
+	contact, err := s.findPatientContact(patientID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	if contact.IsGuardian && !updated.IsGuardian {
+		if err := s.ensureOtherGuardian(patientID, contactID); err != nil {
+			return nil, err
+		}
+	}
+
+	contact.Name = updated.Name
+	contact.Relationship = updated.Relationship
+	contact.PhoneNumber = updated.PhoneNumber
+	contact.Address = updated.Address
+	contact.IsGuardian = updated.IsGuardian
+	contact.IsNextOfKin = updated.IsNextOfKin
+	contact.IsEmergencyContact = updated.IsEmergencyContact
+	contact.MayReceiveInformation = updated.MayReceiveInformation
+	contact.Notes = updated.Notes
+	return s.contactRepo.Update(contact)
+}
+
+// DeleteContact removes a contact. A minor's last guardian cannot be removed.
+func (s *PatientContactService) DeleteContact(patientID, contactID uint) error {
+	contact, err := s.findPatientContact(patientID, contactID)
+	if err != nil {
+		return err
+	}
+	if contact.IsGuardian {
+		if err := s.ensureOtherGuardian(patientID, contactID); err != nil {
+			return err
+		}
+	}
+	return s.contactRepo.Delete(contact.ID)
+}
+
+func (s *PatientContactService) findPatientContact(patientID, contactID uint) (*models.PatientContact, error) {
+	contact, err := s.contactRepo.FindByID(contactID)
+	if err != nil {
+		return nil, err
+	}
+	if contact.PatientID != patientID {
+		return nil, errors.New("contact not found")
+	}
+	return contact, nil
+}
+
+// ensureOtherGuardian fails when the patient is a minor and the given
+// contact is their only guardian
+func (s *PatientContactService) ensureOtherGuardian(patientID, contactID uint) error {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return err
+	}
+	if patient.Age >= models.MinorAgeLimit {
+		return nil
+	}
+	count, err := s.contactRepo.CountGuardians(patientID, contactID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: a patient under %d must keep at least one guardian", ErrConflict, models.MinorAgeLimit)
+	}
+	return nil
+}
+
+func validateContact(contact *models.PatientContact) error {
+	contact.Name = strings.TrimSpace(contact.Name)
+	contact.Relationship = strings.TrimSpace(strings.ToLower(contact.Relationship))
+	contact.PhoneNumber = strings.TrimSpace(contact.PhoneNumber)
+	if contact.Name == "" || contact.Relationship == "" || contact.PhoneNumber == "" {
+		return fmt.Errorf("%w: name, relationship and phone number are required", ErrInvalidInput)
+	}
+	number, err := normalizePhone(contact.PhoneNumber, "")
+	if err != nil {
+		return err
+	}
+	contact.PhoneNumber = number
+	if !contact.IsGuardian && !contact.IsNextOfKin && !contact.IsEmergencyContact {
+		return fmt.Errorf("%w: contact must be a guardian, next of kin or emergency contact", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,505 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/auth"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/pdf"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/xlsx"
+)
+
+// patientExportBatchSize is how many patients are read at a time while an
+// export streams
+const patientExportBatchSize = 500
+
+// Patient export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+	ExportFormatPDF  = "pdf"
+	ExportFormatJSON = "json" // a page of the list endpoint
+)
+
+// Page sizes of the JSON patient list
+const (
+	defaultPatientListPage = 100
+	maxPatientListPage     = 500
+)
+
+// Roles that may export the columns of a group; a nil list allows everyone
+var (
+	contactColumnRoles  = []auth.Role{auth.RoleDoctor, auth.RoleNurse, auth.RoleReceptionist, auth.RoleBilling}
+	clinicalColumnRoles = []auth.Role{auth.RoleDoctor, auth.RoleNurse}
+)
+
+// patientExportColumn is a column of a patient list export
+type patientExportColumn struct {
+	name   string
+	header string
+	roles  []auth.Role
+	width  float64 // share of the PDF table width
+	value  func(patient *models.Patient) string
+}
+
+// patientExportColumns are the columns a patient list can be exported
+// with, in order. Contact details are for staff who reach patients or bill
+// them; clinical text is for clinicians only.
+var patientExportColumns = []patientExportColumn{
+	{"id", "ID", nil, 0.5, func(p *models.Patient) string { return strconv.FormatUint(uint64(p.ID), 10) }},
+	{"name", "Name", nil, 2, func(p *models.Patient) string { return p.Name }},
+	{"age", "Age", nil, 0.5, func(p *models.Patient) string { return strconv.Itoa(p.Age) }},
+	{"date_of_birth", "Date of birth", nil, 1, func(p *models.Patient) string {
+		if p.DateOfBirth == nil {
+			return ""
+		}
+		return p.DateOfBirth.Format("2006-01-02")
+	}},
+	{"gender", "Gender", nil, 0.7, func(p *models.Patient) string { return p.Gender }},
+	{"address_line1", "Address line 1", contactColumnRoles, 2, func(p *models.Patient) string { return p.Address.Line1 }},
+	{"address_line2", "Address line 2", contactColumnRoles, 1.2, func(p *models.Patient) string { return p.Address.Line2 }},
+	{"city", "City", contactColumnRoles, 1.2, func(p *models.Patient) string { return p.Address.City }},
+	{"region", "Region", contactColumnRoles, 0.8, func(p *models.Patient) string { return p.Address.Region }},
+	{"postal_code", "Postal code", contactColumnRoles, 0.8, func(p *models.Patient) string { return p.Address.PostalCode }},
+	{"country", "Country", contactColumnRoles, 0.6, func(p *models.Patient) string { return p.Address.Country }},
+	{"phone_number", "Phone number", contactColumnRoles, 1.2, func(p *models.Patient) string { return p.PhoneNumber }},
+	{"medical_history", "Medical history", clinicalColumnRoles, 2, func(p *models.Patient) string { return p.MedicalHistory }},
+	{"diagnosis", "Diagnosis", clinicalColumnRoles, 2, func(p *models.Patient) string { return p.Diagnosis }},
+	{"treatment", "Treatment", clinicalColumnRoles, 2, func(p *models.Patient) string { return p.Treatment }},
+	{"notes", "Notes", clinicalColumnRoles, 2, func(p *models.Patient) string { return p.Notes }},
+	{"created_at", "Registered", nil, 1, func(p *models.Patient) string { return p.CreatedAt.Format("2006-01-02") }},
+}
+
+// PatientExportRequest describes a patient list export. Fields picks the
+// columns; when empty, every column the role may see is exported. Params
+// carries the list filters.
+type PatientExportRequest struct {
+	Format     string
+	Fields     []string
+	Params     url.Values
+	UserID     uint
+	Role       auth.Role
+	RemoteAddr string
+}
+
+// PatientExport is an export that has been checked and audited and is
+// ready to be written
+type PatientExport struct {
+	ContentType string
+	FileName    string
+	format      string
+	columns     []patientExportColumn
+	search      repositories.PatientSearch
+	auditID     uint
+}
+
+// PatientListPage is one page of the patient list. Each patient is an
+// object holding the listed fields.
+type PatientListPage struct {
+	Patients []map[string]interface{} `json:"patients"`
+	Fields   []string                 `json:"fields"`
+	Total    int64                    `json:"total"`
+	Offset   int                      `json:"offset"`
+	Limit    int                      `json:"limit"`
+}
+
+// PatientExportService exports filtered patient lists as CSV, XLSX or
+// PDF. Rows are streamed from the database in batches; columns are limited
+// to what the exporting role may see, and every export is audited.
+type PatientExportService struct {
+	patientRepo *repositories.PatientRepository
+	auditRepo   *repositories.AuditRepository
+	logger      *zap.Logger
+}
+
+// NewPatientExportService creates a new patient export service instance
+func NewPatientExportService(patientRepo *repositories.PatientRepository, auditRepo *repositories.AuditRepository, logger *zap.Logger) *PatientExportService {
+	return &PatientExportService{
+		patientRepo: patientRepo,
+		auditRepo:   auditRepo,
+		logger:      logger,
+	}
+}
+
+// StartExport checks the export and records it in the audit log. Nothing
+// is exported if the audit entry cannot be written.
+func (s *PatientExportService) StartExport(req PatientExportRequest) (*PatientExport, error) {
+	export := &PatientExport{format: strings.ToLower(req.Format)}
+	switch export.format {
+	case ExportFormatCSV:
+		export.ContentType = "text/csv; charset=utf-8"
+	case ExportFormatXLSX:
+		export.ContentType = xlsx.ContentType
+	case ExportFormatPDF:
+		export.ContentType = "application/pdf"
+	default:
+		return nil, fmt.Errorf("%w: format must be csv, xlsx or pdf", ErrInvalidInput)
+	}
+	export.FileName = fmt.Sprintf("patients-%s.%s", time.Now().Format("20060102-150405"), export.format)
+
+	columns, err := patientExportColumnsFor(req.Role, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	export.columns = columns
+	if export.search, err = parsePatientListFilters(req.Params); err != nil {
+		return nil, err
+	}
+	// Exported lists leave the portal, so patients who have withdrawn
+	// research or data sharing consent are left out as in bulk exports
+	export.search.WithdrawnConsents = []string{models.ConsentTypeResearch, models.ConsentTypeDataSharing}
+
+	if export.format == ExportFormatPDF {
+		count, err := s.patientRepo.CountMatching(export.search)
+		if err != nil {
+			return nil, err
+		}
+		if limit := maxPDFExportRows(); count > int64(limit) {
+			return nil, fmt.Errorf("%w: %d patients match, more than the %d a PDF can list; narrow the filters or export CSV or XLSX", ErrInvalidInput, count, limit)
+		}
+	}
+
+	if export.auditID, err = s.startAudit(req, export.format, columns); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// ListPage returns one page of the filtered patient list with the same
+// columns an export would have, and audits it like an export. Offset and
+// limit page through the list in ID order.
+func (s *PatientExportService) ListPage(req PatientExportRequest, offset, limit int) (*PatientListPage, error) {
+	columns, err := patientExportColumnsFor(req.Role, req.Fields)
+	if err != nil {
+		return nil, err
+	}
+	search, err := parsePatientListFilters(req.Params)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxPatientListPage {
+		limit = defaultPatientListPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	auditID, err := s.startAudit(req, ExportFormatJSON, columns)
+	if err != nil {
+		return nil, err
+	}
+	patients, total, err := s.patientRepo.Search(search, offset, limit)
+	if err != nil {
+		if auditErr := s.auditRepo.Finish(auditID, models.AuditOutcomeFailed, 0, err.Error()); auditErr != nil {
+			s.logger.Error("Failed to complete patient list audit", zap.Error(auditErr), zap.Uint("audit_id", auditID))
+		}
+		return nil, err
+	}
+
+	page := &PatientListPage{
+		Patients: make([]map[string]interface{}, len(patients)),
+		Fields:   make([]string, len(columns)),
+		Total:    total,
+		Offset:   offset,
+		Limit:    limit,
+	}
+	for i, column := range columns {
+		page.Fields[i] = column.name
+	}
+	for i := range patients {
+		row := make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			row[column.name] = jsonValue(column, &patients[i])
+		}
+		page.Patients[i] = row
+	}
+	if err := s.auditRepo.Finish(auditID, models.AuditOutcomeCompleted, len(patients), ""); err != nil {
+		s.logger.Error("Failed to complete patient list audit", zap.Error(err), zap.Uint("audit_id", auditID))
+	}
+	return page, nil
+}
+
+// startAudit records an export of the columns in the audit log before any
+// patient data is read
+func (s *PatientExportService) startAudit(req PatientExportRequest, format string, columns []patientExportColumn) (uint, error) {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.name
+	}
+	filters := url.Values{}
+	for key, values := range req.Params {
+		if key != "format" && key != "fields" {
+			filters[key] = values
+		}
+	}
+	details, err := json.Marshal(map[string]interface{}{
+		"format":  format,
+		"fields":  names,
+		"filters": filters.Encode(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	entry := &models.AuditLog{
+		UserID:     req.UserID,
+		Role:       string(req.Role),
+		Action:     models.AuditActionPatientExport,
+		Details:    string(details),
+		Outcome:    models.AuditOutcomeStarted,
+		RemoteAddr: req.RemoteAddr,
+	}
+	if err := s.auditRepo.Create(entry); err != nil {
+		s.logger.Error("Failed to audit patient export", zap.Error(err), zap.Uint("user_id", req.UserID))
+		return 0, err
+	}
+	return entry.ID, nil
+}
+
+// WriteExport writes the export to w and completes its audit entry. CSV and
+// XLSX rows are flushed batch by batch; a PDF is laid out in memory first.
+func (s *PatientExportService) WriteExport(export *PatientExport, w io.Writer) error {
+	var count int
+	var err error
+	switch export.format {
+	case ExportFormatCSV:
+		count, err = s.writeCSV(export, w)
+	case ExportFormatXLSX:
+		count, err = s.writeXLSX(export, w)
+	default:
+		count, err = s.writePDF(export, w)
+	}
+
+	outcome, message := models.AuditOutcomeCompleted, ""
+	if err != nil {
+		outcome, message = models.AuditOutcomeFailed, err.Error()
+		s.logger.Error("Patient export failed", zap.Error(err), zap.Uint("audit_id", export.auditID), zap.Int("rows", count))
+	}
+	if auditErr := s.auditRepo.Finish(export.auditID, outcome, count, message); auditErr != nil {
+		s.logger.Error("Failed to complete patient export audit", zap.Error(auditErr), zap.Uint("audit_id", export.auditID))
+	}
+	if err == nil {
+		s.logger.Info("Patients exported", zap.Uint("audit_id", export.auditID), zap.String("format", export.format), zap.Int("rows", count))
+	}
+	return err
+}
+
+func (s *PatientExportService) writeCSV(export *PatientExport, w io.Writer) (int, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportHeaders(export.columns)); err != nil {
+		return 0, err
+	}
+	count := 0
+	err := s.patientRepo.SearchInBatches(export.search, patientExportBatchSize, func(patients []models.Patient) error {
+		for i := range patients {
+			values := exportValues(export.columns, &patients[i])
+			for j := range values {
+				values[j] = spreadsheetSafe(values[j])
+			}
+			if err := writer.Write(values); err != nil {
+				return err
+			}
+			count++
+		}
+		writer.Flush()
+		flushWriter(w)
+		return writer.Error()
+	})
+	if err != nil {
+		return count, err
+	}
+	writer.Flush()
+	return count, writer.Error()
+}
+
+func (s *PatientExportService) writeXLSX(export *PatientExport, w io.Writer) (int, error) {
+	writer, err := xlsx.NewWriter(w, "Patients")
+	if err != nil {
+		return 0, err
+	}
+	if err := writer.WriteRow(exportHeaders(export.columns)); err != nil {
+		return 0, err
+	}
+	count := 0
+	err = s.patientRepo.SearchInBatches(export.search, patientExportBatchSize, func(patients []models.Patient) error {
+		for i := range patients {
+			if err := writer.WriteRow(exportValues(export.columns, &patients[i])); err != nil {
+				return err
+			}
+			count++
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		flushWriter(w)
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+func (s *PatientExportService) writePDF(export *PatientExport, w io.Writer) (int, error) {
+	doc := pdf.New()
+	doc.Title = "Patient list"
+	landscape := pdf.Size{Width: pdf.A4.Height, Height: pdf.A4.Width}
+	layout := pdf.NewLayout(doc, landscape, 36)
+	layout.Footer = fmt.Sprintf("%s - patient list exported %s", viper.GetString("hospital.name"), time.Now().Format("2006-01-02 15:04"))
+
+	total := 0.0
+	for _, column := range export.columns {
+		total += column.width
+	}
+	widths := make([]float64, len(export.columns))
+	for i, column := range export.columns {
+		widths[i] = layout.Width() * column.width / total
+	}
+	headers := exportHeaders(export.columns)
+	layout.Paragraph(pdf.HelveticaBold, 14, viper.GetString("hospital.name"))
+	layout.Heading("Patient list", 12)
+	layout.Row(pdf.HelveticaBold, 8, widths, headers)
+	layout.Rule()
+	layout.OnNewPage = func() {
+		layout.Row(pdf.HelveticaBold, 8, widths, headers)
+		layout.Rule()
+	}
+
+	count := 0
+	err := s.patientRepo.SearchInBatches(export.search, patientExportBatchSize, func(patients []models.Patient) error {
+		for i := range patients {
+			layout.Row(pdf.Helvetica, 8, widths, exportValues(export.columns, &patients[i]))
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	layout.Space(6)
+	layout.Paragraph(pdf.Helvetica, 8, fmt.Sprintf("%d patients", count))
+
+	_, err = doc.WriteTo(w)
+	return count, err
+}
+
+// patientExportColumnsFor resolves the requested columns, or all those the
+// role may see. Asking for a column the role may not see is refused rather
+// than silently left out.
+func patientExportColumnsFor(role auth.Role, fields []string) ([]patientExportColumn, error) {
+	allowed := func(column patientExportColumn) bool {
+		return roleAllowed(column.roles, role)
+	}
+
+	var columns []patientExportColumn
+	if len(fields) == 0 {
+		for _, column := range patientExportColumns {
+			if allowed(column) {
+				columns = append(columns, column)
+			}
+		}
+		return columns, nil
+	}
+
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		found := false
+		for _, column := range patientExportColumns {
+			if column.name != field {
+				continue
+			}
+			if !allowed(column) {
+				return nil, fmt.Errorf("%w: %s may not export %s", ErrForbidden, role, field)
+			}
+			columns = append(columns, column)
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s is not a field that can be exported", ErrInvalidInput, field)
+		}
+	}
+	return columns, nil
+}
+
+func exportHeaders(columns []patientExportColumn) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.header
+	}
+	return headers
+}
+
+func exportValues(columns []patientExportColumn, patient *models.Patient) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = column.value(patient)
+	}
+	return values
+}
+
+// jsonValue is the value of a column in the JSON list. IDs and ages stay
+// numbers and an unknown date of birth is null.
+func jsonValue(column patientExportColumn, patient *models.Patient) interface{} {
+	switch column.name {
+	case "id":
+		return patient.ID
+	case "age":
+		return patient.Age
+	case "date_of_birth":
+		if patient.DateOfBirth == nil {
+			return nil
+		}
+	}
+	return column.value(patient)
+}
+
+// spreadsheetSafe keeps a CSV value from being read as a formula when the
+// file is opened in a spreadsheet application. Numbers such as E.164 phone
+// numbers are left alone.
+func spreadsheetSafe(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(strings.TrimPrefix(value, "+"), 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// flushWriter pushes written rows on to the client when w supports it
+func flushWriter(w io.Writer) {
+	if flusher, ok := w.(interface{ Flush() }); ok {
+		flusher.Flush()
+	}
+}
+
+func maxPDFExportRows() int {
+	if limit := viper.GetInt("patients.export.max_pdf_rows"); limit > 0 {
+		return limit
+	}
+	return 2000
+}
+
+// roleAllowed reports whether role is in roles; a nil list allows everyone
+func roleAllowed(roles []auth.Role, role auth.Role) bool {
+	if roles == nil {
+		return true
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
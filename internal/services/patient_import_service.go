@@ -0,0 +1,592 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+	"hospital-portal/internal/xlsx"
+)
+
+// Patient import tuning
+const (
+	// patientImportStaleAfter is how long a job may go without progress
+	// before it counts as interrupted, e.g. by a restart
+	patientImportStaleAfter = 10 * time.Minute
+	// maxImportErrorPage bounds a page of row errors
+	maxImportErrorPage = 1000
+	// maxImportJobs bounds a listing of a user's imports
+	maxImportJobs = 50
+)
+
+// Patient import file formats
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatXLSX = "xlsx"
+)
+
+// patientImportFields are the fields an import file can fill, named as in
+// PatientRequest with the address flattened. identifier is the patient's ID
+// in the old system; guardian_* describe a guardian contact, which minors
+// need.
+var patientImportFields = []string{
+	"identifier",
+	"name",
+	"age",
+	"date_of_birth",
+	"gender",
+	"address_line1",
+	"address_line2",
+	"city",
+	"region",
+	"postal_code",
+	"country",
+	"phone_number",
+	"medical_history",
+	"diagnosis",
+	"treatment",
+	"notes",
+	"guardian_name",
+	"guardian_relationship",
+	"guardian_phone",
+}
+
+// patientImportRequired are the fields every file needs a column for; age
+// may be left out when date_of_birth is there
+var patientImportRequired = []string{"name", "gender", "address_line1", "city", "phone_number"}
+
+// PatientImportUpload is a file of patients to import. Mapping names the
+// file column of each field; unmapped fields are read from the column with
+// the field's own name, if there is one.
+type PatientImportUpload struct {
+	FileName         string
+	Format           string // csv or xlsx; taken from the file name when empty
+	Body             io.ReaderAt
+	Size             int64
+	Mapping          map[string]string
+	IdentifierSystem string // required when the identifier field is mapped
+	DryRun           bool
+}
+
+// importRow is a data row of an import file with its line number
+type importRow struct {
+	number int
+	values []string
+}
+
+// PatientImportService loads patients in bulk from CSV or XLSX files, as
+// when migrating from another system. Rows are checked against the rules of
+// a single registration; a dry run only reports the rows that fail them,
+// otherwise the valid rows are inserted batch by batch in the background.
+type PatientImportService struct {
+	importRepo     *repositories.PatientImportRepository
+	patientRepo    *repositories.PatientRepository
+	patientService *PatientService
+	logger         *zap.Logger
+
+	mu      sync.Mutex
+	running map[uint]bool
+}
+
+// NewPatientImportService creates a new patient import service instance
+func NewPatientImportService(importRepo *repositories.PatientImportRepository, patientRepo *repositories.PatientRepository, patientService *PatientService, logger *zap.Logger) *PatientImportService {
+	return &PatientImportService{
+		importRepo:     importRepo,
+		patientRepo:    patientRepo,
+		patientService: patientService,
+		logger:         logger,
+		running:        make(map[uint]bool),
+	}
+}
+
+// MaxImportSize is the largest import file accepted, in bytes
+func MaxImportSize() int64 {
+	limit := viper.GetInt64("patients.import.max_upload_mb")
+	if limit <= 0 {
+		limit = 50
+	}
+	return limit << 20
+}
+
+// StartImport reads the file and checks its columns, records the job and
+// processes the rows in the background. Problems with the file as a whole
+// are returned; problems with rows end up in the job's report.
+func (s *PatientImportService) StartImport(upload PatientImportUpload, userID uint) (*models.PatientImportJob, error) {
+	format := strings.ToLower(upload.Format)
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(upload.FileName)), ".")
+	}
+	if format != ImportFormatCSV && format != ImportFormatXLSX {
+		return nil, fmt.Errorf("%w: the file must be CSV or XLSX", ErrInvalidInput)
+	}
+
+	rows, err := readImportFile(format, upload.Body, upload.Size)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidInput)
+	}
+	columns, err := importColumns(rows[0].values, upload.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	identifierSystem := strings.TrimSpace(upload.IdentifierSystem)
+	if _, ok := columns["identifier"]; ok && identifierSystem == "" {
+		return nil, fmt.Errorf("%w: identifier_system is required when identifiers are imported", ErrInvalidInput)
+	}
+	rows = rows[1:]
+
+	running, err := s.importRepo.CountInProgressByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if running > 0 {
+		return nil, fmt.Errorf("%w: an import is already running; wait for it to finish", ErrConflict)
+	}
+
+	job, err := s.importRepo.Create(&models.PatientImportJob{
+		RequestedByID:    userID,
+		FileName:         filepath.Base(upload.FileName),
+		Format:           format,
+		DryRun:           upload.DryRun,
+		IdentifierSystem: identifierSystem,
+		Status:           models.PatientImportStatusInProgress,
+		TotalRows:        len(rows),
+	})
+	if err != nil {
+		s.logger.Error("Failed to create patient import job", zap.Error(err))
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.running[job.ID] = true
+	s.mu.Unlock()
+	go s.run(job.ID, rows, columns, format, identifierSystem, upload.DryRun)
+
+	s.logger.Info("Patient import started", zap.Uint("job_id", job.ID), zap.Uint("user_id", userID),
+		zap.String("file", job.FileName), zap.Int("rows", job.TotalRows), zap.Bool("dry_run", job.DryRun))
+	return job, nil
+}
+
+// GetJob retrieves an import for progress polling along with a page of its
+// row errors. A job that stopped making progress without finishing is
+// marked failed.
+func (s *PatientImportService) GetJob(id, userID uint, offset, limit int) (*models.PatientImportJob, error) {
+	job, err := s.ownJob(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == models.PatientImportStatusInProgress && !s.isRunning(job.ID) && time.Since(job.UpdatedAt) > patientImportStaleAfter {
+		s.logger.Warn("Patient import was interrupted", zap.Uint("job_id", job.ID))
+		if job, err = s.finish(job.ID, models.PatientImportStatusFailed, "the import was interrupted; rows after the last processed one were not imported"); err != nil {
+			return nil, err
+		}
+	}
+
+	if limit <= 0 || limit > maxImportErrorPage {
+		limit = maxImportErrorPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if job.Errors, err = s.importRepo.FindErrors(job.ID, offset, limit); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJobs retrieves the user's newest imports
+func (s *PatientImportService) GetJobs(userID uint) ([]models.PatientImportJob, error) {
+	return s.importRepo.FindByUser(userID, maxImportJobs)
+}
+
+func (s *PatientImportService) ownJob(id, userID uint) (*models.PatientImportJob, error) {
+	job, err := s.importRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.RequestedByID != userID {
+		return nil, fmt.Errorf("%w: the import belongs to another user", ErrForbidden)
+	}
+	return job, nil
+}
+
+func (s *PatientImportService) isRunning(id uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[id]
+}
+
+// run checks and, unless it is a dry run, inserts the rows batch by batch.
+// Each batch is committed in its own transaction, so a failure part way
+// leaves the batches before it imported.
+func (s *PatientImportService) run(jobID uint, rows []importRow, columns map[string]int, format, identifierSystem string, dryRun bool) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, jobID)
+		s.mu.Unlock()
+	}()
+
+	batchSize := importBatchSize()
+	seen := make(map[string]int) // identifier to the row it first appeared on
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		valid, validRows, rowErrors, err := s.checkBatch(jobID, rows[start:end], columns, format, identifierSystem, seen)
+		if err != nil {
+			s.logger.Error("Patient import failed", zap.Error(err), zap.Uint("job_id", jobID))
+			s.finish(jobID, models.PatientImportStatusFailed, "failed to check rows against existing patients")
+			return
+		}
+
+		invalid := failedRows(rowErrors)
+		imported, notImported := 0, 0
+		if !dryRun && len(valid) > 0 {
+			if err := s.patientService.ImportPatients(valid); err != nil {
+				// The whole batch was rolled back; report its rows
+				for _, number := range validRows {
+					rowErrors = append(rowErrors, models.PatientImportError{JobID: jobID, Row: number, Message: "not imported: " + err.Error()})
+				}
+				notImported = len(valid)
+			} else {
+				imported = len(valid)
+			}
+		}
+
+		if err := s.importRepo.AddErrors(rowErrors); err != nil {
+			s.logger.Error("Failed to record patient import errors", zap.Error(err), zap.Uint("job_id", jobID))
+			s.finish(jobID, models.PatientImportStatusFailed, "failed to record the row errors")
+			return
+		}
+		_, err = s.importRepo.Update(jobID, func(job *models.PatientImportJob) error {
+			job.ProcessedRows += end - start
+			job.ValidRows += end - start - invalid
+			job.ImportedRows += imported
+			job.FailedRows += invalid + notImported
+			return nil
+		})
+		if err != nil {
+			s.logger.Error("Failed to record patient import progress", zap.Error(err), zap.Uint("job_id", jobID))
+			s.finish(jobID, models.PatientImportStatusFailed, "failed to record the import's progress")
+			return
+		}
+	}
+
+	if job, err := s.finish(jobID, models.PatientImportStatusCompleted, ""); err == nil {
+		s.logger.Info("Patient import completed", zap.Uint("job_id", jobID), zap.Bool("dry_run", dryRun),
+			zap.Int("imported", job.ImportedRows), zap.Int("failed", job.FailedRows))
+	}
+}
+
+// checkBatch turns rows into patients, returning the valid ones with their
+// line numbers and the errors of the others. Identifiers must be new to the
+// system and appear only once in the file.
+func (s *PatientImportService) checkBatch(jobID uint, rows []importRow, columns map[string]int, format, identifierSystem string, seen map[string]int) ([]models.Patient, []int, []models.PatientImportError, error) {
+	var patients []models.Patient
+	var numbers []int
+	var rowErrors []models.PatientImportError
+	var identifiers []string
+	for _, row := range rows {
+		patient, problems := patientFromRow(row.values, columns, format)
+		if len(problems) == 0 && len(patient.Identifiers) > 0 {
+			value := patient.Identifiers[0].Value
+			patient.Identifiers[0].System = identifierSystem
+			if first, ok := seen[value]; ok {
+				problems = append(problems, models.PatientImportError{Field: "identifier", Message: fmt.Sprintf("identifier %s is also on row %d", value, first)})
+			} else {
+				seen[value] = row.number
+				identifiers = append(identifiers, value)
+			}
+		}
+		if len(problems) > 0 {
+			for _, problem := range problems {
+				problem.JobID = jobID
+				problem.Row = row.number
+				rowErrors = append(rowErrors, problem)
+			}
+			continue
+		}
+		patients = append(patients, *patient)
+		numbers = append(numbers, row.number)
+	}
+
+	if len(identifiers) == 0 {
+		return patients, numbers, rowErrors, nil
+	}
+	existing, err := s.patientRepo.FindExistingIdentifiers(identifierSystem, identifiers)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(existing) == 0 {
+		return patients, numbers, rowErrors, nil
+	}
+	known := make(map[string]bool, len(existing))
+	for _, value := range existing {
+		known[value] = true
+	}
+	var newPatients []models.Patient
+	var newNumbers []int
+	for i := range patients {
+		if len(patients[i].Identifiers) > 0 && known[patients[i].Identifiers[0].Value] {
+			rowErrors = append(rowErrors, models.PatientImportError{JobID: jobID, Row: numbers[i], Field: "identifier",
+				Message: fmt.Sprintf("a patient with identifier %s already exists", patients[i].Identifiers[0].Value)})
+			continue
+		}
+		newPatients = append(newPatients, patients[i])
+		newNumbers = append(newNumbers, numbers[i])
+	}
+	return newPatients, newNumbers, rowErrors, nil
+}
+
+// finish records the outcome of a job
+func (s *PatientImportService) finish(jobID uint, status, message string) (*models.PatientImportJob, error) {
+	job, err := s.importRepo.Update(jobID, func(job *models.PatientImportJob) error {
+		now := time.Now()
+		job.Status = status
+		job.Error = message
+		job.CompletedAt = &now
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Failed to record patient import outcome", zap.Error(err), zap.Uint("job_id", jobID), zap.String("status", status))
+		return nil, err
+	}
+	return job, nil
+}
+
+// patientFromRow builds a patient from a row, applying the rules
+// PatientRequest binds with and then those of a registration
+func patientFromRow(values []string, columns map[string]int, format string) (*models.Patient, []models.PatientImportError) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(values) {
+			return strings.TrimSpace(values[i])
+		}
+		return ""
+	}
+	var problems []models.PatientImportError
+	problem := func(name, message string) {
+		problems = append(problems, models.PatientImportError{Field: name, Message: message})
+	}
+
+	patient := &models.Patient{
+		Name:   field("name"),
+		Gender: strings.ToLower(field("gender")),
+		Address: models.Address{
+			Line1:      field("address_line1"),
+			Line2:      field("address_line2"),
+			City:       field("city"),
+			Region:     field("region"),
+			PostalCode: field("postal_code"),
+			Country:    field("country"),
+		},
+		PhoneNumber:    field("phone_number"),
+		MedicalHistory: field("medical_history"),
+		Diagnosis:      field("diagnosis"),
+		Treatment:      field("treatment"),
+		Notes:          field("notes"),
+	}
+
+	if patient.Name == "" {
+		problem("name", "name is required")
+	}
+	if value := field("date_of_birth"); value != "" {
+		birth, err := parseImportDate(value, format)
+		if err != nil {
+			problem("date_of_birth", err.Error())
+		} else {
+			patient.DateOfBirth = &birth
+		}
+	}
+	hasBirth := field("date_of_birth") != ""
+	if value := field("age"); value != "" {
+		age, err := strconv.Atoi(value)
+		switch {
+		case err != nil || age < 0 || age > 150:
+			problem("age", "age must be a whole number from 0 to 150")
+		case age == 0 && !hasBirth:
+			// PatientRequest treats an age of 0 as missing, so a newborn
+			// needs a date of birth
+			problem("age", "age is required without a date of birth")
+		default:
+			patient.Age = age
+		}
+	} else if !hasBirth {
+		problem("age", "age is required without a date of birth")
+	}
+	if !contains([]string{"male", "female", "other"}, patient.Gender) {
+		problem("gender", "gender must be male, female or other")
+	}
+	if patient.Address.Line1 == "" {
+		problem("address_line1", "address line1 is required")
+	}
+	if patient.Address.City == "" {
+		problem("city", "city is required")
+	}
+	if patient.Address.Country != "" && len(patient.Address.Country) != 2 {
+		problem("country", "country must be an ISO 3166-1 alpha-2 code")
+	}
+	if patient.PhoneNumber == "" {
+		problem("phone_number", "phone number is required")
+	}
+
+	if name := field("guardian_name"); name != "" {
+		relationship := field("guardian_relationship")
+		if relationship == "" {
+			relationship = "guardian"
+		}
+		patient.Contacts = []models.PatientContact{{
+			Name:         name,
+			Relationship: relationship,
+			PhoneNumber:  field("guardian_phone"),
+			IsGuardian:   true,
+		}}
+	}
+	if value := field("identifier"); value != "" {
+		patient.Identifiers = []models.PatientIdentifier{{Value: value}}
+	}
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	if err := prepareNewPatient(patient); err != nil {
+		problem("", strings.TrimPrefix(err.Error(), ErrInvalidInput.Error()+": "))
+		return nil, problems
+	}
+	return patient, nil
+}
+
+// parseImportDate reads a YYYY-MM-DD date or, from XLSX files, a date cell
+func parseImportDate(value, format string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if format == ImportFormatXLSX {
+		if serial, err := strconv.ParseFloat(value, 64); err == nil && serial >= 1 {
+			t := xlsx.SerialTime(serial)
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, errors.New("dates must be formatted as YYYY-MM-DD")
+}
+
+// importColumns resolves the column of each field from the header row
+func importColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := positions[name]; !ok && name != "" {
+			positions[name] = i
+		}
+	}
+
+	for name := range mapping {
+		if !contains(patientImportFields, name) {
+			return nil, fmt.Errorf("%w: %s is not a field that can be imported", ErrInvalidInput, name)
+		}
+	}
+
+	columns := make(map[string]int)
+	for _, name := range patientImportFields {
+		column, mapped := mapping[name]
+		if !mapped {
+			column = name
+		}
+		i, ok := positions[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			if mapped {
+				return nil, fmt.Errorf("%w: the file has no column %q for %s", ErrInvalidInput, column, name)
+			}
+			continue
+		}
+		columns[name] = i
+	}
+
+	for _, name := range patientImportRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: a column for %s is required", ErrInvalidInput, name)
+		}
+	}
+	_, hasAge := columns["age"]
+	_, hasBirth := columns["date_of_birth"]
+	if !hasAge && !hasBirth {
+		return nil, fmt.Errorf("%w: a column for age or date_of_birth is required", ErrInvalidInput)
+	}
+	return columns, nil
+}
+
+// readImportFile reads the rows of a CSV or XLSX file, leaving out blank
+// ones. The first row returned is the header.
+func readImportFile(format string, body io.ReaderAt, size int64) ([]importRow, error) {
+	var rows []importRow
+	add := func(number int, record []string) {
+		for _, value := range record {
+			if strings.TrimSpace(value) != "" {
+				rows = append(rows, importRow{number: number, values: record})
+				return
+			}
+		}
+	}
+
+	switch format {
+	case ImportFormatXLSX:
+		records, err := xlsx.ReadRows(body, size)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		for i, record := range records {
+			add(i+1, record)
+		}
+	default:
+		reader := csv.NewReader(io.NewSectionReader(body, 0, size))
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+			}
+			if len(rows) == 0 && len(record) > 0 {
+				// Spreadsheet applications start UTF-8 CSV files with a BOM
+				record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			}
+			// Quoted values may span lines, so report the line a row starts on
+			line, _ := reader.FieldPos(0)
+			add(line, record)
+		}
+	}
+	return rows, nil
+}
+
+// failedRows counts the distinct rows among row errors
+func failedRows(rowErrors []models.PatientImportError) int {
+	rows := make(map[int]bool)
+	for _, rowError := range rowErrors {
+		rows[rowError.Row] = true
+	}
+	return len(rows)
+}
+
+func importBatchSize() int {
+	if size := viper.GetInt("patients.import.batch_size"); size > 0 {
+		return size
+	}
+	return 500
+}
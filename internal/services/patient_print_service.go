@@ -0,0 +1,314 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/barcode"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/pdf"
+	"hospital-portal/internal/repositories"
+)
+
+// Sections a patient summary can be made of
+const (
+	patientSummaryDemographics = "demographics"
+	patientSummaryProblems     = "problems"
+	patientSummaryAllergies    = "allergies"
+	patientSummaryMedications  = "medications"
+)
+
+// pointsPerMM converts label sizes from millimetres to PDF points
+const pointsPerMM = 72 / 25.4
+
+// PatientPrintService renders printable patient documents: a one-page
+// clinical summary and a wristband or label. Their layout is taken from the
+// hospital.templates configuration, so each hospital can match its own
+// paper and label stock.
+type PatientPrintService struct {
+	patientRepo      *repositories.PatientRepository
+	problemRepo      *repositories.ProblemRepository
+	allergyRepo      *repositories.AllergyRepository
+	prescriptionRepo *repositories.PrescriptionRepository
+	logger           *zap.Logger
+}
+
+// NewPatientPrintService creates a new patient print service instance
+func NewPatientPrintService(patientRepo *repositories.PatientRepository, problemRepo *repositories.ProblemRepository, allergyRepo *repositories.AllergyRepository, prescriptionRepo *repositories.PrescriptionRepository, logger *zap.Logger) *PatientPrintService {
+	return &PatientPrintService{
+		patientRepo:      patientRepo,
+		problemRepo:      problemRepo,
+		allergyRepo:      allergyRepo,
+		prescriptionRepo: prescriptionRepo,
+		logger:           logger,
+	}
+}
+
+// WriteSummaryPDF renders a patient's demographics, active problems,
+// allergies and active medications as PDF
+func (s *PatientPrintService) WriteSummaryPDF(patientID uint, w io.Writer) error {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return err
+	}
+
+	title := viper.GetString("hospital.templates.summary.title")
+	if title == "" {
+		title = "Patient summary"
+	}
+	doc := pdf.New()
+	doc.Title = title + " - " + patient.Name
+	doc.Author = viper.GetString("hospital.name")
+	layout := pdf.NewLayout(doc, summaryPageSize(), 50)
+	layout.Footer = fmt.Sprintf("%s - %s, patient %d", viper.GetString("hospital.name"), strings.ToLower(title), patient.ID)
+
+	layout.Paragraph(pdf.HelveticaBold, 14, viper.GetString("hospital.name"))
+	layout.Paragraph(pdf.Helvetica, 9, viper.GetString("hospital.address"))
+	if phone := viper.GetString("hospital.phone"); phone != "" {
+		layout.Paragraph(pdf.Helvetica, 9, phone)
+	}
+	layout.Rule()
+	layout.Heading(title, 16)
+	layout.Paragraph(pdf.Helvetica, 9, "Printed "+time.Now().Format("2006-01-02 15:04 MST"))
+
+	for _, section := range patientSummarySections() {
+		switch section {
+		case patientSummaryDemographics:
+			writeSummaryDemographics(layout, patient)
+		case patientSummaryProblems:
+			problems, err := s.problemRepo.FindByPatient(patient.ID, models.ProblemStatusActive)
+			if err != nil {
+				return err
+			}
+			writeSummaryProblems(layout, problems)
+		case patientSummaryAllergies:
+			allergies, err := s.allergyRepo.FindActiveByPatient(patient.ID)
+			if err != nil {
+				return err
+			}
+			writeSummaryAllergies(layout, allergies)
+		case patientSummaryMedications:
+			prescriptions, err := s.prescriptionRepo.FindByPatient(patient.ID, models.PrescriptionStatusActive)
+			if err != nil {
+				return err
+			}
+			writeSummaryMedications(layout, prescriptions)
+		default:
+			s.logger.Warn("Skipping unknown patient summary section", zap.String("section", section))
+		}
+	}
+
+	if note := viper.GetString("hospital.templates.summary.footer"); note != "" {
+		layout.Space(10)
+		layout.Rule()
+		layout.Paragraph(pdf.Helvetica, 8, note)
+	}
+
+	_, err = doc.WriteTo(w)
+	return err
+}
+
+// WriteLabelPDF renders a wristband or label with the patient's name, the
+// configured details and a barcode of the patient ID, on a page the size
+// of the label stock
+func (s *PatientPrintService) WriteLabelPDF(patientID uint, w io.Writer) error {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return err
+	}
+
+	code := strconv.FormatUint(uint64(patient.ID), 10)
+	var bars []int
+	switch kind := viper.GetString("hospital.templates.label.barcode"); kind {
+	case "", "code128":
+		if bars, err = barcode.Code128(code); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported label barcode %q; only code128 is available", kind)
+	}
+
+	size := labelSize()
+	margin := 2 * pointsPerMM
+	width := size.Width - 2*margin
+
+	doc := pdf.New()
+	doc.Title = "Patient label - " + patient.Name
+	page := doc.AddPage(size)
+	y := margin
+
+	var details []string
+	for _, field := range labelFields() {
+		switch field {
+		case "hospital":
+			y += 6
+			page.Text(margin, y, pdf.Helvetica, 6, fitText(pdf.Helvetica, 6, width, viper.GetString("hospital.name")))
+			y += 2
+		case "name":
+			y += 10
+			page.Text(margin, y, pdf.HelveticaBold, 10, fitText(pdf.HelveticaBold, 10, width, patient.Name))
+			y += 2
+		case "date_of_birth":
+			dob := "unknown"
+			if patient.DateOfBirth != nil {
+				dob = patient.DateOfBirth.Format("2006-01-02")
+			}
+			details = append(details, "DOB "+dob)
+		case "age":
+			details = append(details, fmt.Sprintf("Age %d", patient.Age))
+		case "gender":
+			details = append(details, patient.Gender)
+		case "id":
+			details = append(details, "ID "+code)
+		default:
+			s.logger.Warn("Skipping unknown patient label field", zap.String("field", field))
+		}
+	}
+	if len(details) > 0 {
+		y += 7
+		page.Text(margin, y, pdf.Helvetica, 7, fitText(pdf.Helvetica, 7, width, strings.Join(details, "   ")))
+		y += 2
+	}
+
+	// The barcode fills what is left of the label, with its quiet zones
+	// inside the margins. Wider modules scan more reliably, up to a point.
+	moduleWidth := width / float64(barcode.Modules(bars)+2*barcode.QuietZone)
+	if moduleWidth > 1.5 {
+		moduleWidth = 1.5
+	}
+	height := size.Height - margin - y - 2
+	if height < 8 {
+		return fmt.Errorf("a %.0f x %.0f mm label has no room left for the barcode", size.Width/pointsPerMM, size.Height/pointsPerMM)
+	}
+	page.Bars(margin+barcode.QuietZone*moduleWidth, y+2, moduleWidth, height, bars)
+
+	_, err = doc.WriteTo(w)
+	return err
+}
+
+func writeSummaryDemographics(layout *pdf.Layout, patient *models.Patient) {
+	layout.Heading("Demographics", 12)
+	layout.Field("Name", patient.Name, 10)
+	layout.Field("Patient ID", strconv.FormatUint(uint64(patient.ID), 10), 10)
+	if patient.DateOfBirth != nil {
+		layout.Field("Date of birth", fmt.Sprintf("%s (age %d)", patient.DateOfBirth.Format("2006-01-02"), patient.Age), 10)
+	} else {
+		layout.Field("Age", strconv.Itoa(patient.Age), 10)
+	}
+	layout.Field("Gender", patient.Gender, 10)
+	address := patient.Address.String()
+	if address == "" {
+		address = patient.LegacyAddress
+	}
+	layout.Field("Address", orNone(address), 10)
+	layout.Field("Phone", orNone(patient.PhoneNumber), 10)
+}
+
+func writeSummaryProblems(layout *pdf.Layout, problems []models.Problem) {
+	layout.Heading("Active problems", 12)
+	if len(problems) == 0 {
+		layout.Paragraph(pdf.Helvetica, 10, "None")
+		return
+	}
+	for _, problem := range problems {
+		line := fmt.Sprintf("%s (%s)", problem.Description, problem.ICD10Code)
+		if problem.OnsetDate != nil {
+			line += ", since " + problem.OnsetDate.Format("2006-01-02")
+		}
+		if problem.IsPrimary {
+			line += " - primary"
+		}
+		layout.Bullet(line, 10)
+	}
+}
+
+func writeSummaryAllergies(layout *pdf.Layout, allergies []models.Allergy) {
+	layout.Heading("Allergies", 12)
+	nkda := false
+	written := false
+	for _, allergy := range allergies {
+		if allergy.NKDA {
+			nkda = true
+			continue
+		}
+		line := allergy.Substance
+		if allergy.Reaction != "" {
+			line += " - " + allergy.Reaction
+		}
+		if allergy.Severity != "" {
+			line += " (" + strings.ReplaceAll(allergy.Severity, "_", "-") + ")"
+		}
+		layout.Bullet(line, 10)
+		written = true
+	}
+	switch {
+	case written:
+	case nkda:
+		layout.Paragraph(pdf.Helvetica, 10, "No known drug allergies")
+	default:
+		layout.Paragraph(pdf.HelveticaBold, 10, "Allergy status not recorded")
+	}
+}
+
+func writeSummaryMedications(layout *pdf.Layout, prescriptions []models.Prescription) {
+	layout.Heading("Active medications", 12)
+	if len(prescriptions) == 0 {
+		layout.Paragraph(pdf.Helvetica, 10, "None")
+		return
+	}
+	for _, prescription := range prescriptions {
+		line := fmt.Sprintf("%s %s, %s, %s", prescription.Drug, prescription.Strength, prescription.Route, prescription.Frequency)
+		if prescription.Instructions != "" {
+			line += ". " + prescription.Instructions
+		}
+		layout.Bullet(line, 10)
+	}
+}
+
+// fitText cuts text to fit in width
+func fitText(font *pdf.Font, size, width float64, text string) string {
+	for text != "" && font.TextWidth(text, size) > width {
+		runes := []rune(text)
+		text = string(runes[:len(runes)-1])
+	}
+	return text
+}
+
+func patientSummarySections() []string {
+	if sections := viper.GetStringSlice("hospital.templates.summary.sections"); len(sections) > 0 {
+		return sections
+	}
+	return []string{patientSummaryDemographics, patientSummaryProblems, patientSummaryAllergies, patientSummaryMedications}
+}
+
+func summaryPageSize() pdf.Size {
+	if strings.EqualFold(viper.GetString("hospital.templates.summary.page_size"), "letter") {
+		return pdf.Letter
+	}
+	return pdf.A4
+}
+
+func labelFields() []string {
+	if fields := viper.GetStringSlice("hospital.templates.label.fields"); len(fields) > 0 {
+		return fields
+	}
+	return []string{"name", "date_of_birth", "id"}
+}
+
+// labelSize defaults to a common 80 x 25 mm wristband insert
+func labelSize() pdf.Size {
+	width, height := viper.GetFloat64("hospital.templates.label.width_mm"), viper.GetFloat64("hospital.templates.label.height_mm")
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 25
+	}
+	return pdf.Size{Width: width * pointsPerMM, Height: height * pointsPerMM}
+}
@@ -1,29 +1,133 @@
 package services
 
 import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
 	"go.uber.org/zap"
 
 	"hospital-portal/internal/models"
+	"hospital-portal/internal/phone"
 	"hospital-portal/internal/repositories"
 )
 
-// PatientService handles patient business logic
+// PatientService handles patient business logic. Changes to patients are
+// published as webhook events, without the details of patients who have
+// withdrawn data sharing consent.
 type PatientService struct {
-	patientRepo *repositories.PatientRepository
-	logger      *zap.Logger
+	patientRepo    *repositories.PatientRepository
+	contactRepo    *repositories.ContactRepository
+	webhooks       *WebhookService
+	consentService *ConsentService
+	logger         *zap.Logger
 }
 
 // NewPatientService creates a new patient service instance
-func NewPatientService(patientRepo *repositories.PatientRepository, logger *zap.Logger) *PatientService {
+func NewPatientService(patientRepo *repositories.PatientRepository, contactRepo *repositories.ContactRepository, webhooks *WebhookService, consentService *ConsentService, logger *zap.Logger) *PatientService {
 	return &PatientService{
-		patientRepo: patientRepo,
-		logger:      logger,
+		patientRepo:    patientRepo,
+		contactRepo:    contactRepo,
+		webhooks:       webhooks,
+		consentService: consentService,
+		logger:         logger,
 	}
 }
 
-// CreatePatient creates a new patient
+// patientEventData is a patient as sent in webhook payloads. Clinical
+// fields are left out; subscribers allowed to see them use the API.
+type patientEventData struct {
+	ID          uint                       `json:"id"`
+	Name        string                     `json:"name"`
+	Age         int                        `json:"age"`
+	DateOfBirth string                     `json:"date_of_birth,omitempty"`
+	Gender      string                     `json:"gender"`
+	Address     models.Address             `json:"address"`
+	PhoneNumber string                     `json:"phone_number"`
+	Identifiers []models.PatientIdentifier `json:"identifiers,omitempty"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+}
+
+// withheldPatientEventData stands in for a patient who has withdrawn data
+// sharing consent: subscribers learn that the record changed, not how
+type withheldPatientEventData struct {
+	ID       uint `json:"id"`
+	Withheld bool `json:"withheld"`
+}
+
+// patientEvent builds the webhook event for a new patient. A patient has
+// no consent decisions until after they are registered, so there is
+// nothing to withhold.
+func patientEvent(eventType string, patient *models.Patient) WebhookEvent {
+	return WebhookEvent{Type: eventType, Data: map[string]interface{}{"patient": newPatientEventData(patient)}}
+}
+
+// eventData is an existing patient as sent in webhook payloads. Only the ID
+// is sent for a patient who has withdrawn data sharing consent, or whose
+// consent cannot be checked.
+func (s *PatientService) eventData(patient *models.Patient) interface{} {
+	withdrawn, err := s.consentService.IsWithdrawn(patient.ID, models.ConsentTypeDataSharing)
+	if err != nil {
+		s.logger.Error("Failed to check data sharing consent; withholding patient details from webhooks", zap.Error(err), zap.Uint("patient_id", patient.ID))
+	}
+	if err != nil || withdrawn {
+		return withheldPatientEventData{ID: patient.ID, Withheld: true}
+	}
+	return newPatientEventData(patient)
+}
+
+func newPatientEventData(patient *models.Patient) patientEventData {
+	data := patientEventData{
+		ID:          patient.ID,
+		Name:        patient.Name,
+		Age:         patient.Age,
+		Gender:      patient.Gender,
+		Address:     patient.Address,
+		PhoneNumber: patient.PhoneNumber,
+		Identifiers: patient.Identifiers,
+		CreatedAt:   patient.CreatedAt,
+		UpdatedAt:   patient.UpdatedAt,
+	}
+	if patient.DateOfBirth != nil {
+		data.DateOfBirth = patient.DateOfBirth.Format("2006-01-02")
+	}
+	return data
+}
+
+// CreatePatient creates a new patient together with their contacts.
+// Minors must be registered with at least one guardian.
 func (s *PatientService) CreatePatient(patient *models.Patient) (*models.Patient, error) {
-	return s.patientRepo.Create(patient)
+	if err := prepareNewPatient(patient); err != nil {
+		return nil, err
+	}
+	created, err := s.patientRepo.Create(patient)
+	if err != nil {
+		return nil, err
+	}
+	s.webhooks.Publish(patientEvent(models.WebhookEventPatientCreated, created))
+	return created, nil
+}
+
+// ImportPatients inserts a batch of patients, already checked with
+// prepareNewPatient, in one transaction: either all of them are created or
+// none is
+func (s *PatientService) ImportPatients(patients []models.Patient) error {
+	if len(patients) == 0 {
+		return nil
+	}
+	if err := s.patientRepo.CreateBatch(patients); err != nil {
+		s.logger.Error("Failed to import patients", zap.Error(err), zap.Int("count", len(patients)))
+		return err
+	}
+	events := make([]WebhookEvent, len(patients))
+	for i := range patients {
+		events[i] = patientEvent(models.WebhookEventPatientCreated, &patients[i])
+	}
+	s.webhooks.Publish(events...)
+	return nil
 }
 
 // GetAllPatients retrieves all patients
@@ -41,12 +145,284 @@ func (s *PatientService) GetPatientByName(name string) (*models.Patient, error)
 	return s.patientRepo.FindByName(name)
 }
 
-// UpdatePatient updates a patient
+// UpdatePatient updates a patient. Contacts are managed separately, but a
+// patient cannot become a minor without a guardian on record.
 func (s *PatientService) UpdatePatient(patient *models.Patient) (*models.Patient, error) {
-	return s.patientRepo.Update(patient)
+	if err := applyDateOfBirth(patient); err != nil {
+		return nil, err
+	}
+	if err := normalizeContactDetails(patient); err != nil {
+		return nil, err
+	}
+	if patient.Age < models.MinorAgeLimit {
+		count, err := s.contactRepo.CountGuardians(patient.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("%w: a patient under %d must have at least one guardian", ErrInvalidInput, models.MinorAgeLimit)
+		}
+	}
+	return s.update(patient)
+}
+
+// UpdateContactDetails replaces a patient's address and phone number and
+// clears any pending contact review
+func (s *PatientService) UpdateContactDetails(id uint, address models.Address, phoneNumber string) (*models.Patient, error) {
+	patient, err := s.patientRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	patient.Address = address
+	patient.PhoneNumber = phoneNumber
+	if err := normalizeContactDetails(patient); err != nil {
+		return nil, err
+	}
+	return s.update(patient)
+}
+
+// update saves a changed patient and publishes the change
+func (s *PatientService) update(patient *models.Patient) (*models.Patient, error) {
+	updated, err := s.patientRepo.Update(patient)
+	if err != nil {
+		return nil, err
+	}
+	s.webhooks.Publish(WebhookEvent{
+		Type: models.WebhookEventPatientUpdated,
+		Data: map[string]interface{}{"patient": s.eventData(updated)},
+	})
+	return updated, nil
+}
+
+// GetContactReviewQueue retrieves the patients whose address or phone
+// number could not be parsed automatically
+func (s *PatientService) GetContactReviewQueue() ([]models.Patient, error) {
+	return s.patientRepo.FindContactReviewRequired()
+}
+
+// BackfillContactDetails parses the free-text addresses and phone numbers
+// of records created before contact details were structured. Records that
+// cannot be parsed keep their original values and are flagged for review;
+// a parsed address no longer keeps its free text. It returns the number of
+// records parsed and flagged.
+func (s *PatientService) BackfillContactDetails() (parsed int, flagged int, err error) {
+	err = s.patientRepo.FindUnstructuredInBatches(100, func(patients []models.Patient) error {
+		for i := range patients {
+			patient := &patients[i]
+			var problems []string
+
+			address, parseErr := ParseLegacyAddress(patient.LegacyAddress)
+			if parseErr != nil {
+				problems = append(problems, "address: "+parseErr.Error())
+			} else {
+				patient.Address = address
+				patient.LegacyAddress = ""
+			}
+
+			// An address that could not be parsed may still have named its
+			// country; otherwise the number is read in the default country
+			country := address.Country
+			if country == "" {
+				country = defaultCountry()
+			}
+
+			number, phoneErr := phone.Normalize(patient.PhoneNumber, country)
+			if phoneErr != nil {
+				problems = append(problems, "phone: "+phoneErr.Error())
+			} else {
+				patient.PhoneNumber = number
+			}
+
+			patient.ContactReviewRequired = len(problems) > 0
+			patient.ContactReviewReason = strings.Join(problems, "; ")
+			if patient.ContactReviewRequired {
+				flagged++
+			} else {
+				parsed++
+			}
+			if _, err := s.patientRepo.Update(patient); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	s.logger.Info("Contact details backfilled", zap.Int("parsed", parsed), zap.Int("flagged", flagged))
+	return parsed, flagged, err
+}
+
+// prepareNewPatient validates a patient about to be registered and
+// normalizes their details. Minors must come with at least one guardian.
+func prepareNewPatient(patient *models.Patient) error {
+	if err := applyDateOfBirth(patient); err != nil {
+		return err
+	}
+	if err := normalizeContactDetails(patient); err != nil {
+		return err
+	}
+	hasGuardian := false
+	for i := range patient.Contacts {
+		if err := validateContact(&patient.Contacts[i]); err != nil {
+			return err
+		}
+		hasGuardian = hasGuardian || patient.Contacts[i].IsGuardian
+	}
+	if patient.Age < models.MinorAgeLimit && !hasGuardian {
+		return fmt.Errorf("%w: a patient under %d must have at least one guardian", ErrInvalidInput, models.MinorAgeLimit)
+	}
+	return nil
+}
+
+// applyDateOfBirth derives the patient's age from their date of birth,
+// when one is recorded
+func applyDateOfBirth(patient *models.Patient) error {
+	if patient.DateOfBirth == nil {
+		return nil
+	}
+	now := time.Now()
+	if patient.DateOfBirth.After(now) {
+		return fmt.Errorf("%w: date of birth cannot be in the future", ErrInvalidInput)
+	}
+	patient.Age = ageOn(*patient.DateOfBirth, now)
+	return nil
+}
+
+// ageOn is the age in whole years on the given day of someone born on birth
+func ageOn(birth, on time.Time) int {
+	age := on.Year() - birth.Year()
+	if on.Month() < birth.Month() || (on.Month() == birth.Month() && on.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// normalizeContactDetails validates a patient's address, converts their
+// phone number to E.164 and clears any pending contact review
+func normalizeContactDetails(patient *models.Patient) error {
+	if err := normalizeAddress(&patient.Address); err != nil {
+		return err
+	}
+	number, err := normalizePhone(patient.PhoneNumber, patient.Address.Country)
+	if err != nil {
+		return err
+	}
+	patient.PhoneNumber = number
+	patient.LegacyAddress = ""
+	patient.ContactReviewRequired = false
+	patient.ContactReviewReason = ""
+	return nil
+}
+
+// MergePatients folds a duplicate record into the surviving one: all of
+// the source's records move to the target and the source is deleted. Two
+// records that are both admitted, or both in the queue, cannot be merged
+// until one of the visits ends.
+func (s *PatientService) MergePatients(sourceID, targetID uint) (*models.Patient, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: a patient cannot be merged into itself", ErrInvalidInput)
+	}
+	sourceAdmitted, sourceQueued, err := s.patientRepo.FindActiveVisits(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	targetAdmitted, targetQueued, err := s.patientRepo.FindActiveVisits(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if sourceAdmitted && targetAdmitted {
+		return nil, fmt.Errorf("%w: both patients are admitted; discharge one before merging", ErrConflict)
+	}
+	if sourceQueued && targetQueued {
+		return nil, fmt.Errorf("%w: both patients are in the queue; remove one before merging", ErrConflict)
+	}
+
+	if err := s.patientRepo.Merge(sourceID, targetID); err != nil {
+		s.logger.Error("Failed to merge patients", zap.Error(err), zap.Uint("source_id", sourceID), zap.Uint("target_id", targetID))
+		return nil, err
+	}
+	s.logger.Info("Patients merged", zap.Uint("source_id", sourceID), zap.Uint("target_id", targetID))
+	merged, err := s.patientRepo.FindByID(targetID)
+	if err != nil {
+		return nil, err
+	}
+	s.webhooks.Publish(WebhookEvent{
+		Type: models.WebhookEventPatientMerged,
+		Data: map[string]interface{}{"patient": s.eventData(merged), "merged_patient_id": sourceID},
+	})
+	return merged, nil
 }
 
 // DeletePatient deletes a patient
 func (s *PatientService) DeletePatient(id uint) error {
-	return s.patientRepo.Delete(id)
+	if err := s.patientRepo.Delete(id); err != nil {
+		return err
+	}
+	s.webhooks.Publish(WebhookEvent{
+		Type: models.WebhookEventPatientDeleted,
+		Data: map[string]interface{}{"patient_id": id},
+	})
+	return nil
+}
+
+// parsePatientListFilters reads the filters of the patient list: name
+// (part of it), gender (comma-separated), min_age, max_age, city, country,
+// born_from and born_to, created_from and created_to (YYYY-MM-DD, both
+// inclusive) and contact_review. Unknown parameters are ignored.
+func parsePatientListFilters(params url.Values) (repositories.PatientSearch, error) {
+	var search repositories.PatientSearch
+	if name := strings.TrimSpace(params.Get("name")); name != "" {
+		search.Names = []repositories.NameMatch{{Values: []string{name}, Mode: repositories.NameMatchContains}}
+	}
+	if genders := params.Get("gender"); genders != "" {
+		for _, gender := range strings.Split(genders, ",") {
+			gender = strings.ToLower(strings.TrimSpace(gender))
+			if !contains([]string{"male", "female", "other"}, gender) {
+				return search, fmt.Errorf("%w: gender must be male, female or other", ErrInvalidInput)
+			}
+			search.Genders = append(search.Genders, gender)
+		}
+	}
+	for _, bound := range []struct {
+		name  string
+		value **int
+	}{{"min_age", &search.MinAge}, {"max_age", &search.MaxAge}} {
+		if value := params.Get(bound.name); value != "" {
+			age, err := strconv.Atoi(value)
+			if err != nil || age < 0 {
+				return search, fmt.Errorf("%w: %s must be a whole number of years", ErrInvalidInput, bound.name)
+			}
+			*bound.value = &age
+		}
+	}
+	search.City = strings.TrimSpace(params.Get("city"))
+	search.Country = strings.ToUpper(strings.TrimSpace(params.Get("country")))
+	for _, bound := range []struct {
+		name  string
+		value **time.Time
+		end   bool
+	}{
+		{"born_from", &search.BirthDateFrom, false},
+		{"born_to", &search.BirthDateTo, true},
+		{"created_from", &search.CreatedFrom, false},
+		{"created_to", &search.CreatedTo, true},
+	} {
+		if value := params.Get(bound.name); value != "" {
+			day, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return search, fmt.Errorf("%w: %s must be formatted as YYYY-MM-DD", ErrInvalidInput, bound.name)
+			}
+			if bound.end {
+				// The search bounds are exclusive
+				day = day.AddDate(0, 0, 1)
+			}
+			*bound.value = &day
+		}
+	}
+	if value := params.Get("contact_review"); value != "" {
+		review, err := strconv.ParseBool(value)
+		if err != nil {
+			return search, fmt.Errorf("%w: contact_review must be true or false", ErrInvalidInput)
+		}
+		search.ContactReviewRequired = &review
+	}
+	return search, nil
 }
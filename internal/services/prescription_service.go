@@ -0,0 +1,244 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var prescriptionRoutes = []string{"oral", "sublingual", "topical", "inhaled", "intravenous", "intramuscular", "subcutaneous", "rectal", "ophthalmic", "otic", "nasal", "other"}
+
+//go:embed templates/prescription.html
+var prescriptionTemplateSource string
+
+var prescriptionTemplate = template.Must(template.New("prescription").Parse(prescriptionTemplateSource))
+
+// RenewalOptions holds the fields a doctor may change when renewing
+type RenewalOptions struct {
+	DurationDays   int
+	Quantity       int
+	Refills        *int
+	OverrideReason string
+}
+
+// PrescriptionService handles prescription business logic
+type PrescriptionService struct {
+	prescriptionRepo   *repositories.PrescriptionRepository
+	patientRepo        *repositories.PatientRepository
+	userRepo           *repositories.UserRepository
+	interactionRepo    *repositories.InteractionRepository
+	interactionService *InteractionService
+	logger             *zap.Logger
+}
+
+// NewPrescriptionService creates a new prescription service instance
+func NewPrescriptionService(prescriptionRepo *repositories.PrescriptionRepository, patientRepo *repositories.PatientRepository, userRepo *repositories.UserRepository, interactionRepo *repositories.InteractionRepository, interactionService *InteractionService, logger *zap.Logger) *PrescriptionService {
+	return &PrescriptionService{
+		prescriptionRepo:   prescriptionRepo,
+		patientRepo:        patientRepo,
+		userRepo:           userRepo,
+		interactionRepo:    interactionRepo,
+		interactionService: interactionService,
+		logger:             logger,
+	}
+}
+
+// GetPatientPrescriptions retrieves a patient's prescriptions
+func (s *PrescriptionService) GetPatientPrescriptions(patientID uint, status string) ([]models.Prescription, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.prescriptionRepo.FindByPatient(patientID, status)
+}
+
+// GetPrescriptionByID retrieves a prescription by ID
+func (s *PrescriptionService) GetPrescriptionByID(id uint) (*models.Prescription, error) {
+	return s.prescriptionRepo.FindByID(id)
+}
+
+// GetInteractionAlerts retrieves the interaction alerts stored with a prescription
+func (s *PrescriptionService) GetInteractionAlerts(id uint) ([]models.InteractionAlert, error) {
+	return s.interactionRepo.FindAlertsByPrescription(id)
+}
+
+// CheckInteractions runs the interaction check for a drug without prescribing it
+func (s *PrescriptionService) CheckInteractions(patientID uint, drug, drugCode string) (*InteractionCheck, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.interactionService.Check(patientID, drug, drugCode, 0)
+}
+
+// Prescribe creates a new active prescription for a patient. The drug is
+// checked against the patient's active medications and allergies first;
+// blocking interactions reject it and override-level interactions need
+// an override reason, which is stored with the alerts.
+func (s *PrescriptionService) Prescribe(patientID uint, prescription *models.Prescription, prescriberID uint, overrideReason string) (*models.Prescription, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+
+	prescription.PatientID = patientID
+	prescription.PrescriberID = prescriberID
+	prescription.Status = models.PrescriptionStatusActive
+	if prescription.StartDate.IsZero() {
+		prescription.StartDate = time.Now()
+	}
+	if err := validatePrescription(prescription); err != nil {
+		return nil, err
+	}
+	prescription.EndDate = prescription.StartDate.AddDate(0, 0, prescription.DurationDays)
+
+	alerts, err := s.screenInteractions(patientID, prescription, 0, overrideReason, prescriberID)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.prescriptionRepo.Create(prescription, alerts)
+	if err != nil {
+		s.logger.Error("Failed to create prescription", zap.Error(err), zap.Uint("patient_id", patientID))
+		return nil, err
+	}
+	return created, nil
+}
+
+// Discontinue stops an active prescription
+func (s *PrescriptionService) Discontinue(id uint, reason string, doctorID uint) (*models.Prescription, error) {
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidInput)
+	}
+	return s.prescriptionRepo.Update(id, func(prescription *models.Prescription) error {
+		if prescription.Status != models.PrescriptionStatusActive {
+			return fmt.Errorf("%w: prescription is %s", ErrConflict, prescription.Status)
+		}
+		now := time.Now()
+		prescription.Status = models.PrescriptionStatusDiscontinued
+		prescription.DiscontinuedAt = &now
+		prescription.DiscontinuedByID = &doctorID
+		prescription.DiscontinueReason = reason
+		return nil
+	})
+}
+
+// Renew completes an active prescription and issues a new one with the
+// same drug and schedule, starting today
+func (s *PrescriptionService) Renew(id uint, opts RenewalOptions, doctorID uint) (*models.Prescription, error) {
+	original, err := s.prescriptionRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRenewable(original); err != nil {
+		return nil, err
+	}
+
+	renewal := *original
+	renewal.ID = 0
+	renewal.PrescriberID = doctorID
+	renewal.Status = models.PrescriptionStatusActive
+	renewal.StartDate = time.Now()
+	renewal.RenewedFromID = &original.ID
+	renewal.CreatedAt = time.Time{}
+	renewal.UpdatedAt = time.Time{}
+	if opts.DurationDays > 0 {
+		renewal.DurationDays = opts.DurationDays
+	}
+	if opts.Quantity > 0 {
+		renewal.Quantity = opts.Quantity
+	}
+	if opts.Refills != nil {
+		renewal.Refills = *opts.Refills
+	}
+	if err := validatePrescription(&renewal); err != nil {
+		return nil, err
+	}
+	renewal.EndDate = renewal.StartDate.AddDate(0, 0, renewal.DurationDays)
+
+	alerts, err := s.screenInteractions(original.PatientID, &renewal, original.ID, opts.OverrideReason, doctorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.prescriptionRepo.Renew(original.ID, &renewal, alerts, checkRenewable)
+}
+
+// checkRenewable allows only active prescriptions to be renewed, so one
+// that was completed by an earlier renewal or discontinued is not renewed
+// twice
+func checkRenewable(prescription *models.Prescription) error {
+	if prescription.Status != models.PrescriptionStatusActive {
+		return fmt.Errorf("%w: only active prescriptions can be renewed, this one is %s", ErrConflict, prescription.Status)
+	}
+	return nil
+}
+
+func (s *PrescriptionService) screenInteractions(patientID uint, prescription *models.Prescription, excludeID uint, overrideReason string, doctorID uint) ([]models.InteractionAlert, error) {
+	check, err := s.interactionService.Check(patientID, prescription.Drug, prescription.DrugCode, excludeID)
+	if err != nil {
+		s.logger.Error("Failed to check interactions", zap.Error(err), zap.Uint("patient_id", patientID))
+		return nil, err
+	}
+	if check.Blocked || (check.RequiresOverride && overrideReason == "") {
+		s.logger.Warn("Prescription stopped by interaction check",
+			zap.Uint("patient_id", patientID),
+			zap.String("drug", prescription.Drug),
+			zap.Bool("blocked", check.Blocked))
+		return nil, &InteractionError{Check: check}
+	}
+	return check.alertsFor(overrideReason, doctorID), nil
+}
+
+// RenderPrintable renders a prescription as a printable HTML page
+func (s *PrescriptionService) RenderPrintable(id uint) ([]byte, error) {
+	prescription, err := s.prescriptionRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	patient, err := s.patientRepo.FindByID(prescription.PatientID)
+	if err != nil {
+		return nil, err
+	}
+	prescriber, err := s.userRepo.FindByID(prescription.PrescriberID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = prescriptionTemplate.Execute(&buf, map[string]interface{}{
+		"Prescription": prescription,
+		"Patient":      patient,
+		"Prescriber":   prescriber,
+	})
+	if err != nil {
+		s.logger.Error("Failed to render prescription", zap.Error(err), zap.Uint("id", id))
+		return nil, errors.New("failed to render prescription")
+	}
+	return buf.Bytes(), nil
+}
+
+func validatePrescription(prescription *models.Prescription) error {
+	switch {
+	case prescription.Drug == "":
+		return fmt.Errorf("%w: drug is required", ErrInvalidInput)
+	case prescription.Strength == "":
+		return fmt.Errorf("%w: strength is required", ErrInvalidInput)
+	case !contains(prescriptionRoutes, prescription.Route):
+		return fmt.Errorf("%w: route must be one of %v", ErrInvalidInput, prescriptionRoutes)
+	case prescription.Frequency == "":
+		return fmt.Errorf("%w: frequency is required", ErrInvalidInput)
+	case prescription.DurationDays <= 0:
+		return fmt.Errorf("%w: duration_days must be positive", ErrInvalidInput)
+	case prescription.Quantity <= 0:
+		return fmt.Errorf("%w: quantity must be positive", ErrInvalidInput)
+	case prescription.Refills < 0 || prescription.Refills > 12:
+		return fmt.Errorf("%w: refills must be between 0 and 12", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// ProblemService handles problem list business logic
+type ProblemService struct {
+	problemRepo   *repositories.ProblemRepository
+	patientRepo   *repositories.PatientRepository
+	encounterRepo *repositories.EncounterRepository
+	icd10Service  *ICD10Service
+	logger        *zap.Logger
+}
+
+// NewProblemService creates a new problem service instance
+func NewProblemService(problemRepo *repositories.ProblemRepository, patientRepo *repositories.PatientRepository, encounterRepo *repositories.EncounterRepository, icd10Service *ICD10Service, logger *zap.Logger) *ProblemService {
+	return &ProblemService{
+		problemRepo:   problemRepo,
+		patientRepo:   patientRepo,
+		encounterRepo: encounterRepo,
+		icd10Service:  icd10Service,
+		logger:        logger,
+	}
+}
+
+// GetProblemList retrieves a patient's problem list
+func (s *ProblemService) GetProblemList(patientID uint, status string) ([]models.Problem, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.problemRepo.FindByPatient(patientID, status)
+}
+
+// AddProblem adds a coded diagnosis to a patient's problem list
+func (s *ProblemService) AddProblem(patientID uint, problem *models.Problem, recordedByID uint) (*models.Problem, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+
+	problem.ID = 0
+	problem.PatientID = patientID
+	problem.RecordedByID = recordedByID
+	if problem.Status == "" {
+		problem.Status = models.ProblemStatusActive
+	}
+	if err := s.prepare(problem); err != nil {
+		return nil, err
+	}
+
+	created, err := s.problemRepo.Save(problem)
+	if err != nil {
+		s.logger.Error("Failed to add problem", zap.Error(err), zap.Uint("patient_id", patientID))
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateProblem updates an entry of a patient's problem list
+func (s *ProblemService) UpdateProblem(patientID uint, problem *models.Problem) (*models.Problem, error) {
+	existing, err := s.findPatientProblem(patientID, problem.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	problem.PatientID = existing.PatientID
+	problem.RecordedByID = existing.RecordedByID
+	problem.CreatedAt = existing.CreatedAt
+	if problem.EncounterID == nil {
+		problem.EncounterID = existing.EncounterID
+	}
+	if problem.Status == "" {
+		problem.Status = existing.Status
+	}
+	if problem.ResolvedDate == nil && problem.Status == existing.Status {
+		problem.ResolvedDate = existing.ResolvedDate
+	}
+	if err := s.prepare(problem); err != nil {
+		return nil, err
+	}
+	return s.problemRepo.Save(problem)
+}
+
+// DeleteProblem removes an entry from a patient's problem list
+func (s *ProblemService) DeleteProblem(patientID, problemID uint) error {
+	if _, err := s.findPatientProblem(patientID, problemID); err != nil {
+		return err
+	}
+	return s.problemRepo.Delete(problemID)
+}
+
+// prepare validates a problem against the code table and the patient's
+// encounters, and fills in the code description and resolution date
+func (s *ProblemService) prepare(problem *models.Problem) error {
+	if problem.Status != models.ProblemStatusActive && problem.Status != models.ProblemStatusResolved {
+		return fmt.Errorf("%w: status must be active or resolved", ErrInvalidInput)
+	}
+	if problem.EncounterID != nil {
+		encounter, err := s.encounterRepo.FindByID(*problem.EncounterID)
+		if err != nil || encounter.PatientID != problem.PatientID {
+			return fmt.Errorf("%w: encounter does not belong to this patient", ErrInvalidInput)
+		}
+	}
+
+	entry, err := s.icd10Service.LookupCode(problem.ICD10Code)
+	if err != nil {
+		return err
+	}
+	problem.ICD10Code = entry.Code
+	if problem.Description == "" {
+		problem.Description = entry.Description
+	}
+
+	if problem.OnsetDate != nil && problem.OnsetDate.After(time.Now()) {
+		return fmt.Errorf("%w: onset_date cannot be in the future", ErrInvalidInput)
+	}
+	switch problem.Status {
+	case models.ProblemStatusResolved:
+		if problem.IsPrimary {
+			return fmt.Errorf("%w: a resolved problem cannot be the primary diagnosis", ErrInvalidInput)
+		}
+		if problem.ResolvedDate == nil {
+			now := time.Now()
+			problem.ResolvedDate = &now
+		}
+	case models.ProblemStatusActive:
+		problem.ResolvedDate = nil
+	}
+	return nil
+}
+
+func (s *ProblemService) findPatientProblem(patientID, problemID uint) (*models.Problem, error) {
+	problem, err := s.problemRepo.FindByID(problemID)
+	if err != nil {
+		return nil, err
+	}
+	if problem.PatientID != patientID {
+		return nil, errors.New("problem not found")
+	}
+	return problem, nil
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var queueSources = []string{models.QueueSourceAppointment, models.QueueSourceWalkIn}
+
+// CheckInInput is the data needed to check a patient in
+type CheckInInput struct {
+	Source        string
+	AppointmentAt *time.Time
+	Reason        string
+}
+
+// QueueItem is a queue entry with its current wait
+type QueueItem struct {
+	models.QueueEntry
+	WaitMinutes int `json:"wait_minutes"`
+}
+
+// QueueMetrics summarizes waiting-room activity over a period
+type QueueMetrics struct {
+	From                      time.Time                     `json:"from"`
+	To                        time.Time                     `json:"to"`
+	CheckedIn                 int                           `json:"checked_in"`
+	StatusCounts              map[string]int                `json:"status_counts"`
+	AverageWaitMinutes        float64                       `json:"average_wait_minutes"`
+	AverageTriageMinutes      float64                       `json:"average_triage_minutes"`
+	ByTriageLevel             []repositories.QueueWaitStats `json:"by_triage_level"`
+	CurrentlyWaiting          int                           `json:"currently_waiting"`
+	LongestCurrentWaitMinutes int                           `json:"longest_current_wait_minutes"`
+}
+
+// QueueService handles front-desk check-in, triage and calling patients in
+type QueueService struct {
+	queueRepo   *repositories.QueueRepository
+	patientRepo *repositories.PatientRepository
+	logger      *zap.Logger
+}
+
+// NewQueueService creates a new queue service instance
+func NewQueueService(queueRepo *repositories.QueueRepository, patientRepo *repositories.PatientRepository, logger *zap.Logger) *QueueService {
+	return &QueueService{
+		queueRepo:   queueRepo,
+		patientRepo: patientRepo,
+		logger:      logger,
+	}
+}
+
+// CheckIn adds a patient to the waiting-room queue
+func (s *QueueService) CheckIn(patientID uint, input CheckInInput, userID uint) (*models.QueueEntry, error) {
+	if !contains(queueSources, input.Source) {
+		return nil, fmt.Errorf("%w: source must be one of %v", ErrInvalidInput, queueSources)
+	}
+	if input.Source == models.QueueSourceAppointment && input.AppointmentAt == nil {
+		return nil, fmt.Errorf("%w: appointment_at is required for appointment check-ins", ErrInvalidInput)
+	}
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	if active, err := s.queueRepo.FindActiveByPatient(patientID); err != nil {
+		return nil, err
+	} else if active != nil {
+		return nil, fmt.Errorf("%w: patient is already in the queue (entry %d)", ErrConflict, active.ID)
+	}
+
+	entry, err := s.queueRepo.Create(&models.QueueEntry{
+		PatientID:     patientID,
+		Source:        input.Source,
+		AppointmentAt: input.AppointmentAt,
+		Reason:        input.Reason,
+		Status:        models.QueueStatusWaiting,
+		CheckedInAt:   time.Now(),
+		CheckedInByID: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Patient checked in", zap.Uint("entry_id", entry.ID), zap.Uint("patient_id", patientID), zap.String("source", input.Source))
+	return entry, nil
+}
+
+// GetQueue lists the waiting and in-progress entries in the order doctors
+// will call them, with their current wait
+func (s *QueueService) GetQueue() ([]QueueItem, error) {
+	entries, err := s.queueRepo.FindByStatuses([]string{models.QueueStatusWaiting, models.QueueStatusInProgress})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	items := make([]QueueItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, QueueItem{QueueEntry: entry, WaitMinutes: waitMinutes(entry, now)})
+	}
+	return items, nil
+}
+
+// GetEntry retrieves a queue entry
+func (s *QueueService) GetEntry(id uint) (*models.QueueEntry, error) {
+	return s.queueRepo.FindByID(id)
+}
+
+// Triage assigns or revises the ESI level of a waiting patient
+func (s *QueueService) Triage(id uint, level int, notes string, nurseID uint) (*models.QueueEntry, error) {
+	if level < models.TriageLevelMostUrgent || level > models.TriageLevelLeastUrgent {
+		return nil, fmt.Errorf("%w: triage level must be between %d and %d", ErrInvalidInput, models.TriageLevelMostUrgent, models.TriageLevelLeastUrgent)
+	}
+	entry, err := s.queueRepo.Update(id, func(entry *models.QueueEntry) error {
+		if entry.Status != models.QueueStatusWaiting {
+			return fmt.Errorf("%w: queue entry is %s", ErrConflict, entry.Status)
+		}
+		now := time.Now()
+		entry.TriageLevel = &level
+		entry.TriageNotes = notes
+		entry.TriagedAt = &now
+		entry.TriagedByID = &nurseID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Patient triaged", zap.Uint("entry_id", id), zap.Int("level", level))
+	return entry, nil
+}
+
+// CallNext assigns the most urgent, longest waiting triaged patient to a
+// doctor and opens the encounter
+func (s *QueueService) CallNext(doctorID uint) (*models.QueueEntry, error) {
+	encounterType := viper.GetString("queue.encounter_type")
+	if encounterType == "" {
+		encounterType = "outpatient"
+	}
+	entry, err := s.queueRepo.ClaimNext(doctorID, encounterType, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, errors.New("no triaged patients are waiting")
+	}
+
+	s.logger.Info("Patient called in",
+		zap.Uint("entry_id", entry.ID),
+		zap.Uint("doctor_id", doctorID),
+		zap.Int("wait_minutes", waitMinutes(*entry, *entry.CalledAt)),
+	)
+	return entry, nil
+}
+
+// Complete closes the entry of a patient the doctor has seen
+func (s *QueueService) Complete(id uint, doctorID uint) (*models.QueueEntry, error) {
+	return s.close(id, func(entry *models.QueueEntry) error {
+		if entry.Status != models.QueueStatusInProgress {
+			return fmt.Errorf("%w: queue entry is %s", ErrConflict, entry.Status)
+		}
+		if entry.DoctorID == nil || *entry.DoctorID != doctorID {
+			return fmt.Errorf("%w: the patient was called in by another doctor", ErrForbidden)
+		}
+		entry.Status = models.QueueStatusCompleted
+		return nil
+	})
+}
+
+// Remove takes a waiting patient off the queue, either because they left
+// without being seen or because the check-in was cancelled
+func (s *QueueService) Remove(id uint, left bool, reason string) (*models.QueueEntry, error) {
+	return s.close(id, func(entry *models.QueueEntry) error {
+		if entry.Status != models.QueueStatusWaiting {
+			return fmt.Errorf("%w: queue entry is %s", ErrConflict, entry.Status)
+		}
+		entry.Status = models.QueueStatusCancelled
+		if left {
+			entry.Status = models.QueueStatusLeft
+		}
+		entry.CloseReason = reason
+		return nil
+	})
+}
+
+// GetMetrics reports queue activity for patients checked in during [from, to)
+func (s *QueueService) GetMetrics(from, to time.Time) (*QueueMetrics, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	byLevel, err := s.queueRepo.WaitStats(from, to)
+	if err != nil {
+		return nil, err
+	}
+	triage, err := s.queueRepo.AverageTriageMinutes(from, to)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.queueRepo.CountByStatus(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &QueueMetrics{
+		From:                 from,
+		To:                   to,
+		StatusCounts:         counts,
+		AverageTriageMinutes: round2(triage),
+		ByTriageLevel:        byLevel,
+	}
+	for _, count := range counts {
+		metrics.CheckedIn += count
+	}
+	seen := 0
+	var totalWait float64
+	for i := range byLevel {
+		seen += byLevel[i].Seen
+		totalWait += byLevel[i].AverageWaitMinutes * float64(byLevel[i].Seen)
+		byLevel[i].AverageWaitMinutes = round2(byLevel[i].AverageWaitMinutes)
+		byLevel[i].MaxWaitMinutes = round2(byLevel[i].MaxWaitMinutes)
+	}
+	if seen > 0 {
+		metrics.AverageWaitMinutes = round2(totalWait / float64(seen))
+	}
+
+	waiting, err := s.queueRepo.FindByStatuses([]string{models.QueueStatusWaiting})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	metrics.CurrentlyWaiting = len(waiting)
+	for _, entry := range waiting {
+		if wait := waitMinutes(entry, now); wait > metrics.LongestCurrentWaitMinutes {
+			metrics.LongestCurrentWaitMinutes = wait
+		}
+	}
+	return metrics, nil
+}
+
+func (s *QueueService) close(id uint, change func(entry *models.QueueEntry) error) (*models.QueueEntry, error) {
+	entry, err := s.queueRepo.Update(id, func(entry *models.QueueEntry) error {
+		if err := change(entry); err != nil {
+			return err
+		}
+		now := time.Now()
+		entry.ClosedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Queue entry closed", zap.Uint("entry_id", id), zap.String("status", entry.Status))
+	return entry, nil
+}
+
+// waitMinutes is the time from check-in until the patient was called in,
+// or until now while they are still waiting
+func waitMinutes(entry models.QueueEntry, now time.Time) int {
+	end := now
+	if entry.CalledAt != nil {
+		end = *entry.CalledAt
+	}
+	return int(end.Sub(entry.CheckedInAt).Minutes())
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/auth"
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var (
+	referralUrgencies = []string{models.ReferralUrgencyRoutine, models.ReferralUrgencyUrgent, models.ReferralUrgencyEmergency}
+	referralStatuses  = []string{
+		models.ReferralStatusSent,
+		models.ReferralStatusAccepted,
+		models.ReferralStatusDeclined,
+		models.ReferralStatusCompleted,
+	}
+	// openReferralStatuses are the referrals still needing the receiver's attention
+	openReferralStatuses = []string{models.ReferralStatusSent, models.ReferralStatusAccepted}
+)
+
+// referralCareTeamRole is the care team role given to a doctor accepting a referral
+const referralCareTeamRole = "consulting"
+
+// ReferralInput is the data needed to refer a patient. Exactly one of
+// ReceivingDoctorID and ExternalProvider is set.
+type ReferralInput struct {
+	ReceivingDoctorID *uint
+	ExternalProvider  string
+	ExternalContact   string
+	Specialty         string
+	Reason            string
+	Urgency           string
+	DocumentIDs       []uint
+}
+
+// ReferralService handles referrals between doctors and to external providers
+type ReferralService struct {
+	referralRepo        *repositories.ReferralRepository
+	patientRepo         *repositories.PatientRepository
+	userRepo            *repositories.UserRepository
+	documentRepo        *repositories.DocumentRepository
+	careTeamService     *CareTeamService
+	notificationService *NotificationService
+	logger              *zap.Logger
+}
+
+// NewReferralService creates a new referral service instance
+func NewReferralService(
+	referralRepo *repositories.ReferralRepository,
+	patientRepo *repositories.PatientRepository,
+	userRepo *repositories.UserRepository,
+	documentRepo *repositories.DocumentRepository,
+	careTeamService *CareTeamService,
+	notificationService *NotificationService,
+	logger *zap.Logger,
+) *ReferralService {
+	return &ReferralService{
+		referralRepo:        referralRepo,
+		patientRepo:         patientRepo,
+		userRepo:            userRepo,
+		documentRepo:        documentRepo,
+		careTeamService:     careTeamService,
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// CreateReferral refers a patient and lets the receiving doctor know
+func (s *ReferralService) CreateReferral(patientID uint, input ReferralInput, doctorID uint) (*models.Referral, error) {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidInput)
+	}
+	if input.Urgency == "" {
+		input.Urgency = models.ReferralUrgencyRoutine
+	}
+	if !contains(referralUrgencies, input.Urgency) {
+		return nil, fmt.Errorf("%w: urgency must be one of %v", ErrInvalidInput, referralUrgencies)
+	}
+
+	switch {
+	case input.ReceivingDoctorID != nil && input.ExternalProvider != "":
+		return nil, fmt.Errorf("%w: refer either to a doctor or to an external provider, not both", ErrInvalidInput)
+	case input.ReceivingDoctorID != nil:
+		if *input.ReceivingDoctorID == doctorID {
+			return nil, fmt.Errorf("%w: doctors cannot refer patients to themselves", ErrInvalidInput)
+		}
+		receiver, err := s.userRepo.FindByID(*input.ReceivingDoctorID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		if receiver.Role != string(auth.RoleDoctor) {
+			return nil, fmt.Errorf("%w: referrals can only be sent to doctors", ErrInvalidInput)
+		}
+	case input.ExternalProvider == "":
+		return nil, fmt.Errorf("%w: receiving_doctor_id or external_provider is required", ErrInvalidInput)
+	}
+
+	documents := make([]models.PatientDocument, 0, len(input.DocumentIDs))
+	for _, documentID := range input.DocumentIDs {
+		document, err := s.documentRepo.FindByID(documentID)
+		if err != nil || document.PatientID != patientID {
+			return nil, fmt.Errorf("%w: document %d does not belong to the patient", ErrInvalidInput, documentID)
+		}
+		documents = append(documents, *document)
+	}
+
+	referral, err := s.referralRepo.Create(&models.Referral{
+		PatientID:         patientID,
+		ReferringDoctorID: doctorID,
+		ReceivingDoctorID: input.ReceivingDoctorID,
+		ExternalProvider:  input.ExternalProvider,
+		ExternalContact:   input.ExternalContact,
+		Specialty:         input.Specialty,
+		Reason:            input.Reason,
+		Urgency:           input.Urgency,
+		Status:            models.ReferralStatusSent,
+		Documents:         documents,
+		SentAt:            time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Referral sent",
+		zap.Uint("referral_id", referral.ID),
+		zap.Uint("patient_id", patientID),
+		zap.Uint("referring_doctor_id", doctorID),
+		zap.String("urgency", referral.Urgency),
+	)
+	if referral.ReceivingDoctorID != nil {
+		title := fmt.Sprintf("New %s referral", referral.Urgency)
+		message := fmt.Sprintf("%s has been referred to you: %s", patient.Name, referral.Reason)
+		_ = s.notificationService.Notify(*referral.ReceivingDoctorID, &patientID, models.NotificationTypeReferral, title, message)
+	}
+	return referral, nil
+}
+
+// GetReferral retrieves a referral
+func (s *ReferralService) GetReferral(id uint) (*models.Referral, error) {
+	return s.referralRepo.FindByID(id)
+}
+
+// GetPatientReferrals retrieves the referrals of a patient
+func (s *ReferralService) GetPatientReferrals(patientID uint) ([]models.Referral, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	return s.referralRepo.FindByPatient(patientID)
+}
+
+// GetInbox retrieves the referrals sent to a doctor; without a status,
+// those still sent or accepted
+func (s *ReferralService) GetInbox(doctorID uint, status string) ([]models.Referral, error) {
+	statuses, err := referralStatusFilter(status)
+	if err != nil {
+		return nil, err
+	}
+	return s.referralRepo.FindByReceiver(doctorID, statuses)
+}
+
+// GetSent retrieves the referrals a doctor has sent; without a status,
+// those still sent or accepted
+func (s *ReferralService) GetSent(doctorID uint, status string) ([]models.Referral, error) {
+	statuses, err := referralStatusFilter(status)
+	if err != nil {
+		return nil, err
+	}
+	return s.referralRepo.FindByReferrer(doctorID, statuses)
+}
+
+// Accept accepts a sent referral. A receiving doctor accepting joins the
+// patient's care team.
+func (s *ReferralService) Accept(id, userID uint, note string) (*models.Referral, error) {
+	referral, err := s.referralRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkReferralResponder(referral, userID); err != nil {
+		return nil, err
+	}
+	if referral.Status != models.ReferralStatusSent {
+		return nil, fmt.Errorf("%w: referral is %s", ErrConflict, referral.Status)
+	}
+	// Joining the care team first is safe to repeat should the status
+	// change below lose a race
+	if referral.ReceivingDoctorID != nil {
+		if _, err := s.careTeamService.AddMember(referral.PatientID, *referral.ReceivingDoctorID, referralCareTeamRole, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.respond(id, userID, "accepted", func(referral *models.Referral, now time.Time) error {
+		if referral.Status != models.ReferralStatusSent {
+			return fmt.Errorf("%w: referral is %s", ErrConflict, referral.Status)
+		}
+		referral.Status = models.ReferralStatusAccepted
+		referral.RespondedAt = &now
+		referral.ResponseNote = note
+		return nil
+	})
+}
+
+// Decline declines a sent referral
+func (s *ReferralService) Decline(id, userID uint, reason string) (*models.Referral, error) {
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required to decline a referral", ErrInvalidInput)
+	}
+	return s.respond(id, userID, "declined", func(referral *models.Referral, now time.Time) error {
+		if referral.Status != models.ReferralStatusSent {
+			return fmt.Errorf("%w: referral is %s", ErrConflict, referral.Status)
+		}
+		referral.Status = models.ReferralStatusDeclined
+		referral.RespondedAt = &now
+		referral.ResponseNote = reason
+		return nil
+	})
+}
+
+// Complete closes an accepted referral with its outcome
+func (s *ReferralService) Complete(id, userID uint, outcome string) (*models.Referral, error) {
+	return s.respond(id, userID, "completed", func(referral *models.Referral, now time.Time) error {
+		if referral.Status != models.ReferralStatusAccepted {
+			return fmt.Errorf("%w: referral is %s", ErrConflict, referral.Status)
+		}
+		referral.Status = models.ReferralStatusCompleted
+		referral.CompletedAt = &now
+		referral.Outcome = outcome
+		return nil
+	})
+}
+
+// respond applies a response from the receiving doctor, or from the
+// referring doctor for external referrals, and lets the referring doctor
+// know of responses they did not record themselves
+func (s *ReferralService) respond(id, userID uint, verb string, change func(referral *models.Referral, now time.Time) error) (*models.Referral, error) {
+	referral, err := s.referralRepo.Update(id, func(referral *models.Referral) error {
+		if err := checkReferralResponder(referral, userID); err != nil {
+			return err
+		}
+		return change(referral, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Referral "+verb, zap.Uint("referral_id", id), zap.Uint("user_id", userID))
+	if referral.ReferringDoctorID != userID {
+		title := "Referral " + verb
+		message := fmt.Sprintf("Your referral of %s has been %s", referral.Patient.Name, verb)
+		if referral.ResponseNote != "" && referral.Status == models.ReferralStatusDeclined {
+			message += ": " + referral.ResponseNote
+		}
+		_ = s.notificationService.Notify(referral.ReferringDoctorID, &referral.PatientID, models.NotificationTypeReferral, title, message)
+	}
+	return referral, nil
+}
+
+// checkReferralResponder allows the receiving doctor to answer an internal
+// referral and the referring doctor to record the answer to an external one
+func checkReferralResponder(referral *models.Referral, userID uint) error {
+	if referral.ReceivingDoctorID != nil {
+		if *referral.ReceivingDoctorID != userID {
+			return fmt.Errorf("%w: only the receiving doctor can respond to this referral", ErrForbidden)
+		}
+		return nil
+	}
+	if referral.ReferringDoctorID != userID {
+		return fmt.Errorf("%w: only the referring doctor can record the response of an external provider", ErrForbidden)
+	}
+	return nil
+}
+
+func referralStatusFilter(status string) ([]string, error) {
+	if status == "" {
+		return openReferralStatuses, nil
+	}
+	if !contains(referralStatuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of %v", ErrInvalidInput, referralStatuses)
+	}
+	return []string{status}, nil
+}
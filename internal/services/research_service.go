@@ -0,0 +1,102 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+// ResearchRecord is a de-identified view of one patient. The pseudonym is
+// stable across extracts but cannot be reversed without the key.
+type ResearchRecord struct {
+	Pseudonym  string   `json:"pseudonym"`
+	AgeBand    string   `json:"age_band"`
+	Gender     string   `json:"gender"`
+	ICD10Codes []string `json:"icd10_codes"`
+}
+
+// researchExtractBatchSize is the number of patients read at a time
+const researchExtractBatchSize = 500
+
+// ResearchService produces de-identified research extracts
+type ResearchService struct {
+	patientRepo *repositories.PatientRepository
+	problemRepo *repositories.ProblemRepository
+	logger      *zap.Logger
+}
+
+// NewResearchService creates a new research service instance
+func NewResearchService(patientRepo *repositories.PatientRepository, problemRepo *repositories.ProblemRepository, logger *zap.Logger) *ResearchService {
+	return &ResearchService{
+		patientRepo: patientRepo,
+		problemRepo: problemRepo,
+		logger:      logger,
+	}
+}
+
+// Extract builds a de-identified extract of all patients, skipping those
+// who have withdrawn research consent
+func (s *ResearchService) Extract() ([]ResearchRecord, error) {
+	key := viper.GetString("research.pseudonym_key")
+	if key == "" {
+		return nil, errors.New("research.pseudonym_key is not configured")
+	}
+
+	problems, err := s.problemRepo.FindByStatus(models.ProblemStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	codes := make(map[uint][]string)
+	for _, problem := range problems {
+		codes[problem.PatientID] = append(codes[problem.PatientID], problem.ICD10Code)
+	}
+
+	records := []ResearchRecord{}
+	search := repositories.PatientSearch{WithdrawnConsents: []string{models.ConsentTypeResearch}}
+	err = s.patientRepo.SearchInBatches(search, researchExtractBatchSize, func(patients []models.Patient) error {
+		for _, patient := range patients {
+			patientCodes := codes[patient.ID]
+			if patientCodes == nil {
+				patientCodes = []string{}
+			}
+			records = append(records, ResearchRecord{
+				Pseudonym:  pseudonym(key, patient.ID),
+				AgeBand:    ageBand(patient.Age),
+				Gender:     patient.Gender,
+				ICD10Codes: patientCodes,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Research extract generated", zap.Int("patients", len(records)))
+	return records, nil
+}
+
+func pseudonym(key string, patientID uint) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(strconv.FormatUint(uint64(patientID), 10)))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// ageBand reports age in ten-year bands; ages of 90 and over are grouped
+// together as they are identifying on their own
+func ageBand(age int) string {
+	if age >= 90 {
+		return "90+"
+	}
+	low := age / 10 * 10
+	return fmt.Sprintf("%d-%d", low, low+9)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hospital-portal/internal/models"
+	"hospital-portal/internal/repositories"
+)
+
+var consciousnessLevels = []string{"alert", "confusion", "voice", "pain", "unresponsive"}
+
+// VitalSignsService handles vital signs business logic
+type VitalSignsService struct {
+	vitalsRepo          *repositories.VitalSignsRepository
+	patientRepo         *repositories.PatientRepository
+	encounterRepo       *repositories.EncounterRepository
+	notificationService *NotificationService
+	logger              *zap.Logger
+}
+
+// NewVitalSignsService creates a new vital signs service instance
+func NewVitalSignsService(vitalsRepo *repositories.VitalSignsRepository, patientRepo *repositories.PatientRepository, encounterRepo *repositories.EncounterRepository, notificationService *NotificationService, logger *zap.Logger) *VitalSignsService {
+	return &VitalSignsService{
+		vitalsRepo:          vitalsRepo,
+		patientRepo:         patientRepo,
+		encounterRepo:       encounterRepo,
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// RecordVitals validates and stores a set of vital signs, computing BMI
+// and NEWS2. Patients scoring above the low risk band are flagged to
+// their care team.
+func (s *VitalSignsService) RecordVitals(patientID uint, vitals *models.VitalSigns, recordedByID uint) (*models.VitalSigns, error) {
+	patient, err := s.patientRepo.FindByID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateVitals(vitals); err != nil {
+		return nil, err
+	}
+
+	var encounter *models.Encounter
+	if vitals.EncounterID != nil {
+		encounter, err = s.encounterRepo.FindByID(*vitals.EncounterID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		if encounter.PatientID != patientID {
+			return nil, fmt.Errorf("%w: encounter belongs to another patient", ErrInvalidInput)
+		}
+	}
+
+	vitals.PatientID = patientID
+	vitals.RecordedByID = recordedByID
+	if vitals.RecordedAt.IsZero() {
+		vitals.RecordedAt = time.Now()
+	}
+	if vitals.WeightKg != nil && vitals.HeightCm != nil {
+		heightM := *vitals.HeightCm / 100
+		bmi := math.Round(*vitals.WeightKg/(heightM*heightM)*10) / 10
+		vitals.BMI = &bmi
+	}
+	news2 := CalculateNEWS2(vitals)
+	if news2.Complete {
+		vitals.News2Score = &news2.Score
+		vitals.News2Risk = news2.Risk
+	}
+
+	created, err := s.vitalsRepo.Create(vitals)
+	if err != nil {
+		s.logger.Error("Failed to record vital signs", zap.Error(err), zap.Uint("patient_id", patientID))
+		return nil, err
+	}
+
+	if news2.Complete && news2.Risk != models.News2RiskLow {
+		s.flagDeterioration(patient, created, encounter)
+	}
+	return created, nil
+}
+
+// GetVitalsSeries retrieves a patient's vital signs within a time range
+func (s *VitalSignsService) GetVitalsSeries(patientID uint, from, to time.Time, limit int) ([]models.VitalSigns, error) {
+	if _, err := s.patientRepo.FindByID(patientID); err != nil {
+		return nil, err
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidInput)
+	}
+	return s.vitalsRepo.FindByPatientInRange(patientID, from, to, limit)
+}
+
+func (s *VitalSignsService) flagDeterioration(patient *models.Patient, vitals *models.VitalSigns, encounter *models.Encounter) {
+	title := fmt.Sprintf("NEWS2 %d (%s risk) for %s", *vitals.News2Score, vitals.News2Risk, patient.Name)
+	message := fmt.Sprintf("Vital signs recorded at %s give a NEWS2 score of %d.",
+		vitals.RecordedAt.Format(time.RFC3339), *vitals.News2Score)
+
+	previous, err := s.vitalsRepo.FindLatestScored(patient.ID, vitals.RecordedAt)
+	if err != nil {
+		s.logger.Warn("Failed to fetch previous NEWS2 score", zap.Error(err), zap.Uint("patient_id", patient.ID))
+	} else if previous != nil {
+		message += fmt.Sprintf(" Previous score was %d at %s.",
+			*previous.News2Score, previous.RecordedAt.Format(time.RFC3339))
+	}
+
+	var doctorID uint
+	if encounter != nil {
+		doctorID = encounter.DoctorID
+	}
+	if err := s.notificationService.NotifyCareTeam(patient.ID, models.NotificationTypeDeterioration, title, message, doctorID); err != nil {
+		s.logger.Error("Failed to flag deteriorating patient", zap.Error(err), zap.Uint("patient_id", patient.ID))
+	}
+}
+
+// validateVitals rejects physiologically implausible values
+func validateVitals(v *models.VitalSigns) error {
+	if v.SystolicBP == nil && v.DiastolicBP == nil && v.HeartRate == nil && v.RespiratoryRate == nil &&
+		v.Temperature == nil && v.SpO2 == nil && v.WeightKg == nil && v.HeightCm == nil {
+		return fmt.Errorf("%w: at least one measurement is required", ErrInvalidInput)
+	}
+
+	intRanges := []struct {
+		name     string
+		value    *int
+		min, max int
+	}{
+		{"systolic_bp", v.SystolicBP, 40, 300},
+		{"diastolic_bp", v.DiastolicBP, 20, 200},
+		{"heart_rate", v.HeartRate, 20, 300},
+		{"respiratory_rate", v.RespiratoryRate, 2, 80},
+		{"spo2", v.SpO2, 40, 100},
+	}
+	for _, r := range intRanges {
+		if r.value != nil && (*r.value < r.min || *r.value > r.max) {
+			return fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidInput, r.name, r.min, r.max)
+		}
+	}
+
+	floatRanges := []struct {
+		name     string
+		value    *float64
+		min, max float64
+	}{
+		{"temperature", v.Temperature, 25, 45},
+		{"weight_kg", v.WeightKg, 0.3, 500},
+		{"height_cm", v.HeightCm, 20, 275},
+	}
+	for _, r := range floatRanges {
+		if r.value != nil && (*r.value < r.min || *r.value > r.max) {
+			return fmt.Errorf("%w: %s must be between %g and %g", ErrInvalidInput, r.name, r.min, r.max)
+		}
+	}
+
+	if (v.SystolicBP == nil) != (v.DiastolicBP == nil) {
+		return fmt.Errorf("%w: systolic_bp and diastolic_bp must be recorded together", ErrInvalidInput)
+	}
+	if v.SystolicBP != nil && *v.DiastolicBP >= *v.SystolicBP {
+		return fmt.Errorf("%w: diastolic_bp must be lower than systolic_bp", ErrInvalidInput)
+	}
+	if v.Consciousness != "" && !contains(consciousnessLevels, v.Consciousness) {
+		return fmt.Errorf("%w: consciousness must be one of %v", ErrInvalidInput, consciousnessLevels)
+	}
+	if v.RecordedAt.After(time.Now().Add(5 * time.Minute)) {
+		return fmt.Errorf("%w: recorded_at cannot be in the future", ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// ErrNotFound is returned when a blob does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStorage stores opaque binary objects under string keys
+type BlobStorage interface {
+	// Put stores size bytes read from body under key. checksum is the
+	// hex SHA-256 of the content, computed by the caller.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType, checksum string) error
+	// Get opens the blob stored under key for streaming
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFromConfig builds the storage backend selected by storage.driver
+func NewFromConfig() (BlobStorage, error) {
+	switch driver := viper.GetString("storage.driver"); driver {
+	case "", "local":
+		path := viper.GetString("storage.local.path")
+		if path == "" {
+			path = "./data/blobs"
+		}
+		return NewLocalStorage(path)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  viper.GetString("storage.s3.endpoint"),
+			Region:    viper.GetString("storage.s3.region"),
+			Bucket:    viper.GetString("storage.s3.bucket"),
+			AccessKey: viper.GetString("storage.s3.access_key"),
+			SecretKey: viper.GetString("storage.s3.secret_key"),
+			UseSSL:    viper.GetBool("storage.s3.use_ssl"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
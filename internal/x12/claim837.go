@@ -0,0 +1,235 @@
+package x12
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Party identifies the sender or receiver of an interchange
+type Party struct {
+	Name          string
+	ID            string // ISA/GS ID and NM109 of the submitter or receiver
+	ContactName   string
+	ContactPhone  string
+	QualifierCode string // ISA05/ISA07, "ZZ" when empty
+}
+
+// Address is a postal address in the N3/N4 segments
+type Address struct {
+	Line1      string
+	Line2      string
+	City       string
+	State      string
+	PostalCode string
+	Country    string // ISO code, omitted for US addresses
+}
+
+// Provider is the billing provider
+type Provider struct {
+	Name    string
+	NPI     string
+	TaxID   string
+	Address Address
+}
+
+// Person is a subscriber or patient
+type Person struct {
+	LastName    string
+	FirstName   string
+	MemberID    string // subscribers only
+	Address     Address
+	DateOfBirth *time.Time
+	Gender      string // M, F or U
+}
+
+// ServiceLine is one procedure of a claim
+type ServiceLine struct {
+	ProcedureCode     string // CPT/HCPCS
+	Charge            int64  // cents
+	Units             int
+	ServiceDate       time.Time
+	DiagnosisPointers []int // 1-based indexes into Claim.Diagnoses
+	LineControlNumber string
+}
+
+// Claim is one 837P claim
+type Claim struct {
+	ControlNumber   string // CLM01, echoed back in the 835 CLP01
+	TotalCharge     int64  // cents
+	PlaceOfService  string // CMS place of service code
+	Diagnoses       []string
+	Lines           []ServiceLine
+	PayerName       string
+	PayerID         string
+	ClaimFilingCode string // SBR09, e.g. CI commercial, MB Medicare Part B
+	GroupNumber     string
+	Relationship    string // patient to subscriber: 18 self, 01 spouse, 19 child, G8 other
+	Subscriber      Person
+	Patient         *Person // nil when the patient is the subscriber
+}
+
+// Batch837 is the content of one 837P file
+type Batch837 struct {
+	Sender          Party
+	Receiver        Party
+	Provider        Provider
+	ControlNumber   int // ISA13 and GS06
+	ReferenceID     string
+	CreatedAt       time.Time
+	ProductionUsage bool // ISA15 P, otherwise T
+	Claims          []Claim
+}
+
+// Write837P writes a batch as an 005010X222A1 professional claim
+// interchange with one transaction set
+func Write837P(out io.Writer, batch Batch837) error {
+	if len(batch.Claims) == 0 {
+		return fmt.Errorf("x12: a claim batch needs at least one claim")
+	}
+	w := NewWriter(out)
+	control := fmt.Sprintf("%09d", batch.ControlNumber)
+	usage := "T"
+	if batch.ProductionUsage {
+		usage = "P"
+	}
+	qualifier := func(p Party) string {
+		if p.QualifierCode != "" {
+			return p.QualifierCode
+		}
+		return "ZZ"
+	}
+
+	w.Segment("ISA", "00", pad("", 10), "00", pad("", 10),
+		qualifier(batch.Sender), pad(batch.Sender.ID, 15), qualifier(batch.Receiver), pad(batch.Receiver.ID, 15),
+		batch.CreatedAt.Format("060102"), batch.CreatedAt.Format("1504"), string(RepetitionSeparator), "00501",
+		control, "0", usage, string(ComponentSeparator))
+	w.Segment("GS", "HC", batch.Sender.ID, batch.Receiver.ID, batch.CreatedAt.Format("20060102"),
+		batch.CreatedAt.Format("1504"), fmt.Sprint(batch.ControlNumber), "X", "005010X222A1")
+
+	w.ResetCount()
+	w.Segment("ST", "837", "0001", "005010X222A1")
+	w.Segment("BHT", "0019", "00", Clean(batch.ReferenceID, 50), batch.CreatedAt.Format("20060102"), batch.CreatedAt.Format("1504"), "CH")
+
+	// 1000A submitter and 1000B receiver
+	w.Segment("NM1", "41", "2", Clean(batch.Sender.Name, 60), "", "", "", "", "46", batch.Sender.ID)
+	w.Segment("PER", "IC", Clean(batch.Sender.ContactName, 60), "TE", digits(batch.Sender.ContactPhone))
+	w.Segment("NM1", "40", "2", Clean(batch.Receiver.Name, 60), "", "", "", "", "46", batch.Receiver.ID)
+
+	// 2000A/2010AA billing provider
+	w.Segment("HL", "1", "", "20", "1")
+	w.Segment("NM1", "85", "2", Clean(batch.Provider.Name, 60), "", "", "", "", "XX", batch.Provider.NPI)
+	writeAddress(w, batch.Provider.Address)
+	w.Segment("REF", "EI", digits(batch.Provider.TaxID))
+
+	hl := 1
+	for _, claim := range batch.Claims {
+		// 2000B subscriber, one per claim to keep the claims independent
+		hl++
+		subscriberHL := hl
+		hasPatientLoop := claim.Patient != nil
+		child := "0"
+		relationship := "18"
+		if hasPatientLoop {
+			child = "1"
+			relationship = ""
+		}
+		w.Segment("HL", fmt.Sprint(subscriberHL), "1", "22", child)
+		w.Segment("SBR", "P", relationship, Clean(claim.GroupNumber, 50), "", "", "", "", "", claim.ClaimFilingCode)
+		writePerson(w, "IL", claim.Subscriber, true)
+		w.Segment("NM1", "PR", "2", Clean(claim.PayerName, 60), "", "", "", "", "PI", claim.PayerID)
+
+		// 2000C patient, when not the subscriber
+		if hasPatientLoop {
+			hl++
+			w.Segment("HL", fmt.Sprint(hl), fmt.Sprint(subscriberHL), "23", "0")
+			w.Segment("PAT", claim.Relationship)
+			writePerson(w, "QC", *claim.Patient, false)
+		}
+
+		// 2300 claim
+		w.Segment("CLM", claim.ControlNumber, FormatAmount(claim.TotalCharge), "", "",
+			Composite(claim.PlaceOfService, "B", "1"), "Y", "A", "Y", "Y")
+		diagnoses := make([]string, 0, len(claim.Diagnoses))
+		for i, code := range claim.Diagnoses {
+			qualifier := "ABF"
+			if i == 0 {
+				qualifier = "ABK"
+			}
+			// ICD-10 codes are sent without the decimal point
+			diagnoses = append(diagnoses, Composite(qualifier, digitsAndLetters(code)))
+		}
+		w.Segment("HI", diagnoses...)
+
+		// 2400 service lines
+		for i, line := range claim.Lines {
+			pointers := make([]string, 0, len(line.DiagnosisPointers))
+			for _, pointer := range line.DiagnosisPointers {
+				pointers = append(pointers, fmt.Sprint(pointer))
+			}
+			w.Segment("LX", fmt.Sprint(i+1))
+			w.Segment("SV1", Composite("HC", line.ProcedureCode), FormatAmount(line.Charge), "UN", fmt.Sprint(line.Units),
+				"", "", Composite(pointers...))
+			w.Segment("DTP", "472", "D8", line.ServiceDate.Format("20060102"))
+			if line.LineControlNumber != "" {
+				w.Segment("REF", "6R", line.LineControlNumber)
+			}
+		}
+	}
+
+	w.Segment("SE", fmt.Sprint(w.Count()+1), "0001")
+	w.Segment("GE", "1", fmt.Sprint(batch.ControlNumber))
+	w.Segment("IEA", "1", control)
+	return w.Flush()
+}
+
+// writePerson writes the NM1, N3/N4 and DMG segments of a subscriber or
+// patient. DMG is only written when the date of birth is known.
+func writePerson(w *Writer, entity string, person Person, subscriber bool) {
+	if subscriber {
+		w.Segment("NM1", entity, "1", Clean(person.LastName, 60), Clean(person.FirstName, 35), "", "", "", "MI", Clean(person.MemberID, 80))
+	} else {
+		w.Segment("NM1", entity, "1", Clean(person.LastName, 60), Clean(person.FirstName, 35))
+	}
+	if person.Address.Line1 != "" {
+		writeAddress(w, person.Address)
+	}
+	if person.DateOfBirth != nil {
+		gender := person.Gender
+		if gender == "" {
+			gender = "U"
+		}
+		w.Segment("DMG", "D8", person.DateOfBirth.Format("20060102"), gender)
+	}
+}
+
+func writeAddress(w *Writer, address Address) {
+	w.Segment("N3", Clean(address.Line1, 55), Clean(address.Line2, 55))
+	country := address.Country
+	if country == "US" {
+		country = ""
+	}
+	w.Segment("N4", Clean(address.City, 30), Clean(address.State, 2), digitsAndLetters(address.PostalCode), country)
+}
+
+// digits keeps only the digits of a phone number or tax ID
+func digits(value string) string {
+	out := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] >= '0' && value[i] <= '9' {
+			out = append(out, value[i])
+		}
+	}
+	return string(out)
+}
+
+func digitsAndLetters(value string) string {
+	out := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' {
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
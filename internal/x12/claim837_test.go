@@ -0,0 +1,207 @@
+package x12
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the sample 837P from Write837P")
+
+const sample837P = "../../data/edi/sample-837p.x12"
+
+func sampleBatch() Batch837 {
+	serviceDate := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	birth := time.Date(1980, 4, 12, 0, 0, 0, 0, time.UTC)
+	childBirth := time.Date(2015, 7, 3, 0, 0, 0, 0, time.UTC)
+	return Batch837{
+		Sender:   Party{Name: "General Hospital", ID: "HOSPITALPORTAL", ContactName: "Billing Office", ContactPhone: "(312) 555-0100"},
+		Receiver: Party{Name: "Clearinghouse", ID: "CLEARINGHOUSE"},
+		Provider: Provider{
+			Name:    "General Hospital",
+			NPI:     "1234567893",
+			TaxID:   "12-3456789",
+			Address: Address{Line1: "1 Hospital Way", City: "Chicago", State: "IL", PostalCode: "60601", Country: "US"},
+		},
+		ControlNumber: 101,
+		ReferenceID:   "BATCH101",
+		CreatedAt:     time.Date(2026, 10, 2, 9, 30, 0, 0, time.UTC),
+		Claims: []Claim{
+			{
+				ControlNumber:   "CLM0000001",
+				TotalCharge:     20000,
+				PlaceOfService:  "11",
+				Diagnoses:       []string{"E11.9", "I10"},
+				PayerName:       "Blue Cross Blue Shield",
+				PayerID:         "BCBS01",
+				ClaimFilingCode: "CI",
+				GroupNumber:     "GRP100",
+				Relationship:    "18",
+				Subscriber: Person{
+					LastName:    "Doe",
+					FirstName:   "Jane",
+					MemberID:    "XYZ123456789",
+					Address:     Address{Line1: "12 Elm St", Line2: "Apt 3", City: "Chicago", State: "IL", PostalCode: "60614", Country: "US"},
+					DateOfBirth: &birth,
+					Gender:      "F",
+				},
+				Lines: []ServiceLine{
+					{ProcedureCode: "99213", Charge: 15000, Units: 1, ServiceDate: serviceDate, DiagnosisPointers: []int{1, 2}, LineControlNumber: "CHG1"},
+					{ProcedureCode: "81002", Charge: 5000, Units: 1, ServiceDate: serviceDate, DiagnosisPointers: []int{1}},
+				},
+			},
+			{
+				ControlNumber:   "CLM0000002",
+				TotalCharge:     15050,
+				PlaceOfService:  "23",
+				Diagnoses:       []string{"S52.501A"},
+				PayerName:       "Blue Cross Blue Shield",
+				PayerID:         "BCBS01",
+				ClaimFilingCode: "CI",
+				Relationship:    "19",
+				Subscriber:      Person{LastName: "Roe", FirstName: "Richard", MemberID: "XYZ987654321"},
+				Patient: &Person{
+					LastName:    "Roe",
+					FirstName:   "Sam",
+					Address:     Address{Line1: "5 Oak Ave", City: "Evanston", State: "IL", PostalCode: "60201"},
+					DateOfBirth: &childBirth,
+					Gender:      "M",
+				},
+				Lines: []ServiceLine{
+					{ProcedureCode: "25600", Charge: 15050, Units: 1, ServiceDate: serviceDate, DiagnosisPointers: []int{1}},
+				},
+			},
+		},
+	}
+}
+
+func TestWrite837PMatchesSample(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write837P(&out, sampleBatch()); err != nil {
+		t.Fatalf("Write837P failed: %v", err)
+	}
+	if *update {
+		if err := os.WriteFile(sample837P, out.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(sample837P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		gotLines, wantLines := strings.Split(out.String(), "\n"), strings.Split(string(want), "\n")
+		for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+			var got, expected string
+			if i < len(gotLines) {
+				got = gotLines[i]
+			}
+			if i < len(wantLines) {
+				expected = wantLines[i]
+			}
+			if got != expected {
+				t.Fatalf("line %d differs from %s:\ngot  %q\nwant %q", i+1, sample837P, got, expected)
+			}
+		}
+	}
+}
+
+func TestWrite837PRoundTrip(t *testing.T) {
+	batch := sampleBatch()
+	var out bytes.Buffer
+	if err := Write837P(&out, batch); err != nil {
+		t.Fatalf("Write837P failed: %v", err)
+	}
+	interchange, err := Parse(&out)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var clms, dmgs, svs []Segment
+	stIndex, seIndex := -1, -1
+	for i, segment := range interchange.Segments {
+		switch segment.ID {
+		case "ST":
+			stIndex = i
+		case "SE":
+			seIndex = i
+			if want := i - stIndex + 1; segment.Element(1) != strconv.Itoa(want) {
+				t.Errorf("SE01 = %s, want %d", segment.Element(1), want)
+			}
+		case "CLM":
+			clms = append(clms, segment)
+		case "DMG":
+			dmgs = append(dmgs, segment)
+		case "SV1":
+			svs = append(svs, segment)
+		}
+	}
+	if stIndex < 0 || seIndex < 0 {
+		t.Fatal("transaction set envelope is missing")
+	}
+
+	if len(clms) != len(batch.Claims) {
+		t.Fatalf("got %d CLM segments, want %d", len(clms), len(batch.Claims))
+	}
+	for i, clm := range clms {
+		claim := batch.Claims[i]
+		if clm.Element(1) != claim.ControlNumber {
+			t.Errorf("CLM01 = %s, want %s", clm.Element(1), claim.ControlNumber)
+		}
+		total, err := ParseAmount(clm.Element(2))
+		if err != nil || total != claim.TotalCharge {
+			t.Errorf("CLM02 = %s, want %d cents", clm.Element(2), claim.TotalCharge)
+		}
+		if place := interchange.Components(clm.Element(5))[0]; place != claim.PlaceOfService {
+			t.Errorf("CLM05-1 = %s, want %s", place, claim.PlaceOfService)
+		}
+	}
+
+	var charged int64
+	for _, sv := range svs {
+		amount, err := ParseAmount(sv.Element(2))
+		if err != nil {
+			t.Fatalf("SV102 %q: %v", sv.Element(2), err)
+		}
+		charged += amount
+	}
+	if want := batch.Claims[0].TotalCharge + batch.Claims[1].TotalCharge; charged != want {
+		t.Errorf("service lines charge %d, want %d", charged, want)
+	}
+
+	wantDMG := []string{"D8*19800412*F", "D8*20150703*M"}
+	if len(dmgs) != len(wantDMG) {
+		t.Fatalf("got %d DMG segments, want %d", len(dmgs), len(wantDMG))
+	}
+	for i, dmg := range dmgs {
+		if got := strings.Join(dmg.Elements, "*"); got != wantDMG[i] {
+			t.Errorf("DMG = %s, want %s", got, wantDMG[i])
+		}
+	}
+}
+
+func TestWrite837POmitsDMGWithoutBirthDate(t *testing.T) {
+	batch := sampleBatch()
+	batch.Claims = batch.Claims[:1]
+	batch.Claims[0].Subscriber.DateOfBirth = nil
+	var out bytes.Buffer
+	if err := Write837P(&out, batch); err != nil {
+		t.Fatalf("Write837P failed: %v", err)
+	}
+	if strings.Contains(out.String(), "DMG*") {
+		t.Error("DMG written without a date of birth")
+	}
+}
+
+func TestWrite837PNeedsAClaim(t *testing.T) {
+	batch := sampleBatch()
+	batch.Claims = nil
+	if err := Write837P(&bytes.Buffer{}, batch); err == nil {
+		t.Error("an empty batch was written")
+	}
+}
@@ -0,0 +1,163 @@
+package x12
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Adjustment is one CAS adjustment: a group code (CO contractual, PR
+// patient responsibility, OA other, PI payer initiated), a reason code
+// and an amount in cents
+type Adjustment struct {
+	GroupCode  string
+	ReasonCode string
+	Amount     int64
+}
+
+// ServicePayment is the payment of one service line (SVC loop)
+type ServicePayment struct {
+	ProcedureCode string
+	Charged       int64
+	Paid          int64
+	Adjustments   []Adjustment
+}
+
+// ClaimPayment is the payment of one claim (CLP loop)
+type ClaimPayment struct {
+	ControlNumber         string // our CLM01
+	StatusCode            string // 1 primary, 2 secondary, 4 denied, 22 reversal...
+	Charged               int64
+	Paid                  int64
+	PatientResponsibility int64
+	PayerClaimNumber      string
+	Adjustments           []Adjustment // claim and service level
+	Services              []ServicePayment
+}
+
+// Remittance is the content of one 835 transaction set
+type Remittance struct {
+	PayerName   string
+	TraceNumber string // check or EFT trace number
+	PaymentDate time.Time
+	TotalPaid   int64
+	Claims      []ClaimPayment
+}
+
+// Claim status codes that mean the payer denied the claim
+const ClaimStatusDenied = "4"
+
+// Parse835 extracts the remittances of an 835 interchange
+func Parse835(interchange *Interchange) ([]Remittance, error) {
+	var remittances []Remittance
+	var current *Remittance
+	var claim *ClaimPayment
+	var service *ServicePayment
+
+	for _, segment := range interchange.Segments {
+		var err error
+		switch segment.ID {
+		case "ST":
+			if segment.Element(1) != "835" {
+				return nil, fmt.Errorf("x12: transaction set %s is not an 835", segment.Element(1))
+			}
+			remittances = append(remittances, Remittance{})
+			current = &remittances[len(remittances)-1]
+			claim, service = nil, nil
+		case "BPR":
+			if current == nil {
+				continue
+			}
+			if current.TotalPaid, err = ParseAmount(segment.Element(2)); err != nil {
+				return nil, err
+			}
+			if date := segment.Element(16); date != "" {
+				if current.PaymentDate, err = time.Parse("20060102", date); err != nil {
+					return nil, fmt.Errorf("x12: invalid BPR16 date %q", date)
+				}
+			}
+		case "TRN":
+			if current != nil {
+				current.TraceNumber = segment.Element(2)
+			}
+		case "N1":
+			if current != nil && segment.Element(1) == "PR" {
+				current.PayerName = segment.Element(2)
+			}
+		case "CLP":
+			if current == nil {
+				return nil, errors.New("x12: CLP segment outside a transaction set")
+			}
+			current.Claims = append(current.Claims, ClaimPayment{
+				ControlNumber:    segment.Element(1),
+				StatusCode:       segment.Element(2),
+				PayerClaimNumber: segment.Element(7),
+			})
+			claim = &current.Claims[len(current.Claims)-1]
+			service = nil
+			if claim.Charged, err = ParseAmount(segment.Element(3)); err != nil {
+				return nil, err
+			}
+			if claim.Paid, err = ParseAmount(segment.Element(4)); err != nil {
+				return nil, err
+			}
+			if claim.PatientResponsibility, err = ParseAmount(segment.Element(5)); err != nil {
+				return nil, err
+			}
+		case "SVC":
+			if claim == nil {
+				return nil, errors.New("x12: SVC segment outside a claim")
+			}
+			components := interchange.Components(segment.Element(1))
+			payment := ServicePayment{}
+			if len(components) > 1 {
+				payment.ProcedureCode = components[1]
+			}
+			if payment.Charged, err = ParseAmount(segment.Element(2)); err != nil {
+				return nil, err
+			}
+			if payment.Paid, err = ParseAmount(segment.Element(3)); err != nil {
+				return nil, err
+			}
+			claim.Services = append(claim.Services, payment)
+			service = &claim.Services[len(claim.Services)-1]
+		case "CAS":
+			if claim == nil {
+				return nil, errors.New("x12: CAS segment outside a claim")
+			}
+			adjustments, err := parseCAS(segment)
+			if err != nil {
+				return nil, err
+			}
+			claim.Adjustments = append(claim.Adjustments, adjustments...)
+			if service != nil {
+				service.Adjustments = append(service.Adjustments, adjustments...)
+			}
+		case "SE":
+			current, claim, service = nil, nil, nil
+		}
+	}
+
+	if len(remittances) == 0 {
+		return nil, errors.New("x12: no 835 transaction set found")
+	}
+	return remittances, nil
+}
+
+// parseCAS reads the up to six reason/amount/quantity triples of a CAS segment
+func parseCAS(segment Segment) ([]Adjustment, error) {
+	group := segment.Element(1)
+	var adjustments []Adjustment
+	for i := 2; i <= 17; i += 3 {
+		reason := segment.Element(i)
+		if reason == "" {
+			break
+		}
+		amount, err := ParseAmount(segment.Element(i + 1))
+		if err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, Adjustment{GroupCode: group, ReasonCode: reason, Amount: amount})
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,123 @@
+package x12
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse835Sample(t *testing.T) {
+	file, err := os.Open("../../data/edi/sample-835.x12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	interchange, err := Parse(file)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	remittances, err := Parse835(interchange)
+	if err != nil {
+		t.Fatalf("Parse835 failed: %v", err)
+	}
+	if len(remittances) != 1 {
+		t.Fatalf("got %d remittances, want 1", len(remittances))
+	}
+
+	remittance := remittances[0]
+	if remittance.PayerName != "BLUE CROSS BLUE SHIELD" {
+		t.Errorf("PayerName = %q", remittance.PayerName)
+	}
+	if remittance.TraceNumber != "EFT20261015001" {
+		t.Errorf("TraceNumber = %q", remittance.TraceNumber)
+	}
+	if want := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC); !remittance.PaymentDate.Equal(want) {
+		t.Errorf("PaymentDate = %v, want %v", remittance.PaymentDate, want)
+	}
+	if remittance.TotalPaid != 13250 {
+		t.Errorf("TotalPaid = %d, want 13250", remittance.TotalPaid)
+	}
+	if len(remittance.Claims) != 2 {
+		t.Fatalf("got %d claims, want 2", len(remittance.Claims))
+	}
+
+	paid := remittance.Claims[0]
+	if paid.ControlNumber != "CLM0000001" || paid.StatusCode != "1" || paid.PayerClaimNumber != "PAYERCLAIM0001" {
+		t.Errorf("first claim = %+v", paid)
+	}
+	if paid.Charged != 20000 || paid.Paid != 13250 || paid.PatientResponsibility != 2000 {
+		t.Errorf("first claim amounts: charged %d, paid %d, patient %d", paid.Charged, paid.Paid, paid.PatientResponsibility)
+	}
+	wantAdjustments := []Adjustment{
+		{GroupCode: "CO", ReasonCode: "45", Amount: 4750},
+		{GroupCode: "PR", ReasonCode: "2", Amount: 2000},
+	}
+	if !reflect.DeepEqual(paid.Adjustments, wantAdjustments) {
+		t.Errorf("first claim adjustments = %+v, want %+v", paid.Adjustments, wantAdjustments)
+	}
+	if len(paid.Services) != 1 {
+		t.Fatalf("first claim has %d services, want 1", len(paid.Services))
+	}
+	service := paid.Services[0]
+	if service.ProcedureCode != "99213" || service.Charged != 20000 || service.Paid != 13250 {
+		t.Errorf("service = %+v", service)
+	}
+	if !reflect.DeepEqual(service.Adjustments, wantAdjustments) {
+		t.Errorf("service adjustments = %+v, want %+v", service.Adjustments, wantAdjustments)
+	}
+
+	denied := remittance.Claims[1]
+	if denied.ControlNumber != "CLM0000002" || denied.StatusCode != ClaimStatusDenied {
+		t.Errorf("second claim = %+v", denied)
+	}
+	if denied.Charged != 15000 || denied.Paid != 0 {
+		t.Errorf("second claim amounts: charged %d, paid %d", denied.Charged, denied.Paid)
+	}
+	// A claim-level CAS belongs to no service line
+	if len(denied.Services) != 0 {
+		t.Errorf("second claim has %d services, want none", len(denied.Services))
+	}
+	if want := []Adjustment{{GroupCode: "CO", ReasonCode: "50", Amount: 15000}}; !reflect.DeepEqual(denied.Adjustments, want) {
+		t.Errorf("second claim adjustments = %+v, want %+v", denied.Adjustments, want)
+	}
+}
+
+func TestParseCASReadsAllTriples(t *testing.T) {
+	segment := Segment{ID: "CAS", Elements: strings.Split("CO*45*10**253*2*1*A1*3**B1*4**B2*5**B3*6", "*")}
+	adjustments, err := parseCAS(segment)
+	if err != nil {
+		t.Fatalf("parseCAS failed: %v", err)
+	}
+	want := []Adjustment{
+		{"CO", "45", 1000}, {"CO", "253", 200}, {"CO", "A1", 300},
+		{"CO", "B1", 400}, {"CO", "B2", 500}, {"CO", "B3", 600},
+	}
+	if !reflect.DeepEqual(adjustments, want) {
+		t.Errorf("adjustments = %+v, want %+v", adjustments, want)
+	}
+}
+
+func TestParse835Errors(t *testing.T) {
+	isa := "ISA*00*          *00*          *ZZ*SENDER         *ZZ*RECEIVER       *261015*1200*^*00501*000000001*0*T*:~"
+	for name, body := range map[string]string{
+		"not an 835":     "ST*837*0001~SE*2*0001~",
+		"no transaction": "GS*HP*A*B~GE*1*1~",
+		"CLP outside ST": "CLP*X*1*1*1~",
+		"SVC before CLP": "ST*835*0001~SVC*HC:99213*1*1~SE*3*0001~",
+		"bad amount":     "ST*835*0001~CLP*X*1*1.234*1~SE*3*0001~",
+		"bad BPR date":   "ST*835*0001~BPR*I*1*C*ACH*CCP*01*1*DA*1*1**01*1*DA*1*2026-10-15~SE*3*0001~",
+		"bad CAS amount": "ST*835*0001~CLP*X*1*1*1~CAS*CO*45*abc~SE*4*0001~",
+		"CAS before CLP": "ST*835*0001~CAS*CO*45*1~SE*3*0001~",
+	} {
+		interchange, err := Parse(strings.NewReader(isa + body + "IEA*1*000000001~"))
+		if err != nil {
+			t.Fatalf("%s: Parse failed: %v", name, err)
+		}
+		if _, err := Parse835(interchange); err == nil {
+			t.Errorf("%s: Parse835 succeeded, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package x12 reads and writes ANSI ASC X12 interchanges. It knows the
+// envelope (ISA/GS/ST) and the segments used by the 837P professional
+// claim and the 835 remittance advice; it is not a general EDI library.
+package x12
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Delimiters used when writing interchanges
+const (
+	ElementSeparator    = '*'
+	ComponentSeparator  = ':'
+	RepetitionSeparator = '^'
+	SegmentTerminator   = '~'
+)
+
+// Segment is one X12 segment: its ID followed by its elements. Elements
+// keep their component separators; use Components to split them.
+type Segment struct {
+	ID       string
+	Elements []string
+}
+
+// Element returns the 1-based element n, or "" when absent
+func (s Segment) Element(n int) string {
+	if n < 1 || n > len(s.Elements) {
+		return ""
+	}
+	return s.Elements[n-1]
+}
+
+// Interchange is a parsed X12 file
+type Interchange struct {
+	Segments           []Segment
+	ComponentSeparator byte
+}
+
+// Components splits a composite element
+func (i Interchange) Components(element string) []string {
+	return strings.Split(element, string(i.ComponentSeparator))
+}
+
+// Parse reads an X12 interchange. The delimiters are taken from the
+// fixed-width ISA segment, so files from other systems parse regardless
+// of the separators they chose.
+func Parse(r io.Reader) (*Interchange, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // UTF-8 byte order mark
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) < 106 || string(data[:3]) != "ISA" {
+		return nil, errors.New("x12: file does not start with an ISA segment")
+	}
+	elementSep := data[3]
+	componentSep := data[104]
+	terminator := data[105]
+
+	interchange := &Interchange{ComponentSeparator: componentSep}
+	for _, raw := range bytes.Split(data, []byte{terminator}) {
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		parts := strings.Split(string(raw), string(elementSep))
+		interchange.Segments = append(interchange.Segments, Segment{ID: parts[0], Elements: parts[1:]})
+	}
+	if last := interchange.Segments[len(interchange.Segments)-1]; last.ID != "IEA" {
+		return nil, errors.New("x12: interchange is not terminated by an IEA segment")
+	}
+	return interchange, nil
+}
+
+// Writer writes segments with the package delimiters, one per line, and
+// counts the segments of the current transaction set for SE01
+type Writer struct {
+	w     *bufio.Writer
+	count int
+	err   error
+}
+
+// NewWriter creates a writer on w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Segment writes a segment, dropping trailing empty elements. Elements are
+// expected to be cleaned already; composites legitimately carry the
+// component separator, so only the element separator and segment
+// terminator are rejected here.
+func (w *Writer) Segment(id string, elements ...string) {
+	if w.err != nil {
+		return
+	}
+	for len(elements) > 0 && elements[len(elements)-1] == "" {
+		elements = elements[:len(elements)-1]
+	}
+	for i, element := range elements {
+		if strings.ContainsRune(element, ElementSeparator) || strings.ContainsRune(element, SegmentTerminator) {
+			w.err = fmt.Errorf("x12: %s%02d contains a delimiter: %q", id, i+1, element)
+			return
+		}
+	}
+	line := id
+	if len(elements) > 0 {
+		line += string(ElementSeparator) + strings.Join(elements, string(ElementSeparator))
+	}
+	_, w.err = w.w.WriteString(line + string(SegmentTerminator) + "\n")
+	w.count++
+}
+
+// Composite joins the components of a composite element
+func Composite(components ...string) string {
+	for len(components) > 0 && components[len(components)-1] == "" {
+		components = components[:len(components)-1]
+	}
+	return strings.Join(components, string(ComponentSeparator))
+}
+
+// ResetCount starts counting a new transaction set
+func (w *Writer) ResetCount() {
+	w.count = 0
+}
+
+// Count returns the segments written since the last ResetCount
+func (w *Writer) Count() int {
+	return w.count
+}
+
+// Flush writes buffered data and reports the first error
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// Clean makes free text safe for an element: delimiters are replaced,
+// text is upper-cased as most payers expect and cut to max characters
+func Clean(value string, max int) string {
+	value = strings.NewReplacer("*", " ", ":", " ", "^", " ", "~", " ", "\n", " ", "\r", " ").Replace(value)
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if max > 0 && len(value) > max {
+		value = value[:max]
+	}
+	return value
+}
+
+// FormatAmount formats cents as an X12 decimal amount, e.g. 12550 as 125.5
+func FormatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	whole, fraction := cents/100, cents%100
+	switch {
+	case fraction == 0:
+		return fmt.Sprintf("%s%d", sign, whole)
+	case fraction%10 == 0:
+		return fmt.Sprintf("%s%d.%d", sign, whole, fraction/10)
+	default:
+		return fmt.Sprintf("%s%d.%02d", sign, whole, fraction)
+	}
+}
+
+// ParseAmount parses an X12 decimal amount into cents
+func ParseAmount(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("x12: amount %q has more than two decimals", value)
+	}
+	fraction = (fraction + "00")[:2]
+	var cents int64
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("x12: invalid amount %q", value)
+		}
+		cents = cents*10 + int64(r-'0')
+	}
+	if negative {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+// pad left-justifies value in a fixed-width ISA element
+func pad(value string, width int) string {
+	if len(value) > width {
+		return value[:width]
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
@@ -0,0 +1,139 @@
+package x12
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		cents int64
+		want  string
+	}{
+		{0, "0"},
+		{5, "0.05"},
+		{50, "0.5"},
+		{100, "1"},
+		{12550, "125.5"},
+		{12555, "125.55"},
+		{-12550, "-125.5"},
+		{-7, "-0.07"},
+		{100000000, "1000000"},
+	}
+	for _, tt := range tests {
+		if got := FormatAmount(tt.cents); got != tt.want {
+			t.Errorf("FormatAmount(%d) = %q, want %q", tt.cents, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"", 0},
+		{" ", 0},
+		{"0", 0},
+		{"132.5", 13250},
+		{"132.50", 13250},
+		{"132.05", 13205},
+		{".5", 50},
+		{"7.", 700},
+		{"-20", -2000},
+		{"-0.07", -7},
+		{" 47.5 ", 4750},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.value)
+		if err != nil {
+			t.Errorf("ParseAmount(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAmount(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmountRejectsInvalid(t *testing.T) {
+	for _, value := range []string{"1.234", "12a", "1,000", "--1", "1.-5", "+1"} {
+		if got, err := ParseAmount(value); err == nil {
+			t.Errorf("ParseAmount(%q) = %d, want an error", value, got)
+		}
+	}
+}
+
+func TestAmountRoundTrip(t *testing.T) {
+	for _, cents := range []int64{0, 1, 10, 99, 100, 101, 12550, -12550, 999999999} {
+		got, err := ParseAmount(FormatAmount(cents))
+		if err != nil || got != cents {
+			t.Errorf("ParseAmount(FormatAmount(%d)) = %d, %v", cents, got, err)
+		}
+	}
+}
+
+func TestParseTakesDelimitersFromISA(t *testing.T) {
+	file := "\ufeff" + strings.Join([]string{
+		"ISA|00|          |00|          |ZZ|SENDER         |ZZ|RECEIVER       |261015|1200|^|00501|000000001|0|T|>",
+		"ST|835|0001",
+		"SVC|HC>99213|200|132.5",
+		"IEA|1|000000001",
+	}, "\n") + "\n"
+
+	interchange, err := Parse(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(interchange.Segments) != 4 {
+		t.Fatalf("got %d segments, want 4", len(interchange.Segments))
+	}
+	svc := interchange.Segments[2]
+	if svc.ID != "SVC" || svc.Element(2) != "200" {
+		t.Errorf("SVC segment = %+v", svc)
+	}
+	if components := interchange.Components(svc.Element(1)); len(components) != 2 || components[1] != "99213" {
+		t.Errorf("SVC01 components = %q", components)
+	}
+}
+
+func TestParseRejectsIncompleteInterchanges(t *testing.T) {
+	isa := "ISA*00*          *00*          *ZZ*SENDER         *ZZ*RECEIVER       *261015*1200*^*00501*000000001*0*T*:~"
+	for name, file := range map[string]string{
+		"empty":   "",
+		"no ISA":  "GS*HP*A*B~",
+		"no IEA":  isa + "ST*835*0001~SE*2*0001~",
+		"cut ISA": isa[:60],
+	} {
+		if _, err := Parse(strings.NewReader(file)); err == nil {
+			t.Errorf("%s: Parse succeeded, want an error", name)
+		}
+	}
+}
+
+func TestWriterDropsTrailingEmptyElementsAndRejectsDelimiters(t *testing.T) {
+	var out strings.Builder
+	w := NewWriter(&out)
+	w.Segment("NM1", "QC", "1", "DOE", "", "")
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if got := out.String(); got != "NM1*QC*1*DOE~\n" {
+		t.Errorf("got %q", got)
+	}
+
+	w = NewWriter(&strings.Builder{})
+	w.Segment("NM1", "QC", "DOE*JANE")
+	if err := w.Flush(); err == nil {
+		t.Error("a delimiter in an element was accepted")
+	}
+}
+
+func TestClean(t *testing.T) {
+	if got := Clean(" o'brien*smith:jr~ ", 0); got != "O'BRIEN SMITH JR" {
+		t.Errorf("Clean = %q", got)
+	}
+	if got := Clean("abcdef", 3); got != "ABC" {
+		t.Errorf("Clean with max = %q", got)
+	}
+}
@@ -0,0 +1,269 @@
+// Package xlsx reads and writes the plain tabular subset of Office Open XML
+// spreadsheets: the text and number cells of one worksheet. Styles,
+// formulas and further sheets are ignored when reading and never written.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Relationship type of a workbook's worksheets
+const worksheetRelationship = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet"
+
+// The largest sheet spreadsheet applications allow, up to column XFD
+const (
+	maxColumns = 16384
+	maxRows    = 1048576
+)
+
+// ReadRows reads the cell values of the first worksheet of a workbook.
+// Rows[i] is spreadsheet row i+1, so rows left empty in the sheet come back
+// as nil rows; within a row, missing cells are empty strings. Numbers are
+// returned as written, so dates appear as serial numbers (see SerialTime).
+func ReadRows(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an XLSX workbook: %w", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("workbook has no worksheet %s", sheetPath)
+	}
+
+	var shared []string
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(file); err != nil {
+			return nil, err
+		}
+	}
+	return readSheet(sheet, shared)
+}
+
+// SerialTime converts a date serial number, days since 30 December 1899 as
+// spreadsheets in the 1900 date system count them, to a UTC time
+func SerialTime(serial float64) time.Time {
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	days := int(serial)
+	seconds := int((serial - float64(days)) * 86400)
+	return epoch.AddDate(0, 0, days).Add(time.Duration(seconds) * time.Second)
+}
+
+// firstSheetPath resolves the part name of the workbook's first sheet
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	var workbook struct {
+		Sheets []struct {
+			RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var relationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Type   string `xml:"Type,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	workbookFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("not an XLSX workbook: xl/workbook.xml is missing")
+	}
+	if err := decodePart(workbookFile, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+	relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	if err := decodePart(relsFile, &relationships); err != nil {
+		return "", err
+	}
+	for _, rel := range relationships.Relationships {
+		if rel.ID != workbook.Sheets[0].RelationshipID || rel.Type != worksheetRelationship {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+// sharedString is an <si> item: plain text, or rich text in runs
+type sharedString struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s sharedString) String() string {
+	if len(s.Runs) == 0 {
+		return s.Text
+	}
+	var b strings.Builder
+	for _, run := range s.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+func readSharedStrings(file *zip.File) ([]string, error) {
+	var table struct {
+		Items []sharedString `xml:"si"`
+	}
+	if err := decodePart(file, &table); err != nil {
+		return nil, err
+	}
+	shared := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		shared[i] = item.String()
+	}
+	return shared, nil
+}
+
+type sheetCell struct {
+	Ref    string       `xml:"r,attr"`
+	Type   string       `xml:"t,attr"`
+	Value  string       `xml:"v"`
+	Inline sharedString `xml:"is"`
+}
+
+type sheetRow struct {
+	Number int         `xml:"r,attr"`
+	Cells  []sheetCell `xml:"c"`
+}
+
+// readSheet streams the rows of a worksheet, so only the values are held
+// in memory
+func readSheet(file *zip.File, shared []string) ([][]string, error) {
+	part, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer part.Close()
+
+	var rows [][]string
+	decoder := xml.NewDecoder(part)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid worksheet: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row sheetRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("invalid worksheet: %w", err)
+		}
+		number := row.Number
+		if number <= 0 {
+			number = len(rows) + 1
+		}
+		if number > maxRows {
+			return nil, fmt.Errorf("invalid worksheet: row %d is beyond the last row", number)
+		}
+		if number < len(rows)+1 {
+			return nil, fmt.Errorf("invalid worksheet: row %d is out of order", number)
+		}
+		for len(rows) < number-1 {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for _, cell := range row.Cells {
+			column := len(values)
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			value, err := cellValue(cell, shared)
+			if err != nil {
+				return nil, err
+			}
+			for len(values) < column {
+				values = append(values, "")
+			}
+			if column < len(values) {
+				values[column] = value
+			} else {
+				values = append(values, value)
+			}
+		}
+		rows = append(rows, values)
+	}
+}
+
+func cellValue(cell sheetCell, shared []string) (string, error) {
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+		if err != nil || i < 0 || i >= len(shared) {
+			return "", fmt.Errorf("invalid worksheet: cell %s refers to a missing shared string", cell.Ref)
+		}
+		return shared[i], nil
+	case "inlineStr":
+		return cell.Inline.String(), nil
+	case "b":
+		if cell.Value == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	default:
+		// Numbers, formula results and error values
+		return cell.Value, nil
+	}
+}
+
+// columnIndex returns the 0-based column of a cell reference such as "AB12"
+func columnIndex(ref string) (int, error) {
+	column := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z' && column <= maxColumns; i++ {
+		column = column*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 || column > maxColumns {
+		return 0, fmt.Errorf("invalid worksheet: bad cell reference %q", ref)
+	}
+	return column - 1, nil
+}
+
+func decodePart(file *zip.File, v interface{}) error {
+	part, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer part.Close()
+	if err := xml.NewDecoder(part).Decode(v); err != nil {
+		return fmt.Errorf("invalid workbook part %s: %w", file.Name, err)
+	}
+	return nil
+}
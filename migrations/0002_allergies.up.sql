@@ -0,0 +1,22 @@
+-- Create allergies table
+CREATE TABLE IF NOT EXISTS allergies (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    nkda BOOLEAN NOT NULL DEFAULT FALSE,
+    substance VARCHAR(255),
+    substance_code VARCHAR(100),
+    code_system VARCHAR(100),
+    category VARCHAR(50) CHECK (category IN ('drug', 'food', 'environment', 'other')),
+    reaction TEXT,
+    severity VARCHAR(50) CHECK (severity IN ('', 'mild', 'moderate', 'severe', 'life_threatening')),
+    status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive', 'resolved', 'entered_in_error')),
+    notes TEXT,
+    recorded_by_id INTEGER NOT NULL REFERENCES users(id),
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create index on allergies patient_id for per-patient lookups
+CREATE INDEX idx_allergies_patient ON allergies(patient_id);
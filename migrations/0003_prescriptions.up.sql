@@ -0,0 +1,30 @@
+-- Create prescriptions table
+CREATE TABLE IF NOT EXISTS prescriptions (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    prescriber_id INTEGER NOT NULL REFERENCES users(id),
+    drug VARCHAR(255) NOT NULL,
+    drug_code VARCHAR(100),
+    strength VARCHAR(100) NOT NULL,
+    route VARCHAR(50) NOT NULL,
+    frequency VARCHAR(100) NOT NULL,
+    duration_days INTEGER NOT NULL CHECK (duration_days > 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    refills INTEGER NOT NULL DEFAULT 0 CHECK (refills BETWEEN 0 AND 12),
+    instructions TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'discontinued', 'completed')),
+    start_date TIMESTAMP WITH TIME ZONE,
+    end_date TIMESTAMP WITH TIME ZONE,
+    renewed_from_id INTEGER REFERENCES prescriptions(id),
+    discontinued_at TIMESTAMP WITH TIME ZONE,
+    discontinued_by_id INTEGER REFERENCES users(id),
+    discontinue_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes on prescriptions for per-patient and per-prescriber lookups
+CREATE INDEX idx_prescriptions_patient ON prescriptions(patient_id);
+CREATE INDEX idx_prescriptions_prescriber ON prescriptions(prescriber_id);
+CREATE INDEX idx_prescriptions_status ON prescriptions(status);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS interaction_alerts;
+DROP TABLE IF EXISTS drug_interactions;
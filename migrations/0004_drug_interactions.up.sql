@@ -0,0 +1,30 @@
+-- Create drug_interactions table holding the locally imported dataset
+CREATE TABLE IF NOT EXISTS drug_interactions (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL CHECK (kind IN ('drug_drug', 'drug_allergy')),
+    subject_a VARCHAR(255) NOT NULL,
+    subject_b VARCHAR(255) NOT NULL,
+    severity VARCHAR(50) NOT NULL CHECK (severity IN ('minor', 'moderate', 'major', 'contraindicated')),
+    description TEXT,
+    source VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on drug_interactions for pair lookups
+CREATE INDEX idx_drug_interactions_lookup ON drug_interactions(kind, subject_a, subject_b);
+
+-- Create interaction_alerts table recording alerts and overrides per prescription
+CREATE TABLE IF NOT EXISTS interaction_alerts (
+    id SERIAL PRIMARY KEY,
+    prescription_id INTEGER NOT NULL REFERENCES prescriptions(id),
+    kind VARCHAR(50) NOT NULL,
+    severity VARCHAR(50) NOT NULL,
+    interacting VARCHAR(255),
+    description TEXT,
+    override_reason TEXT,
+    acknowledged_by_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_interaction_alerts_prescription ON interaction_alerts(prescription_id);
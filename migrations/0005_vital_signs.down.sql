@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS care_team_members;
+DROP TABLE IF EXISTS vital_signs;
+DROP TABLE IF EXISTS encounters;
@@ -0,0 +1,73 @@
+-- Create encounters table
+CREATE TABLE IF NOT EXISTS encounters (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    doctor_id INTEGER NOT NULL REFERENCES users(id),
+    type VARCHAR(50) NOT NULL CHECK (type IN ('outpatient', 'inpatient', 'emergency', 'telehealth')),
+    status VARCHAR(50) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'finished', 'cancelled')),
+    reason TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_encounters_patient ON encounters(patient_id);
+CREATE INDEX idx_encounters_doctor ON encounters(doctor_id);
+
+-- Create vital_signs table
+CREATE TABLE IF NOT EXISTS vital_signs (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    encounter_id INTEGER REFERENCES encounters(id),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recorded_by_id INTEGER NOT NULL REFERENCES users(id),
+    systolic_bp INTEGER CHECK (systolic_bp BETWEEN 40 AND 300),
+    diastolic_bp INTEGER CHECK (diastolic_bp BETWEEN 20 AND 200),
+    heart_rate INTEGER CHECK (heart_rate BETWEEN 20 AND 300),
+    respiratory_rate INTEGER CHECK (respiratory_rate BETWEEN 2 AND 80),
+    temperature NUMERIC(4, 1) CHECK (temperature BETWEEN 25 AND 45),
+    sp_o2 INTEGER CHECK (sp_o2 BETWEEN 40 AND 100),
+    on_supplemental_oxygen BOOLEAN NOT NULL DEFAULT FALSE,
+    hypercapnic_failure BOOLEAN NOT NULL DEFAULT FALSE,
+    consciousness VARCHAR(50),
+    weight_kg NUMERIC(5, 2),
+    height_cm NUMERIC(5, 1),
+    bmi NUMERIC(4, 1),
+    news2_score INTEGER,
+    news2_risk VARCHAR(50),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create index on vital_signs for time-series range queries
+CREATE INDEX idx_vital_signs_patient_time ON vital_signs(patient_id, recorded_at);
+
+-- Create care_team_members table
+CREATE TABLE IF NOT EXISTS care_team_members (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    role VARCHAR(50) NOT NULL,
+    added_by_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_care_team_patient_user ON care_team_members(patient_id, user_id);
+
+-- Create notifications table
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    patient_id INTEGER REFERENCES patients(id),
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id);
@@ -0,0 +1,28 @@
+-- Create icd10_codes table holding the locally loaded code table
+CREATE TABLE IF NOT EXISTS icd10_codes (
+    code VARCHAR(8) PRIMARY KEY,
+    description TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create problems table
+CREATE TABLE IF NOT EXISTS problems (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    encounter_id INTEGER REFERENCES encounters(id),
+    icd10_code VARCHAR(8) NOT NULL REFERENCES icd10_codes(code),
+    description TEXT NOT NULL,
+    onset_date TIMESTAMP WITH TIME ZONE,
+    resolved_date TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(50) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'resolved')),
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    recorded_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_problems_patient ON problems(patient_id);
+CREATE INDEX idx_problems_icd10_code ON problems(icd10_code);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS lab_results;
+DROP TABLE IF EXISTS lab_orders;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('doctor', 'receptionist'));
@@ -0,0 +1,54 @@
+-- Allow the lab_technician role
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('doctor', 'receptionist', 'lab_technician'));
+
+-- Create lab_orders table
+CREATE TABLE IF NOT EXISTS lab_orders (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    encounter_id INTEGER REFERENCES encounters(id),
+    ordering_doctor_id INTEGER NOT NULL REFERENCES users(id),
+    test_code VARCHAR(50) NOT NULL,
+    test_name VARCHAR(255) NOT NULL,
+    priority VARCHAR(20) NOT NULL DEFAULT 'routine' CHECK (priority IN ('routine', 'urgent', 'stat')),
+    specimen_type VARCHAR(100) NOT NULL,
+    clinical_notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'ordered' CHECK (status IN ('ordered', 'collected', 'resulted', 'verified', 'cancelled')),
+    ordered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    collected_at TIMESTAMP WITH TIME ZONE,
+    collected_by_id INTEGER REFERENCES users(id),
+    resulted_at TIMESTAMP WITH TIME ZONE,
+    resulted_by_id INTEGER REFERENCES users(id),
+    verified_at TIMESTAMP WITH TIME ZONE,
+    verified_by_id INTEGER REFERENCES users(id),
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    cancel_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_lab_orders_patient ON lab_orders(patient_id);
+CREATE INDEX idx_lab_orders_doctor ON lab_orders(ordering_doctor_id);
+CREATE INDEX idx_lab_orders_status ON lab_orders(status);
+
+-- Create lab_results table
+CREATE TABLE IF NOT EXISTS lab_results (
+    id SERIAL PRIMARY KEY,
+    lab_order_id INTEGER NOT NULL REFERENCES lab_orders(id) ON DELETE CASCADE,
+    analyte_code VARCHAR(50),
+    analyte_name VARCHAR(255) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    numeric_value DOUBLE PRECISION,
+    unit VARCHAR(50),
+    ref_low DOUBLE PRECISION,
+    ref_high DOUBLE PRECISION,
+    critical_low DOUBLE PRECISION,
+    critical_high DOUBLE PRECISION,
+    flag VARCHAR(20),
+    comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_lab_results_order ON lab_results(lab_order_id);
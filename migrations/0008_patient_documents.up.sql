@@ -0,0 +1,19 @@
+-- Create patient_documents table; file content lives in blob storage
+CREATE TABLE IF NOT EXISTS patient_documents (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    category VARCHAR(50) NOT NULL CHECK (category IN ('referral', 'consent', 'imaging_report', 'lab_report', 'other')),
+    title VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'clinical' CHECK (visibility IN ('all_staff', 'clinical', 'doctors')),
+    uploaded_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_patient_documents_patient ON patient_documents(patient_id);
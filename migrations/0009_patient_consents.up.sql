@@ -0,0 +1,20 @@
+-- Create patient_consents table; the newest record of a type is the current decision
+CREATE TABLE IF NOT EXISTS patient_consents (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('data_sharing', 'research', 'sms_contact', 'treatment')),
+    version VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('granted', 'withdrawn')),
+    granted_at TIMESTAMP WITH TIME ZONE,
+    withdrawn_at TIMESTAMP WITH TIME ZONE,
+    witness_name VARCHAR(255),
+    witness_user_id INTEGER REFERENCES users(id),
+    document_id INTEGER REFERENCES patient_documents(id),
+    recorded_by_id INTEGER NOT NULL REFERENCES users(id),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_patient_consents_lookup ON patient_consents(patient_id, type);
@@ -0,0 +1,20 @@
+-- Create patient_contacts table for guardians, next of kin and emergency contacts
+CREATE TABLE IF NOT EXISTS patient_contacts (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    name VARCHAR(255) NOT NULL,
+    relationship VARCHAR(50) NOT NULL,
+    phone_number VARCHAR(100) NOT NULL,
+    address TEXT,
+    is_guardian BOOLEAN NOT NULL DEFAULT FALSE,
+    is_next_of_kin BOOLEAN NOT NULL DEFAULT FALSE,
+    is_emergency_contact BOOLEAN NOT NULL DEFAULT FALSE,
+    may_receive_information BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CHECK (is_guardian OR is_next_of_kin OR is_emergency_contact)
+);
+
+CREATE INDEX idx_patient_contacts_patient ON patient_contacts(patient_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_patients_contact_review;
+ALTER TABLE patients DROP COLUMN IF EXISTS contact_review_reason;
+ALTER TABLE patients DROP COLUMN IF EXISTS contact_review_required;
+ALTER TABLE patients DROP COLUMN IF EXISTS address_country;
+ALTER TABLE patients DROP COLUMN IF EXISTS address_postal_code;
+ALTER TABLE patients DROP COLUMN IF EXISTS address_region;
+ALTER TABLE patients DROP COLUMN IF EXISTS address_city;
+ALTER TABLE patients DROP COLUMN IF EXISTS address_line2;
+ALTER TABLE patients DROP COLUMN IF EXISTS address_line1;
+ALTER TABLE patients ALTER COLUMN address DROP DEFAULT;
@@ -0,0 +1,18 @@
+-- Structured address components; the free-text address column is kept for
+-- records that have not been parsed yet. Parsing addresses and phone numbers
+-- needs the offline phone metadata, so it is not done in SQL: the contacts
+-- backfill must run after this migration. `make migrate-up` and the server's
+-- startup both run it; `make backfill task=contacts` runs it on its own.
+ALTER TABLE patients ALTER COLUMN address SET DEFAULT '';
+ALTER TABLE patients ADD COLUMN address_line1 VARCHAR(255);
+ALTER TABLE patients ADD COLUMN address_line2 VARCHAR(255);
+ALTER TABLE patients ADD COLUMN address_city VARCHAR(100);
+ALTER TABLE patients ADD COLUMN address_region VARCHAR(100);
+ALTER TABLE patients ADD COLUMN address_postal_code VARCHAR(20);
+ALTER TABLE patients ADD COLUMN address_country CHAR(2);
+
+-- Records whose address or phone number could not be parsed
+ALTER TABLE patients ADD COLUMN contact_review_required BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE patients ADD COLUMN contact_review_reason TEXT;
+
+CREATE INDEX idx_patients_contact_review ON patients(contact_review_required) WHERE contact_review_required;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS insurance_coverages;
+DROP TABLE IF EXISTS payers;
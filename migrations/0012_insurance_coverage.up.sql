@@ -0,0 +1,35 @@
+-- Create payers table; the catalogue is loaded with `make import dataset=payers`
+CREATE TABLE IF NOT EXISTS payers (
+    code VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    edi_payer_id VARCHAR(20),
+    member_id_pattern VARCHAR(255),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create insurance_coverages table
+CREATE TABLE IF NOT EXISTS insurance_coverages (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    payer_code VARCHAR(20) NOT NULL REFERENCES payers(code),
+    plan_name VARCHAR(255) NOT NULL,
+    member_id VARCHAR(50) NOT NULL,
+    group_number VARCHAR(50),
+    subscriber_relationship VARCHAR(20) NOT NULL DEFAULT 'self' CHECK (subscriber_relationship IN ('self', 'spouse', 'child', 'other')),
+    subscriber_name VARCHAR(255),
+    priority SMALLINT NOT NULL CHECK (priority BETWEEN 1 AND 3),
+    effective_from DATE NOT NULL,
+    effective_to DATE,
+    eligibility_status VARCHAR(20) NOT NULL DEFAULT 'unknown' CHECK (eligibility_status IN ('unknown', 'eligible', 'ineligible', 'error')),
+    eligibility_message TEXT,
+    eligibility_checked_at TIMESTAMP WITH TIME ZONE,
+    recorded_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    CHECK (effective_to IS NULL OR effective_to >= effective_from)
+);
+
+CREATE INDEX idx_insurance_coverages_patient ON insurance_coverages(patient_id);
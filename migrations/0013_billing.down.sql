@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS invoice_lines;
+DROP TABLE IF EXISTS charges;
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS charge_master_items;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('doctor', 'receptionist', 'lab_technician'));
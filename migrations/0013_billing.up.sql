@@ -0,0 +1,100 @@
+-- Allow the billing role
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('doctor', 'receptionist', 'lab_technician', 'billing'));
+
+-- Create charge_master_items table; all amounts in billing are in cents
+CREATE TABLE IF NOT EXISTS charge_master_items (
+    code VARCHAR(20) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL,
+    cpt_code VARCHAR(10),
+    price BIGINT NOT NULL CHECK (price >= 0),
+    taxable BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create invoices table
+CREATE TABLE IF NOT EXISTS invoices (
+    id SERIAL PRIMARY KEY,
+    number VARCHAR(20) UNIQUE,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'issued' CHECK (status IN ('issued', 'paid', 'voided')),
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    discount_percent NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    tax_rate_percent NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    subtotal BIGINT NOT NULL,
+    discount_total BIGINT NOT NULL,
+    tax_total BIGINT NOT NULL,
+    total BIGINT NOT NULL,
+    amount_paid BIGINT NOT NULL DEFAULT 0,
+    balance BIGINT NOT NULL,
+    issued_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_invoices_patient ON invoices(patient_id);
+
+-- Create charges table
+CREATE TABLE IF NOT EXISTS charges (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    encounter_id INTEGER NOT NULL REFERENCES encounters(id),
+    service_code VARCHAR(20) NOT NULL REFERENCES charge_master_items(code),
+    description VARCHAR(255) NOT NULL,
+    cpt_code VARCHAR(10),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    taxable BOOLEAN NOT NULL DEFAULT FALSE,
+    service_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'posted' CHECK (status IN ('posted', 'voided')),
+    invoice_id INTEGER REFERENCES invoices(id),
+    posted_by_id INTEGER NOT NULL REFERENCES users(id),
+    void_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_charges_patient ON charges(patient_id);
+CREATE INDEX idx_charges_encounter ON charges(encounter_id);
+CREATE INDEX idx_charges_invoice ON charges(invoice_id);
+
+-- Create invoice_lines table
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    id SERIAL PRIMARY KEY,
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    charge_id INTEGER NOT NULL REFERENCES charges(id),
+    description VARCHAR(255) NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_price BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    discount BIGINT NOT NULL,
+    tax BIGINT NOT NULL,
+    total BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invoice_lines_invoice ON invoice_lines(invoice_id);
+
+-- Create payments table; refunds are stored with kind 'refund' and a positive amount
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    invoice_id INTEGER NOT NULL REFERENCES invoices(id),
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('payment', 'refund')),
+    method VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card', 'check', 'insurance', 'other')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    reference VARCHAR(100),
+    notes TEXT,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    recorded_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payments_invoice ON payments(invoice_id);
+CREATE INDEX idx_payments_received_at ON payments(received_at);
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS claim_adjustments;
+DROP TABLE IF EXISTS insurance_claims;
+DROP TABLE IF EXISTS remittances;
+DROP TABLE IF EXISTS claim_batches;
+
+DELETE FROM payments WHERE kind = 'adjustment';
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_kind_check;
+ALTER TABLE payments ADD CONSTRAINT payments_kind_check CHECK (kind IN ('payment', 'refund'));
+ALTER TABLE invoices DROP COLUMN IF EXISTS amount_adjusted;
+ALTER TABLE payers DROP COLUMN IF EXISTS claim_filing_code;
@@ -0,0 +1,74 @@
+-- Claim filing indicator sent in SBR09 of 837P claims
+ALTER TABLE payers ADD COLUMN IF NOT EXISTS claim_filing_code VARCHAR(2) NOT NULL DEFAULT 'CI';
+
+-- Payer adjustments written off an invoice are tracked separately from payments
+ALTER TABLE invoices ADD COLUMN IF NOT EXISTS amount_adjusted BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_kind_check;
+ALTER TABLE payments ADD CONSTRAINT payments_kind_check CHECK (kind IN ('payment', 'refund', 'adjustment'));
+
+-- Create claim_batches table; one row per generated 837P file
+CREATE TABLE IF NOT EXISTS claim_batches (
+    id SERIAL PRIMARY KEY,
+    file_name VARCHAR(255),
+    storage_key VARCHAR(512),
+    claim_count INTEGER NOT NULL,
+    total_charge BIGINT NOT NULL,
+    created_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create remittances table; one row per 835 transaction
+CREATE TABLE IF NOT EXISTS remittances (
+    id SERIAL PRIMARY KEY,
+    file_name VARCHAR(255),
+    storage_key VARCHAR(512),
+    trace_number VARCHAR(50) NOT NULL UNIQUE,
+    payer_name VARCHAR(255),
+    payment_date TIMESTAMP WITH TIME ZONE,
+    total_paid BIGINT NOT NULL,
+    claims_matched INTEGER NOT NULL DEFAULT 0,
+    claims_unmatched INTEGER NOT NULL DEFAULT 0,
+    imported_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create insurance_claims table
+CREATE TABLE IF NOT EXISTS insurance_claims (
+    id SERIAL PRIMARY KEY,
+    batch_id INTEGER NOT NULL REFERENCES claim_batches(id) ON DELETE CASCADE,
+    encounter_id INTEGER NOT NULL REFERENCES encounters(id),
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    coverage_id INTEGER NOT NULL REFERENCES insurance_coverages(id),
+    payer_code VARCHAR(20) NOT NULL REFERENCES payers(code),
+    control_number VARCHAR(20) UNIQUE,
+    total_charge BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'submitted' CHECK (status IN ('submitted', 'paid', 'partially_paid', 'denied')),
+    paid_amount BIGINT NOT NULL DEFAULT 0,
+    adjustment_amount BIGINT NOT NULL DEFAULT 0,
+    patient_responsibility BIGINT NOT NULL DEFAULT 0,
+    payer_claim_number VARCHAR(50),
+    remittance_id INTEGER REFERENCES remittances(id),
+    adjudicated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_insurance_claims_batch ON insurance_claims(batch_id);
+CREATE INDEX idx_insurance_claims_encounter ON insurance_claims(encounter_id);
+CREATE INDEX idx_insurance_claims_patient ON insurance_claims(patient_id);
+
+-- Create claim_adjustments table; CAS adjustments reported per claim
+CREATE TABLE IF NOT EXISTS claim_adjustments (
+    id SERIAL PRIMARY KEY,
+    claim_id INTEGER NOT NULL REFERENCES insurance_claims(id) ON DELETE CASCADE,
+    remittance_id INTEGER NOT NULL REFERENCES remittances(id),
+    group_code VARCHAR(2) NOT NULL CHECK (group_code IN ('CO', 'PR', 'OA', 'PI')),
+    reason_code VARCHAR(10) NOT NULL,
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_claim_adjustments_claim ON claim_adjustments(claim_id);
+CREATE INDEX idx_claim_adjustments_remittance ON claim_adjustments(remittance_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS admission_bed_movements;
+DROP TABLE IF EXISTS admissions;
+DROP TABLE IF EXISTS beds;
+DROP TABLE IF EXISTS rooms;
+DROP TABLE IF EXISTS wards;
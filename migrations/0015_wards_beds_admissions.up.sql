@@ -0,0 +1,73 @@
+-- Create wards table
+CREATE TABLE IF NOT EXISTS wards (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    floor VARCHAR(20),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create rooms table
+CREATE TABLE IF NOT EXISTS rooms (
+    id SERIAL PRIMARY KEY,
+    ward_id INTEGER NOT NULL REFERENCES wards(id),
+    number VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_rooms_ward_number UNIQUE (ward_id, number)
+);
+
+-- Create beds table; patient_id is set while the bed is occupied
+CREATE TABLE IF NOT EXISTS beds (
+    id SERIAL PRIMARY KEY,
+    ward_id INTEGER NOT NULL REFERENCES wards(id),
+    room_id INTEGER NOT NULL REFERENCES rooms(id),
+    label VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'occupied', 'cleaning', 'blocked')),
+    status_note TEXT,
+    patient_id INTEGER REFERENCES patients(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_beds_room_label UNIQUE (room_id, label),
+    CHECK ((status = 'occupied') = (patient_id IS NOT NULL))
+);
+
+CREATE INDEX idx_beds_ward_id ON beds(ward_id);
+
+-- Create admissions table
+CREATE TABLE IF NOT EXISTS admissions (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    encounter_id INTEGER NOT NULL REFERENCES encounters(id),
+    attending_doctor_id INTEGER NOT NULL REFERENCES users(id),
+    bed_id INTEGER REFERENCES beds(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'admitted' CHECK (status IN ('admitted', 'discharged')),
+    reason TEXT,
+    admitted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    admitted_by_id INTEGER NOT NULL REFERENCES users(id),
+    discharged_at TIMESTAMP WITH TIME ZONE,
+    discharged_by_id INTEGER REFERENCES users(id),
+    disposition VARCHAR(20),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_admissions_patient_id ON admissions(patient_id);
+CREATE INDEX idx_admissions_attending_doctor_id ON admissions(attending_doctor_id);
+-- A patient has at most one current admission and a bed at most one patient
+CREATE UNIQUE INDEX idx_admissions_one_active ON admissions(patient_id) WHERE status = 'admitted';
+CREATE UNIQUE INDEX idx_admissions_bed ON admissions(bed_id) WHERE bed_id IS NOT NULL;
+
+-- Create admission_bed_movements table
+CREATE TABLE IF NOT EXISTS admission_bed_movements (
+    id SERIAL PRIMARY KEY,
+    admission_id INTEGER NOT NULL REFERENCES admissions(id) ON DELETE CASCADE,
+    bed_id INTEGER NOT NULL REFERENCES beds(id),
+    from_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    to_time TIMESTAMP WITH TIME ZONE,
+    reason TEXT,
+    moved_by_id INTEGER NOT NULL REFERENCES users(id)
+);
+
+CREATE INDEX idx_admission_bed_movements_admission_id ON admission_bed_movements(admission_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS discharge_summary_items;
+DROP TABLE IF EXISTS discharge_summaries;
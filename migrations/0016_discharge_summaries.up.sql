@@ -0,0 +1,35 @@
+-- Create discharge_summaries table; one summary per admission, frozen once signed
+CREATE TABLE IF NOT EXISTS discharge_summaries (
+    id SERIAL PRIMARY KEY,
+    admission_id INTEGER NOT NULL UNIQUE REFERENCES admissions(id),
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    attending_doctor_id INTEGER NOT NULL REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'signed')),
+    admitted_at TIMESTAMP WITH TIME ZONE,
+    discharged_at TIMESTAMP WITH TIME ZONE,
+    disposition VARCHAR(20),
+    admission_reason TEXT,
+    hospital_course TEXT,
+    follow_up TEXT,
+    signed_at TIMESTAMP WITH TIME ZONE,
+    signed_by_id INTEGER REFERENCES users(id),
+    content_hash VARCHAR(64),
+    created_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_discharge_summaries_patient_id ON discharge_summaries(patient_id);
+
+-- Create discharge_summary_items table
+CREATE TABLE IF NOT EXISTS discharge_summary_items (
+    id SERIAL PRIMARY KEY,
+    summary_id INTEGER NOT NULL REFERENCES discharge_summaries(id) ON DELETE CASCADE,
+    section VARCHAR(20) NOT NULL CHECK (section IN ('diagnosis', 'procedure', 'medication')),
+    position INTEGER NOT NULL,
+    code VARCHAR(50),
+    description TEXT NOT NULL,
+    detail TEXT
+);
+
+CREATE INDEX idx_discharge_summary_items_summary_id ON discharge_summary_items(summary_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS queue_entries;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('doctor', 'receptionist', 'lab_technician', 'billing'));
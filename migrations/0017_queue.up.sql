@@ -0,0 +1,33 @@
+-- Allow the nurse role
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('doctor', 'receptionist', 'lab_technician', 'billing', 'nurse'));
+
+-- Create queue_entries table; triage_level is the ESI level, 1 being the most urgent
+CREATE TABLE IF NOT EXISTS queue_entries (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    source VARCHAR(20) NOT NULL CHECK (source IN ('appointment', 'walk_in')),
+    appointment_at TIMESTAMP WITH TIME ZONE,
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'in_progress', 'completed', 'left', 'cancelled')),
+    checked_in_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    checked_in_by_id INTEGER NOT NULL REFERENCES users(id),
+    triage_level INTEGER CHECK (triage_level BETWEEN 1 AND 5),
+    triage_notes TEXT,
+    triaged_at TIMESTAMP WITH TIME ZONE,
+    triaged_by_id INTEGER REFERENCES users(id),
+    called_at TIMESTAMP WITH TIME ZONE,
+    doctor_id INTEGER REFERENCES users(id),
+    encounter_id INTEGER REFERENCES encounters(id),
+    closed_at TIMESTAMP WITH TIME ZONE,
+    close_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_queue_entries_patient_id ON queue_entries(patient_id);
+CREATE INDEX idx_queue_entries_status ON queue_entries(status);
+CREATE INDEX idx_queue_entries_checked_in_at ON queue_entries(checked_in_at);
+
+-- A patient can only be in the queue once at a time
+CREATE UNIQUE INDEX idx_queue_entries_one_active_per_patient ON queue_entries(patient_id) WHERE status IN ('waiting', 'in_progress');
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS immunizations;
+
+ALTER TABLE patients DROP COLUMN IF EXISTS date_of_birth;
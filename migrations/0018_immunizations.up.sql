@@ -0,0 +1,27 @@
+-- Record dates of birth; age is derived from it when present
+ALTER TABLE patients ADD COLUMN IF NOT EXISTS date_of_birth DATE;
+
+-- Create immunizations table; historical records transcribe doses given
+-- elsewhere and have no administering user
+CREATE TABLE IF NOT EXISTS immunizations (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    vaccine_code VARCHAR(10) NOT NULL,
+    vaccine_name VARCHAR(255),
+    dose_number INTEGER NOT NULL CHECK (dose_number >= 1),
+    lot_number VARCHAR(50),
+    site VARCHAR(20) CHECK (site IN ('left_arm', 'right_arm', 'left_thigh', 'right_thigh', 'oral', 'nasal')),
+    administered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    administered_by_id INTEGER REFERENCES users(id),
+    historical BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'completed' CHECK (status IN ('completed', 'entered_in_error')),
+    status_reason TEXT,
+    notes TEXT,
+    recorded_by_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (historical OR administered_by_id IS NOT NULL)
+);
+
+CREATE INDEX idx_immunizations_patient_id ON immunizations(patient_id);
+CREATE INDEX idx_immunizations_vaccine_code ON immunizations(vaccine_code);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS referral_documents;
+DROP TABLE IF EXISTS referrals;
@@ -0,0 +1,33 @@
+-- Create referrals table; external referrals have no receiving doctor
+CREATE TABLE IF NOT EXISTS referrals (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    referring_doctor_id INTEGER NOT NULL REFERENCES users(id),
+    receiving_doctor_id INTEGER REFERENCES users(id),
+    external_provider VARCHAR(255),
+    external_contact TEXT,
+    specialty VARCHAR(100),
+    reason TEXT NOT NULL,
+    urgency VARCHAR(20) NOT NULL DEFAULT 'routine' CHECK (urgency IN ('routine', 'urgent', 'emergency')),
+    status VARCHAR(20) NOT NULL DEFAULT 'sent' CHECK (status IN ('sent', 'accepted', 'declined', 'completed')),
+    sent_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    response_note TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    outcome TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((receiving_doctor_id IS NULL) <> (COALESCE(external_provider, '') = ''))
+);
+
+CREATE INDEX idx_referrals_patient_id ON referrals(patient_id);
+CREATE INDEX idx_referrals_referring_doctor_id ON referrals(referring_doctor_id);
+CREATE INDEX idx_referrals_receiving_doctor_id ON referrals(receiving_doctor_id);
+CREATE INDEX idx_referrals_status ON referrals(status);
+
+-- Documents attached to a referral
+CREATE TABLE IF NOT EXISTS referral_documents (
+    referral_id INTEGER NOT NULL REFERENCES referrals(id) ON DELETE CASCADE,
+    patient_document_id INTEGER NOT NULL REFERENCES patient_documents(id),
+    PRIMARY KEY (referral_id, patient_document_id)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_patients_updated_at;
+DROP TABLE IF EXISTS bulk_export_files;
+DROP TABLE IF EXISTS bulk_export_jobs;
@@ -0,0 +1,34 @@
+-- Create FHIR bulk export jobs and the NDJSON files they write
+CREATE TABLE IF NOT EXISTS bulk_export_jobs (
+    id SERIAL PRIMARY KEY,
+    requested_by_id INTEGER NOT NULL REFERENCES users(id),
+    request TEXT NOT NULL,
+    resource_types TEXT NOT NULL,
+    since TIMESTAMP WITH TIME ZONE,
+    transaction_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed', 'failed', 'cancelled')),
+    progress VARCHAR(255),
+    error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_export_jobs_requested_by_id ON bulk_export_jobs(requested_by_id);
+CREATE INDEX idx_bulk_export_jobs_status ON bulk_export_jobs(status);
+
+CREATE TABLE IF NOT EXISTS bulk_export_files (
+    id SERIAL PRIMARY KEY,
+    job_id INTEGER NOT NULL REFERENCES bulk_export_jobs(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    count INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_export_files_job_id ON bulk_export_files(job_id);
+
+-- Incremental exports filter on updated_at
+CREATE INDEX IF NOT EXISTS idx_patients_updated_at ON patients(updated_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS hl7_dead_letters;
+DROP TABLE IF EXISTS patient_identifiers;
@@ -0,0 +1,29 @@
+-- Create identifiers other systems know patients by, and the dead-letter
+-- table for HL7 ADT messages that were not applied
+CREATE TABLE IF NOT EXISTS patient_identifiers (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    system VARCHAR(255) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_patient_identifiers_patient_id ON patient_identifiers(patient_id);
+CREATE UNIQUE INDEX idx_patient_identifiers_system_value ON patient_identifiers(system, value);
+
+CREATE TABLE IF NOT EXISTS hl7_dead_letters (
+    id SERIAL PRIMARY KEY,
+    control_id VARCHAR(255),
+    message_type VARCHAR(50),
+    sender VARCHAR(255),
+    remote_addr VARCHAR(255),
+    ack_code VARCHAR(2) NOT NULL CHECK (ack_code IN ('AE', 'AR')),
+    error_code VARCHAR(10),
+    error TEXT NOT NULL,
+    message TEXT NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_hl7_dead_letters_control_id ON hl7_dead_letters(control_id);
+CREATE INDEX idx_hl7_dead_letters_received_at ON hl7_dead_letters(received_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS patient_import_errors;
+DROP TABLE IF EXISTS patient_import_jobs;
@@ -0,0 +1,32 @@
+-- Create bulk patient import jobs and their per-row error reports
+CREATE TABLE IF NOT EXISTS patient_import_jobs (
+    id SERIAL PRIMARY KEY,
+    requested_by_id INTEGER NOT NULL REFERENCES users(id),
+    file_name VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'xlsx')),
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    identifier_system VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed', 'failed')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    valid_rows INTEGER NOT NULL DEFAULT 0,
+    imported_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_patient_import_jobs_requested_by_id ON patient_import_jobs(requested_by_id);
+CREATE INDEX idx_patient_import_jobs_status ON patient_import_jobs(status);
+
+CREATE TABLE IF NOT EXISTS patient_import_errors (
+    id SERIAL PRIMARY KEY,
+    job_id INTEGER NOT NULL REFERENCES patient_import_jobs(id) ON DELETE CASCADE,
+    row INTEGER NOT NULL,
+    field VARCHAR(50),
+    message TEXT NOT NULL
+);
+
+CREATE INDEX idx_patient_import_errors_job_id ON patient_import_errors(job_id);
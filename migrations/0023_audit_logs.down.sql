@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_patients_address_city;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,21 @@
+-- Create the audit log of data leaving the system, starting with patient
+-- list exports
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    role VARCHAR(50) NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    details TEXT,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('started', 'completed', 'failed')),
+    error TEXT,
+    remote_addr VARCHAR(255),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_logs_user_id ON audit_logs(user_id);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
+
+CREATE INDEX idx_patients_address_city ON patients(LOWER(address_city));
@@ -42,7 +42,7 @@ CREATE TABLE IF NOT EXISTS users (
     name VARCHAR(255) NOT NULL,
     email VARCHAR(255) NOT NULL UNIQUE,
     password VARCHAR(255) NOT NULL,
-    role VARCHAR(50) NOT NULL CHECK (role IN ('doctor', 'receptionist')),
+    role VARCHAR(50) NOT NULL CHECK (role IN ('doctor', 'receptionist', 'lab_technician', 'billing', 'nurse', 'admin')),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP WITH TIME ZONE
@@ -79,6 +79,10 @@ WHERE NOT EXISTS (SELECT 1 FROM users WHERE email = 'doctor@example.com');
 INSERT INTO users (name, email, password, role)
 SELECT 'Admin Receptionist', 'receptionist@example.com', '\$2a\$10\$NqRvFBJbhYY5XKHMfA9XJu2dTd5QPjJhPfUo5zuYmOW.mSZ9ThAGu', 'receptionist'
 WHERE NOT EXISTS (SELECT 1 FROM users WHERE email = 'receptionist@example.com');
+
+INSERT INTO users (name, email, password, role)
+SELECT 'Admin', 'admin@example.com', '\$2a\$10\$NqRvFBJbhYY5XKHMfA9XJu2dTd5QPjJhPfUo5zuYmOW.mSZ9ThAGu', 'admin'
+WHERE NOT EXISTS (SELECT 1 FROM users WHERE email = 'admin@example.com');
 "
 
 # Seed sample data